@@ -6,15 +6,16 @@ import (
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
 	"n3-solana-test/client"
+	"n3-solana-test/keystore"
 	"testing"
 )
 
 var (
 	// Define your variables
 	ProgramID    = solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy")
-	RPC_ENDPOINT = "https://api.devnet.solana.com"                                                     // Or your preferred endpoint
-	provider, _  = solana.PrivateKeyFromSolanaKeygenFile("/Users/kevinsheeran/.config/solana/id.json") // Generate or load your provider keypair
-	admin, _     = solana.PrivateKeyFromSolanaKeygenFile("/Users/kevinsheeran/.config/solana/id.json") // Generate or load your admin keypair
+	RPC_ENDPOINT = "https://api.devnet.solana.com" // Or your preferred endpoint
+	provider, _  = keystore.FromEnv("provider-0")
+	admin, _     = keystore.FromEnv("admin")
 	//providerTokenAccount solana.PublicKey                  // Set your provider token account
 	//mint                 solana.PublicKey                  // Set your token mint
 	mint                 = solana.MustPublicKeyFromBase58("4vSVTKJtE1Fmr5z9HgDuh4d7yw93PUQXXVUDp23vXtG5") // Your token mint address
@@ -0,0 +1,293 @@
+// Package amount holds token amounts bound to the decimals of their mint.
+// An Amount is a count of base units, as the program and the token program
+// store it, with the decimals it is written in: Parse("12.5", 9) is
+// 12_500_000_000 units. Parsing, formatting and arithmetic are exact and fail
+// rather than round, wrap or mix decimals.
+package amount
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/client"
+	"n3-solana-test/rpcclient"
+)
+
+// MaxDecimals is the largest number of decimals a uint64 amount can carry.
+const MaxDecimals = 19
+
+var (
+	// ErrOverflow is returned when an amount does not fit in 64 bits.
+	ErrOverflow = errors.New("amount overflows u64")
+	// ErrNegative is returned when a subtraction would go below zero.
+	ErrNegative = errors.New("amount is negative")
+	// ErrPrecision is returned for strings with more fractional digits than
+	// the mint has decimals.
+	ErrPrecision = errors.New("amount has more decimals than the mint")
+	// ErrDecimals is returned when combining amounts of different decimals.
+	ErrDecimals = errors.New("amounts have different decimals")
+	// ErrSyntax is returned for strings that are not decimal numbers.
+	ErrSyntax = errors.New("invalid amount")
+)
+
+// Amount is a token amount: Units base units of a mint with Decimals
+// decimals. The zero value is zero units of a mint without decimals.
+type Amount struct {
+	units    uint64
+	decimals uint8
+}
+
+// New returns the amount of units base units.
+func New(units uint64, decimals uint8) Amount {
+	return Amount{units: units, decimals: decimals}
+}
+
+// Tokens returns the amount of whole tokens, whole × 10^decimals units.
+func Tokens(whole uint64, decimals uint8) (Amount, error) {
+	scale, err := pow10(decimals)
+	if err != nil {
+		return Amount{}, err
+	}
+	hi, lo := bits.Mul64(whole, scale)
+	if hi != 0 {
+		return Amount{}, fmt.Errorf("%d tokens: %w", whole, ErrOverflow)
+	}
+	return New(lo, decimals), nil
+}
+
+// MustTokens is Tokens for constants; it panics on overflow.
+func MustTokens(whole uint64, decimals uint8) Amount {
+	a, err := Tokens(whole, decimals)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Parse reads a decimal token amount such as "12.5", "0.000000001" or "7".
+// It fails with ErrPrecision rather than round when s has more fractional
+// digits than decimals, so "0.0000000001" does not parse with 9 decimals.
+func Parse(s string, decimals uint8) (Amount, error) {
+	if _, err := pow10(decimals); err != nil {
+		return Amount{}, err
+	}
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" && frac == "" || !digits(whole) || !digits(frac) || hasPoint && frac == "" {
+		return Amount{}, fmt.Errorf("%q: %w", s, ErrSyntax)
+	}
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > int(decimals) {
+		return Amount{}, fmt.Errorf("%q: %w (%d)", s, ErrPrecision, decimals)
+	}
+	units := strings.TrimLeft(whole+frac+strings.Repeat("0", int(decimals)-len(frac)), "0")
+	if units == "" {
+		return New(0, decimals), nil
+	}
+	n, err := strconv.ParseUint(units, 10, 64)
+	if err != nil {
+		return Amount{}, fmt.Errorf("%q: %w", s, ErrOverflow)
+	}
+	return New(n, decimals), nil
+}
+
+func digits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func pow10(decimals uint8) (uint64, error) {
+	if decimals > MaxDecimals {
+		return 0, fmt.Errorf("%d decimals: %w", decimals, ErrOverflow)
+	}
+	scale := uint64(1)
+	for i := uint8(0); i < decimals; i++ {
+		scale *= 10
+	}
+	return scale, nil
+}
+
+// Units returns the amount in base units.
+func (a Amount) Units() uint64 {
+	return a.units
+}
+
+// Decimals returns the decimals of the mint.
+func (a Amount) Decimals() uint8 {
+	return a.decimals
+}
+
+// IsZero reports whether a is zero units.
+func (a Amount) IsZero() bool {
+	return a.units == 0
+}
+
+// String formats a in tokens without trailing fractional zeros, as the
+// uiAmountString of getTokenAccountBalance: "12.5", "0.000000001", "7".
+func (a Amount) String() string {
+	s := a.Fixed()
+	if a.decimals == 0 {
+		return s
+	}
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// Fixed formats a in tokens with all of its decimals: "12.500000000".
+func (a Amount) Fixed() string {
+	s := strconv.FormatUint(a.units, 10)
+	if a.decimals == 0 {
+		return s
+	}
+	if pad := int(a.decimals) + 1 - len(s); pad > 0 {
+		s = strings.Repeat("0", pad) + s
+	}
+	point := len(s) - int(a.decimals)
+	return s[:point] + "." + s[point:]
+}
+
+// Add returns a + b.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if a.decimals != b.decimals {
+		return Amount{}, fmt.Errorf("%s + %s: %w", a, b, ErrDecimals)
+	}
+	sum, carry := bits.Add64(a.units, b.units, 0)
+	if carry != 0 {
+		return Amount{}, fmt.Errorf("%s + %s: %w", a, b, ErrOverflow)
+	}
+	return New(sum, a.decimals), nil
+}
+
+// Sub returns a - b.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if a.decimals != b.decimals {
+		return Amount{}, fmt.Errorf("%s - %s: %w", a, b, ErrDecimals)
+	}
+	if b.units > a.units {
+		return Amount{}, fmt.Errorf("%s - %s: %w", a, b, ErrNegative)
+	}
+	return New(a.units-b.units, a.decimals), nil
+}
+
+// Mul returns a × n, such as the stake of a device: the staking coefficient
+// times its k-value.
+func (a Amount) Mul(n uint64) (Amount, error) {
+	hi, lo := bits.Mul64(a.units, n)
+	if hi != 0 {
+		return Amount{}, fmt.Errorf("%s × %d: %w", a, n, ErrOverflow)
+	}
+	return New(lo, a.decimals), nil
+}
+
+// Cmp compares a and b by units, returning -1, 0 or +1; amounts of
+// different decimals are compared by value.
+func (a Amount) Cmp(b Amount) int {
+	if a.decimals == b.decimals {
+		return cmp.Compare(a.units, b.units)
+	}
+	return scaled(a, b.decimals).Cmp(scaled(b, a.decimals))
+}
+
+// scaled returns the units of a with max(a.decimals, decimals) decimals.
+func scaled(a Amount, decimals uint8) *big.Int {
+	n := new(big.Int).SetUint64(a.units)
+	if decimals <= a.decimals {
+		return n
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals-a.decimals)), nil)
+	return n.Mul(n, scale)
+}
+
+// MarshalJSON encodes a as {"amount": units, "decimals": n, "ui": tokens},
+// with units and tokens as strings.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonAmount{Units: strconv.FormatUint(a.units, 10), Decimals: a.decimals, UI: a.String()})
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON, ignoring "ui".
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var in jsonAmount
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	units, err := strconv.ParseUint(in.Units, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%q: %w", in.Units, ErrSyntax)
+	}
+	if _, err := pow10(in.Decimals); err != nil {
+		return err
+	}
+	*a = New(units, in.Decimals)
+	return nil
+}
+
+type jsonAmount struct {
+	Units    string `json:"amount"`
+	Decimals uint8  `json:"decimals"`
+	UI       string `json:"ui,omitempty"`
+}
+
+// FromTokenAmount converts the balance returned by getTokenAccountBalance.
+func FromTokenAmount(balance *rpc.UiTokenAmount) (Amount, error) {
+	units, err := strconv.ParseUint(balance.Amount, 10, 64)
+	if err != nil {
+		return Amount{}, fmt.Errorf("%q: %w", balance.Amount, ErrSyntax)
+	}
+	return New(units, balance.Decimals), nil
+}
+
+// TokenAmount converts a to the balance shape of getTokenAccountBalance.
+func (a Amount) TokenAmount() *rpc.UiTokenAmount {
+	return &rpc.UiTokenAmount{Amount: strconv.FormatUint(a.units, 10), Decimals: a.decimals, UiAmountString: a.String()}
+}
+
+// MintDecimals reads the decimals of a token mint.
+func MintDecimals(ctx context.Context, c rpcclient.Client, mint solana.PublicKey) (uint8, error) {
+	out, err := c.GetAccountInfo(ctx, mint)
+	if err != nil {
+		return 0, fmt.Errorf("mint %s: %w", mint, err)
+	}
+	if out == nil || out.Value == nil {
+		return 0, fmt.Errorf("mint %s: %w", mint, rpc.ErrNotFound)
+	}
+	var m token.Mint
+	if err := m.UnmarshalWithDecoder(ag_binary.NewBinDecoder(out.Value.Data.GetBinary())); err != nil {
+		return 0, fmt.Errorf("mint %s: %w", mint, err)
+	}
+	return m.Decimals, nil
+}
+
+// PolicyDecimals reads the decimals recorded in the policy of the supernode
+// state of the program client is configured for.
+func PolicyDecimals(ctx context.Context, c rpcclient.Client) (uint8, error) {
+	supernode, _, err := client.NewInitializeInstructionBuilder().FindSupernodeAddress()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find supernode PDA: %w", err)
+	}
+	out, err := c.GetAccountInfo(ctx, supernode)
+	if err != nil {
+		return 0, fmt.Errorf("supernode state %s: %w", supernode, err)
+	}
+	if out == nil || out.Value == nil {
+		return 0, fmt.Errorf("supernode state %s: %w", supernode, rpc.ErrNotFound)
+	}
+	var state client.SupernodeStateAccount
+	if err := state.UnmarshalWithDecoder(ag_binary.NewBorshDecoder(out.Value.Data.GetBinary())); err != nil {
+		return 0, fmt.Errorf("supernode state %s: %w", supernode, err)
+	}
+	return state.Policy.Decimals, nil
+}
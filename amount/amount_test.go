@@ -0,0 +1,153 @@
+package amount
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/client"
+	"n3-solana-test/simulator"
+)
+
+var programID = solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy")
+
+func init() {
+	client.SetProgramID(programID)
+}
+
+func TestParse(t *testing.T) {
+	for _, c := range []struct {
+		in       string
+		decimals uint8
+		units    uint64
+		err      error
+	}{
+		{"12.5", 9, 12_500_000_000, nil},
+		{"7", 9, 7_000_000_000, nil},
+		{"0.000000001", 9, 1, nil},
+		{".5", 2, 50, nil},
+		{"007.10", 2, 710, nil},
+		{"1.50000000000", 2, 150, nil},
+		{"0", 0, 0, nil},
+		{"18446744073709551615", 0, 1<<64 - 1, nil},
+		{"18446744073.709551615", 9, 1<<64 - 1, nil},
+		{"18446744073.709551616", 9, 0, ErrOverflow},
+		{"18446744074", 9, 0, ErrOverflow},
+		{"0.0000000001", 9, 0, ErrPrecision},
+		{"1.5", 0, 0, ErrPrecision},
+		{"", 9, 0, ErrSyntax},
+		{".", 9, 0, ErrSyntax},
+		{"1.", 9, 0, ErrSyntax},
+		{"-1", 9, 0, ErrSyntax},
+		{"1e9", 9, 0, ErrSyntax},
+		{"1,5", 9, 0, ErrSyntax},
+		{" 1", 9, 0, ErrSyntax},
+		{"1", 20, 0, ErrOverflow},
+	} {
+		got, err := Parse(c.in, c.decimals)
+		if c.err != nil {
+			ag_require.ErrorIs(t, err, c.err, c.in)
+			continue
+		}
+		ag_require.NoError(t, err, c.in)
+		ag_require.Equal(t, New(c.units, c.decimals), got, c.in)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for _, c := range []struct {
+		a     Amount
+		s     string
+		fixed string
+	}{
+		{New(12_500_000_000, 9), "12.5", "12.500000000"},
+		{New(1, 9), "0.000000001", "0.000000001"},
+		{New(0, 9), "0", "0.000000000"},
+		{New(7_000, 3), "7", "7.000"},
+		{New(42, 0), "42", "42"},
+		{New(1<<64-1, 19), "1.8446744073709551615", "1.8446744073709551615"},
+	} {
+		ag_require.Equal(t, c.s, c.a.String())
+		ag_require.Equal(t, c.fixed, c.a.Fixed())
+		back, err := Parse(c.a.String(), c.a.Decimals())
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, c.a, back)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := MustTokens(3, 9), New(500_000_000, 9)
+	sum, err := a.Add(b)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, "3.5", sum.String())
+	diff, err := a.Sub(b)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, "2.5", diff.String())
+	product, err := b.Mul(7)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, "3.5", product.String())
+
+	_, err = b.Sub(a)
+	ag_require.ErrorIs(t, err, ErrNegative)
+	_, err = New(1<<64-1, 9).Add(New(1, 9))
+	ag_require.ErrorIs(t, err, ErrOverflow)
+	_, err = a.Mul(1 << 40)
+	ag_require.ErrorIs(t, err, ErrOverflow)
+	_, err = a.Add(New(1, 6))
+	ag_require.ErrorIs(t, err, ErrDecimals)
+	_, err = Tokens(1<<63, 9)
+	ag_require.ErrorIs(t, err, ErrOverflow)
+
+	ag_require.Equal(t, -1, b.Cmp(a))
+	ag_require.Equal(t, 1, a.Cmp(b))
+	ag_require.Equal(t, 0, a.Cmp(MustTokens(3, 9)))
+	ag_require.Equal(t, 0, a.Cmp(MustTokens(3, 2)))
+	ag_require.Equal(t, -1, New(1<<64-1, 19).Cmp(MustTokens(2, 0)))
+	ag_require.True(t, New(0, 9).IsZero())
+}
+
+func TestJSON(t *testing.T) {
+	a := New(12_500_000_000, 9)
+	data, err := json.Marshal(a)
+	ag_require.NoError(t, err)
+	ag_require.JSONEq(t, `{"amount":"12500000000","decimals":9,"ui":"12.5"}`, string(data))
+	var got Amount
+	ag_require.NoError(t, json.Unmarshal(data, &got))
+	ag_require.Equal(t, a, got)
+	ag_require.Error(t, json.Unmarshal([]byte(`{"amount":"-1","decimals":9}`), &got))
+
+	balance, err := FromTokenAmount(a.TokenAmount())
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, a, balance)
+}
+
+func TestDecimals(t *testing.T) {
+	ctx := context.Background()
+	sim := simulator.New(programID)
+	mint := solana.NewWallet().PublicKey()
+	_, err := MintDecimals(ctx, sim, mint)
+	ag_require.ErrorIs(t, err, rpc.ErrNotFound)
+
+	buf := new(bytes.Buffer)
+	ag_require.NoError(t, ag_binary.NewBinEncoder(buf).Encode(token.Mint{Decimals: 6, IsInitialized: true}))
+	sim.SetAccount(mint, &simulator.Account{Lamports: 1, Owner: solana.TokenProgramID, Data: buf.Bytes()})
+	decimals, err := MintDecimals(ctx, sim, mint)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, uint8(6), decimals)
+
+	supernode, _, err := client.NewInitializeInstructionBuilder().FindSupernodeAddress()
+	ag_require.NoError(t, err)
+	buf.Reset()
+	state := &client.SupernodeStateAccount{Token: mint, Policy: client.Policy{Decimals: 6}}
+	ag_require.NoError(t, state.MarshalWithEncoder(ag_binary.NewBorshEncoder(buf)))
+	sim.SetAccount(supernode, &simulator.Account{Lamports: 1, Owner: programID, Data: buf.Bytes()})
+	decimals, err = PolicyDecimals(ctx, sim)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, uint8(6), decimals)
+}
@@ -0,0 +1,233 @@
+// Package batch packs supernode instructions into as few transactions as fit
+// the Solana packet and compute limits.
+package batch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"n3-solana-test/client"
+)
+
+const (
+	// MaxTransactionSize is the largest serialized transaction a validator accepts.
+	MaxTransactionSize = 1232
+	// MaxComputeUnits is the per-transaction compute unit ceiling.
+	MaxComputeUnits = 1_400_000
+	// DefaultUnitsPerInstruction is the compute budget reserved for each
+	// packed instruction when Options.UnitsPerInstruction is zero.
+	DefaultUnitsPerInstruction = 50_000
+)
+
+// Options controls how instructions are packed.
+type Options struct {
+	// FeePayer pays for every transaction of the plan.
+	FeePayer solana.PublicKey
+	// UnitsPerInstruction is the compute budget reserved per instruction.
+	UnitsPerInstruction uint32
+	// ComputeUnitPrice is the priority fee in micro-lamports per compute
+	// unit; zero omits the SetComputeUnitPrice instruction.
+	ComputeUnitPrice uint64
+	// AddressTables, when set, produces v0 transactions that load the
+	// tables' accounts through lookups instead of listing them inline.
+	AddressTables map[solana.PublicKey]solana.PublicKeySlice
+}
+
+// Batch is one transaction of a Plan.
+type Batch struct {
+	Transaction *solana.Transaction
+	// Instructions holds the indexes, into the slice given to Pack, of the
+	// instructions carried by this transaction.
+	Instructions     []int
+	Size             int
+	ComputeUnitLimit uint32
+}
+
+// Plan is the result of Pack.
+type Plan struct {
+	Batches []*Batch
+	names   []string
+}
+
+// Transactions returns the unsigned transactions of the plan.
+func (p *Plan) Transactions() []*solana.Transaction {
+	out := make([]*solana.Transaction, len(p.Batches))
+	for i, b := range p.Batches {
+		out[i] = b.Transaction
+	}
+	return out
+}
+
+// String renders which instruction landed in which transaction.
+func (p *Plan) String() string {
+	var sb strings.Builder
+	for i, b := range p.Batches {
+		fmt.Fprintf(&sb, "tx %d: %d bytes, %d CU\n", i, b.Size, b.ComputeUnitLimit)
+		for _, idx := range b.Instructions {
+			fmt.Fprintf(&sb, "  [%d] %s\n", idx, p.names[idx])
+		}
+	}
+	return sb.String()
+}
+
+// Pack greedily fills transactions in instruction order. Every transaction
+// starts with SetComputeUnitLimit (and SetComputeUnitPrice when a price is
+// set) and is closed once the next instruction would push it over
+// MaxTransactionSize or MaxComputeUnits. Pack fails when UnitsPerInstruction
+// exceeds MaxComputeUnits, as then no instruction fits a transaction.
+func Pack(instructions []solana.Instruction, blockhash solana.Hash, opts Options) (*Plan, error) {
+	if opts.FeePayer.IsZero() {
+		return nil, fmt.Errorf("fee payer is not set")
+	}
+	perInstruction := opts.UnitsPerInstruction
+	if perInstruction == 0 {
+		perInstruction = DefaultUnitsPerInstruction
+	}
+	if perInstruction > MaxComputeUnits {
+		return nil, fmt.Errorf("%d compute units per instruction exceed the %d a transaction can use", perInstruction, MaxComputeUnits)
+	}
+
+	plan := &Plan{names: make([]string, len(instructions))}
+	for i, inst := range instructions {
+		plan.names[i] = instructionName(inst)
+	}
+
+	var current *Batch
+	for i := range instructions {
+		if current != nil {
+			indexes := append(append([]int(nil), current.Instructions...), i)
+			if computeUnitLimit(len(indexes), perInstruction) <= MaxComputeUnits {
+				candidate, err := build(instructions, indexes, blockhash, perInstruction, opts)
+				if err != nil {
+					return nil, err
+				}
+				if candidate.Size <= MaxTransactionSize {
+					current = candidate
+					continue
+				}
+			}
+			plan.Batches = append(plan.Batches, current)
+		}
+
+		single, err := build(instructions, []int{i}, blockhash, perInstruction, opts)
+		if err != nil {
+			return nil, err
+		}
+		if single.Size > MaxTransactionSize {
+			return nil, fmt.Errorf("instruction %d (%s) alone needs %d bytes, more than %d", i, plan.names[i], single.Size, MaxTransactionSize)
+		}
+		if single.ComputeUnitLimit > MaxComputeUnits {
+			return nil, fmt.Errorf("instruction %d (%s) alone needs %d compute units, more than %d", i, plan.names[i], single.ComputeUnitLimit, MaxComputeUnits)
+		}
+		current = single
+	}
+	if current != nil {
+		plan.Batches = append(plan.Batches, current)
+	}
+	return plan, nil
+}
+
+// computeUnitLimit is the budget of n instructions, computed in 64 bits so
+// it cannot wrap.
+func computeUnitLimit(n int, perInstruction uint32) uint64 {
+	return uint64(n) * uint64(perInstruction)
+}
+
+// build assembles the transaction of indexes. The caller keeps their compute
+// budget within MaxComputeUnits, so it fits the uint32 of
+// SetComputeUnitLimit.
+func build(instructions []solana.Instruction, indexes []int, blockhash solana.Hash, perInstruction uint32, opts Options) (*Batch, error) {
+	limit := uint32(computeUnitLimit(len(indexes), perInstruction))
+	ixs := []solana.Instruction{computebudget.NewSetComputeUnitLimitInstruction(limit).Build()}
+	if opts.ComputeUnitPrice > 0 {
+		ixs = append(ixs, computebudget.NewSetComputeUnitPriceInstruction(opts.ComputeUnitPrice).Build())
+	}
+	for _, idx := range indexes {
+		inst, err := clone(instructions[idx])
+		if err != nil {
+			return nil, fmt.Errorf("instruction %d: %w", idx, err)
+		}
+		ixs = append(ixs, inst)
+	}
+
+	txOpts := []solana.TransactionOption{solana.TransactionPayer(opts.FeePayer)}
+	if len(opts.AddressTables) > 0 {
+		txOpts = append(txOpts, solana.TransactionAddressTables(opts.AddressTables))
+	}
+	tx, err := solana.NewTransaction(ixs, blockhash, txOpts...)
+	if err != nil {
+		return nil, err
+	}
+	size, err := SerializedSize(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &Batch{
+		Transaction:      tx,
+		Instructions:     indexes,
+		Size:             size,
+		ComputeUnitLimit: limit,
+	}, nil
+}
+
+// SerializedSize returns the wire size of tx once every required signature
+// is present.
+func SerializedSize(tx *solana.Transaction) (int, error) {
+	msg, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	signatures := int(tx.Message.Header.NumRequiredSignatures)
+	return compactU16Len(signatures) + signatures*solana.SignatureLength + len(msg), nil
+}
+
+func compactU16Len(n int) int {
+	switch {
+	case n < 0x80:
+		return 1
+	case n < 0x4000:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// instruction is a detached copy of a solana.Instruction.
+type instruction struct {
+	programID solana.PublicKey
+	accounts  []*solana.AccountMeta
+	data      []byte
+}
+
+func (inst *instruction) ProgramID() solana.PublicKey     { return inst.programID }
+func (inst *instruction) Accounts() []*solana.AccountMeta { return inst.accounts }
+func (inst *instruction) Data() ([]byte, error)           { return inst.data, nil }
+
+// clone copies the account metas of inst. solana.NewTransaction deduplicates
+// account metas by merging the writable flag into the first meta it sees for
+// a key; working on copies keeps that merge from leaking into the caller's
+// instructions or into other transactions of the plan.
+func clone(inst solana.Instruction) (solana.Instruction, error) {
+	data, err := inst.Data()
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]*solana.AccountMeta, len(inst.Accounts()))
+	for i, meta := range inst.Accounts() {
+		if meta == nil {
+			return nil, fmt.Errorf("account %d is not set", i)
+		}
+		m := *meta
+		accounts[i] = &m
+	}
+	return &instruction{programID: inst.ProgramID(), accounts: accounts, data: data}, nil
+}
+
+func instructionName(inst solana.Instruction) string {
+	if ix, ok := inst.(*client.Instruction); ok {
+		return client.InstructionIDToName(ix.TypeID)
+	}
+	return inst.ProgramID().String()
+}
@@ -0,0 +1,117 @@
+package batch
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/client"
+)
+
+func onboarding(t *testing.T, devices int) (instructions []solana.Instruction, provider solana.PublicKey, static solana.PublicKeySlice) {
+	client.SetProgramID(solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy"))
+	provider = solana.NewWallet().PublicKey()
+	admin := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+
+	b := client.NewStakeDeviceInstructionBuilder()
+	supernode := b.MustFindSupernodeAddress()
+	stake := b.MustFindSupernodeStakeAccountAddress()
+	stakeInfo := b.MustFindProviderStakeInfoAddress(provider)
+	ata, _, err := solana.FindAssociatedTokenAddress(provider, mint)
+	ag_require.NoError(t, err)
+
+	for i := 0; i < devices; i++ {
+		instructions = append(instructions, client.NewStakeDeviceInstruction(
+			uint64(i), 1,
+			supernode, stake, stakeInfo, ata, mint, provider, provider, admin,
+			solana.TokenProgramID, solana.SystemProgramID, solana.SPLAssociatedTokenAccountProgramID,
+		).Build())
+	}
+	instructions = append(instructions, client.NewAddExtraControllerInstruction(
+		supernode, stakeInfo, provider, provider, admin, solana.NewWallet().PublicKey(),
+	).Build())
+
+	static = solana.PublicKeySlice{supernode, stake, mint, solana.TokenProgramID, solana.SystemProgramID, solana.SPLAssociatedTokenAccountProgramID}
+	return
+}
+
+func TestPack(t *testing.T) {
+	instructions, provider, _ := onboarding(t, 40)
+	supernodeMeta := *instructions[0].Accounts()[0]
+
+	plan, err := Pack(instructions, solana.Hash{1}, Options{FeePayer: provider, ComputeUnitPrice: 1000})
+	ag_require.NoError(t, err)
+	ag_require.Greater(t, len(plan.Batches), 1)
+
+	var placed []int
+	for _, b := range plan.Batches {
+		ag_require.LessOrEqual(t, b.Size, MaxTransactionSize)
+		ag_require.LessOrEqual(t, b.ComputeUnitLimit, uint32(MaxComputeUnits))
+		raw, err := b.Transaction.MarshalBinary()
+		ag_require.NoError(t, err)
+		// Unsigned transactions serialize without signatures.
+		ag_require.Equal(t, b.Size, len(raw)+int(b.Transaction.Message.Header.NumRequiredSignatures)*solana.SignatureLength)
+
+		decoded, err := client.DecodeInstructions(&b.Transaction.Message)
+		ag_require.NoError(t, err)
+		ag_require.Len(t, decoded, len(b.Instructions))
+		placed = append(placed, b.Instructions...)
+	}
+	for i := range instructions {
+		ag_require.Equal(t, i, placed[i])
+	}
+	ag_require.Contains(t, plan.String(), "AddExtraController")
+
+	// Packing must not alter the caller's account metas.
+	ag_require.Equal(t, supernodeMeta, *instructions[0].Accounts()[0])
+}
+
+func TestPack_AddressTable(t *testing.T) {
+	instructions, provider, static := onboarding(t, 40)
+	table := solana.NewWallet().PublicKey()
+
+	inline, err := Pack(instructions, solana.Hash{1}, Options{FeePayer: provider})
+	ag_require.NoError(t, err)
+	withTable, err := Pack(instructions, solana.Hash{1}, Options{
+		FeePayer:      provider,
+		AddressTables: map[solana.PublicKey]solana.PublicKeySlice{table: static},
+	})
+	ag_require.NoError(t, err)
+	ag_require.LessOrEqual(t, len(withTable.Batches), len(inline.Batches))
+	ag_require.True(t, withTable.Batches[0].Transaction.Message.IsVersioned())
+}
+
+func TestPack_ComputeLimit(t *testing.T) {
+	instructions, provider, _ := onboarding(t, 10)
+	plan, err := Pack(instructions, solana.Hash{1}, Options{FeePayer: provider, UnitsPerInstruction: 500_000})
+	ag_require.NoError(t, err)
+	for _, b := range plan.Batches {
+		ag_require.LessOrEqual(t, len(b.Instructions), 2)
+	}
+}
+
+func TestPack_UnitsPerInstruction(t *testing.T) {
+	instructions, provider, _ := onboarding(t, 3)
+
+	// One instruction may use the whole transaction budget.
+	plan, err := Pack(instructions, solana.Hash{1}, Options{FeePayer: provider, UnitsPerInstruction: MaxComputeUnits})
+	ag_require.NoError(t, err)
+	ag_require.Len(t, plan.Batches, len(instructions))
+	for _, b := range plan.Batches {
+		ag_require.Len(t, b.Instructions, 1)
+		ag_require.EqualValues(t, MaxComputeUnits, b.ComputeUnitLimit)
+	}
+
+	// A budget over the ceiling fits no transaction, even alone.
+	_, err = Pack(instructions, solana.Hash{1}, Options{FeePayer: provider, UnitsPerInstruction: MaxComputeUnits + 1})
+	ag_require.EqualError(t, err, "1400001 compute units per instruction exceed the 1400000 a transaction can use")
+
+	// Two instructions of 1<<31 units wrap a 32-bit limit to zero.
+	_, err = Pack(instructions, solana.Hash{1}, Options{FeePayer: provider, UnitsPerInstruction: 1 << 31})
+	ag_require.EqualError(t, err, "2147483648 compute units per instruction exceed the 1400000 a transaction can use")
+}
+
+func TestComputeUnitLimit(t *testing.T) {
+	ag_require.EqualValues(t, 1<<32, computeUnitLimit(2, 1<<31))
+}
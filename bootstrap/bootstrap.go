@@ -0,0 +1,490 @@
+// Package bootstrap brings up a ready-to-use supernode deployment: the token
+// mint, funded role wallets (admin, providers, controllers and tenants) with
+// associated token accounts holding test balances, the initialized program
+// and a seeded reward vault.
+//
+// Run is idempotent: every step checks the ledger first, so a run against a
+// complete deployment sends nothing and an interrupted run picks up where it
+// stopped. It works against any rpcclient.Client, be it a cluster, the
+// simulator or an rpctest server.
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/amount"
+	"n3-solana-test/client"
+	"n3-solana-test/config"
+	"n3-solana-test/priorityfee"
+	"n3-solana-test/rpcclient"
+)
+
+const (
+	mintSize = 82
+	// maxInstructions bounds the instructions packed into one transaction.
+	maxInstructions = 4
+)
+
+// Options configure Run. Zero fields take the defaults.
+type Options struct {
+	// Providers, Controllers and Tenants are the numbers of wallets of each
+	// role (default 1, 2 and 1).
+	Providers   int
+	Controllers int
+	Tenants     int
+	// Decimals of a new mint (default 9). An existing mint keeps its own.
+	Decimals uint8
+	// AdminLamports and Lamports are the balances the admin and the other
+	// wallets are funded to (default 10 and 1 SOL). A wallet is topped up
+	// only once it falls below half of its balance, so the fees a run spends
+	// do not make the next one fund again.
+	AdminLamports uint64
+	Lamports      uint64
+	// ProviderTokens and TenantTokens are the token balances of the
+	// provider and tenant accounts (default 1,000,000 tokens each).
+	ProviderTokens amount.Amount
+	TenantTokens   amount.Amount
+	// RewardTokens is the balance of the reward vault (default 10,000,000
+	// tokens).
+	RewardTokens amount.Amount
+	// RewardLockedTime and StakingCoefficient are the policy Initialize sets
+	// (default 90 and 10 tokens).
+	RewardLockedTime   uint64
+	StakingCoefficient amount.Amount
+	// Fees, when set, prices the transactions Run sends and rebroadcasts
+	// them at a rising price until they land. It must query the client
+	// given to Run.
+	Fees *priorityfee.Estimator
+}
+
+func (o Options) withDefaults() Options {
+	if o.Providers == 0 {
+		o.Providers = 1
+	}
+	if o.Controllers == 0 {
+		o.Controllers = 2
+	}
+	if o.Tenants == 0 {
+		o.Tenants = 1
+	}
+	if o.Decimals == 0 {
+		o.Decimals = 9
+	}
+	if o.AdminLamports == 0 {
+		o.AdminLamports = 10 * solana.LAMPORTS_PER_SOL
+	}
+	if o.Lamports == 0 {
+		o.Lamports = solana.LAMPORTS_PER_SOL
+	}
+	if o.RewardLockedTime == 0 {
+		o.RewardLockedTime = 90
+	}
+	return o
+}
+
+// Roles returns the wallet roles of a deployment: the admin, the mint
+// keypair, the providers, the controllers and the tenants.
+func (o Options) Roles() []string {
+	o = o.withDefaults()
+	roles := []string{RoleAdmin, RoleMint}
+	for i := 0; i < o.Providers; i++ {
+		roles = append(roles, Provider(i))
+	}
+	for i := 0; i < o.Controllers; i++ {
+		roles = append(roles, Controller(i))
+	}
+	for i := 0; i < o.Tenants; i++ {
+		roles = append(roles, Tenant(i))
+	}
+	return roles
+}
+
+// Deployment describes a bootstrapped deployment.
+type Deployment struct {
+	ProgramID solana.PublicKey
+	Mint      solana.PublicKey
+	Decimals  uint8
+	// Wallets are the public keys of the wallets by role, and TokenAccounts
+	// the associated token accounts of the wallets other than the admin.
+	Wallets       map[string]solana.PublicKey
+	TokenAccounts map[string]solana.PublicKey
+	Supernode     solana.PublicKey
+	RewardVault   solana.PublicKey
+	// Signatures are the transactions Run sent, in order; none when the
+	// deployment was already complete.
+	Signatures []solana.Signature
+}
+
+// Record writes the deployment into profile.
+func (d *Deployment) Record(profile *config.Profile) {
+	profile.ProgramID = d.ProgramID
+	profile.Admin = d.Wallets[RoleAdmin]
+	profile.Mint = d.Mint
+	profile.Decimals = d.Decimals
+	profile.Wallets = d.Wallets
+}
+
+// Run brings up the deployment of the program client is configured for with
+// wallets, which must hold a keypair for every role of opts.Roles.
+func Run(ctx context.Context, c rpcclient.Client, wallets Wallets, opts Options) (*Deployment, error) {
+	opts = opts.withDefaults()
+	for _, role := range opts.Roles() {
+		if _, ok := wallets[role]; !ok {
+			return nil, fmt.Errorf("no %s wallet", role)
+		}
+	}
+	r := &runner{ctx: ctx, c: c, wallets: wallets, opts: opts, admin: wallets[RoleAdmin]}
+	r.d = &Deployment{
+		ProgramID:     client.ProgramID,
+		Mint:          wallets[RoleMint].PublicKey(),
+		Wallets:       map[string]solana.PublicKey{},
+		TokenAccounts: map[string]solana.PublicKey{},
+	}
+	for _, role := range opts.Roles() {
+		if role != RoleMint {
+			r.d.Wallets[role] = wallets[role].PublicKey()
+		}
+	}
+
+	for _, step := range []struct {
+		name string
+		run  func() error
+	}{
+		{"fund wallets", r.fund},
+		{"create mint", r.mint},
+		{"create token accounts", r.tokenAccounts},
+		{"mint test balances", r.balances},
+		{"initialize", r.initialize},
+		{"init reward account", r.rewardAccount},
+		{"seed reward vault", r.seedRewards},
+	} {
+		if err := step.run(); err != nil {
+			return r.d, fmt.Errorf("%s: %w", step.name, err)
+		}
+	}
+	return r.d, nil
+}
+
+type runner struct {
+	ctx     context.Context
+	c       rpcclient.Client
+	wallets Wallets
+	opts    Options
+	admin   solana.PrivateKey
+	d       *Deployment
+}
+
+// holders are the roles with token accounts: every wallet but the admin and
+// the mint keypair.
+func (r *runner) holders() []string {
+	return r.opts.Roles()[2:]
+}
+
+func (r *runner) fund() error {
+	admin := r.admin.PublicKey()
+	var transfers []solana.Instruction
+	need := r.opts.AdminLamports
+	for _, role := range r.holders() {
+		key := r.d.Wallets[role]
+		balance, err := r.c.GetBalance(r.ctx, key, rpc.CommitmentConfirmed)
+		if err != nil {
+			return fmt.Errorf("%s: %w", role, err)
+		}
+		if balance.Value < r.opts.Lamports/2 {
+			transfers = append(transfers, system.NewTransferInstruction(r.opts.Lamports-balance.Value, admin, key).Build())
+			need += r.opts.Lamports - balance.Value
+		}
+	}
+
+	balance, err := r.c.GetBalance(r.ctx, admin, rpc.CommitmentConfirmed)
+	if err != nil {
+		return fmt.Errorf("admin: %w", err)
+	}
+	if balance.Value < need-r.opts.AdminLamports/2 {
+		sig, err := r.c.RequestAirdrop(r.ctx, admin, need-balance.Value, rpc.CommitmentConfirmed)
+		if err != nil {
+			return fmt.Errorf("airdrop to admin %s: %w", admin, err)
+		}
+		if err := rpcclient.WaitForConfirmation(r.ctx, r.c, sig, rpc.CommitmentConfirmed); err != nil {
+			return fmt.Errorf("airdrop to admin %s: %w", admin, err)
+		}
+		r.d.Signatures = append(r.d.Signatures, sig)
+	}
+	return r.sendAll(transfers)
+}
+
+func (r *runner) mint() error {
+	mint := r.d.Mint
+	acct, err := r.account(mint)
+	if err != nil {
+		return err
+	}
+	if acct != nil {
+		if !acct.Owner.Equals(solana.TokenProgramID) {
+			return fmt.Errorf("mint %s is owned by %s", mint, acct.Owner)
+		}
+		if r.d.Decimals, err = amount.MintDecimals(r.ctx, r.c, mint); err != nil {
+			return err
+		}
+		return r.amounts()
+	}
+
+	r.d.Decimals = r.opts.Decimals
+	if err := r.amounts(); err != nil {
+		return err
+	}
+	admin := r.admin.PublicKey()
+	rent, err := r.c.GetMinimumBalanceForRentExemption(r.ctx, mintSize, rpc.CommitmentConfirmed)
+	if err != nil {
+		return err
+	}
+	return r.send([]solana.PrivateKey{r.admin, r.wallets[RoleMint]},
+		system.NewCreateAccountInstruction(rent, mintSize, solana.TokenProgramID, admin, mint).Build(),
+		token.NewInitializeMint2Instruction(r.d.Decimals, admin, admin, mint).Build(),
+	)
+}
+
+// amounts defaults the token amounts of the options in the decimals of the
+// mint and checks the ones given are in them.
+func (r *runner) amounts() error {
+	for _, a := range []struct {
+		name  string
+		value *amount.Amount
+		whole uint64
+	}{
+		{"provider tokens", &r.opts.ProviderTokens, 1_000_000},
+		{"tenant tokens", &r.opts.TenantTokens, 1_000_000},
+		{"reward tokens", &r.opts.RewardTokens, 10_000_000},
+		{"staking coefficient", &r.opts.StakingCoefficient, 10},
+	} {
+		if a.value.IsZero() {
+			v, err := amount.Tokens(a.whole, r.d.Decimals)
+			if err != nil {
+				return fmt.Errorf("%s: %w", a.name, err)
+			}
+			*a.value = v
+		}
+		if a.value.Decimals() != r.d.Decimals {
+			return fmt.Errorf("%s %s has %d decimals, the mint %d: %w", a.name, a.value, a.value.Decimals(), r.d.Decimals, amount.ErrDecimals)
+		}
+	}
+	return nil
+}
+
+func (r *runner) tokenAccounts() error {
+	admin := r.admin.PublicKey()
+	var creates []solana.Instruction
+	for _, role := range r.holders() {
+		owner := r.d.Wallets[role]
+		ata, _, err := solana.FindAssociatedTokenAddress(owner, r.d.Mint)
+		if err != nil {
+			return err
+		}
+		r.d.TokenAccounts[role] = ata
+		acct, err := r.account(ata)
+		if err != nil {
+			return err
+		}
+		if acct == nil {
+			creates = append(creates, associatedtokenaccount.NewCreateInstruction(admin, owner, r.d.Mint).Build())
+		}
+	}
+	return r.sendAll(creates)
+}
+
+func (r *runner) balances() error {
+	var mints []solana.Instruction
+	for _, role := range r.holders() {
+		want := r.opts.ProviderTokens
+		switch {
+		case strings.HasPrefix(role, "tenant-"):
+			want = r.opts.TenantTokens
+		case strings.HasPrefix(role, "controller-"):
+			continue
+		}
+		ix, err := r.topUp(r.d.TokenAccounts[role], want)
+		if err != nil {
+			return fmt.Errorf("%s: %w", role, err)
+		}
+		if ix != nil {
+			mints = append(mints, ix)
+		}
+	}
+	return r.sendAll(mints)
+}
+
+// topUp returns the MintTo instruction raising the balance of account to
+// want, or nil when it holds enough.
+func (r *runner) topUp(account solana.PublicKey, want amount.Amount) (solana.Instruction, error) {
+	out, err := r.c.GetTokenAccountBalance(r.ctx, account, rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	have, err := amount.FromTokenAmount(out.Value)
+	if err != nil {
+		return nil, err
+	}
+	missing, err := want.Sub(have)
+	if errors.Is(err, amount.ErrNegative) || err == nil && missing.IsZero() {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token.NewMintToInstruction(missing.Units(), r.d.Mint, account, r.admin.PublicKey(), nil).Build(), nil
+}
+
+func (r *runner) initialize() error {
+	b := client.NewInitializeInstructionBuilder()
+	supernode, _, err := b.FindSupernodeAddress()
+	if err != nil {
+		return err
+	}
+	r.d.Supernode = supernode
+	acct, err := r.account(supernode)
+	if err != nil {
+		return err
+	}
+	if acct != nil {
+		var state client.SupernodeStateAccount
+		if err := state.UnmarshalWithDecoder(ag_binary.NewBorshDecoder(acct.Data.GetBinary())); err != nil {
+			return fmt.Errorf("supernode state %s: %w", supernode, err)
+		}
+		if !state.Token.Equals(r.d.Mint) {
+			return fmt.Errorf("supernode is initialized with mint %s, not %s", state.Token, r.d.Mint)
+		}
+		return nil
+	}
+
+	stake, _, err := b.FindSupernodeStakeAccountAddress()
+	if err != nil {
+		return err
+	}
+	vesting, _, err := b.FindSupernodeVestingAccountAddress()
+	if err != nil {
+		return err
+	}
+	rental, _, err := b.FindSupernodeRentalAccountAddress()
+	if err != nil {
+		return err
+	}
+	ix, err := b.SetRewardLockedTime(r.opts.RewardLockedTime).
+		SetStakingCoefficient(r.opts.StakingCoefficient.Units()).
+		SetSupernodeAccount(supernode).
+		SetSupernodeStakeAccountAccount(stake).
+		SetSupernodeVestingAccountAccount(vesting).
+		SetSupernodeRentalAccountAccount(rental).
+		SetTokenAccount(r.d.Mint).
+		SetAdminAccount(r.admin.PublicKey()).
+		ValidateAndBuild()
+	if err != nil {
+		return err
+	}
+	return r.send([]solana.PrivateKey{r.admin}, ix)
+}
+
+func (r *runner) rewardAccount() error {
+	b := client.NewInitRewardAccountInstructionBuilder()
+	vault, _, err := b.FindSupernodeRewardAccountAddress()
+	if err != nil {
+		return err
+	}
+	r.d.RewardVault = vault
+	acct, err := r.account(vault)
+	if err != nil || acct != nil {
+		return err
+	}
+	ix, err := b.SetSupernodeAccount(r.d.Supernode).
+		SetSupernodeRewardAccountAccount(vault).
+		SetTokenAccount(r.d.Mint).
+		SetAdminAccount(r.admin.PublicKey()).
+		ValidateAndBuild()
+	if err != nil {
+		return err
+	}
+	return r.send([]solana.PrivateKey{r.admin}, ix)
+}
+
+func (r *runner) seedRewards() error {
+	ix, err := r.topUp(r.d.RewardVault, r.opts.RewardTokens)
+	if err != nil || ix == nil {
+		return err
+	}
+	return r.send([]solana.PrivateKey{r.admin}, ix)
+}
+
+// account returns the account at key, nil when there is none.
+func (r *runner) account(key solana.PublicKey) (*rpc.Account, error) {
+	out, err := r.c.GetAccountInfo(r.ctx, key)
+	if errors.Is(err, rpc.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("account %s: %w", key, err)
+	}
+	if out == nil || out.Value == nil {
+		return nil, nil
+	}
+	return out.Value, nil
+}
+
+// sendAll sends ixs signed by the admin, maxInstructions per transaction.
+func (r *runner) sendAll(ixs []solana.Instruction) error {
+	for len(ixs) > 0 {
+		n := min(len(ixs), maxInstructions)
+		if err := r.send([]solana.PrivateKey{r.admin}, ixs[:n]...); err != nil {
+			return err
+		}
+		ixs = ixs[n:]
+	}
+	return nil
+}
+
+// send signs ixs with signers, the first of which pays, and waits for the
+// transaction to be confirmed.
+func (r *runner) send(signers []solana.PrivateKey, ixs ...solana.Instruction) error {
+	if r.opts.Fees != nil {
+		sig, err := r.opts.Fees.Send(r.ctx, signers, ixs...)
+		if err != nil {
+			return err
+		}
+		r.d.Signatures = append(r.d.Signatures, sig)
+		return nil
+	}
+	recent, err := r.c.GetLatestBlockhash(r.ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return err
+	}
+	tx, err := solana.NewTransaction(ixs, recent.Value.Blockhash, solana.TransactionPayer(signers[0].PublicKey()))
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		for i := range signers {
+			if signers[i].PublicKey().Equals(key) {
+				return &signers[i]
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	sig, err := r.c.SendTransaction(r.ctx, tx)
+	if err != nil {
+		return err
+	}
+	if err := rpcclient.WaitForConfirmation(r.ctx, r.c, sig, rpc.CommitmentConfirmed); err != nil {
+		return err
+	}
+	r.d.Signatures = append(r.d.Signatures, sig)
+	return nil
+}
@@ -0,0 +1,144 @@
+package bootstrap
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/amount"
+	"n3-solana-test/client"
+	"n3-solana-test/config"
+	"n3-solana-test/keystore"
+	"n3-solana-test/priorityfee"
+	"n3-solana-test/rpcclient"
+	"n3-solana-test/rpctest"
+	"n3-solana-test/simulator"
+)
+
+var programID = solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy")
+
+func init() {
+	client.SetProgramID(programID)
+	rpcclient.PollInterval = 0
+}
+
+func tokens(t *testing.T, c rpcclient.Client, account solana.PublicKey) string {
+	out, err := c.GetTokenAccountBalance(context.Background(), account, rpc.CommitmentConfirmed)
+	ag_require.NoError(t, err)
+	return out.Value.UiAmountString
+}
+
+func checkDeployment(t *testing.T, c rpcclient.Client, d *Deployment) {
+	ctx := context.Background()
+	ag_require.Len(t, d.Wallets, 5)
+	ag_require.Len(t, d.TokenAccounts, 4)
+	ag_require.Equal(t, "1000000", tokens(t, c, d.TokenAccounts[Provider(0)]))
+	ag_require.Equal(t, "1000000", tokens(t, c, d.TokenAccounts[Tenant(0)]))
+	ag_require.Equal(t, "0", tokens(t, c, d.TokenAccounts[Controller(1)]))
+	ag_require.Equal(t, "10000000", tokens(t, c, d.RewardVault))
+	for role, key := range d.Wallets {
+		balance, err := c.GetBalance(ctx, key, rpc.CommitmentConfirmed)
+		ag_require.NoError(t, err)
+		ag_require.Greater(t, balance.Value, solana.LAMPORTS_PER_SOL/2, role)
+	}
+
+	out, err := c.GetAccountInfo(ctx, d.Supernode)
+	ag_require.NoError(t, err)
+	var state client.SupernodeStateAccount
+	ag_require.NoError(t, state.UnmarshalWithDecoder(ag_binary.NewBorshDecoder(out.Value.Data.GetBinary())))
+	ag_require.Equal(t, d.Mint, state.Token)
+	ag_require.Equal(t, d.Wallets[RoleAdmin], state.Admin)
+	ag_require.Equal(t, amount.MustTokens(10, 9).Units(), state.Policy.StakingCoefficient)
+	ag_require.Equal(t, uint8(9), state.Policy.Decimals)
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	sim := simulator.New(programID)
+	dir := t.TempDir()
+	store, err := keystore.Create(filepath.Join(dir, "keystore.json"), []byte("test"), "", keystore.Options{ScryptN: 1 << 10})
+	ag_require.NoError(t, err)
+	wallets, err := StoreWallets(store, Options{}.Roles())
+	ag_require.NoError(t, err)
+
+	d, err := Run(ctx, sim, wallets, Options{})
+	ag_require.NoError(t, err)
+	ag_require.NotEmpty(t, d.Signatures)
+	checkDeployment(t, sim, d)
+
+	profilePath := filepath.Join(dir, "supernode.json")
+	profile := &config.Profile{RPC: "sim"}
+	d.Record(profile)
+	ag_require.NoError(t, config.Save(profilePath, profile))
+	loaded, err := config.Load(profilePath)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, d.Mint, loaded.Mint)
+	ag_require.Equal(t, d.Wallets, loaded.Wallets)
+	ag_require.Equal(t, uint8(9), loaded.Decimals)
+
+	t.Run("again", func(t *testing.T) {
+		store, err := keystore.Open(filepath.Join(dir, "keystore.json"), []byte("test"))
+		ag_require.NoError(t, err)
+		reopened, err := StoreWallets(store, Options{}.Roles())
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, wallets, reopened)
+		again, err := Run(ctx, sim, reopened, Options{})
+		ag_require.NoError(t, err)
+		ag_require.Empty(t, again.Signatures)
+		ag_require.Equal(t, d.Supernode, again.Supernode)
+		checkDeployment(t, sim, again)
+	})
+
+	t.Run("more providers", func(t *testing.T) {
+		opts := Options{Providers: 2}
+		grown, err := StoreWallets(store, opts.Roles())
+		ag_require.NoError(t, err)
+		more, err := Run(ctx, sim, grown, opts)
+		ag_require.NoError(t, err)
+		ag_require.NotEmpty(t, more.Signatures)
+		ag_require.Equal(t, "1000000", tokens(t, sim, more.TokenAccounts[Provider(1)]))
+		ag_require.Equal(t, "10000000", tokens(t, sim, more.RewardVault))
+	})
+
+	t.Run("other mint", func(t *testing.T) {
+		other := NewWallets(Options{}.Roles())
+		other[RoleAdmin] = wallets[RoleAdmin]
+		_, err := Run(ctx, sim, other, Options{})
+		ag_require.Error(t, err)
+		ag_require.Contains(t, err.Error(), "initialized with mint")
+	})
+
+	t.Run("decimals", func(t *testing.T) {
+		_, err := Run(ctx, sim, wallets, Options{RewardTokens: amount.New(1, 6)})
+		ag_require.ErrorIs(t, err, amount.ErrDecimals)
+	})
+}
+
+func TestRun_PriorityFees(t *testing.T) {
+	sim := simulator.New(programID)
+	wallets := NewWallets(Options{}.Roles())
+	d, err := Run(context.Background(), sim, wallets, Options{Fees: priorityfee.New(sim, priorityfee.Options{})})
+	ag_require.NoError(t, err)
+	ag_require.NotEmpty(t, d.Signatures)
+	checkDeployment(t, sim, d)
+}
+
+func TestRun_RPC(t *testing.T) {
+	server := rpctest.NewServer(simulator.New(programID))
+	defer server.Close()
+	d, err := Run(context.Background(), server.Client(), NewWallets(Options{}.Roles()), Options{})
+	ag_require.NoError(t, err)
+	checkDeployment(t, server.Client(), d)
+}
+
+func TestRun_MissingWallet(t *testing.T) {
+	wallets := NewWallets(Options{}.Roles())
+	delete(wallets, Tenant(0))
+	_, err := Run(context.Background(), simulator.New(programID), wallets, Options{})
+	ag_require.Error(t, err)
+	ag_require.Contains(t, err.Error(), "no tenant-0 wallet")
+}
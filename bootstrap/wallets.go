@@ -0,0 +1,75 @@
+package bootstrap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+	"n3-solana-test/keystore"
+)
+
+// Role names of the wallets a deployment uses.
+const (
+	RoleAdmin = "admin"
+	// RoleMint is the keypair the token mint is created at.
+	RoleMint = "mint"
+)
+
+// Provider, Controller and Tenant return the role names of the i-th
+// provider, controller and tenant wallets.
+func Provider(i int) string   { return fmt.Sprintf("provider-%d", i) }
+func Controller(i int) string { return fmt.Sprintf("controller-%d", i) }
+func Tenant(i int) string     { return fmt.Sprintf("tenant-%d", i) }
+
+// Wallets holds the keypairs of a deployment by role.
+type Wallets map[string]solana.PrivateKey
+
+// NewWallets generates a keypair for every role.
+func NewWallets(roles []string) Wallets {
+	w := Wallets{}
+	for _, role := range roles {
+		w[role] = solana.NewWallet().PrivateKey
+	}
+	return w
+}
+
+// StoreWallets returns the key of every role from the keystore s, and
+// generates the missing ones into it. Running it again returns the same
+// wallets.
+func StoreWallets(s *keystore.Store, roles []string) (Wallets, error) {
+	w := Wallets{}
+	for _, role := range roles {
+		key, err := s.Key(role)
+		if errors.Is(err, keystore.ErrNotFound) {
+			key, err = s.Generate(role)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s wallet: %w", role, err)
+		}
+		w[role] = key
+	}
+	return w, nil
+}
+
+// PublicKeys returns the public keys of w by role, without the mint
+// keypair.
+func (w Wallets) PublicKeys() map[string]solana.PublicKey {
+	out := map[string]solana.PublicKey{}
+	for role, key := range w {
+		if role != RoleMint {
+			out[role] = key.PublicKey()
+		}
+	}
+	return out
+}
+
+// Roles returns the roles of w, sorted.
+func (w Wallets) Roles() []string {
+	roles := make([]string, 0, len(w))
+	for role := range w {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
@@ -1,11 +1,15 @@
-// Code generated by https://github.com/gagliardetto/anchor-go. DO NOT EDIT.
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
 
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 	ag_binary "github.com/gagliardetto/binary"
 	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_text "github.com/gagliardetto/solana-go/text"
+	ag_format "github.com/gagliardetto/solana-go/text/format"
+	ag_treeout "github.com/gagliardetto/treeout"
 )
 
 type ProviderStakeInfoAccount struct {
@@ -45,7 +49,7 @@ func (obj *ProviderStakeInfoAccount) UnmarshalWithDecoder(decoder *ag_binary.Dec
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[200 104 62 31 28 110 31 45]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `ExtraControllers`:
@@ -61,6 +65,67 @@ func (obj *ProviderStakeInfoAccount) UnmarshalWithDecoder(decoder *ag_binary.Dec
 	return nil
 }
 
+// MarshalJSON encodes the fields of ProviderStakeInfoAccount under their IDL names, next to
+// its "account" name. 64-bit integers are strings.
+func (obj ProviderStakeInfoAccount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of ProviderStakeInfoAccount, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj ProviderStakeInfoAccount) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName         string                   `json:"account"`
+		ExtraControllers [2]ag_solanago.PublicKey `json:"extra_controllers"`
+		Devices          []interface{}            `json:"devices"`
+	}{
+		JSONName:         "ProviderStakeInfo",
+		ExtraControllers: obj.ExtraControllers,
+		Devices:          jsonValues(obj.Devices, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *ProviderStakeInfoAccount) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName         string                   `json:"account"`
+		ExtraControllers [2]ag_solanago.PublicKey `json:"extra_controllers"`
+		Devices          []DeviceState            `json:"devices"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("account", in.JSONName, "ProviderStakeInfo"); err != nil {
+		return err
+	}
+	obj.ExtraControllers = in.ExtraControllers
+	obj.Devices = in.Devices
+	return nil
+}
+
+// TextEncode writes the JSON of ProviderStakeInfoAccount for solana-go's text encoder.
+func (obj ProviderStakeInfoAccount) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj ProviderStakeInfoAccount) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Account")) + ": " + ag_text.Bold("ProviderStakeInfo")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Param("ExtraControllers", obj.ExtraControllers))
+			parent.Child(listLabel("Devices", len(obj.Devices))).ParentFunc(func(items ag_treeout.Branches) {
+				for i := range obj.Devices {
+					obj.Devices[i].EncodeToTree(items.Child(itemLabel(i)))
+				}
+			})
+		})
+}
+
 type ProviderVestingInfoAccount struct {
 	EndIdx         uint8
 	LastReleaseDay uint16
@@ -110,21 +175,21 @@ func (obj *ProviderVestingInfoAccount) UnmarshalWithDecoder(decoder *ag_binary.D
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[138 109 208 248 196 12 164 112]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `EndIdx`:
-	err = decoder.Decode(&obj.EndIdx)
+	obj.EndIdx, err = decoder.ReadUint8()
 	if err != nil {
 		return err
 	}
 	// Deserialize `LastReleaseDay`:
-	err = decoder.Decode(&obj.LastReleaseDay)
+	obj.LastReleaseDay, err = decoder.ReadUint16(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `ReleasedAmount`:
-	err = decoder.Decode(&obj.ReleasedAmount)
+	obj.ReleasedAmount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
@@ -136,6 +201,80 @@ func (obj *ProviderVestingInfoAccount) UnmarshalWithDecoder(decoder *ag_binary.D
 	return nil
 }
 
+// MarshalJSON encodes the fields of ProviderVestingInfoAccount under their IDL names, next to
+// its "account" name. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj ProviderVestingInfoAccount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of ProviderVestingInfoAccount, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj ProviderVestingInfoAccount) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName         string        `json:"account"`
+		EndIdx           uint8         `json:"end_idx"`
+		LastReleaseDay   uint16        `json:"last_release_day"`
+		ReleasedAmount   jsonUint64    `json:"released_amount"`
+		ReleasedAmountUI *string       `json:"released_amount_ui,omitempty"`
+		Schedules        []interface{} `json:"schedules"`
+	}{
+		JSONName:         "ProviderVestingInfo",
+		EndIdx:           obj.EndIdx,
+		LastReleaseDay:   obj.LastReleaseDay,
+		ReleasedAmount:   jsonUint64(obj.ReleasedAmount),
+		ReleasedAmountUI: formatAmount(obj.ReleasedAmount, decimals),
+		Schedules:        jsonValues(obj.Schedules, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *ProviderVestingInfoAccount) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName       string     `json:"account"`
+		EndIdx         uint8      `json:"end_idx"`
+		LastReleaseDay uint16     `json:"last_release_day"`
+		ReleasedAmount jsonUint64 `json:"released_amount"`
+		Schedules      []Schedule `json:"schedules"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("account", in.JSONName, "ProviderVestingInfo"); err != nil {
+		return err
+	}
+	obj.EndIdx = in.EndIdx
+	obj.LastReleaseDay = in.LastReleaseDay
+	obj.ReleasedAmount = uint64(in.ReleasedAmount)
+	obj.Schedules = in.Schedules
+	return nil
+}
+
+// TextEncode writes the JSON of ProviderVestingInfoAccount for solana-go's text encoder.
+func (obj ProviderVestingInfoAccount) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj ProviderVestingInfoAccount) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Account")) + ": " + ag_text.Bold("ProviderVestingInfo")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Param("EndIdx", obj.EndIdx))
+			parent.Child(ag_format.Param("LastReleaseDay", obj.LastReleaseDay))
+			parent.Child(ag_format.Param("ReleasedAmount", obj.ReleasedAmount))
+			parent.Child(listLabel("Schedules", len(obj.Schedules))).ParentFunc(func(items ag_treeout.Branches) {
+				for i := range obj.Schedules {
+					obj.Schedules[i].EncodeToTree(items.Child(itemLabel(i)))
+				}
+			})
+		})
+}
+
 type SupernodeStateAccount struct {
 	Admin  ag_solanago.PublicKey
 	Token  ag_solanago.PublicKey
@@ -179,16 +318,16 @@ func (obj *SupernodeStateAccount) UnmarshalWithDecoder(decoder *ag_binary.Decode
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[2 22 141 62 77 123 126 67]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Admin`:
-	err = decoder.Decode(&obj.Admin)
+	err = readPublicKey(decoder, &obj.Admin)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Token`:
-	err = decoder.Decode(&obj.Token)
+	err = readPublicKey(decoder, &obj.Token)
 	if err != nil {
 		return err
 	}
@@ -200,6 +339,68 @@ func (obj *SupernodeStateAccount) UnmarshalWithDecoder(decoder *ag_binary.Decode
 	return nil
 }
 
+// MarshalJSON encodes the fields of SupernodeStateAccount under their IDL names, next to
+// its "account" name. 64-bit integers are strings.
+func (obj SupernodeStateAccount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of SupernodeStateAccount, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj SupernodeStateAccount) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName string                `json:"account"`
+		Admin    ag_solanago.PublicKey `json:"admin"`
+		Token    ag_solanago.PublicKey `json:"token"`
+		Policy   interface{}           `json:"policy"`
+	}{
+		JSONName: "SupernodeState",
+		Admin:    obj.Admin,
+		Token:    obj.Token,
+		Policy:   obj.Policy.jsonValue(decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *SupernodeStateAccount) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName string                `json:"account"`
+		Admin    ag_solanago.PublicKey `json:"admin"`
+		Token    ag_solanago.PublicKey `json:"token"`
+		Policy   Policy                `json:"policy"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("account", in.JSONName, "SupernodeState"); err != nil {
+		return err
+	}
+	obj.Admin = in.Admin
+	obj.Token = in.Token
+	obj.Policy = in.Policy
+	return nil
+}
+
+// TextEncode writes the JSON of SupernodeStateAccount for solana-go's text encoder.
+func (obj SupernodeStateAccount) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj SupernodeStateAccount) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Account")) + ": " + ag_text.Bold("SupernodeState")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Account("Admin", obj.Admin))
+			parent.Child(ag_format.Account("Token", obj.Token))
+			obj.Policy.EncodeToTree(parent.Child("Policy"))
+		})
+}
+
 type TenantInfoAccount struct {
 	Funds     uint64
 	Withdrawn uint64
@@ -237,18 +438,80 @@ func (obj *TenantInfoAccount) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[239 62 8 238 217 205 200 193]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Funds`:
-	err = decoder.Decode(&obj.Funds)
+	obj.Funds, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Withdrawn`:
-	err = decoder.Decode(&obj.Withdrawn)
+	obj.Withdrawn, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// MarshalJSON encodes the fields of TenantInfoAccount under their IDL names, next to
+// its "account" name. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj TenantInfoAccount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of TenantInfoAccount, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj TenantInfoAccount) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName    string     `json:"account"`
+		Funds       jsonUint64 `json:"funds"`
+		FundsUI     *string    `json:"funds_ui,omitempty"`
+		Withdrawn   jsonUint64 `json:"withdrawn"`
+		WithdrawnUI *string    `json:"withdrawn_ui,omitempty"`
+	}{
+		JSONName:    "TenantInfo",
+		Funds:       jsonUint64(obj.Funds),
+		FundsUI:     formatAmount(obj.Funds, decimals),
+		Withdrawn:   jsonUint64(obj.Withdrawn),
+		WithdrawnUI: formatAmount(obj.Withdrawn, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *TenantInfoAccount) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName  string     `json:"account"`
+		Funds     jsonUint64 `json:"funds"`
+		Withdrawn jsonUint64 `json:"withdrawn"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("account", in.JSONName, "TenantInfo"); err != nil {
+		return err
+	}
+	obj.Funds = uint64(in.Funds)
+	obj.Withdrawn = uint64(in.Withdrawn)
+	return nil
+}
+
+// TextEncode writes the JSON of TenantInfoAccount for solana-go's text encoder.
+func (obj TenantInfoAccount) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj TenantInfoAccount) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Account")) + ": " + ag_text.Bold("TenantInfo")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Param("Funds", obj.Funds))
+			parent.Child(ag_format.Param("Withdrawn", obj.Withdrawn))
+		})
+}
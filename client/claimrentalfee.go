@@ -1,4 +1,4 @@
-// Code generated by https://github.com/gagliardetto/anchor-go. DO NOT EDIT.
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
 
 package client
 
@@ -36,12 +36,22 @@ type ClaimRentalFee struct {
 	//
 	// [10] = [] associated_token_program
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewClaimRentalFeeInstructionBuilder creates a new `ClaimRentalFee` instruction builder.
+// NewClaimRentalFeeInstructionBuilder creates a new `ClaimRentalFee` instruction builder
+// for the default program.
 func NewClaimRentalFeeInstructionBuilder() *ClaimRentalFee {
+	return (*Program)(nil).NewClaimRentalFeeInstructionBuilder()
+}
+
+// NewClaimRentalFeeInstructionBuilder creates a new `ClaimRentalFee` instruction builder
+// for the program.
+func (p *Program) NewClaimRentalFeeInstructionBuilder() *ClaimRentalFee {
 	nd := &ClaimRentalFee{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 11),
+		program:          p,
 	}
 	nd.AccountMetaSlice[8] = ag_solanago.Meta(Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"])
 	nd.AccountMetaSlice[9] = ag_solanago.Meta(Addresses["11111111111111111111111111111111"])
@@ -49,6 +59,10 @@ func NewClaimRentalFeeInstructionBuilder() *ClaimRentalFee {
 	return nd
 }
 
+func (inst *ClaimRentalFee) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetAmount sets the "amount" parameter.
 func (inst *ClaimRentalFee) SetAmount(amount uint64) *ClaimRentalFee {
 	inst.Amount = &amount
@@ -61,28 +75,20 @@ func (inst *ClaimRentalFee) SetSupernodeAccount(supernode ag_solanago.PublicKey)
 	return inst
 }
 
-func (inst *ClaimRentalFee) findFindSupernodeAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernode returns the seeds of the Supernode account address, without the bump.
+func (inst *ClaimRentalFee) seedsSupernode() (seeds [][]byte) {
 	// const: supernode
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeAddressWithBumpSeed calculates Supernode account address with given seeds and a known bump seed.
 func (inst *ClaimRentalFee) FindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernode(), bumpSeed, inst.program.ID())
 }
 
 func (inst *ClaimRentalFee) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(bumpSeed)
+	pda, err := inst.FindSupernodeAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -91,12 +97,11 @@ func (inst *ClaimRentalFee) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8)
 
 // FindSupernodeAddress finds Supernode account address with given seeds.
 func (inst *ClaimRentalFee) FindSupernodeAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernode(), inst.program.ID())
 }
 
 func (inst *ClaimRentalFee) MustFindSupernodeAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(0)
+	pda, _, err := inst.FindSupernodeAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -114,28 +119,20 @@ func (inst *ClaimRentalFee) SetSupernodeRentalAccountAccount(supernodeRentalAcco
 	return inst
 }
 
-func (inst *ClaimRentalFee) findFindSupernodeRentalAccountAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernodeRentalAccount returns the seeds of the SupernodeRentalAccount account address, without the bump.
+func (inst *ClaimRentalFee) seedsSupernodeRentalAccount() (seeds [][]byte) {
 	// const: supernode_rental_account
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65), byte(0x5f), byte(0x72), byte(0x65), byte(0x6e), byte(0x74), byte(0x61), byte(0x6c), byte(0x5f), byte(0x61), byte(0x63), byte(0x63), byte(0x6f), byte(0x75), byte(0x6e), byte(0x74)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeRentalAccountAddressWithBumpSeed calculates SupernodeRentalAccount account address with given seeds and a known bump seed.
 func (inst *ClaimRentalFee) FindSupernodeRentalAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeRentalAccountAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernodeRentalAccount(), bumpSeed, inst.program.ID())
 }
 
 func (inst *ClaimRentalFee) MustFindSupernodeRentalAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeRentalAccountAddress(bumpSeed)
+	pda, err := inst.FindSupernodeRentalAccountAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -144,12 +141,11 @@ func (inst *ClaimRentalFee) MustFindSupernodeRentalAccountAddressWithBumpSeed(bu
 
 // FindSupernodeRentalAccountAddress finds SupernodeRentalAccount account address with given seeds.
 func (inst *ClaimRentalFee) FindSupernodeRentalAccountAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeRentalAccountAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernodeRentalAccount(), inst.program.ID())
 }
 
 func (inst *ClaimRentalFee) MustFindSupernodeRentalAccountAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeRentalAccountAddress(0)
+	pda, _, err := inst.FindSupernodeRentalAccountAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -167,30 +163,22 @@ func (inst *ClaimRentalFee) SetProviderStakeInfoAccount(providerStakeInfo ag_sol
 	return inst
 }
 
-func (inst *ClaimRentalFee) findFindProviderStakeInfoAddress(provider ag_solanago.PublicKey, knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsProviderStakeInfo returns the seeds of the ProviderStakeInfo account address, without the bump.
+func (inst *ClaimRentalFee) seedsProviderStakeInfo(provider ag_solanago.PublicKey) (seeds [][]byte) {
 	// const: provider_stake_info
 	seeds = append(seeds, []byte{byte(0x70), byte(0x72), byte(0x6f), byte(0x76), byte(0x69), byte(0x64), byte(0x65), byte(0x72), byte(0x5f), byte(0x73), byte(0x74), byte(0x61), byte(0x6b), byte(0x65), byte(0x5f), byte(0x69), byte(0x6e), byte(0x66), byte(0x6f)})
 	// path: provider
 	seeds = append(seeds, provider.Bytes())
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindProviderStakeInfoAddressWithBumpSeed calculates ProviderStakeInfo account address with given seeds and a known bump seed.
 func (inst *ClaimRentalFee) FindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
-	return
+	return createProgramAddress(inst.seedsProviderStakeInfo(provider), bumpSeed, inst.program.ID())
 }
 
 func (inst *ClaimRentalFee) MustFindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
+	pda, err := inst.FindProviderStakeInfoAddressWithBumpSeed(provider, bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -199,12 +187,11 @@ func (inst *ClaimRentalFee) MustFindProviderStakeInfoAddressWithBumpSeed(provide
 
 // FindProviderStakeInfoAddress finds ProviderStakeInfo account address with given seeds.
 func (inst *ClaimRentalFee) FindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindProviderStakeInfoAddress(provider, 0)
-	return
+	return findProgramAddress(inst.seedsProviderStakeInfo(provider), inst.program.ID())
 }
 
 func (inst *ClaimRentalFee) MustFindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, 0)
+	pda, _, err := inst.FindProviderStakeInfoAddress(provider)
 	if err != nil {
 		panic(err)
 	}
@@ -308,7 +295,7 @@ func (inst ClaimRentalFee) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_ClaimRentalFee,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -368,8 +355,52 @@ func (inst *ClaimRentalFee) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *ClaimRentalFee) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	errs = append(errs, checkFlags("claim_rental_fee", "supernode", accounts[0], false, true))
+	{
+		pda, _, err := inst.FindSupernodeAddress()
+		errs = append(errs, checkAccount("claim_rental_fee", "supernode", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("claim_rental_fee", "supernode_rental_account", accounts[1], false, true))
+	{
+		pda, _, err := inst.FindSupernodeRentalAccountAddress()
+		errs = append(errs, checkAccount("claim_rental_fee", "supernode_rental_account", accounts[1].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	{
+		pda, _, err := inst.FindProviderStakeInfoAddress(accounts[5].PublicKey)
+		errs = append(errs, checkAccount("claim_rental_fee", "provider_stake_info", accounts[2].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("claim_rental_fee", "provider_token_account", accounts[3], false, true))
+	{
+		ata, err := associatedTokenAddress(accounts[5].PublicKey, accounts[4].PublicKey)
+		errs = append(errs, checkAccount("claim_rental_fee", "provider_token_account", accounts[3].PublicKey, ata, err, ErrNotAssociatedTokenAccount))
+	}
+	errs = append(errs, checkFlags("claim_rental_fee", "controller", accounts[6], true, true))
+	errs = append(errs, checkFlags("claim_rental_fee", "admin", accounts[7], true, false))
+	errs = append(errs, checkAccount("claim_rental_fee", "token_program", accounts[8].PublicKey, Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("claim_rental_fee", "system_program", accounts[9].PublicKey, Addresses["11111111111111111111111111111111"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("claim_rental_fee", "associated_token_program", accounts[10].PublicKey, Addresses["ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"], nil, ErrProgramMismatch))
+	if *inst.Amount == 0 {
+		errs = append(errs, &ArgumentError{Instruction: "claim_rental_fee", Argument: "amount", Err: ErrZeroArgument})
+	}
+	return errors.Join(errs...)
+}
+
 func (inst *ClaimRentalFee) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("ClaimRentalFee")).
@@ -416,7 +447,8 @@ func (obj *ClaimRentalFee) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err
 	return nil
 }
 
-// NewClaimRentalFeeInstruction declares a new ClaimRentalFee instruction with the provided parameters and accounts.
+// NewClaimRentalFeeInstruction declares a new ClaimRentalFee instruction of the default
+// program with the provided parameters and accounts.
 func NewClaimRentalFeeInstruction(
 	// Parameters:
 	amount uint64,
@@ -432,7 +464,27 @@ func NewClaimRentalFeeInstruction(
 	tokenProgram ag_solanago.PublicKey,
 	systemProgram ag_solanago.PublicKey,
 	associatedTokenProgram ag_solanago.PublicKey) *ClaimRentalFee {
-	return NewClaimRentalFeeInstructionBuilder().
+	return (*Program)(nil).NewClaimRentalFeeInstruction(amount, supernode, supernodeRentalAccount, providerStakeInfo, providerTokenAccount, token, provider, controller, admin, tokenProgram, systemProgram, associatedTokenProgram)
+}
+
+// NewClaimRentalFeeInstruction declares a new ClaimRentalFee instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewClaimRentalFeeInstruction(
+	// Parameters:
+	amount uint64,
+	// Accounts:
+	supernode ag_solanago.PublicKey,
+	supernodeRentalAccount ag_solanago.PublicKey,
+	providerStakeInfo ag_solanago.PublicKey,
+	providerTokenAccount ag_solanago.PublicKey,
+	token ag_solanago.PublicKey,
+	provider ag_solanago.PublicKey,
+	controller ag_solanago.PublicKey,
+	admin ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	associatedTokenProgram ag_solanago.PublicKey) *ClaimRentalFee {
+	return p.NewClaimRentalFeeInstructionBuilder().
 		SetAmount(amount).
 		SetSupernodeAccount(supernode).
 		SetSupernodeRentalAccountAccount(supernodeRentalAccount).
@@ -1,4 +1,4 @@
-// Code generated by https://github.com/gagliardetto/anchor-go. DO NOT EDIT.
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
 
 package client
 
@@ -36,12 +36,22 @@ type ClaimReward struct {
 	//
 	// [10] = [] associated_token_program
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewClaimRewardInstructionBuilder creates a new `ClaimReward` instruction builder.
+// NewClaimRewardInstructionBuilder creates a new `ClaimReward` instruction builder
+// for the default program.
 func NewClaimRewardInstructionBuilder() *ClaimReward {
+	return (*Program)(nil).NewClaimRewardInstructionBuilder()
+}
+
+// NewClaimRewardInstructionBuilder creates a new `ClaimReward` instruction builder
+// for the program.
+func (p *Program) NewClaimRewardInstructionBuilder() *ClaimReward {
 	nd := &ClaimReward{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 11),
+		program:          p,
 	}
 	nd.AccountMetaSlice[8] = ag_solanago.Meta(Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"])
 	nd.AccountMetaSlice[9] = ag_solanago.Meta(Addresses["11111111111111111111111111111111"])
@@ -49,6 +59,10 @@ func NewClaimRewardInstructionBuilder() *ClaimReward {
 	return nd
 }
 
+func (inst *ClaimReward) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetAmount sets the "amount" parameter.
 func (inst *ClaimReward) SetAmount(amount uint64) *ClaimReward {
 	inst.Amount = &amount
@@ -61,28 +75,20 @@ func (inst *ClaimReward) SetSupernodeAccount(supernode ag_solanago.PublicKey) *C
 	return inst
 }
 
-func (inst *ClaimReward) findFindSupernodeAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernode returns the seeds of the Supernode account address, without the bump.
+func (inst *ClaimReward) seedsSupernode() (seeds [][]byte) {
 	// const: supernode
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeAddressWithBumpSeed calculates Supernode account address with given seeds and a known bump seed.
 func (inst *ClaimReward) FindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernode(), bumpSeed, inst.program.ID())
 }
 
 func (inst *ClaimReward) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(bumpSeed)
+	pda, err := inst.FindSupernodeAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -91,12 +97,11 @@ func (inst *ClaimReward) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (p
 
 // FindSupernodeAddress finds Supernode account address with given seeds.
 func (inst *ClaimReward) FindSupernodeAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernode(), inst.program.ID())
 }
 
 func (inst *ClaimReward) MustFindSupernodeAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(0)
+	pda, _, err := inst.FindSupernodeAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -114,28 +119,20 @@ func (inst *ClaimReward) SetSupernodeRewardAccountAccount(supernodeRewardAccount
 	return inst
 }
 
-func (inst *ClaimReward) findFindSupernodeRewardAccountAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernodeRewardAccount returns the seeds of the SupernodeRewardAccount account address, without the bump.
+func (inst *ClaimReward) seedsSupernodeRewardAccount() (seeds [][]byte) {
 	// const: supernode_reward_account
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65), byte(0x5f), byte(0x72), byte(0x65), byte(0x77), byte(0x61), byte(0x72), byte(0x64), byte(0x5f), byte(0x61), byte(0x63), byte(0x63), byte(0x6f), byte(0x75), byte(0x6e), byte(0x74)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeRewardAccountAddressWithBumpSeed calculates SupernodeRewardAccount account address with given seeds and a known bump seed.
 func (inst *ClaimReward) FindSupernodeRewardAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeRewardAccountAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernodeRewardAccount(), bumpSeed, inst.program.ID())
 }
 
 func (inst *ClaimReward) MustFindSupernodeRewardAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeRewardAccountAddress(bumpSeed)
+	pda, err := inst.FindSupernodeRewardAccountAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -144,12 +141,11 @@ func (inst *ClaimReward) MustFindSupernodeRewardAccountAddressWithBumpSeed(bumpS
 
 // FindSupernodeRewardAccountAddress finds SupernodeRewardAccount account address with given seeds.
 func (inst *ClaimReward) FindSupernodeRewardAccountAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeRewardAccountAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernodeRewardAccount(), inst.program.ID())
 }
 
 func (inst *ClaimReward) MustFindSupernodeRewardAccountAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeRewardAccountAddress(0)
+	pda, _, err := inst.FindSupernodeRewardAccountAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -167,30 +163,22 @@ func (inst *ClaimReward) SetProviderStakeInfoAccount(providerStakeInfo ag_solana
 	return inst
 }
 
-func (inst *ClaimReward) findFindProviderStakeInfoAddress(provider ag_solanago.PublicKey, knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsProviderStakeInfo returns the seeds of the ProviderStakeInfo account address, without the bump.
+func (inst *ClaimReward) seedsProviderStakeInfo(provider ag_solanago.PublicKey) (seeds [][]byte) {
 	// const: provider_stake_info
 	seeds = append(seeds, []byte{byte(0x70), byte(0x72), byte(0x6f), byte(0x76), byte(0x69), byte(0x64), byte(0x65), byte(0x72), byte(0x5f), byte(0x73), byte(0x74), byte(0x61), byte(0x6b), byte(0x65), byte(0x5f), byte(0x69), byte(0x6e), byte(0x66), byte(0x6f)})
 	// path: provider
 	seeds = append(seeds, provider.Bytes())
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindProviderStakeInfoAddressWithBumpSeed calculates ProviderStakeInfo account address with given seeds and a known bump seed.
 func (inst *ClaimReward) FindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
-	return
+	return createProgramAddress(inst.seedsProviderStakeInfo(provider), bumpSeed, inst.program.ID())
 }
 
 func (inst *ClaimReward) MustFindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
+	pda, err := inst.FindProviderStakeInfoAddressWithBumpSeed(provider, bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -199,12 +187,11 @@ func (inst *ClaimReward) MustFindProviderStakeInfoAddressWithBumpSeed(provider a
 
 // FindProviderStakeInfoAddress finds ProviderStakeInfo account address with given seeds.
 func (inst *ClaimReward) FindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindProviderStakeInfoAddress(provider, 0)
-	return
+	return findProgramAddress(inst.seedsProviderStakeInfo(provider), inst.program.ID())
 }
 
 func (inst *ClaimReward) MustFindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, 0)
+	pda, _, err := inst.FindProviderStakeInfoAddress(provider)
 	if err != nil {
 		panic(err)
 	}
@@ -308,7 +295,7 @@ func (inst ClaimReward) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_ClaimReward,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -368,8 +355,52 @@ func (inst *ClaimReward) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *ClaimReward) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	errs = append(errs, checkFlags("claim_reward", "supernode", accounts[0], false, true))
+	{
+		pda, _, err := inst.FindSupernodeAddress()
+		errs = append(errs, checkAccount("claim_reward", "supernode", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("claim_reward", "supernode_reward_account", accounts[1], false, true))
+	{
+		pda, _, err := inst.FindSupernodeRewardAccountAddress()
+		errs = append(errs, checkAccount("claim_reward", "supernode_reward_account", accounts[1].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	{
+		pda, _, err := inst.FindProviderStakeInfoAddress(accounts[5].PublicKey)
+		errs = append(errs, checkAccount("claim_reward", "provider_stake_info", accounts[2].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("claim_reward", "provider_token_account", accounts[3], false, true))
+	{
+		ata, err := associatedTokenAddress(accounts[5].PublicKey, accounts[4].PublicKey)
+		errs = append(errs, checkAccount("claim_reward", "provider_token_account", accounts[3].PublicKey, ata, err, ErrNotAssociatedTokenAccount))
+	}
+	errs = append(errs, checkFlags("claim_reward", "controller", accounts[6], true, true))
+	errs = append(errs, checkFlags("claim_reward", "admin", accounts[7], true, true))
+	errs = append(errs, checkAccount("claim_reward", "token_program", accounts[8].PublicKey, Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("claim_reward", "system_program", accounts[9].PublicKey, Addresses["11111111111111111111111111111111"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("claim_reward", "associated_token_program", accounts[10].PublicKey, Addresses["ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"], nil, ErrProgramMismatch))
+	if *inst.Amount == 0 {
+		errs = append(errs, &ArgumentError{Instruction: "claim_reward", Argument: "amount", Err: ErrZeroArgument})
+	}
+	return errors.Join(errs...)
+}
+
 func (inst *ClaimReward) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("ClaimReward")).
@@ -416,7 +447,8 @@ func (obj *ClaimReward) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err er
 	return nil
 }
 
-// NewClaimRewardInstruction declares a new ClaimReward instruction with the provided parameters and accounts.
+// NewClaimRewardInstruction declares a new ClaimReward instruction of the default
+// program with the provided parameters and accounts.
 func NewClaimRewardInstruction(
 	// Parameters:
 	amount uint64,
@@ -432,7 +464,27 @@ func NewClaimRewardInstruction(
 	tokenProgram ag_solanago.PublicKey,
 	systemProgram ag_solanago.PublicKey,
 	associatedTokenProgram ag_solanago.PublicKey) *ClaimReward {
-	return NewClaimRewardInstructionBuilder().
+	return (*Program)(nil).NewClaimRewardInstruction(amount, supernode, supernodeRewardAccount, providerStakeInfo, providerTokenAccount, token, provider, controller, admin, tokenProgram, systemProgram, associatedTokenProgram)
+}
+
+// NewClaimRewardInstruction declares a new ClaimReward instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewClaimRewardInstruction(
+	// Parameters:
+	amount uint64,
+	// Accounts:
+	supernode ag_solanago.PublicKey,
+	supernodeRewardAccount ag_solanago.PublicKey,
+	providerStakeInfo ag_solanago.PublicKey,
+	providerTokenAccount ag_solanago.PublicKey,
+	token ag_solanago.PublicKey,
+	provider ag_solanago.PublicKey,
+	controller ag_solanago.PublicKey,
+	admin ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	associatedTokenProgram ag_solanago.PublicKey) *ClaimReward {
+	return p.NewClaimRewardInstructionBuilder().
 		SetAmount(amount).
 		SetSupernodeAccount(supernode).
 		SetSupernodeRewardAccountAccount(supernodeRewardAccount).
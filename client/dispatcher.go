@@ -0,0 +1,362 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_rpc "github.com/gagliardetto/solana-go/rpc"
+)
+
+// EventMeta is where a dispatched event comes from.
+type EventMeta struct {
+	Name      string
+	Signature ag_solanago.Signature
+	Slot      uint64
+	// BlockTime is zero when the source does not carry it, as log
+	// notifications do not.
+	BlockTime time.Time
+	// Index is the position of the event among the events of the program
+	// in the transaction.
+	Index        int
+	Instruction  int
+	Depth        int
+	ComputeUnits uint64
+}
+
+// EventHandler handles the data of one event.
+type EventHandler func(ctx context.Context, data EventData, meta EventMeta) error
+
+// EventMiddleware wraps the handlers of a Dispatcher.
+type EventMiddleware func(next EventHandler) EventHandler
+
+// ErrorPolicy is what a Dispatcher does when a handler fails.
+type ErrorPolicy int
+
+const (
+	// StopOnError stops the dispatch of a transaction at the first handler
+	// error and returns it.
+	StopOnError ErrorPolicy = iota
+	// ContinueOnError delivers the remaining events of the transaction and
+	// returns the handler errors joined.
+	ContinueOnError
+)
+
+// HandlerError is an event a handler failed on.
+type HandlerError struct {
+	Meta EventMeta
+	Err  error
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handling %s, event %d of transaction %s: %v", e.Meta.Name, e.Meta.Index, e.Meta.Signature, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// TransactionLogs are the log messages of a transaction, as a logsSubscribe
+// notification carries them.
+type TransactionLogs struct {
+	Signature ag_solanago.Signature
+	Slot      uint64
+	// Err is the error the transaction failed with, nil when it succeeded.
+	Err  interface{}
+	Logs []string
+}
+
+// Dispatcher delivers the events of a program to handlers registered by
+// event type with On<Event> methods, the same for transactions fetched by a
+// backfill, log notifications of a live subscription and the simulator.
+//
+// Dispatches are serialized: the events of a transaction reach the handlers
+// in the order the program emitted them, and the transactions in the order
+// they are dispatched. Events of failed transactions are not delivered.
+// Register handlers and middleware before dispatching, and not from a
+// handler. The data handed to handlers is only valid until they return.
+type Dispatcher struct {
+	program          ag_solanago.PublicKey
+	getAddressTables AddressTablesGetter
+
+	mu         sync.Mutex
+	policy     ErrorPolicy
+	attempts   int
+	backoff    time.Duration
+	handlers   map[string][]EventHandler
+	all        []EventHandler
+	middleware []EventMiddleware
+	chain      EventHandler
+	stream     *EventStream
+
+	// The dispatch in progress.
+	ctx   context.Context
+	meta  EventMeta
+	index int
+	errs  []error
+}
+
+// NewDispatcher returns a dispatcher of the events of programID, resolving
+// the lookups of v0 transactions through the getter installed with
+// SetAddressTablesGetter.
+func NewDispatcher(programID ag_solanago.PublicKey) *Dispatcher {
+	d := &Dispatcher{program: programID, handlers: make(map[string][]EventHandler)}
+	d.stream = NewEventStream(programID, d.deliver)
+	return d
+}
+
+// NewDispatcher returns a dispatcher of the events of p.
+func (p *Program) NewDispatcher() *Dispatcher {
+	d := NewDispatcher(p.ID())
+	d.getAddressTables = p.addressTables()
+	return d
+}
+
+// SetErrorPolicy sets what the dispatcher does when a handler fails,
+// StopOnError by default.
+func (d *Dispatcher) SetErrorPolicy(policy ErrorPolicy) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.policy = policy
+}
+
+// SetRetry makes the dispatcher call a failing handler up to attempts times,
+// waiting backoff, doubled after each attempt, in between. Only the failing
+// handler is called again: the handlers of the event that succeeded before
+// it are not. By default handlers are called once.
+func (d *Dispatcher) SetRetry(attempts int, backoff time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.attempts, d.backoff = attempts, backoff
+}
+
+// Use wraps the handlers in middleware, the first given outermost.
+// Middleware sees every event, including those no handler is registered
+// for.
+func (d *Dispatcher) Use(middleware ...EventMiddleware) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.middleware = append(d.middleware, middleware...)
+	d.chain = nil
+}
+
+// OnAny registers handler for every event, called after the handlers of
+// the event's type.
+func (d *Dispatcher) OnAny(handler EventHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.all = append(d.all, handler)
+}
+
+func (d *Dispatcher) on(name string, handler EventHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[name] = append(d.handlers[name], handler)
+}
+
+// route calls the handlers of the event in the order they were registered.
+func (d *Dispatcher) route(ctx context.Context, data EventData, meta EventMeta) error {
+	for _, handler := range d.handlers[meta.Name] {
+		if err := d.call(ctx, handler, data, meta); err != nil {
+			return err
+		}
+	}
+	for _, handler := range d.all {
+		if err := d.call(ctx, handler, data, meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// call calls handler, again while it fails as SetRetry allows.
+func (d *Dispatcher) call(ctx context.Context, handler EventHandler, data EventData, meta EventMeta) error {
+	wait := d.backoff
+	for attempt := 1; ; attempt++ {
+		err := handler(ctx, data, meta)
+		if err == nil || attempt >= d.attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// DispatchTransaction delivers the events of a transaction returned by
+// getTransaction, logged and emitted through emit_cpi!. Payloads that do not
+// decode are returned as *EventError values joined with the handler errors.
+func (d *Dispatcher) DispatchTransaction(ctx context.Context, txData *ag_rpc.GetTransactionResult) error {
+	if txData.Meta == nil {
+		return errors.New("transaction has no meta")
+	}
+	if txData.Meta.Err != nil {
+		return nil
+	}
+	tx, err := txData.Transaction.GetTransaction()
+	if err != nil {
+		return err
+	}
+	evts, decodeErr := DecodeEvents(txData, d.program, d.getAddressTables)
+	if decodeErr != nil && !onlyEventErrors(decodeErr) {
+		return decodeErr
+	}
+	meta := EventMeta{Slot: txData.Slot}
+	if len(tx.Signatures) > 0 {
+		meta.Signature = tx.Signatures[0]
+	}
+	if txData.BlockTime != nil {
+		meta.BlockTime = txData.BlockTime.Time()
+	}
+	return errors.Join(d.Dispatch(ctx, meta, evts), decodeErr)
+}
+
+// onlyEventErrors reports whether err joins *EventError values only, the
+// payloads DecodeEvents skipped.
+func onlyEventErrors(err error) bool {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return false
+	}
+	for _, err := range joined.Unwrap() {
+		if _, ok := err.(*EventError); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// DispatchLogs delivers the events the program logged in a transaction, as
+// a log subscription notifies them. Events emitted through emit_cpi! are not
+// in the logs: dispatch the transaction to get them. Decoding streams
+// through an EventStream.
+func (d *Dispatcher) DispatchLogs(ctx context.Context, logs TransactionLogs) error {
+	if logs.Err != nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.begin(ctx, EventMeta{Signature: logs.Signature, Slot: logs.Slot})
+	defer d.end()
+	err := d.stream.Decode(logs.Logs)
+	return errors.Join(append(d.errs, err)...)
+}
+
+// Dispatch delivers evts, decoded from the transaction meta describes, such
+// as the result of Simulator.Events. The fields of meta describing the event
+// are set from each event.
+func (d *Dispatcher) Dispatch(ctx context.Context, meta EventMeta, evts []*Event) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.begin(ctx, meta)
+	defer d.end()
+	for _, evt := range evts {
+		if err := d.deliver(evt); err != nil {
+			return err
+		}
+	}
+	return errors.Join(d.errs...)
+}
+
+// begin starts a dispatch. d.mu must be held.
+func (d *Dispatcher) begin(ctx context.Context, meta EventMeta) {
+	if d.chain == nil {
+		d.chain = d.route
+		for i := len(d.middleware) - 1; i >= 0; i-- {
+			d.chain = d.middleware[i](d.chain)
+		}
+	}
+	d.ctx, d.meta, d.index = ctx, meta, 0
+}
+
+// end releases the state of the dispatch.
+func (d *Dispatcher) end() {
+	d.ctx, d.errs = nil, nil
+}
+
+// deliver passes evt through the middleware to its handlers, applying the
+// error policy. A done context stops the dispatch.
+func (d *Dispatcher) deliver(evt *Event) error {
+	if err := d.ctx.Err(); err != nil {
+		return err
+	}
+	meta := d.meta
+	meta.Name, meta.Index = evt.Name, d.index
+	meta.Instruction, meta.Depth, meta.ComputeUnits = evt.Instruction, evt.Depth, evt.ComputeUnits
+	d.index++
+	if err := d.chain(d.ctx, evt.Data, meta); err != nil {
+		handlerErr := &HandlerError{Meta: meta, Err: err}
+		if d.policy == StopOnError {
+			return handlerErr
+		}
+		d.errs = append(d.errs, handlerErr)
+	}
+	return nil
+}
+
+// LogEvents logs every event with the time its handlers took, or their
+// error.
+func LogEvents(logger *log.Logger) EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, data EventData, meta EventMeta) error {
+			start := time.Now()
+			err := next(ctx, data, meta)
+			if err != nil {
+				logger.Printf("%s %d of %s (slot %d) failed: %v", meta.Name, meta.Index, meta.Signature, meta.Slot, err)
+			} else {
+				logger.Printf("%s %d of %s (slot %d) handled in %s", meta.Name, meta.Index, meta.Signature, meta.Slot, time.Since(start))
+			}
+			return err
+		}
+	}
+}
+
+// MeasureEvents calls observe with the time the handlers of every event
+// took and their error, to feed a metrics library.
+func MeasureEvents(observe func(meta EventMeta, elapsed time.Duration, err error)) EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, data EventData, meta EventMeta) error {
+			start := time.Now()
+			err := next(ctx, data, meta)
+			observe(meta, time.Since(start), err)
+			return err
+		}
+	}
+}
+
+// FilterEvents drops the events keep returns false for.
+func FilterEvents(keep func(data EventData, meta EventMeta) bool) EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, data EventData, meta EventMeta) error {
+			if !keep(data, meta) {
+				return nil
+			}
+			return next(ctx, data, meta)
+		}
+	}
+}
+
+// FilterProviders keeps the events of providers, dropping the events of
+// other providers and the events that name no provider, such as policy
+// updates.
+func FilterProviders(providers ...ag_solanago.PublicKey) EventMiddleware {
+	return FilterEvents(func(data EventData, _ EventMeta) bool {
+		provider, ok := eventProvider(data)
+		if !ok {
+			return false
+		}
+		for _, p := range providers {
+			if p.Equals(provider) {
+				return true
+			}
+		}
+		return false
+	})
+}
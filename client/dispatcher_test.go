@@ -0,0 +1,183 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_require "github.com/stretchr/testify/require"
+)
+
+// recorder registers typed handlers for the events of cpiTransaction,
+// recording the events they see.
+type recorder struct {
+	seen []string
+}
+
+func (r *recorder) record(name string, meta EventMeta) {
+	r.seen = append(r.seen, fmt.Sprintf("%s %d %d/%d %d", name, meta.Index, meta.Instruction, meta.Depth, meta.ComputeUnits))
+}
+
+func (r *recorder) register(d *Dispatcher) {
+	d.OnDeviceStaked(func(_ context.Context, data *DeviceStakedEventEventData, meta EventMeta) error {
+		r.record(fmt.Sprintf("staked device %d", data.DeviceId), meta)
+		return nil
+	})
+	d.OnWithdraw(func(_ context.Context, data *WithdrawEventEventData, meta EventMeta) error {
+		r.record(fmt.Sprintf("withdrew %d", data.Amount), meta)
+		return nil
+	})
+	d.OnRewardClaimed(func(_ context.Context, data *RewardClaimedEventEventData, meta EventMeta) error {
+		r.record(fmt.Sprintf("claimed %d", data.Amount), meta)
+		return nil
+	})
+}
+
+func TestDispatcher(t *testing.T) {
+	ctx := context.Background()
+	txData := cpiTransaction(t)
+	txData.Slot = 7
+
+	d := NewDispatcher(testProgramID)
+	var r recorder
+	r.register(d)
+	var metas []EventMeta
+	d.OnAny(func(_ context.Context, _ EventData, meta EventMeta) error {
+		metas = append(metas, meta)
+		return nil
+	})
+	ag_require.NoError(t, d.DispatchTransaction(ctx, txData))
+	ag_require.Equal(t, []string{
+		"staked device 1 0 1/1 30000",
+		"withdrew 5 1 2/2 12000",
+		"claimed 3 2 2/2 12000",
+	}, r.seen)
+	ag_require.Len(t, metas, 3)
+	for _, meta := range metas {
+		ag_require.Equal(t, uint64(7), meta.Slot)
+	}
+	ag_require.Equal(t, "RewardClaimedEvent", metas[2].Name)
+
+	// The logs of the transaction carry the logged events only.
+	r.seen, metas = nil, nil
+	ag_require.NoError(t, d.DispatchLogs(ctx, TransactionLogs{Slot: 7, Logs: txData.Meta.LogMessages}))
+	ag_require.Equal(t, []string{"staked device 1 0 1/1 30000", "withdrew 5 1 2/2 12000"}, r.seen)
+	ag_require.Len(t, metas, 2)
+
+	// Failed transactions emitted nothing.
+	r.seen = nil
+	ag_require.NoError(t, d.DispatchLogs(ctx, TransactionLogs{Err: "failed", Logs: txData.Meta.LogMessages}))
+	txData.Meta.Err = "failed"
+	ag_require.NoError(t, d.DispatchTransaction(ctx, txData))
+	ag_require.Empty(t, r.seen)
+}
+
+func TestDispatcher_Middleware(t *testing.T) {
+	txData := cpiTransaction(t)
+	tx, err := txData.Transaction.GetTransaction()
+	ag_require.NoError(t, err)
+	payer := tx.Message.AccountKeys[0]
+
+	d := NewDispatcher(testProgramID)
+	var r recorder
+	r.register(d)
+	var order []string
+	trace := func(name string) EventMiddleware {
+		return func(next EventHandler) EventHandler {
+			return func(ctx context.Context, data EventData, meta EventMeta) error {
+				order = append(order, name)
+				return next(ctx, data, meta)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	var measured []string
+	d.Use(trace("outer"), LogEvents(log.New(&buf, "", 0)), MeasureEvents(func(meta EventMeta, elapsed time.Duration, err error) {
+		ag_require.NoError(t, err)
+		measured = append(measured, meta.Name)
+	}))
+	d.Use(FilterProviders(payer), trace("inner"))
+
+	ag_require.NoError(t, d.DispatchTransaction(context.Background(), txData))
+	// The withdrawal names no provider.
+	ag_require.Equal(t, []string{"staked device 1 0 1/1 30000", "claimed 3 2 2/2 12000"}, r.seen)
+	ag_require.Equal(t, []string{"outer", "inner", "outer", "outer", "inner"}, order)
+	ag_require.Equal(t, []string{"DeviceStakedEvent", "WithdrawEvent", "RewardClaimedEvent"}, measured)
+	ag_require.Equal(t, 3, strings.Count(buf.String(), "handled in"))
+
+	r.seen = nil
+	d = NewDispatcher(testProgramID)
+	r.register(d)
+	d.Use(FilterProviders(ag_solanago.NewWallet().PublicKey()))
+	ag_require.NoError(t, d.DispatchTransaction(context.Background(), txData))
+	ag_require.Empty(t, r.seen)
+}
+
+func TestDispatcher_Errors(t *testing.T) {
+	ctx := context.Background()
+	txData := cpiTransaction(t)
+	failure := errors.New("store unavailable")
+	newDispatcher := func(failures int) (*Dispatcher, *[]string) {
+		d := NewDispatcher(testProgramID)
+		var delivered []string
+		d.OnAny(func(_ context.Context, _ EventData, meta EventMeta) error {
+			if meta.Name == "DeviceStakedEvent" && failures > 0 {
+				failures--
+				return failure
+			}
+			delivered = append(delivered, meta.Name)
+			return nil
+		})
+		return d, &delivered
+	}
+
+	// StopOnError by default.
+	d, delivered := newDispatcher(1)
+	err := d.DispatchTransaction(ctx, txData)
+	ag_require.ErrorIs(t, err, failure)
+	var handlerErr *HandlerError
+	ag_require.True(t, errors.As(err, &handlerErr))
+	ag_require.Equal(t, "DeviceStakedEvent", handlerErr.Meta.Name)
+	ag_require.Equal(t, 1, handlerErr.Meta.Instruction)
+	ag_require.Empty(t, *delivered)
+
+	d, delivered = newDispatcher(1)
+	d.SetErrorPolicy(ContinueOnError)
+	err = d.DispatchLogs(ctx, TransactionLogs{Logs: txData.Meta.LogMessages})
+	ag_require.ErrorIs(t, err, failure)
+	ag_require.Equal(t, []string{"WithdrawEvent"}, *delivered)
+
+	// Only the failing handler is called again.
+	d, delivered = newDispatcher(2)
+	var r recorder
+	r.register(d)
+	d.SetRetry(3, time.Millisecond)
+	ag_require.NoError(t, d.DispatchTransaction(ctx, txData))
+	ag_require.Equal(t, []string{"DeviceStakedEvent", "WithdrawEvent", "RewardClaimedEvent"}, *delivered)
+	ag_require.Len(t, r.seen, 3)
+
+	d, _ = newDispatcher(3)
+	d.SetRetry(3, time.Millisecond)
+	ag_require.ErrorIs(t, d.DispatchTransaction(ctx, txData), failure)
+
+	d, delivered = newDispatcher(0)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	ag_require.ErrorIs(t, d.DispatchTransaction(canceled, txData), context.Canceled)
+	ag_require.ErrorIs(t, d.DispatchLogs(canceled, TransactionLogs{Logs: txData.Meta.LogMessages}), context.Canceled)
+	ag_require.Empty(t, *delivered)
+
+	// Payloads that do not decode are reported next to the events that did.
+	unknown := eventBytes(t, &WithdrawEventEventData{})
+	copy(unknown, "unknown!")
+	d, delivered = newDispatcher(0)
+	err = d.DispatchTransaction(ctx, emitTransaction(t, [][]byte{unknown, eventBytes(t, &WithdrawEventEventData{})}, nil))
+	ag_require.ErrorIs(t, err, ErrUnknownEvent)
+	ag_require.Equal(t, []string{"WithdrawEvent"}, *delivered)
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	ag_binary "github.com/gagliardetto/binary"
+	ag_solanago "github.com/gagliardetto/solana-go"
+)
+
+// jsonUint64 and jsonInt64 are 64-bit integers marshalled as JSON strings,
+// which JavaScript reads without losing precision. They unmarshal from
+// strings and numbers.
+type (
+	jsonUint64 uint64
+	jsonInt64  int64
+)
+
+func (v jsonUint64) MarshalJSON() ([]byte, error) {
+	return strconv.AppendQuote(nil, strconv.FormatUint(uint64(v), 10)), nil
+}
+
+func (v *jsonUint64) UnmarshalJSON(data []byte) error {
+	n, err := strconv.ParseUint(string(unquoteNumber(data)), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid u64 %s", data)
+	}
+	*v = jsonUint64(n)
+	return nil
+}
+
+func (v jsonInt64) MarshalJSON() ([]byte, error) {
+	return strconv.AppendQuote(nil, strconv.FormatInt(int64(v), 10)), nil
+}
+
+func (v *jsonInt64) UnmarshalJSON(data []byte) error {
+	n, err := strconv.ParseInt(string(unquoteNumber(data)), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid i64 %s", data)
+	}
+	*v = jsonInt64(n)
+	return nil
+}
+
+func unquoteNumber(data []byte) []byte {
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		return data[1 : len(data)-1]
+	}
+	return data
+}
+
+// convertInts converts a slice of 64-bit integers to and from its JSON form,
+// keeping nil slices nil.
+func convertInts[T, F ~uint64 | ~int64](in []F) []T {
+	if in == nil {
+		return nil
+	}
+	out := make([]T, len(in))
+	for i, v := range in {
+		out[i] = T(v)
+	}
+	return out
+}
+
+// AmountsMarshaler is a generated account, type or event, which marshals to
+// JSON with decimal companions next to its token amounts when wrapped by
+// WithAmountDecimals.
+type AmountsMarshaler interface {
+	jsonValue(decimals int) interface{}
+}
+
+// WithAmountDecimals wraps v so that it marshals to JSON with a decimal
+// "<field>_ui" string next to every token amount, such as "amount_ui":
+// "1.500000000" next to "amount": "1500000000" for 9 decimals, the decimals
+// of the supernode token mint. The types nested in v get the companions too.
+func WithAmountDecimals(v AmountsMarshaler, decimals uint8) json.Marshaler {
+	return amountsJSON{v: v, decimals: decimals}
+}
+
+type amountsJSON struct {
+	v        AmountsMarshaler
+	decimals uint8
+}
+
+func (a amountsJSON) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.v.jsonValue(int(a.decimals)))
+}
+
+// jsonValues returns the JSON forms of values, keeping nil slices nil.
+func jsonValues[T AmountsMarshaler](values []T, decimals int) []interface{} {
+	if values == nil {
+		return nil
+	}
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v.jsonValue(decimals)
+	}
+	return out
+}
+
+// formatAmount returns v in token units, or nil when decimals is negative.
+func formatAmount(v uint64, decimals int) *string {
+	if decimals < 0 {
+		return nil
+	}
+	digits := strconv.FormatUint(v, 10)
+	if decimals == 0 {
+		return &digits
+	}
+	if len(digits) <= decimals {
+		digits = string(bytes.Repeat([]byte{'0'}, decimals-len(digits)+1)) + digits
+	}
+	point := len(digits) - decimals
+	s := digits[:point] + "." + digits[point:]
+	return &s
+}
+
+// checkJSONName checks the account or event name of a JSON object, which may
+// be left out.
+func checkJSONName(tag, got, want string) error {
+	if got != "" && got != want {
+		return fmt.Errorf("%s is %s, want %s", tag, got, want)
+	}
+	return nil
+}
+
+// listLabel and itemLabel label the branches of a list in EncodeToTree.
+func listLabel(name string, n int) string {
+	return fmt.Sprintf("%s[len=%d]", name, n)
+}
+
+func itemLabel(i int) string {
+	return fmt.Sprintf("[%d]", i)
+}
+
+// readPublicKey reads a public key as Decode does, without reflection.
+func readPublicKey(decoder *ag_binary.Decoder, key *ag_solanago.PublicKey) error {
+	data, err := decoder.ReadNBytes(ag_solanago.PublicKeyLength)
+	if err != nil {
+		return err
+	}
+	copy(key[:], data)
+	return nil
+}
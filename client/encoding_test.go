@@ -0,0 +1,91 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_text "github.com/gagliardetto/solana-go/text"
+	ag_treeout "github.com/gagliardetto/treeout"
+	ag_require "github.com/stretchr/testify/require"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	provider := ag_solanago.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy")
+	event := &DeviceStakedEventEventData{Provider: provider, DeviceId: 1<<63 + 1, SpecId: 2, Amount: 1500000000}
+
+	data, err := json.Marshal(event)
+	ag_require.NoError(t, err)
+	ag_require.JSONEq(t, `{
+		"event": "DeviceStakedEvent",
+		"provider": "549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy",
+		"device_id": "9223372036854775809",
+		"spec_id": "2",
+		"amount": "1500000000"
+	}`, string(data))
+
+	data, err = json.Marshal(WithAmountDecimals(event, 9))
+	ag_require.NoError(t, err)
+	ag_require.Contains(t, string(data), `"amount_ui":"1.500000000"`)
+	ag_require.NotContains(t, string(data), `"device_id_ui"`)
+
+	// Nested types get the companions too, and other values none.
+	info := ProviderVestingInfoAccount{ReleasedAmount: 7, Schedules: []Schedule{{Day: 3, Amount: 9}}}
+	data, err = json.Marshal(WithAmountDecimals(info, 2))
+	ag_require.NoError(t, err)
+	ag_require.Contains(t, string(data), `"released_amount_ui":"0.07"`)
+	ag_require.Contains(t, string(data), `"schedules":[{"day":3,"amount":"9","amount_ui":"0.09"}]`)
+	data, err = json.Marshal(info)
+	ag_require.NoError(t, err)
+	ag_require.NotContains(t, string(data), `_ui`)
+
+	t.Run("numbers", func(t *testing.T) {
+		var got TenantInfoAccount
+		ag_require.NoError(t, json.Unmarshal([]byte(`{"funds": 5, "withdrawn": "3", "funds_ui": "0.000000005"}`), &got))
+		ag_require.Equal(t, TenantInfoAccount{Funds: 5, Withdrawn: 3}, got)
+	})
+
+	t.Run("wrong name", func(t *testing.T) {
+		var got TenantInfoAccount
+		err := json.Unmarshal([]byte(`{"account": "SupernodeState"}`), &got)
+		ag_require.Error(t, err)
+		ag_require.Contains(t, err.Error(), "account is SupernodeState, want TenantInfo")
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		var got TenantInfoAccount
+		ag_require.Error(t, json.Unmarshal([]byte(`{"funds": "18446744073709551616"}`), &got))
+	})
+}
+
+func TestFormatAmount(t *testing.T) {
+	ag_require.Nil(t, formatAmount(1, -1))
+	for _, c := range []struct {
+		decimals int
+		v        uint64
+		want     string
+	}{
+		{0, 12, "12"},
+		{2, 0, "0.00"},
+		{2, 5, "0.05"},
+		{2, 1234, "12.34"},
+		{9, 1<<64 - 1, "18446744073.709551615"},
+	} {
+		ag_require.Equal(t, c.want, *formatAmount(c.v, c.decimals))
+	}
+}
+
+func TestTextEncode(t *testing.T) {
+	info := &ProviderVestingInfoAccount{ReleasedAmount: 7, Schedules: []Schedule{{Day: 3, Amount: 9}}}
+	buf := new(bytes.Buffer)
+	ag_require.NoError(t, ag_text.NewEncoder(buf).Encode(info, nil))
+	ag_require.Contains(t, buf.String(), `"account":"ProviderVestingInfo"`)
+	ag_require.Contains(t, buf.String(), `"schedules":[{"day":3,"amount":"9"}]`)
+
+	tree := ag_treeout.New("")
+	info.EncodeToTree(tree)
+	ag_require.Contains(t, tree.String(), "ProviderVestingInfo")
+	ag_require.Contains(t, tree.String(), "Schedules[len=1]")
+	ag_require.Contains(t, tree.String(), "Day")
+}
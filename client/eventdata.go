@@ -0,0 +1,162 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	ag_binary "github.com/gagliardetto/binary"
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_rpc "github.com/gagliardetto/solana-go/rpc"
+)
+
+// eventIxTag prefixes the data of the self-invocations Anchor's emit_cpi!
+// makes to carry an event: the first 8 bytes of sha256("anchor:event").
+var eventIxTag = [8]byte{0xe4, 0x45, 0xa5, 0x2e, 0x51, 0xcb, 0x9a, 0x1d}
+
+var (
+	// ErrShortEvent is reported for event data shorter than a
+	// discriminator.
+	ErrShortEvent = errors.New("event data shorter than its discriminator")
+	// ErrUnknownEvent is reported for event data whose discriminator is not
+	// one of the IDL's events, such as an event of a newer program version.
+	ErrUnknownEvent = errors.New("unknown event discriminator")
+)
+
+// EventError is an event payload DecodeEvents could not decode.
+type EventError struct {
+	// Instruction is the outer instruction the payload was emitted under
+	// and Inner the position of its self-CPI among the inner instructions
+	// of that instruction, -1 for a payload logged in a "Program data: "
+	// line.
+	Instruction int
+	Inner       int
+	// Discriminator is the first 8 bytes of the payload, zero when it is
+	// shorter.
+	Discriminator [8]byte
+	Err           error
+}
+
+func (e *EventError) Error() string {
+	if e.Inner < 0 {
+		return fmt.Sprintf("event logged by instruction %d: %v", e.Instruction, e.Err)
+	}
+	return fmt.Sprintf("event of inner instruction %d of instruction %d: %v", e.Inner, e.Instruction, e.Err)
+}
+
+// Unwrap returns Err, so errors.Is matches ErrShortEvent and
+// ErrUnknownEvent.
+func (e *EventError) Unwrap() error {
+	return e.Err
+}
+
+// eventPayload is the data of an event with the invocation that emitted it.
+type eventPayload struct {
+	data []byte
+	// line is the position of the data line in the logs of a logged event.
+	line         int
+	instruction  int
+	inner        int
+	depth        int
+	computeUnits uint64
+}
+
+func (p eventPayload) error(err error) *EventError {
+	e := &EventError{Instruction: p.instruction, Inner: p.inner, Err: err}
+	copy(e.Discriminator[:], p.data)
+	return e
+}
+
+// eventsFromLogs returns the data lines programID logged in instructions,
+// in the order they were logged.
+func eventsFromLogs(instructions []*Invocation, programID ag_solanago.PublicKey) (payloads []eventPayload) {
+	var walk func(inv *Invocation)
+	walk = func(inv *Invocation) {
+		if inv.ProgramID.Equals(programID) {
+			for i, data := range inv.Data {
+				payloads = append(payloads, eventPayload{data: data, line: inv.dataLines[i], instruction: inv.Instruction, inner: -1, depth: inv.Depth, computeUnits: inv.ComputeUnits})
+			}
+		}
+		for _, call := range inv.Calls {
+			walk(call)
+		}
+	}
+	for _, inv := range instructions {
+		walk(inv)
+	}
+	// An invocation logs around the calls it makes.
+	slices.SortFunc(payloads, func(a, b eventPayload) int { return a.line - b.line })
+	return
+}
+
+// eventsFromEmitCPI returns the events programID emitted through self-CPIs:
+// its inner instructions whose data starts with the event tag. Other inner
+// instructions of programID are CPIs into it, not events.
+func eventsFromEmitCPI(innerInstructions []ag_rpc.InnerInstruction, accountKeys ag_solanago.PublicKeySlice, programID ag_solanago.PublicKey, instructions []*Invocation) (payloads []eventPayload, err error) {
+	for _, inner := range innerInstructions {
+		// The inner instructions of an outer instruction are its CPIs in
+		// the order the log tree holds them.
+		var calls []*Invocation
+		if int(inner.Index) < len(instructions) {
+			calls = instructions[inner.Index].calls()
+		}
+		for j, ix := range inner.Instructions {
+			if int(ix.ProgramIDIndex) >= len(accountKeys) {
+				return nil, fmt.Errorf("inner instruction %d of instruction %d: program index %d out of %d accounts", j, inner.Index, ix.ProgramIDIndex, len(accountKeys))
+			}
+			if !accountKeys[ix.ProgramIDIndex].Equals(programID) || !bytes.HasPrefix(ix.Data, eventIxTag[:]) {
+				continue
+			}
+			payload := eventPayload{data: ix.Data[len(eventIxTag):], instruction: int(inner.Index), inner: j}
+			// The event is emitted by the invocation making the self-CPI.
+			if j < len(calls) && calls[j].ProgramID.Equals(programID) {
+				emitter := calls[j].Parent
+				payload.depth, payload.computeUnits = emitter.Depth, emitter.ComputeUnits
+			}
+			payloads = append(payloads, payload)
+		}
+	}
+	return
+}
+
+// dedupeEvents drops the emitted payloads that repeat one logged by the
+// same invocation, for programs that both emit! and emit_cpi! an event.
+// Each logged payload absorbs at most one emitted copy.
+func dedupeEvents(logged, emitted []eventPayload) []eventPayload {
+	matched := make([]bool, len(logged))
+	out := logged[:len(logged):len(logged)]
+	for _, cpi := range emitted {
+		duplicate := false
+		for i, log := range logged {
+			if matched[i] || log.instruction != cpi.instruction || (cpi.depth != 0 && log.depth != cpi.depth) {
+				continue
+			}
+			if bytes.Equal(log.data, cpi.data) {
+				matched[i], duplicate = true, true
+				break
+			}
+		}
+		if !duplicate {
+			out = append(out, cpi)
+		}
+	}
+	return out
+}
+
+// decode decodes p with decoder into a value of values, or a new value when
+// values is nil.
+func (p eventPayload) decode(decoder *ag_binary.Decoder, values *eventValues) (Event, *EventError) {
+	if len(p.data) < 8 {
+		return Event{}, p.error(ErrShortEvent)
+	}
+	name, data, ok := values.get([8]byte(p.data))
+	if !ok {
+		return Event{}, p.error(ErrUnknownEvent)
+	}
+	decoder.Reset(p.data)
+	if err := data.UnmarshalWithDecoder(decoder); err != nil {
+		return Event{}, p.error(fmt.Errorf("failed to unmarshal event %s: %w", name, err))
+	}
+	return Event{Name: name, Data: data, Instruction: p.instruction, Depth: p.depth, ComputeUnits: p.computeUnits}, nil
+}
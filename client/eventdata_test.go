@@ -0,0 +1,210 @@
+package client
+
+import (
+	"encoding/base64"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_rpc "github.com/gagliardetto/solana-go/rpc"
+	ag_require "github.com/stretchr/testify/require"
+)
+
+// emitTransaction is a transaction of one supernode instruction that logs
+// the payloads of logged and makes one inner instruction of the program per
+// payload of emitted, its data taken as is.
+func emitTransaction(t *testing.T, logged, emitted [][]byte) *ag_rpc.GetTransactionResult {
+	payer := ag_solanago.NewWallet().PublicKey()
+	tx, err := ag_solanago.NewTransaction([]ag_solanago.Instruction{
+		ag_solanago.NewInstruction(testProgramID, nil, make([]byte, 8)),
+	}, ag_solanago.Hash{}, ag_solanago.TransactionPayer(payer))
+	ag_require.NoError(t, err)
+	programIndex, err := tx.Message.GetAccountIndex(testProgramID)
+	ag_require.NoError(t, err)
+
+	logs := []string{logLine(testProgramID, "invoke [1]")}
+	for _, data := range logged {
+		logs = append(logs, eventLogPrefix+base64.StdEncoding.EncodeToString(data))
+	}
+	inner := ag_rpc.InnerInstruction{Index: 0}
+	for _, data := range emitted {
+		logs = append(logs, logLine(testProgramID, "invoke [2]"), logLine(testProgramID, "success"))
+		inner.Instructions = append(inner.Instructions, ag_solanago.CompiledInstruction{ProgramIDIndex: programIndex, Data: data})
+	}
+	logs = append(logs, logLine(testProgramID, "consumed 5000 of 200000 compute units"), logLine(testProgramID, "success"))
+	return transactionResult(t, tx, logs, []ag_rpc.InnerInstruction{inner})
+}
+
+func eventBytes(t *testing.T, event roundTripper) []byte {
+	data, err := marshal(event)
+	ag_require.NoError(t, err)
+	return data
+}
+
+func tagged(data []byte) []byte {
+	return append(eventIxTag[:], data...)
+}
+
+func TestDecodeEvents_EmitCPI(t *testing.T) {
+	staked := eventBytes(t, &DeviceStakedEventEventData{DeviceId: 4, Amount: 10})
+	withdrawn := eventBytes(t, &WithdrawEventEventData{Amount: 5})
+	unknown := append([]byte{1, 2, 3, 4, 5, 6, 7, 8}, staked[8:]...)
+
+	for _, c := range []struct {
+		name    string
+		logged  [][]byte
+		emitted [][]byte
+		events  []string
+		// errs are the Inner positions of the payloads reported, with the
+		// error they wrap.
+		errs map[int]error
+	}{
+		{
+			name:    "untagged instructions are not events",
+			emitted: [][]byte{nil, {1, 2, 3}, staked, tagged(staked)},
+			events:  []string{"DeviceStakedEvent"},
+		},
+		{
+			name:    "short payloads",
+			emitted: [][]byte{tagged(nil), tagged(staked[:5])},
+			errs:    map[int]error{0: ErrShortEvent, 1: ErrShortEvent},
+		},
+		{
+			name:    "unknown discriminators",
+			logged:  [][]byte{unknown, withdrawn},
+			emitted: [][]byte{tagged(unknown[:12]), tagged(staked)},
+			events:  []string{"WithdrawEvent", "DeviceStakedEvent"},
+			errs:    map[int]error{-1: ErrUnknownEvent, 0: ErrUnknownEvent},
+		},
+		{
+			name:    "truncated event",
+			emitted: [][]byte{tagged(staked[:len(staked)-1])},
+			errs:    map[int]error{0: nil},
+		},
+		{
+			name:    "logged and emitted once",
+			logged:  [][]byte{staked, withdrawn},
+			emitted: [][]byte{tagged(withdrawn), tagged(staked)},
+			events:  []string{"DeviceStakedEvent", "WithdrawEvent"},
+		},
+		{
+			name:    "emitted twice, logged once",
+			logged:  [][]byte{staked},
+			emitted: [][]byte{tagged(staked), tagged(staked)},
+			events:  []string{"DeviceStakedEvent", "DeviceStakedEvent"},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			evts, err := DecodeEvents(emitTransaction(t, c.logged, c.emitted), testProgramID, nil)
+			var names []string
+			for _, evt := range evts {
+				names = append(names, evt.Name)
+				ag_require.Equal(t, 0, evt.Instruction)
+				ag_require.Equal(t, 1, evt.Depth)
+				ag_require.Equal(t, uint64(5000), evt.ComputeUnits)
+			}
+			ag_require.Equal(t, c.events, names)
+			if len(c.errs) == 0 {
+				ag_require.NoError(t, err)
+				return
+			}
+			ag_require.Error(t, err)
+			reported := map[int]error{}
+			for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+				var eventErr *EventError
+				ag_require.True(t, errors.As(err, &eventErr), "%v", err)
+				ag_require.Equal(t, 0, eventErr.Instruction)
+				reported[eventErr.Inner] = eventErr.Err
+			}
+			ag_require.Len(t, reported, len(c.errs))
+			for inner, want := range c.errs {
+				ag_require.Contains(t, reported, inner)
+				if want != nil {
+					ag_require.ErrorIs(t, reported[inner], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeEvents_ProgramIndexOutOfRange(t *testing.T) {
+	txData := emitTransaction(t, nil, [][]byte{tagged(nil)})
+	txData.Meta.InnerInstructions[0].Instructions[0].ProgramIDIndex = 200
+	_, err := DecodeEvents(txData, testProgramID, nil)
+	ag_require.Error(t, err)
+	ag_require.Contains(t, err.Error(), "program index 200 out of")
+}
+
+// TestDecodeEvents_NoPanic decodes random payloads, logged and emitted.
+func TestDecodeEvents_NoPanic(t *testing.T) {
+	rng := rand.New(rand.NewSource(48))
+	discriminators := [][8]byte{DeviceStakedEventEventDataDiscriminator, WithdrawEventEventDataDiscriminator, eventIxTag}
+	payload := func() []byte {
+		data := make([]byte, rng.Intn(80))
+		rng.Read(data)
+		if len(data) >= 8 && rng.Intn(2) == 0 {
+			copy(data, discriminators[rng.Intn(len(discriminators))][:])
+		}
+		return data
+	}
+	for i := 0; i < 500; i++ {
+		logged := [][]byte{payload(), payload()}
+		emitted := [][]byte{payload(), tagged(payload())}
+		ag_require.NotPanics(t, func() {
+			_, _ = DecodeEvents(emitTransaction(t, logged, emitted), testProgramID, nil)
+		})
+	}
+}
+
+// benchmarkLogs returns the logs of a transaction of one supernode
+// instruction per golden event, each logging its event.
+func benchmarkLogs(b testing.TB) []string {
+	captures, err := filepath.Glob(filepath.Join(goldenDir, "events", "*.log"))
+	ag_require.NoError(b, err)
+	ag_require.NotEmpty(b, captures)
+	var logs []string
+	for _, capture := range captures {
+		line, err := os.ReadFile(capture)
+		ag_require.NoError(b, err)
+		logs = append(logs,
+			logLine(testProgramID, "invoke [1]"),
+			"Program log: Instruction: StakeDevice",
+			strings.TrimSpace(string(line)),
+			logLine(testProgramID, "consumed 30000 of 200000 compute units"),
+			logLine(testProgramID, "success"),
+		)
+	}
+	return logs
+}
+
+func BenchmarkParseEvents(b *testing.B) {
+	instructions, err := ParseLogs(benchmarkLogs(b))
+	ag_require.NoError(b, err)
+	payloads := eventsFromLogs(instructions, testProgramID)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parseEvents(payloads); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeEvents(b *testing.B) {
+	logs := benchmarkLogs(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		instructions, err := ParseLogs(logs)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := parseEvents(eventsFromLogs(instructions, testProgramID)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
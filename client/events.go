@@ -1,16 +1,22 @@
-// Code generated by https://github.com/gagliardetto/anchor-go. DO NOT EDIT.
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
 
 package client
 
 import (
+	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	ag_binary "github.com/gagliardetto/binary"
 	ag_solanago "github.com/gagliardetto/solana-go"
 	ag_rpc "github.com/gagliardetto/solana-go/rpc"
+	ag_text "github.com/gagliardetto/solana-go/text"
+	ag_format "github.com/gagliardetto/solana-go/text/format"
+	ag_treeout "github.com/gagliardetto/treeout"
 	ag_base58 "github.com/mr-tron/base58"
-	"reflect"
 	"strings"
+	"sync"
 )
 
 type ClaimRentalFeeEventEventData struct {
@@ -56,27 +62,92 @@ func (obj *ClaimRentalFeeEventEventData) UnmarshalWithDecoder(decoder *ag_binary
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[233 172 46 252 236 68 236 26]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Controller`:
-	err = decoder.Decode(&obj.Controller)
+	err = readPublicKey(decoder, &obj.Controller)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of ClaimRentalFeeEventEventData under their IDL names, next to
+// its "event" name. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj ClaimRentalFeeEventEventData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of ClaimRentalFeeEventEventData, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj ClaimRentalFeeEventEventData) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName   string                `json:"event"`
+		Provider   ag_solanago.PublicKey `json:"provider"`
+		Controller ag_solanago.PublicKey `json:"controller"`
+		Amount     jsonUint64            `json:"amount"`
+		AmountUI   *string               `json:"amount_ui,omitempty"`
+	}{
+		JSONName:   "ClaimRentalFeeEvent",
+		Provider:   obj.Provider,
+		Controller: obj.Controller,
+		Amount:     jsonUint64(obj.Amount),
+		AmountUI:   formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *ClaimRentalFeeEventEventData) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName   string                `json:"event"`
+		Provider   ag_solanago.PublicKey `json:"provider"`
+		Controller ag_solanago.PublicKey `json:"controller"`
+		Amount     jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("event", in.JSONName, "ClaimRentalFeeEvent"); err != nil {
+		return err
+	}
+	obj.Provider = in.Provider
+	obj.Controller = in.Controller
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of ClaimRentalFeeEventEventData for solana-go's text encoder.
+func (obj ClaimRentalFeeEventEventData) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj ClaimRentalFeeEventEventData) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Event")) + ": " + ag_text.Bold("ClaimRentalFeeEvent")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Account("Provider", obj.Provider))
+			parent.Child(ag_format.Account("Controller", obj.Controller))
+			parent.Child(ag_format.Param("Amount", obj.Amount))
+		})
+}
+
 func (*ClaimRentalFeeEventEventData) isEventData() {}
 
 type DeviceKValueUpdatedEventData struct {
@@ -128,32 +199,99 @@ func (obj *DeviceKValueUpdatedEventData) UnmarshalWithDecoder(decoder *ag_binary
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[145 201 163 41 27 221 84 22]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `SpecId`:
-	err = decoder.Decode(&obj.SpecId)
+	obj.SpecId, err = decoder.ReadUint16(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Old`:
-	err = decoder.Decode(&obj.Old)
+	obj.Old, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `New`:
-	err = decoder.Decode(&obj.New)
+	obj.New, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Admin`:
-	err = decoder.Decode(&obj.Admin)
+	err = readPublicKey(decoder, &obj.Admin)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of DeviceKValueUpdatedEventData under their IDL names, next to
+// its "event" name. 64-bit integers are strings.
+func (obj DeviceKValueUpdatedEventData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of DeviceKValueUpdatedEventData, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj DeviceKValueUpdatedEventData) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName string                `json:"event"`
+		SpecId   uint16                `json:"spec_id"`
+		Old      jsonUint64            `json:"old"`
+		New      jsonUint64            `json:"new"`
+		Admin    ag_solanago.PublicKey `json:"admin"`
+	}{
+		JSONName: "DeviceKValueUpdated",
+		SpecId:   obj.SpecId,
+		Old:      jsonUint64(obj.Old),
+		New:      jsonUint64(obj.New),
+		Admin:    obj.Admin,
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *DeviceKValueUpdatedEventData) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName string                `json:"event"`
+		SpecId   uint16                `json:"spec_id"`
+		Old      jsonUint64            `json:"old"`
+		New      jsonUint64            `json:"new"`
+		Admin    ag_solanago.PublicKey `json:"admin"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("event", in.JSONName, "DeviceKValueUpdated"); err != nil {
+		return err
+	}
+	obj.SpecId = in.SpecId
+	obj.Old = uint64(in.Old)
+	obj.New = uint64(in.New)
+	obj.Admin = in.Admin
+	return nil
+}
+
+// TextEncode writes the JSON of DeviceKValueUpdatedEventData for solana-go's text encoder.
+func (obj DeviceKValueUpdatedEventData) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj DeviceKValueUpdatedEventData) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Event")) + ": " + ag_text.Bold("DeviceKValueUpdated")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Param("SpecId", obj.SpecId))
+			parent.Child(ag_format.Param("Old", obj.Old))
+			parent.Child(ag_format.Param("New", obj.New))
+			parent.Child(ag_format.Account("Admin", obj.Admin))
+		})
+}
+
 func (*DeviceKValueUpdatedEventData) isEventData() {}
 
 type DeviceStakedEventEventData struct {
@@ -205,32 +343,102 @@ func (obj *DeviceStakedEventEventData) UnmarshalWithDecoder(decoder *ag_binary.D
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[167 219 80 186 13 21 172 123]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `DeviceId`:
-	err = decoder.Decode(&obj.DeviceId)
+	obj.DeviceId, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `SpecId`:
-	err = decoder.Decode(&obj.SpecId)
+	obj.SpecId, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of DeviceStakedEventEventData under their IDL names, next to
+// its "event" name. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj DeviceStakedEventEventData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of DeviceStakedEventEventData, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj DeviceStakedEventEventData) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName string                `json:"event"`
+		Provider ag_solanago.PublicKey `json:"provider"`
+		DeviceId jsonUint64            `json:"device_id"`
+		SpecId   jsonUint64            `json:"spec_id"`
+		Amount   jsonUint64            `json:"amount"`
+		AmountUI *string               `json:"amount_ui,omitempty"`
+	}{
+		JSONName: "DeviceStakedEvent",
+		Provider: obj.Provider,
+		DeviceId: jsonUint64(obj.DeviceId),
+		SpecId:   jsonUint64(obj.SpecId),
+		Amount:   jsonUint64(obj.Amount),
+		AmountUI: formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *DeviceStakedEventEventData) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName string                `json:"event"`
+		Provider ag_solanago.PublicKey `json:"provider"`
+		DeviceId jsonUint64            `json:"device_id"`
+		SpecId   jsonUint64            `json:"spec_id"`
+		Amount   jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("event", in.JSONName, "DeviceStakedEvent"); err != nil {
+		return err
+	}
+	obj.Provider = in.Provider
+	obj.DeviceId = uint64(in.DeviceId)
+	obj.SpecId = uint64(in.SpecId)
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of DeviceStakedEventEventData for solana-go's text encoder.
+func (obj DeviceStakedEventEventData) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj DeviceStakedEventEventData) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Event")) + ": " + ag_text.Bold("DeviceStakedEvent")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Account("Provider", obj.Provider))
+			parent.Child(ag_format.Param("DeviceId", obj.DeviceId))
+			parent.Child(ag_format.Param("SpecId", obj.SpecId))
+			parent.Child(ag_format.Param("Amount", obj.Amount))
+		})
+}
+
 func (*DeviceStakedEventEventData) isEventData() {}
 
 type DeviceUnstakeEventEventData struct {
@@ -282,32 +490,102 @@ func (obj *DeviceUnstakeEventEventData) UnmarshalWithDecoder(decoder *ag_binary.
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[219 57 57 40 225 213 205 161]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `DeviceId`:
-	err = decoder.Decode(&obj.DeviceId)
+	obj.DeviceId, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `ProviderVestingInfoKey`:
-	err = decoder.Decode(&obj.ProviderVestingInfoKey)
+	err = readPublicKey(decoder, &obj.ProviderVestingInfoKey)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of DeviceUnstakeEventEventData under their IDL names, next to
+// its "event" name. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj DeviceUnstakeEventEventData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of DeviceUnstakeEventEventData, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj DeviceUnstakeEventEventData) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName               string                `json:"event"`
+		Provider               ag_solanago.PublicKey `json:"provider"`
+		DeviceId               jsonUint64            `json:"device_id"`
+		Amount                 jsonUint64            `json:"amount"`
+		AmountUI               *string               `json:"amount_ui,omitempty"`
+		ProviderVestingInfoKey ag_solanago.PublicKey `json:"provider_vesting_info_key"`
+	}{
+		JSONName:               "DeviceUnstakeEvent",
+		Provider:               obj.Provider,
+		DeviceId:               jsonUint64(obj.DeviceId),
+		Amount:                 jsonUint64(obj.Amount),
+		AmountUI:               formatAmount(obj.Amount, decimals),
+		ProviderVestingInfoKey: obj.ProviderVestingInfoKey,
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *DeviceUnstakeEventEventData) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName               string                `json:"event"`
+		Provider               ag_solanago.PublicKey `json:"provider"`
+		DeviceId               jsonUint64            `json:"device_id"`
+		Amount                 jsonUint64            `json:"amount"`
+		ProviderVestingInfoKey ag_solanago.PublicKey `json:"provider_vesting_info_key"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("event", in.JSONName, "DeviceUnstakeEvent"); err != nil {
+		return err
+	}
+	obj.Provider = in.Provider
+	obj.DeviceId = uint64(in.DeviceId)
+	obj.Amount = uint64(in.Amount)
+	obj.ProviderVestingInfoKey = in.ProviderVestingInfoKey
+	return nil
+}
+
+// TextEncode writes the JSON of DeviceUnstakeEventEventData for solana-go's text encoder.
+func (obj DeviceUnstakeEventEventData) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj DeviceUnstakeEventEventData) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Event")) + ": " + ag_text.Bold("DeviceUnstakeEvent")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Account("Provider", obj.Provider))
+			parent.Child(ag_format.Param("DeviceId", obj.DeviceId))
+			parent.Child(ag_format.Param("Amount", obj.Amount))
+			parent.Child(ag_format.Account("ProviderVestingInfoKey", obj.ProviderVestingInfoKey))
+		})
+}
+
 func (*DeviceUnstakeEventEventData) isEventData() {}
 
 type PayRentalEventEventData struct {
@@ -347,22 +625,82 @@ func (obj *PayRentalEventEventData) UnmarshalWithDecoder(decoder *ag_binary.Deco
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[226 244 245 60 4 57 138 93]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Tenant`:
-	err = decoder.Decode(&obj.Tenant)
+	err = readPublicKey(decoder, &obj.Tenant)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of PayRentalEventEventData under their IDL names, next to
+// its "event" name. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj PayRentalEventEventData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of PayRentalEventEventData, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj PayRentalEventEventData) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName string                `json:"event"`
+		Tenant   ag_solanago.PublicKey `json:"tenant"`
+		Amount   jsonUint64            `json:"amount"`
+		AmountUI *string               `json:"amount_ui,omitempty"`
+	}{
+		JSONName: "PayRentalEvent",
+		Tenant:   obj.Tenant,
+		Amount:   jsonUint64(obj.Amount),
+		AmountUI: formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *PayRentalEventEventData) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName string                `json:"event"`
+		Tenant   ag_solanago.PublicKey `json:"tenant"`
+		Amount   jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("event", in.JSONName, "PayRentalEvent"); err != nil {
+		return err
+	}
+	obj.Tenant = in.Tenant
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of PayRentalEventEventData for solana-go's text encoder.
+func (obj PayRentalEventEventData) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj PayRentalEventEventData) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Event")) + ": " + ag_text.Bold("PayRentalEvent")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Account("Tenant", obj.Tenant))
+			parent.Child(ag_format.Param("Amount", obj.Amount))
+		})
+}
+
 func (*PayRentalEventEventData) isEventData() {}
 
 type ProviderControllerChangedEventEventData struct {
@@ -420,37 +758,109 @@ func (obj *ProviderControllerChangedEventEventData) UnmarshalWithDecoder(decoder
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[216 183 242 141 71 46 230 182]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Action`:
-	err = decoder.Decode(&obj.Action)
+	obj.Action, err = decoder.ReadString()
 	if err != nil {
 		return err
 	}
 	// Deserialize `NewController`:
-	err = decoder.Decode(&obj.NewController)
+	err = readPublicKey(decoder, &obj.NewController)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Operator`:
-	err = decoder.Decode(&obj.Operator)
+	err = readPublicKey(decoder, &obj.Operator)
 	if err != nil {
 		return err
 	}
 	// Deserialize `OldController`:
-	err = decoder.Decode(&obj.OldController)
+	err = readPublicKey(decoder, &obj.OldController)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of ProviderControllerChangedEventEventData under their IDL names, next to
+// its "event" name. 64-bit integers are strings.
+func (obj ProviderControllerChangedEventEventData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of ProviderControllerChangedEventEventData, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj ProviderControllerChangedEventEventData) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName      string                `json:"event"`
+		Provider      ag_solanago.PublicKey `json:"provider"`
+		Action        string                `json:"action"`
+		NewController ag_solanago.PublicKey `json:"new_controller"`
+		Operator      ag_solanago.PublicKey `json:"operator"`
+		OldController ag_solanago.PublicKey `json:"old_controller"`
+	}{
+		JSONName:      "ProviderControllerChangedEvent",
+		Provider:      obj.Provider,
+		Action:        obj.Action,
+		NewController: obj.NewController,
+		Operator:      obj.Operator,
+		OldController: obj.OldController,
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *ProviderControllerChangedEventEventData) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName      string                `json:"event"`
+		Provider      ag_solanago.PublicKey `json:"provider"`
+		Action        string                `json:"action"`
+		NewController ag_solanago.PublicKey `json:"new_controller"`
+		Operator      ag_solanago.PublicKey `json:"operator"`
+		OldController ag_solanago.PublicKey `json:"old_controller"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("event", in.JSONName, "ProviderControllerChangedEvent"); err != nil {
+		return err
+	}
+	obj.Provider = in.Provider
+	obj.Action = in.Action
+	obj.NewController = in.NewController
+	obj.Operator = in.Operator
+	obj.OldController = in.OldController
+	return nil
+}
+
+// TextEncode writes the JSON of ProviderControllerChangedEventEventData for solana-go's text encoder.
+func (obj ProviderControllerChangedEventEventData) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj ProviderControllerChangedEventEventData) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Event")) + ": " + ag_text.Bold("ProviderControllerChangedEvent")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Account("Provider", obj.Provider))
+			parent.Child(ag_format.Param("Action", obj.Action))
+			parent.Child(ag_format.Account("NewController", obj.NewController))
+			parent.Child(ag_format.Account("Operator", obj.Operator))
+			parent.Child(ag_format.Account("OldController", obj.OldController))
+		})
+}
+
 func (*ProviderControllerChangedEventEventData) isEventData() {}
 
 type RewardClaimedEventEventData struct {
@@ -490,22 +900,82 @@ func (obj *RewardClaimedEventEventData) UnmarshalWithDecoder(decoder *ag_binary.
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[246 43 215 228 82 49 230 56]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of RewardClaimedEventEventData under their IDL names, next to
+// its "event" name. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj RewardClaimedEventEventData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of RewardClaimedEventEventData, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj RewardClaimedEventEventData) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName string                `json:"event"`
+		Provider ag_solanago.PublicKey `json:"provider"`
+		Amount   jsonUint64            `json:"amount"`
+		AmountUI *string               `json:"amount_ui,omitempty"`
+	}{
+		JSONName: "RewardClaimedEvent",
+		Provider: obj.Provider,
+		Amount:   jsonUint64(obj.Amount),
+		AmountUI: formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *RewardClaimedEventEventData) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName string                `json:"event"`
+		Provider ag_solanago.PublicKey `json:"provider"`
+		Amount   jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("event", in.JSONName, "RewardClaimedEvent"); err != nil {
+		return err
+	}
+	obj.Provider = in.Provider
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of RewardClaimedEventEventData for solana-go's text encoder.
+func (obj RewardClaimedEventEventData) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj RewardClaimedEventEventData) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Event")) + ": " + ag_text.Bold("RewardClaimedEvent")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Account("Provider", obj.Provider))
+			parent.Child(ag_format.Param("Amount", obj.Amount))
+		})
+}
+
 func (*RewardClaimedEventEventData) isEventData() {}
 
 type RewardLockedTimeUpdatedEventData struct {
@@ -551,27 +1021,89 @@ func (obj *RewardLockedTimeUpdatedEventData) UnmarshalWithDecoder(decoder *ag_bi
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[37 131 22 147 118 100 143 26]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Old`:
-	err = decoder.Decode(&obj.Old)
+	obj.Old, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `New`:
-	err = decoder.Decode(&obj.New)
+	obj.New, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Admin`:
-	err = decoder.Decode(&obj.Admin)
+	err = readPublicKey(decoder, &obj.Admin)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of RewardLockedTimeUpdatedEventData under their IDL names, next to
+// its "event" name. 64-bit integers are strings.
+func (obj RewardLockedTimeUpdatedEventData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of RewardLockedTimeUpdatedEventData, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj RewardLockedTimeUpdatedEventData) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName string                `json:"event"`
+		Old      jsonUint64            `json:"old"`
+		New      jsonUint64            `json:"new"`
+		Admin    ag_solanago.PublicKey `json:"admin"`
+	}{
+		JSONName: "RewardLockedTimeUpdated",
+		Old:      jsonUint64(obj.Old),
+		New:      jsonUint64(obj.New),
+		Admin:    obj.Admin,
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *RewardLockedTimeUpdatedEventData) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName string                `json:"event"`
+		Old      jsonUint64            `json:"old"`
+		New      jsonUint64            `json:"new"`
+		Admin    ag_solanago.PublicKey `json:"admin"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("event", in.JSONName, "RewardLockedTimeUpdated"); err != nil {
+		return err
+	}
+	obj.Old = uint64(in.Old)
+	obj.New = uint64(in.New)
+	obj.Admin = in.Admin
+	return nil
+}
+
+// TextEncode writes the JSON of RewardLockedTimeUpdatedEventData for solana-go's text encoder.
+func (obj RewardLockedTimeUpdatedEventData) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj RewardLockedTimeUpdatedEventData) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Event")) + ": " + ag_text.Bold("RewardLockedTimeUpdated")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Param("Old", obj.Old))
+			parent.Child(ag_format.Param("New", obj.New))
+			parent.Child(ag_format.Account("Admin", obj.Admin))
+		})
+}
+
 func (*RewardLockedTimeUpdatedEventData) isEventData() {}
 
 type StakingCoefficientUpdatedEventData struct {
@@ -617,27 +1149,89 @@ func (obj *StakingCoefficientUpdatedEventData) UnmarshalWithDecoder(decoder *ag_
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[11 31 245 203 59 83 112 36]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Old`:
-	err = decoder.Decode(&obj.Old)
+	obj.Old, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `New`:
-	err = decoder.Decode(&obj.New)
+	obj.New, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Admin`:
-	err = decoder.Decode(&obj.Admin)
+	err = readPublicKey(decoder, &obj.Admin)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of StakingCoefficientUpdatedEventData under their IDL names, next to
+// its "event" name. 64-bit integers are strings.
+func (obj StakingCoefficientUpdatedEventData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of StakingCoefficientUpdatedEventData, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj StakingCoefficientUpdatedEventData) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName string                `json:"event"`
+		Old      jsonUint64            `json:"old"`
+		New      jsonUint64            `json:"new"`
+		Admin    ag_solanago.PublicKey `json:"admin"`
+	}{
+		JSONName: "StakingCoefficientUpdated",
+		Old:      jsonUint64(obj.Old),
+		New:      jsonUint64(obj.New),
+		Admin:    obj.Admin,
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *StakingCoefficientUpdatedEventData) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName string                `json:"event"`
+		Old      jsonUint64            `json:"old"`
+		New      jsonUint64            `json:"new"`
+		Admin    ag_solanago.PublicKey `json:"admin"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("event", in.JSONName, "StakingCoefficientUpdated"); err != nil {
+		return err
+	}
+	obj.Old = uint64(in.Old)
+	obj.New = uint64(in.New)
+	obj.Admin = in.Admin
+	return nil
+}
+
+// TextEncode writes the JSON of StakingCoefficientUpdatedEventData for solana-go's text encoder.
+func (obj StakingCoefficientUpdatedEventData) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj StakingCoefficientUpdatedEventData) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Event")) + ": " + ag_text.Bold("StakingCoefficientUpdated")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Param("Old", obj.Old))
+			parent.Child(ag_format.Param("New", obj.New))
+			parent.Child(ag_format.Account("Admin", obj.Admin))
+		})
+}
+
 func (*StakingCoefficientUpdatedEventData) isEventData() {}
 
 type TokenReleasedEventEventData struct {
@@ -683,27 +1277,92 @@ func (obj *TokenReleasedEventEventData) UnmarshalWithDecoder(decoder *ag_binary.
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[166 215 137 155 84 0 149 214]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Controller`:
-	err = decoder.Decode(&obj.Controller)
+	err = readPublicKey(decoder, &obj.Controller)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of TokenReleasedEventEventData under their IDL names, next to
+// its "event" name. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj TokenReleasedEventEventData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of TokenReleasedEventEventData, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj TokenReleasedEventEventData) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName   string                `json:"event"`
+		Controller ag_solanago.PublicKey `json:"controller"`
+		Provider   ag_solanago.PublicKey `json:"provider"`
+		Amount     jsonUint64            `json:"amount"`
+		AmountUI   *string               `json:"amount_ui,omitempty"`
+	}{
+		JSONName:   "TokenReleasedEvent",
+		Controller: obj.Controller,
+		Provider:   obj.Provider,
+		Amount:     jsonUint64(obj.Amount),
+		AmountUI:   formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *TokenReleasedEventEventData) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName   string                `json:"event"`
+		Controller ag_solanago.PublicKey `json:"controller"`
+		Provider   ag_solanago.PublicKey `json:"provider"`
+		Amount     jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("event", in.JSONName, "TokenReleasedEvent"); err != nil {
+		return err
+	}
+	obj.Controller = in.Controller
+	obj.Provider = in.Provider
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of TokenReleasedEventEventData for solana-go's text encoder.
+func (obj TokenReleasedEventEventData) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj TokenReleasedEventEventData) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Event")) + ": " + ag_text.Bold("TokenReleasedEvent")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Account("Controller", obj.Controller))
+			parent.Child(ag_format.Account("Provider", obj.Provider))
+			parent.Child(ag_format.Param("Amount", obj.Amount))
+		})
+}
+
 func (*TokenReleasedEventEventData) isEventData() {}
 
 type VestingScheduledEventEventData struct {
@@ -749,27 +1408,92 @@ func (obj *VestingScheduledEventEventData) UnmarshalWithDecoder(decoder *ag_bina
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[139 64 225 127 141 206 190 86]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Day`:
-	err = decoder.Decode(&obj.Day)
+	obj.Day, err = decoder.ReadUint16(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of VestingScheduledEventEventData under their IDL names, next to
+// its "event" name. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj VestingScheduledEventEventData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of VestingScheduledEventEventData, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj VestingScheduledEventEventData) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName string                `json:"event"`
+		Provider ag_solanago.PublicKey `json:"provider"`
+		Day      uint16                `json:"day"`
+		Amount   jsonUint64            `json:"amount"`
+		AmountUI *string               `json:"amount_ui,omitempty"`
+	}{
+		JSONName: "VestingScheduledEvent",
+		Provider: obj.Provider,
+		Day:      obj.Day,
+		Amount:   jsonUint64(obj.Amount),
+		AmountUI: formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *VestingScheduledEventEventData) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName string                `json:"event"`
+		Provider ag_solanago.PublicKey `json:"provider"`
+		Day      uint16                `json:"day"`
+		Amount   jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("event", in.JSONName, "VestingScheduledEvent"); err != nil {
+		return err
+	}
+	obj.Provider = in.Provider
+	obj.Day = in.Day
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of VestingScheduledEventEventData for solana-go's text encoder.
+func (obj VestingScheduledEventEventData) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj VestingScheduledEventEventData) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Event")) + ": " + ag_text.Bold("VestingScheduledEvent")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Account("Provider", obj.Provider))
+			parent.Child(ag_format.Param("Day", obj.Day))
+			parent.Child(ag_format.Param("Amount", obj.Amount))
+		})
+}
+
 func (*VestingScheduledEventEventData) isEventData() {}
 
 type WithdrawEventEventData struct {
@@ -809,38 +1533,290 @@ func (obj *WithdrawEventEventData) UnmarshalWithDecoder(decoder *ag_binary.Decod
 			return fmt.Errorf(
 				"wrong discriminator: wanted %s, got %s",
 				"[22 9 133 26 160 44 71 192]",
-				fmt.Sprint(discriminator[:]))
+				fmt.Sprint([8]byte(discriminator)))
 		}
 	}
 	// Deserialize `Tenant`:
-	err = decoder.Decode(&obj.Tenant)
+	err = readPublicKey(decoder, &obj.Tenant)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of WithdrawEventEventData under their IDL names, next to
+// its "event" name. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj WithdrawEventEventData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of WithdrawEventEventData, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj WithdrawEventEventData) jsonValue(decimals int) interface{} {
+	return struct {
+		JSONName string                `json:"event"`
+		Tenant   ag_solanago.PublicKey `json:"tenant"`
+		Amount   jsonUint64            `json:"amount"`
+		AmountUI *string               `json:"amount_ui,omitempty"`
+	}{
+		JSONName: "WithdrawEvent",
+		Tenant:   obj.Tenant,
+		Amount:   jsonUint64(obj.Amount),
+		AmountUI: formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *WithdrawEventEventData) UnmarshalJSON(data []byte) error {
+	var in struct {
+		JSONName string                `json:"event"`
+		Tenant   ag_solanago.PublicKey `json:"tenant"`
+		Amount   jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if err := checkJSONName("event", in.JSONName, "WithdrawEvent"); err != nil {
+		return err
+	}
+	obj.Tenant = in.Tenant
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of WithdrawEventEventData for solana-go's text encoder.
+func (obj WithdrawEventEventData) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj WithdrawEventEventData) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_text.Purple(ag_text.Bold("Event")) + ": " + ag_text.Bold("WithdrawEvent")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+			parent.Child(ag_format.Account("Tenant", obj.Tenant))
+			parent.Child(ag_format.Param("Amount", obj.Amount))
+		})
+}
+
 func (*WithdrawEventEventData) isEventData() {}
 
-var eventTypes = map[[8]byte]reflect.Type{
-	ClaimRentalFeeEventEventDataDiscriminator:            reflect.TypeOf(ClaimRentalFeeEventEventData{}),
-	DeviceKValueUpdatedEventDataDiscriminator:            reflect.TypeOf(DeviceKValueUpdatedEventData{}),
-	DeviceStakedEventEventDataDiscriminator:              reflect.TypeOf(DeviceStakedEventEventData{}),
-	DeviceUnstakeEventEventDataDiscriminator:             reflect.TypeOf(DeviceUnstakeEventEventData{}),
-	PayRentalEventEventDataDiscriminator:                 reflect.TypeOf(PayRentalEventEventData{}),
-	ProviderControllerChangedEventEventDataDiscriminator: reflect.TypeOf(ProviderControllerChangedEventEventData{}),
-	RewardClaimedEventEventDataDiscriminator:             reflect.TypeOf(RewardClaimedEventEventData{}),
-	RewardLockedTimeUpdatedEventDataDiscriminator:        reflect.TypeOf(RewardLockedTimeUpdatedEventData{}),
-	StakingCoefficientUpdatedEventDataDiscriminator:      reflect.TypeOf(StakingCoefficientUpdatedEventData{}),
-	TokenReleasedEventEventDataDiscriminator:             reflect.TypeOf(TokenReleasedEventEventData{}),
-	VestingScheduledEventEventDataDiscriminator:          reflect.TypeOf(VestingScheduledEventEventData{}),
-	WithdrawEventEventDataDiscriminator:                  reflect.TypeOf(WithdrawEventEventData{}),
+// eventValues holds one value of every event, reused by decoders that hand
+// out one event at a time.
+type eventValues struct {
+	ClaimRentalFeeEventEventData            ClaimRentalFeeEventEventData
+	DeviceKValueUpdatedEventData            DeviceKValueUpdatedEventData
+	DeviceStakedEventEventData              DeviceStakedEventEventData
+	DeviceUnstakeEventEventData             DeviceUnstakeEventEventData
+	PayRentalEventEventData                 PayRentalEventEventData
+	ProviderControllerChangedEventEventData ProviderControllerChangedEventEventData
+	RewardClaimedEventEventData             RewardClaimedEventEventData
+	RewardLockedTimeUpdatedEventData        RewardLockedTimeUpdatedEventData
+	StakingCoefficientUpdatedEventData      StakingCoefficientUpdatedEventData
+	TokenReleasedEventEventData             TokenReleasedEventEventData
+	VestingScheduledEventEventData          VestingScheduledEventEventData
+	WithdrawEventEventData                  WithdrawEventEventData
+}
+
+// get returns the name of the event of discriminator and a zero value of its
+// data: a value of values reset, or a new one when values is nil. ok is false
+// for an unknown discriminator.
+func (values *eventValues) get(discriminator [8]byte) (name string, data EventData, ok bool) {
+	switch discriminator {
+	case ClaimRentalFeeEventEventDataDiscriminator:
+		if values == nil {
+			return "ClaimRentalFeeEvent", new(ClaimRentalFeeEventEventData), true
+		}
+		values.ClaimRentalFeeEventEventData = ClaimRentalFeeEventEventData{}
+		return "ClaimRentalFeeEvent", &values.ClaimRentalFeeEventEventData, true
+	case DeviceKValueUpdatedEventDataDiscriminator:
+		if values == nil {
+			return "DeviceKValueUpdated", new(DeviceKValueUpdatedEventData), true
+		}
+		values.DeviceKValueUpdatedEventData = DeviceKValueUpdatedEventData{}
+		return "DeviceKValueUpdated", &values.DeviceKValueUpdatedEventData, true
+	case DeviceStakedEventEventDataDiscriminator:
+		if values == nil {
+			return "DeviceStakedEvent", new(DeviceStakedEventEventData), true
+		}
+		values.DeviceStakedEventEventData = DeviceStakedEventEventData{}
+		return "DeviceStakedEvent", &values.DeviceStakedEventEventData, true
+	case DeviceUnstakeEventEventDataDiscriminator:
+		if values == nil {
+			return "DeviceUnstakeEvent", new(DeviceUnstakeEventEventData), true
+		}
+		values.DeviceUnstakeEventEventData = DeviceUnstakeEventEventData{}
+		return "DeviceUnstakeEvent", &values.DeviceUnstakeEventEventData, true
+	case PayRentalEventEventDataDiscriminator:
+		if values == nil {
+			return "PayRentalEvent", new(PayRentalEventEventData), true
+		}
+		values.PayRentalEventEventData = PayRentalEventEventData{}
+		return "PayRentalEvent", &values.PayRentalEventEventData, true
+	case ProviderControllerChangedEventEventDataDiscriminator:
+		if values == nil {
+			return "ProviderControllerChangedEvent", new(ProviderControllerChangedEventEventData), true
+		}
+		values.ProviderControllerChangedEventEventData = ProviderControllerChangedEventEventData{}
+		return "ProviderControllerChangedEvent", &values.ProviderControllerChangedEventEventData, true
+	case RewardClaimedEventEventDataDiscriminator:
+		if values == nil {
+			return "RewardClaimedEvent", new(RewardClaimedEventEventData), true
+		}
+		values.RewardClaimedEventEventData = RewardClaimedEventEventData{}
+		return "RewardClaimedEvent", &values.RewardClaimedEventEventData, true
+	case RewardLockedTimeUpdatedEventDataDiscriminator:
+		if values == nil {
+			return "RewardLockedTimeUpdated", new(RewardLockedTimeUpdatedEventData), true
+		}
+		values.RewardLockedTimeUpdatedEventData = RewardLockedTimeUpdatedEventData{}
+		return "RewardLockedTimeUpdated", &values.RewardLockedTimeUpdatedEventData, true
+	case StakingCoefficientUpdatedEventDataDiscriminator:
+		if values == nil {
+			return "StakingCoefficientUpdated", new(StakingCoefficientUpdatedEventData), true
+		}
+		values.StakingCoefficientUpdatedEventData = StakingCoefficientUpdatedEventData{}
+		return "StakingCoefficientUpdated", &values.StakingCoefficientUpdatedEventData, true
+	case TokenReleasedEventEventDataDiscriminator:
+		if values == nil {
+			return "TokenReleasedEvent", new(TokenReleasedEventEventData), true
+		}
+		values.TokenReleasedEventEventData = TokenReleasedEventEventData{}
+		return "TokenReleasedEvent", &values.TokenReleasedEventEventData, true
+	case VestingScheduledEventEventDataDiscriminator:
+		if values == nil {
+			return "VestingScheduledEvent", new(VestingScheduledEventEventData), true
+		}
+		values.VestingScheduledEventEventData = VestingScheduledEventEventData{}
+		return "VestingScheduledEvent", &values.VestingScheduledEventEventData, true
+	case WithdrawEventEventDataDiscriminator:
+		if values == nil {
+			return "WithdrawEvent", new(WithdrawEventEventData), true
+		}
+		values.WithdrawEventEventData = WithdrawEventEventData{}
+		return "WithdrawEvent", &values.WithdrawEventEventData, true
+	}
+	return "", nil, false
+}
+
+// eventDecoders pools the Borsh decoders of event payloads.
+var eventDecoders = sync.Pool{New: func() any { return ag_binary.NewDecoderWithEncoding(nil, ag_binary.EncodingBorsh) }}
+
+// OnClaimRentalFee registers handler for ClaimRentalFeeEvent events.
+func (d *Dispatcher) OnClaimRentalFee(handler func(ctx context.Context, data *ClaimRentalFeeEventEventData, meta EventMeta) error) {
+	d.on("ClaimRentalFeeEvent", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*ClaimRentalFeeEventEventData), meta)
+	})
+}
+
+// OnDeviceKValueUpdated registers handler for DeviceKValueUpdated events.
+func (d *Dispatcher) OnDeviceKValueUpdated(handler func(ctx context.Context, data *DeviceKValueUpdatedEventData, meta EventMeta) error) {
+	d.on("DeviceKValueUpdated", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*DeviceKValueUpdatedEventData), meta)
+	})
 }
+
+// OnDeviceStaked registers handler for DeviceStakedEvent events.
+func (d *Dispatcher) OnDeviceStaked(handler func(ctx context.Context, data *DeviceStakedEventEventData, meta EventMeta) error) {
+	d.on("DeviceStakedEvent", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*DeviceStakedEventEventData), meta)
+	})
+}
+
+// OnDeviceUnstake registers handler for DeviceUnstakeEvent events.
+func (d *Dispatcher) OnDeviceUnstake(handler func(ctx context.Context, data *DeviceUnstakeEventEventData, meta EventMeta) error) {
+	d.on("DeviceUnstakeEvent", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*DeviceUnstakeEventEventData), meta)
+	})
+}
+
+// OnPayRental registers handler for PayRentalEvent events.
+func (d *Dispatcher) OnPayRental(handler func(ctx context.Context, data *PayRentalEventEventData, meta EventMeta) error) {
+	d.on("PayRentalEvent", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*PayRentalEventEventData), meta)
+	})
+}
+
+// OnProviderControllerChanged registers handler for ProviderControllerChangedEvent events.
+func (d *Dispatcher) OnProviderControllerChanged(handler func(ctx context.Context, data *ProviderControllerChangedEventEventData, meta EventMeta) error) {
+	d.on("ProviderControllerChangedEvent", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*ProviderControllerChangedEventEventData), meta)
+	})
+}
+
+// OnRewardClaimed registers handler for RewardClaimedEvent events.
+func (d *Dispatcher) OnRewardClaimed(handler func(ctx context.Context, data *RewardClaimedEventEventData, meta EventMeta) error) {
+	d.on("RewardClaimedEvent", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*RewardClaimedEventEventData), meta)
+	})
+}
+
+// OnRewardLockedTimeUpdated registers handler for RewardLockedTimeUpdated events.
+func (d *Dispatcher) OnRewardLockedTimeUpdated(handler func(ctx context.Context, data *RewardLockedTimeUpdatedEventData, meta EventMeta) error) {
+	d.on("RewardLockedTimeUpdated", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*RewardLockedTimeUpdatedEventData), meta)
+	})
+}
+
+// OnStakingCoefficientUpdated registers handler for StakingCoefficientUpdated events.
+func (d *Dispatcher) OnStakingCoefficientUpdated(handler func(ctx context.Context, data *StakingCoefficientUpdatedEventData, meta EventMeta) error) {
+	d.on("StakingCoefficientUpdated", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*StakingCoefficientUpdatedEventData), meta)
+	})
+}
+
+// OnTokenReleased registers handler for TokenReleasedEvent events.
+func (d *Dispatcher) OnTokenReleased(handler func(ctx context.Context, data *TokenReleasedEventEventData, meta EventMeta) error) {
+	d.on("TokenReleasedEvent", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*TokenReleasedEventEventData), meta)
+	})
+}
+
+// OnVestingScheduled registers handler for VestingScheduledEvent events.
+func (d *Dispatcher) OnVestingScheduled(handler func(ctx context.Context, data *VestingScheduledEventEventData, meta EventMeta) error) {
+	d.on("VestingScheduledEvent", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*VestingScheduledEventEventData), meta)
+	})
+}
+
+// OnWithdraw registers handler for WithdrawEvent events.
+func (d *Dispatcher) OnWithdraw(handler func(ctx context.Context, data *WithdrawEventEventData, meta EventMeta) error) {
+	d.on("WithdrawEvent", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*WithdrawEventEventData), meta)
+	})
+}
+
+// eventProvider returns the provider of the events that name one.
+func eventProvider(data EventData) (provider ag_solanago.PublicKey, ok bool) {
+	switch data := data.(type) {
+	case *ClaimRentalFeeEventEventData:
+		return data.Provider, true
+	case *DeviceStakedEventEventData:
+		return data.Provider, true
+	case *DeviceUnstakeEventEventData:
+		return data.Provider, true
+	case *ProviderControllerChangedEventEventData:
+		return data.Provider, true
+	case *RewardClaimedEventEventData:
+		return data.Provider, true
+	case *TokenReleasedEventEventData:
+		return data.Provider, true
+	case *VestingScheduledEventEventData:
+		return data.Provider, true
+	}
+	return ag_solanago.PublicKey{}, false
+}
+
 var eventNames = map[[8]byte]string{
 	ClaimRentalFeeEventEventDataDiscriminator:            "ClaimRentalFeeEvent",
 	DeviceKValueUpdatedEventDataDiscriminator:            "DeviceKValueUpdated",
@@ -874,6 +1850,14 @@ var (
 type Event struct {
 	Name string
 	Data EventData
+	// Instruction is the index of the outer instruction the event was
+	// emitted under and Depth the invocation depth of the program that
+	// emitted it: 1 in the outer instruction, 2 and more under a CPI.
+	// ComputeUnits is the compute units that invocation consumed. Depth and
+	// ComputeUnits are zero when the logs do not cover the invocation.
+	Instruction  int
+	Depth        int
+	ComputeUnits uint64
 }
 
 type EventData interface {
@@ -883,99 +1867,70 @@ type EventData interface {
 
 const eventLogPrefix = "Program data: "
 
-func DecodeEvents(txData *ag_rpc.GetTransactionResult, targetProgramId ag_solanago.PublicKey, getAddressTables func(altAddresses []ag_solanago.PublicKey) (tables map[ag_solanago.PublicKey]ag_solanago.PublicKeySlice, err error)) (evts []*Event, err error) {
+// DecodeEvents decodes the events targetProgramId emitted in txData. Lookups of
+// v0 transactions are resolved through getAddressTables, or through the getter
+// installed with SetAddressTablesGetter when getAddressTables is nil.
+//
+// Logged events are read from the data lines the log tree attributes to
+// targetProgramId, so events of other programs in the transaction are left
+// out; events emitted through a self-CPI are read from the inner
+// instructions carrying Anchor's event tag, and dropped when the same
+// invocation also logged them. Payloads that do not decode are reported as
+// *EventError values joined into err, next to the events that did.
+func DecodeEvents(txData *ag_rpc.GetTransactionResult, targetProgramId ag_solanago.PublicKey, getAddressTables AddressTablesGetter) (evts []*Event, err error) {
 	var tx *ag_solanago.Transaction
 	if tx, err = txData.Transaction.GetTransaction(); err != nil {
 		return
 	}
 
-	altAddresses := make([]ag_solanago.PublicKey, len(tx.Message.AddressTableLookups))
-	for i, alt := range tx.Message.AddressTableLookups {
-		altAddresses[i] = alt.AccountKey
-	}
-	if len(altAddresses) > 0 {
-		var tables map[ag_solanago.PublicKey]ag_solanago.PublicKeySlice
-		if tables, err = getAddressTables(altAddresses); err != nil {
+	if tx.Message.NumLookups() > 0 {
+		var message *ag_solanago.Message
+		if message, err = withAddressTables(&tx.Message, getAddressTables); err != nil {
 			return
 		}
-		tx.Message.SetAddressTables(tables)
-		if err = tx.Message.ResolveLookups(); err != nil {
+		if err = message.ResolveLookups(); err != nil {
 			return
 		}
+		tx.Message = *message
 	}
 
-	var base64Binaries [][]byte
-	logMessageEventBinaries, err := decodeEventsFromLogMessage(txData.Meta.LogMessages)
-	if err != nil {
+	// Truncated logs still hold the invocations before the cut.
+	invocations, err := ParseLogs(txData.Meta.LogMessages)
+	if err != nil && !errors.Is(err, ErrLogTruncated) {
 		return
 	}
+	logged := eventsFromLogs(invocations, targetProgramId)
 
-	emitedCPIEventBinaries, err := decodeEventsFromEmitCPI(txData.Meta.InnerInstructions, tx.Message.AccountKeys, targetProgramId)
+	emitted, err := eventsFromEmitCPI(txData.Meta.InnerInstructions, tx.Message.AccountKeys, targetProgramId, invocations)
 	if err != nil {
 		return
 	}
 
-	base64Binaries = append(base64Binaries, logMessageEventBinaries...)
-	base64Binaries = append(base64Binaries, emitedCPIEventBinaries...)
-	evts, err = parseEvents(base64Binaries)
-	return
-}
-
-func decodeEventsFromLogMessage(logMessages []string) (eventBinaries [][]byte, err error) {
-	for _, log := range logMessages {
-		if strings.HasPrefix(log, eventLogPrefix) {
-			eventBase64 := log[len(eventLogPrefix):]
-
-			var eventBinary []byte
-			if eventBinary, err = base64.StdEncoding.DecodeString(eventBase64); err != nil {
-				err = fmt.Errorf("failed to decode logMessage event: %s", eventBase64)
-				return
-			}
-			eventBinaries = append(eventBinaries, eventBinary)
-		}
-	}
+	evts, err = parseEvents(dedupeEvents(logged, emitted))
 	return
 }
 
-func decodeEventsFromEmitCPI(InnerInstructions []ag_rpc.InnerInstruction, accountKeys ag_solanago.PublicKeySlice, targetProgramId ag_solanago.PublicKey) (eventBinaries [][]byte, err error) {
-	for _, parsedIx := range InnerInstructions {
-		for _, ix := range parsedIx.Instructions {
-			if accountKeys[ix.ProgramIDIndex] != targetProgramId {
-				continue
-			}
+// parseEvents decodes payloads, returning the events that decode and the
+// *EventError of each payload that does not.
+func parseEvents(payloads []eventPayload) (evts []*Event, err error) {
+	decoder := eventDecoders.Get().(*ag_binary.Decoder)
+	defer eventDecoders.Put(decoder)
 
-			var ixData []byte
-			if ixData, err = ag_base58.Decode(ix.Data.String()); err != nil {
-				return
-			}
-			eventBase64 := base64.StdEncoding.EncodeToString(ixData[8:])
-			var eventBinary []byte
-			if eventBinary, err = base64.StdEncoding.DecodeString(eventBase64); err != nil {
-				return
-			}
-			eventBinaries = append(eventBinaries, eventBinary)
+	events := make([]Event, 0, len(payloads))
+	var errs []error
+	for _, payload := range payloads {
+		evt, err := payload.decode(decoder, nil)
+		if err != nil {
+			errs = append(errs, err)
+			continue
 		}
+		events = append(events, evt)
 	}
-	return
-}
-
-func parseEvents(base64Binaries [][]byte) (evts []*Event, err error) {
-	decoder := ag_binary.NewDecoderWithEncoding(nil, ag_binary.EncodingBorsh)
-
-	for _, eventBinary := range base64Binaries {
-		eventDiscriminator := ag_binary.TypeID(eventBinary[:8])
-		if eventType, ok := eventTypes[eventDiscriminator]; ok {
-			eventData := reflect.New(eventType).Interface().(EventData)
-			decoder.Reset(eventBinary)
-			if err = eventData.UnmarshalWithDecoder(decoder); err != nil {
-				err = fmt.Errorf("failed to unmarshal event %s: %w", eventType.String(), err)
-				return
-			}
-			evts = append(evts, &Event{
-				Name: eventNames[eventDiscriminator],
-				Data: eventData,
-			})
+	if len(events) > 0 {
+		evts = make([]*Event, len(events))
+		for i := range events {
+			evts[i] = &events[i]
 		}
 	}
-	return
+	return evts, errors.Join(errs...)
 }
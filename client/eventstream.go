@@ -0,0 +1,152 @@
+package client
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+
+	ag_binary "github.com/gagliardetto/binary"
+	ag_solanago "github.com/gagliardetto/solana-go"
+)
+
+// EventStream decodes the events a program logs straight from the log lines
+// of its transactions into a handler, for backfills and log subscriptions
+// that see millions of transactions. It follows the invocations as ParseLogs
+// does without building the call tree, and reuses its buffers and event
+// values, so decoding allocates nothing per event beyond the strings events
+// hold. Events emitted through emit_cpi! are not in the logs; DecodeEvents
+// reads them from the inner instructions.
+//
+// An EventStream is not safe for concurrent use.
+type EventStream struct {
+	program string
+	handler func(*Event) error
+	walker  logWalker
+	decoder *ag_binary.Decoder
+	values  eventValues
+	event   Event
+
+	// frames are the invocations of the current outer instruction and
+	// stack the positions in frames of the running ones.
+	frames []streamFrame
+	stack  []int
+	// pending are the events of the current outer instruction, held until
+	// the compute units of their invocations are logged, their data in buf.
+	pending []pendingEvent
+	buf     []byte
+	// text holds the base64 of the data line being decoded.
+	text []byte
+}
+
+type streamFrame struct {
+	target bool
+	depth  int
+	units  uint64
+}
+
+type pendingEvent struct {
+	frame      int
+	start, end int
+}
+
+// NewEventStream returns a stream passing the events programID logs to
+// handler.
+func NewEventStream(programID ag_solanago.PublicKey, handler func(*Event) error) *EventStream {
+	return &EventStream{
+		program: programID.String(),
+		handler: handler,
+		decoder: ag_binary.NewDecoderWithEncoding(nil, ag_binary.EncodingBorsh),
+	}
+}
+
+// NewEventStream returns a stream passing the events of p to handler.
+func (p *Program) NewEventStream(handler func(*Event) error) *EventStream {
+	return NewEventStream(p.ID(), handler)
+}
+
+// Decode decodes the events the program logged in logs, the log messages of
+// one transaction, and passes them to the handler in the order they were
+// logged, once the outer instruction that logged them completed. The *Event
+// and its Data are reused after the handler returns: copy what you keep.
+//
+// Payloads that do not decode are reported as *EventError values joined into
+// the returned error, after the handler saw the other events. An error of the
+// handler stops the decoding and is returned as is, and logs that do not
+// follow the runtime's invocations fail like ParseLogs. Truncated logs
+// deliver the events before the cut.
+func (s *EventStream) Decode(logs []string) error {
+	s.walker = logWalker{stack: s.walker.stack[:0]}
+	s.reset()
+	var errs []error
+lines:
+	for i, line := range logs {
+		entry, err := s.walker.step(i, line)
+		if err != nil {
+			return err
+		}
+		switch entry.kind {
+		case logCut:
+			break lines
+		case logInvoke:
+			s.frames = append(s.frames, streamFrame{target: entry.program == s.program, depth: entry.depth})
+			s.stack = append(s.stack, len(s.frames)-1)
+		case logData:
+			top := s.stack[len(s.stack)-1]
+			if !s.frames[top].target {
+				continue
+			}
+			start := len(s.buf)
+			s.buf = slices.Grow(s.buf, base64.StdEncoding.DecodedLen(len(entry.text)))
+			s.text = append(s.text[:0], entry.text...)
+			n, err := base64.StdEncoding.Decode(s.buf[start:cap(s.buf)], s.text)
+			if err != nil {
+				return fmt.Errorf("log %d: program data %q is not base64: %w", i, entry.text, ErrMalformedLogs)
+			}
+			s.buf = s.buf[:start+n]
+			s.pending = append(s.pending, pendingEvent{frame: top, start: start, end: start + n})
+		case logConsumed:
+			s.frames[s.stack[len(s.stack)-1]].units = entry.units
+		case logSuccess, logFailed:
+			if s.stack = s.stack[:len(s.stack)-1]; len(s.stack) == 0 {
+				if err := s.flush(&errs); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := s.flush(&errs); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// flush decodes the pending events of the current outer instruction into
+// the handler, appending the payloads that do not decode to errs.
+func (s *EventStream) flush(errs *[]error) error {
+	defer s.reset()
+	for _, p := range s.pending {
+		frame := s.frames[p.frame]
+		payload := eventPayload{
+			data:         s.buf[p.start:p.end],
+			instruction:  s.walker.instructions - 1,
+			inner:        -1,
+			depth:        frame.depth,
+			computeUnits: frame.units,
+		}
+		evt, err := payload.decode(s.decoder, &s.values)
+		if err != nil {
+			*errs = append(*errs, err)
+			continue
+		}
+		s.event = evt
+		if err := s.handler(&s.event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *EventStream) reset() {
+	s.frames, s.stack, s.pending, s.buf = s.frames[:0], s.stack[:0], s.pending[:0], s.buf[:0]
+}
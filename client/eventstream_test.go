@@ -0,0 +1,175 @@
+package client
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	ag_require "github.com/stretchr/testify/require"
+)
+
+// streamed is an event copied out of a stream handler.
+type streamed struct {
+	Name         string
+	Data         []byte
+	Instruction  int
+	Depth        int
+	ComputeUnits uint64
+}
+
+func copyEvent(t testing.TB, evt *Event) streamed {
+	data, err := marshal(evt.Data.(roundTripper))
+	ag_require.NoError(t, err)
+	return streamed{evt.Name, data, evt.Instruction, evt.Depth, evt.ComputeUnits}
+}
+
+func streamLogs(t testing.TB, logs []string) ([]streamed, error) {
+	var out []streamed
+	err := NewEventStream(testProgramID, func(evt *Event) error {
+		out = append(out, copyEvent(t, evt))
+		return nil
+	}).Decode(logs)
+	return out, err
+}
+
+// nestedLogs are the logs of an instruction logging events before and after
+// calling itself, which logs one too.
+func nestedLogs(t *testing.T) []string {
+	program := testProgramID
+	return []string{
+		logLine(program, "invoke [1]"),
+		dataLine(t, &DeviceStakedEventEventData{DeviceId: 1}),
+		logLine(program, "invoke [2]"),
+		dataLine(t, &DeviceStakedEventEventData{DeviceId: 2}),
+		logLine(program, "consumed 100 of 1000 compute units"),
+		logLine(program, "success"),
+		dataLine(t, &DeviceStakedEventEventData{DeviceId: 3}),
+		logLine(program, "consumed 500 of 2000 compute units"),
+		logLine(program, "success"),
+	}
+}
+
+// TestEventStream_DecodeEvents streams the logged events DecodeEvents
+// returns, in the same order.
+func TestEventStream_DecodeEvents(t *testing.T) {
+	for name, logs := range map[string][]string{
+		"cpi":    cpiTransaction(t).Meta.LogMessages,
+		"golden": benchmarkLogs(t),
+		"nested": nestedLogs(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			txData := cpiTransaction(t)
+			txData.Meta.LogMessages, txData.Meta.InnerInstructions = logs, nil
+			evts, err := DecodeEvents(txData, testProgramID, nil)
+			ag_require.NoError(t, err)
+			ag_require.NotEmpty(t, evts)
+			var want []streamed
+			for _, evt := range evts {
+				want = append(want, copyEvent(t, evt))
+			}
+			got, err := streamLogs(t, logs)
+			ag_require.NoError(t, err)
+			ag_require.Equal(t, want, got)
+		})
+	}
+
+	// Events come in the order they were logged, around the calls of the
+	// invocation logging them.
+	got, err := streamLogs(t, nestedLogs(t))
+	ag_require.NoError(t, err)
+	ag_require.Len(t, got, 3)
+	for i, want := range []streamed{{Depth: 1, ComputeUnits: 500}, {Depth: 2, ComputeUnits: 100}, {Depth: 1, ComputeUnits: 500}} {
+		ag_require.Equal(t, eventBytes(t, &DeviceStakedEventEventData{DeviceId: uint64(i + 1)}), got[i].Data)
+		ag_require.Equal(t, want.Depth, got[i].Depth)
+		ag_require.Equal(t, want.ComputeUnits, got[i].ComputeUnits)
+	}
+}
+
+func TestEventStream_Errors(t *testing.T) {
+	program := testProgramID
+	unknown := eventBytes(t, &WithdrawEventEventData{})
+	copy(unknown, "unknown!")
+	logs := []string{
+		logLine(program, "invoke [1]"),
+		dataLine(t, &WithdrawEventEventData{Amount: 1}),
+		eventLogPrefix + "AAAA",
+		eventLogPrefix + base64.StdEncoding.EncodeToString(unknown),
+		dataLine(t, &WithdrawEventEventData{Amount: 2}),
+		logLine(program, "success"),
+	}
+	got, err := streamLogs(t, logs)
+	ag_require.Len(t, got, 2)
+	ag_require.ErrorIs(t, err, ErrShortEvent)
+	ag_require.ErrorIs(t, err, ErrUnknownEvent)
+	var eventErr *EventError
+	ag_require.True(t, errors.As(err, &eventErr))
+	ag_require.Equal(t, -1, eventErr.Inner)
+
+	// An error of the handler stops the stream.
+	stop := errors.New("stop")
+	var seen int
+	err = NewEventStream(program, func(*Event) error {
+		seen++
+		return stop
+	}).Decode(logs)
+	ag_require.Equal(t, stop, err)
+	ag_require.Equal(t, 1, seen)
+
+	for name, logs := range map[string][]string{
+		"depth skipped": {logLine(program, "invoke [2]")},
+		"not base64":    {logLine(program, "invoke [1]"), eventLogPrefix + "!!"},
+	} {
+		_, err = streamLogs(t, logs)
+		ag_require.ErrorIs(t, err, ErrMalformedLogs, name)
+	}
+
+	// Truncated logs deliver the events before the cut.
+	got, err = streamLogs(t, append(logs[:2:2], logTruncated, dataLine(t, &WithdrawEventEventData{})))
+	ag_require.NoError(t, err)
+	ag_require.Len(t, got, 1)
+	ag_require.Equal(t, eventBytes(t, &WithdrawEventEventData{Amount: 1}), got[0].Data)
+}
+
+// TestEventStream_Reuse decodes transaction after transaction with one
+// stream, which hands the same event to the handler.
+func TestEventStream_Reuse(t *testing.T) {
+	logs := benchmarkLogs(t)
+	var seen []*Event
+	var instructions []int
+	stream := NewEventStream(testProgramID, func(evt *Event) error {
+		seen = append(seen, evt)
+		instructions = append(instructions, evt.Instruction)
+		return nil
+	})
+	for i := 0; i < 3; i++ {
+		ag_require.NoError(t, stream.Decode(logs))
+	}
+	// benchmarkLogs logs one event in five lines.
+	n := len(logs) / 5
+	ag_require.Len(t, seen, 3*n)
+	for i := range seen {
+		ag_require.Same(t, seen[0], seen[i])
+		ag_require.Equal(t, i%n, instructions[i])
+	}
+
+	stream = NewEventStream(testProgramID, func(*Event) error { return nil })
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := stream.Decode(logs); err != nil {
+			t.Fatal(err)
+		}
+	})
+	// Only the string fields of the events allocate.
+	ag_require.LessOrEqual(t, allocs, 1.0)
+}
+
+func BenchmarkEventStream(b *testing.B) {
+	logs := benchmarkLogs(b)
+	stream := NewEventStream(testProgramID, func(*Event) error { return nil })
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := stream.Decode(logs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
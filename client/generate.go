@@ -0,0 +1,8 @@
+package client
+
+// The client is generated from the supernode IDL checked in at
+// ../idl/supernode.json; files starting with the idlgen header must not be
+// edited by hand. The -amounts fields hold token amounts and get a decimal
+// companion in JSON; the -nonzero arguments are rejected by the program when
+// zero and checked by ValidateDeep.
+//go:generate go run ../cmd/idlgen -idl ../idl/supernode.json -out . -amounts amount,released_amount,TenantInfo.funds,TenantInfo.withdrawn -nonzero amount,update_staking_coefficient.val
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/idl"
+	"n3-solana-test/idlgen"
+)
+
+// TestGenerated fails when the checked-in client drifts from what idlgen
+// generates from the checked-in IDL.
+func TestGenerated(t *testing.T) {
+	directive, err := os.ReadFile("generate.go")
+	ag_require.NoError(t, err)
+	amounts := regexp.MustCompile(`(?m)^//go:generate .* -amounts (\S+)`).FindSubmatch(directive)
+	ag_require.NotNil(t, amounts, "generate.go has no -amounts flag")
+	nonZero := regexp.MustCompile(`(?m)^//go:generate .* -nonzero (\S+)`).FindSubmatch(directive)
+	ag_require.NotNil(t, nonZero, "generate.go has no -nonzero flag")
+	files, err := idlgen.Generate(idl.Supernode(), idlgen.Options{
+		Amounts: strings.Split(string(amounts[1]), ","),
+		NonZero: strings.Split(string(nonZero[1]), ","),
+	})
+	ag_require.NoError(t, err)
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		got, err := os.ReadFile(name)
+		ag_require.NoError(t, err, "%s is missing; run go generate ./client", name)
+		ag_require.Equal(t, string(files[name]), string(got), "%s is stale; run go generate ./client", name)
+	}
+
+	existing, err := filepath.Glob("*.go")
+	ag_require.NoError(t, err)
+	for _, name := range existing {
+		if _, ok := files[name]; ok {
+			continue
+		}
+		src, err := os.ReadFile(name)
+		ag_require.NoError(t, err)
+		ag_require.False(t, idlgen.IsGenerated(src), "%s is no longer generated; run go generate ./client", name)
+	}
+}
@@ -0,0 +1,262 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	ag_binary "github.com/gagliardetto/binary"
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_rpc "github.com/gagliardetto/solana-go/rpc"
+	ag_require "github.com/stretchr/testify/require"
+)
+
+// The golden corpus holds one captured payload per account, instruction and
+// event under testdata/golden/<kind>/<name>, next to the JSON it decodes to.
+// Refresh it from a snapshot file with
+//
+//	go test -run TestGolden -snapshot /path/to/snapshot.json
+var snapshotFile = flag.String("snapshot", "", "refresh testdata/golden from this snapshot file before checking it")
+
+const goldenDir = "testdata/golden"
+
+// snapshot is a capture of the supernode program on a cluster: the
+// getProgramAccounts result and getTransaction results of the program's
+// transactions, as returned by the RPC with base64 encoding.
+type snapshot struct {
+	ProgramID    ag_solanago.PublicKey          `json:"programId"`
+	Accounts     []*ag_rpc.KeyedAccount         `json:"accounts"`
+	Transactions []*ag_rpc.GetTransactionResult `json:"transactions"`
+}
+
+// goldenKinds lists the corpus directories. decode decodes a capture of the
+// named account, instruction or event and returns the decoded value, compared
+// with the JSON file, and the value encoded back into a capture.
+var goldenKinds = []struct {
+	dir, ext string
+	decode   func(name, capture string) (value interface{}, encoded string, err error)
+}{
+	{"accounts", ".b64", decodeGoldenAccount},
+	{"instructions", ".b64", decodeGoldenInstruction},
+	{"events", ".log", decodeGoldenEvent},
+}
+
+var goldenInstructions = []ag_binary.TypeID{
+	Instruction_AddExtraController,
+	Instruction_ClaimRentalFee,
+	Instruction_ClaimReward,
+	Instruction_InitRewardAccount,
+	Instruction_Initialize,
+	Instruction_PayRentalFee,
+	Instruction_Releasable,
+	Instruction_Release,
+	Instruction_RemoveExtraController,
+	Instruction_ReplaceExtraController,
+	Instruction_StakeDevice,
+	Instruction_UnstakeDevice,
+	Instruction_UpdateKValue,
+	Instruction_UpdateRewardLockTime,
+	Instruction_UpdateStakingCoefficient,
+	Instruction_WithdrawRentalFee,
+}
+
+func decodeGoldenAccount(name, capture string) (interface{}, string, error) {
+	data, err := base64.StdEncoding.DecodeString(capture)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, c := range roundTripCases {
+		if c.name != name || c.discriminator == nil {
+			continue
+		}
+		v, err := unmarshal(c, data)
+		if err != nil {
+			return nil, "", err
+		}
+		encoded, err := marshal(v)
+		return v, base64.StdEncoding.EncodeToString(encoded), err
+	}
+	return nil, "", fmt.Errorf("unknown account %s", name)
+}
+
+func decodeGoldenInstruction(name, capture string) (interface{}, string, error) {
+	data, err := base64.StdEncoding.DecodeString(capture)
+	if err != nil {
+		return nil, "", err
+	}
+	inst, err := decodeInstruction(nil, data)
+	if err != nil {
+		return nil, "", err
+	}
+	if got := InstructionIDToName(inst.TypeID); got != name {
+		return nil, "", fmt.Errorf("decoded %s, want %s", got, name)
+	}
+	encoded, err := inst.Data()
+	return inst.Impl, base64.StdEncoding.EncodeToString(encoded), err
+}
+
+func decodeGoldenEvent(name, capture string) (interface{}, string, error) {
+	data, ok := strings.CutPrefix(capture, eventLogPrefix)
+	if !ok {
+		return nil, "", fmt.Errorf("capture is not a %q line", eventLogPrefix)
+	}
+	binary, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, "", err
+	}
+	evts, err := parseEvents([]eventPayload{{data: binary}})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(evts) != 1 || evts[0].Name != name {
+		return nil, "", fmt.Errorf("decoded %d events, want one %s", len(evts), name)
+	}
+	encoded, err := marshal(evts[0].Data.(roundTripper))
+	return evts[0].Data, eventLogPrefix + base64.StdEncoding.EncodeToString(encoded), err
+}
+
+// goldenJSON renders a decoded value. The accounts of decoded instructions
+// are not part of their data and are left out.
+func goldenJSON(value interface{}) ([]byte, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "AccountMetaSlice")
+	out, err := json.MarshalIndent(fields, "", "  ")
+	return append(out, '\n'), err
+}
+
+func TestGolden(t *testing.T) {
+	if *snapshotFile != "" {
+		refreshGolden(t, *snapshotFile)
+	}
+	for _, kind := range goldenKinds {
+		captures, err := filepath.Glob(filepath.Join(goldenDir, kind.dir, "*"+kind.ext))
+		ag_require.NoError(t, err)
+		for _, path := range captures {
+			name := strings.TrimSuffix(filepath.Base(path), kind.ext)
+			t.Run(kind.dir+"/"+name, func(t *testing.T) {
+				capture, err := os.ReadFile(path)
+				ag_require.NoError(t, err)
+				want, err := os.ReadFile(strings.TrimSuffix(path, kind.ext) + ".json")
+				ag_require.NoError(t, err)
+
+				got, encoded, err := kind.decode(name, strings.TrimSpace(string(capture)))
+				ag_require.NoError(t, err)
+				expected := reflect.New(reflect.TypeOf(got).Elem()).Interface()
+				ag_require.NoError(t, json.Unmarshal(want, expected))
+				ag_require.Equal(t, expected, got)
+				ag_require.Equal(t, strings.TrimSpace(string(capture)), encoded)
+			})
+		}
+	}
+}
+
+func TestGoldenCoverage(t *testing.T) {
+	want := map[string][]string{}
+	for _, c := range roundTripCases {
+		if c.discriminator != nil && !strings.HasSuffix(c.name, "EventData") {
+			want["accounts"] = append(want["accounts"], c.name)
+		}
+	}
+	for _, id := range goldenInstructions {
+		want["instructions"] = append(want["instructions"], InstructionIDToName(id))
+	}
+	for _, name := range eventNames {
+		want["events"] = append(want["events"], name)
+	}
+	for _, kind := range goldenKinds {
+		var missing []string
+		for _, name := range want[kind.dir] {
+			if _, err := os.Stat(filepath.Join(goldenDir, kind.dir, name+kind.ext)); err != nil {
+				missing = append(missing, name)
+			}
+		}
+		sort.Strings(missing)
+		ag_require.Empty(t, missing, "no golden %s", kind.dir)
+	}
+}
+
+// refreshGolden rewrites the corpus from the snapshot at path, keeping the
+// largest capture of every account, instruction and event it holds. Captures
+// of names missing from the snapshot are left in place.
+func refreshGolden(t *testing.T, path string) {
+	raw, err := os.ReadFile(path)
+	ag_require.NoError(t, err)
+	var snap snapshot
+	ag_require.NoError(t, json.Unmarshal(raw, &snap))
+
+	captures := map[string]map[string]string{}
+	add := func(dir, name, capture string) {
+		if captures[dir] == nil {
+			captures[dir] = map[string]string{}
+		}
+		if len(capture) > len(captures[dir][name]) {
+			captures[dir][name] = capture
+		}
+	}
+	for _, account := range snap.Accounts {
+		data := account.Account.Data.GetBinary()
+		for _, c := range roundTripCases {
+			if c.discriminator != nil && bytes.HasPrefix(data, c.discriminator) {
+				add("accounts", c.name, base64.StdEncoding.EncodeToString(data))
+			}
+		}
+	}
+	for _, result := range snap.Transactions {
+		tx, err := result.Transaction.GetTransaction()
+		ag_require.NoError(t, err)
+		for _, ins := range tx.Message.Instructions {
+			program, err := tx.Message.Program(ins.ProgramIDIndex)
+			ag_require.NoError(t, err)
+			if !program.Equals(snap.ProgramID) || len(ins.Data) < 8 {
+				continue
+			}
+			if name := InstructionIDToName(ag_binary.TypeID(ins.Data[:8])); name != "" {
+				add("instructions", name, base64.StdEncoding.EncodeToString(ins.Data))
+			}
+		}
+		if result.Meta == nil {
+			continue
+		}
+		for _, line := range result.Meta.LogMessages {
+			if !strings.HasPrefix(line, eventLogPrefix) {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(line[len(eventLogPrefix):])
+			if err != nil || len(data) < 8 {
+				continue
+			}
+			if name, ok := eventNames[[8]byte(data[:8])]; ok {
+				add("events", name, line)
+			}
+		}
+	}
+
+	for _, kind := range goldenKinds {
+		ag_require.NoError(t, os.MkdirAll(filepath.Join(goldenDir, kind.dir), 0755))
+		for name, capture := range captures[kind.dir] {
+			value, _, err := kind.decode(name, capture)
+			ag_require.NoError(t, err, "%s/%s", kind.dir, name)
+			out, err := goldenJSON(value)
+			ag_require.NoError(t, err)
+			base := filepath.Join(goldenDir, kind.dir, name)
+			ag_require.NoError(t, os.WriteFile(base+kind.ext, []byte(capture+"\n"), 0644))
+			ag_require.NoError(t, os.WriteFile(base+".json", out, 0644))
+		}
+		t.Logf("refreshed %d %s", len(captures[kind.dir]), kind.dir)
+	}
+}
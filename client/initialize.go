@@ -1,3 +1,5 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
@@ -31,12 +33,22 @@ type Initialize struct {
 	//
 	// [8] = [] associated_token_program
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewInitializeInstructionBuilder creates a new `Initialize` instruction builder.
+// NewInitializeInstructionBuilder creates a new `Initialize` instruction builder
+// for the default program.
 func NewInitializeInstructionBuilder() *Initialize {
+	return (*Program)(nil).NewInitializeInstructionBuilder()
+}
+
+// NewInitializeInstructionBuilder creates a new `Initialize` instruction builder
+// for the program.
+func (p *Program) NewInitializeInstructionBuilder() *Initialize {
 	nd := &Initialize{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 9),
+		program:          p,
 	}
 	nd.AccountMetaSlice[6] = ag_solanago.Meta(Addresses["11111111111111111111111111111111"])
 	nd.AccountMetaSlice[7] = ag_solanago.Meta(Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"])
@@ -44,6 +56,10 @@ func NewInitializeInstructionBuilder() *Initialize {
 	return nd
 }
 
+func (inst *Initialize) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetRewardLockedTime sets the "reward_locked_time" parameter.
 func (inst *Initialize) SetRewardLockedTime(reward_locked_time uint64) *Initialize {
 	inst.RewardLockedTime = &reward_locked_time
@@ -62,28 +78,20 @@ func (inst *Initialize) SetSupernodeAccount(supernode ag_solanago.PublicKey) *In
 	return inst
 }
 
-func (inst *Initialize) findFindSupernodeAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernode returns the seeds of the Supernode account address, without the bump.
+func (inst *Initialize) seedsSupernode() (seeds [][]byte) {
 	// const: supernode
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeAddressWithBumpSeed calculates Supernode account address with given seeds and a known bump seed.
 func (inst *Initialize) FindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernode(), bumpSeed, inst.program.ID())
 }
 
 func (inst *Initialize) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(bumpSeed)
+	pda, err := inst.FindSupernodeAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -92,12 +100,11 @@ func (inst *Initialize) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pd
 
 // FindSupernodeAddress finds Supernode account address with given seeds.
 func (inst *Initialize) FindSupernodeAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernode(), inst.program.ID())
 }
 
 func (inst *Initialize) MustFindSupernodeAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(0)
+	pda, _, err := inst.FindSupernodeAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -115,28 +122,20 @@ func (inst *Initialize) SetSupernodeStakeAccountAccount(supernodeStakeAccount ag
 	return inst
 }
 
-func (inst *Initialize) findFindSupernodeStakeAccountAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernodeStakeAccount returns the seeds of the SupernodeStakeAccount account address, without the bump.
+func (inst *Initialize) seedsSupernodeStakeAccount() (seeds [][]byte) {
 	// const: supernode_stake_account
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65), byte(0x5f), byte(0x73), byte(0x74), byte(0x61), byte(0x6b), byte(0x65), byte(0x5f), byte(0x61), byte(0x63), byte(0x63), byte(0x6f), byte(0x75), byte(0x6e), byte(0x74)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeStakeAccountAddressWithBumpSeed calculates SupernodeStakeAccount account address with given seeds and a known bump seed.
 func (inst *Initialize) FindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeStakeAccountAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernodeStakeAccount(), bumpSeed, inst.program.ID())
 }
 
 func (inst *Initialize) MustFindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeStakeAccountAddress(bumpSeed)
+	pda, err := inst.FindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -145,12 +144,11 @@ func (inst *Initialize) MustFindSupernodeStakeAccountAddressWithBumpSeed(bumpSee
 
 // FindSupernodeStakeAccountAddress finds SupernodeStakeAccount account address with given seeds.
 func (inst *Initialize) FindSupernodeStakeAccountAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeStakeAccountAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernodeStakeAccount(), inst.program.ID())
 }
 
 func (inst *Initialize) MustFindSupernodeStakeAccountAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeStakeAccountAddress(0)
+	pda, _, err := inst.FindSupernodeStakeAccountAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -168,28 +166,20 @@ func (inst *Initialize) SetSupernodeVestingAccountAccount(supernodeVestingAccoun
 	return inst
 }
 
-func (inst *Initialize) findFindSupernodeVestingAccountAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernodeVestingAccount returns the seeds of the SupernodeVestingAccount account address, without the bump.
+func (inst *Initialize) seedsSupernodeVestingAccount() (seeds [][]byte) {
 	// const: supernode_vesting_account
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65), byte(0x5f), byte(0x76), byte(0x65), byte(0x73), byte(0x74), byte(0x69), byte(0x6e), byte(0x67), byte(0x5f), byte(0x61), byte(0x63), byte(0x63), byte(0x6f), byte(0x75), byte(0x6e), byte(0x74)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeVestingAccountAddressWithBumpSeed calculates SupernodeVestingAccount account address with given seeds and a known bump seed.
 func (inst *Initialize) FindSupernodeVestingAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeVestingAccountAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernodeVestingAccount(), bumpSeed, inst.program.ID())
 }
 
 func (inst *Initialize) MustFindSupernodeVestingAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeVestingAccountAddress(bumpSeed)
+	pda, err := inst.FindSupernodeVestingAccountAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -198,12 +188,11 @@ func (inst *Initialize) MustFindSupernodeVestingAccountAddressWithBumpSeed(bumpS
 
 // FindSupernodeVestingAccountAddress finds SupernodeVestingAccount account address with given seeds.
 func (inst *Initialize) FindSupernodeVestingAccountAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeVestingAccountAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernodeVestingAccount(), inst.program.ID())
 }
 
 func (inst *Initialize) MustFindSupernodeVestingAccountAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeVestingAccountAddress(0)
+	pda, _, err := inst.FindSupernodeVestingAccountAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -232,28 +221,20 @@ func (inst *Initialize) SetSupernodeRentalAccountAccount(supernodeRentalAccount
 	return inst
 }
 
-func (inst *Initialize) findFindSupernodeRentalAccountAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernodeRentalAccount returns the seeds of the SupernodeRentalAccount account address, without the bump.
+func (inst *Initialize) seedsSupernodeRentalAccount() (seeds [][]byte) {
 	// const: supernode_rental_account
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65), byte(0x5f), byte(0x72), byte(0x65), byte(0x6e), byte(0x74), byte(0x61), byte(0x6c), byte(0x5f), byte(0x61), byte(0x63), byte(0x63), byte(0x6f), byte(0x75), byte(0x6e), byte(0x74)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeRentalAccountAddressWithBumpSeed calculates SupernodeRentalAccount account address with given seeds and a known bump seed.
 func (inst *Initialize) FindSupernodeRentalAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeRentalAccountAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernodeRentalAccount(), bumpSeed, inst.program.ID())
 }
 
 func (inst *Initialize) MustFindSupernodeRentalAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeRentalAccountAddress(bumpSeed)
+	pda, err := inst.FindSupernodeRentalAccountAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -262,12 +243,11 @@ func (inst *Initialize) MustFindSupernodeRentalAccountAddressWithBumpSeed(bumpSe
 
 // FindSupernodeRentalAccountAddress finds SupernodeRentalAccount account address with given seeds.
 func (inst *Initialize) FindSupernodeRentalAccountAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeRentalAccountAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernodeRentalAccount(), inst.program.ID())
 }
 
 func (inst *Initialize) MustFindSupernodeRentalAccountAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeRentalAccountAddress(0)
+	pda, _, err := inst.FindSupernodeRentalAccountAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -327,7 +307,7 @@ func (inst Initialize) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_Initialize,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -384,8 +364,49 @@ func (inst *Initialize) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *Initialize) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	errs = append(errs, checkFlags("initialize", "supernode", accounts[0], false, true))
+	{
+		pda, _, err := inst.FindSupernodeAddress()
+		errs = append(errs, checkAccount("initialize", "supernode", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("initialize", "supernode_stake_account", accounts[1], false, true))
+	{
+		pda, _, err := inst.FindSupernodeStakeAccountAddress()
+		errs = append(errs, checkAccount("initialize", "supernode_stake_account", accounts[1].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("initialize", "supernode_vesting_account", accounts[2], false, true))
+	{
+		pda, _, err := inst.FindSupernodeVestingAccountAddress()
+		errs = append(errs, checkAccount("initialize", "supernode_vesting_account", accounts[2].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("initialize", "supernode_rental_account", accounts[4], false, true))
+	{
+		pda, _, err := inst.FindSupernodeRentalAccountAddress()
+		errs = append(errs, checkAccount("initialize", "supernode_rental_account", accounts[4].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("initialize", "admin", accounts[5], true, true))
+	errs = append(errs, checkAccount("initialize", "system_program", accounts[6].PublicKey, Addresses["11111111111111111111111111111111"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("initialize", "token_program", accounts[7].PublicKey, Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("initialize", "associated_token_program", accounts[8].PublicKey, Addresses["ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"], nil, ErrProgramMismatch))
+	return errors.Join(errs...)
+}
+
 func (inst *Initialize) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("Initialize")).
@@ -441,7 +462,8 @@ func (obj *Initialize) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err err
 	return nil
 }
 
-// NewInitializeInstruction declares a new Initialize instruction with the provided parameters and accounts.
+// NewInitializeInstruction declares a new Initialize instruction of the default
+// program with the provided parameters and accounts.
 func NewInitializeInstruction(
 	// Parameters:
 	reward_locked_time uint64,
@@ -456,7 +478,26 @@ func NewInitializeInstruction(
 	systemProgram ag_solanago.PublicKey,
 	tokenProgram ag_solanago.PublicKey,
 	associatedTokenProgram ag_solanago.PublicKey) *Initialize {
-	return NewInitializeInstructionBuilder().
+	return (*Program)(nil).NewInitializeInstruction(reward_locked_time, staking_coefficient, supernode, supernodeStakeAccount, supernodeVestingAccount, token, supernodeRentalAccount, admin, systemProgram, tokenProgram, associatedTokenProgram)
+}
+
+// NewInitializeInstruction declares a new Initialize instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewInitializeInstruction(
+	// Parameters:
+	reward_locked_time uint64,
+	staking_coefficient uint64,
+	// Accounts:
+	supernode ag_solanago.PublicKey,
+	supernodeStakeAccount ag_solanago.PublicKey,
+	supernodeVestingAccount ag_solanago.PublicKey,
+	token ag_solanago.PublicKey,
+	supernodeRentalAccount ag_solanago.PublicKey,
+	admin ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+	associatedTokenProgram ag_solanago.PublicKey) *Initialize {
+	return p.NewInitializeInstructionBuilder().
 		SetRewardLockedTime(reward_locked_time).
 		SetStakingCoefficient(staking_coefficient).
 		SetSupernodeAccount(supernode).
@@ -1,3 +1,5 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
@@ -25,12 +27,22 @@ type InitRewardAccount struct {
 	//
 	// [6] = [] associated_token_program
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewInitRewardAccountInstructionBuilder creates a new `InitRewardAccount` instruction builder.
+// NewInitRewardAccountInstructionBuilder creates a new `InitRewardAccount` instruction builder
+// for the default program.
 func NewInitRewardAccountInstructionBuilder() *InitRewardAccount {
+	return (*Program)(nil).NewInitRewardAccountInstructionBuilder()
+}
+
+// NewInitRewardAccountInstructionBuilder creates a new `InitRewardAccount` instruction builder
+// for the program.
+func (p *Program) NewInitRewardAccountInstructionBuilder() *InitRewardAccount {
 	nd := &InitRewardAccount{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 7),
+		program:          p,
 	}
 	nd.AccountMetaSlice[4] = ag_solanago.Meta(Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"])
 	nd.AccountMetaSlice[5] = ag_solanago.Meta(Addresses["11111111111111111111111111111111"])
@@ -38,34 +50,30 @@ func NewInitRewardAccountInstructionBuilder() *InitRewardAccount {
 	return nd
 }
 
+func (inst *InitRewardAccount) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetSupernodeAccount sets the "supernode" account.
 func (inst *InitRewardAccount) SetSupernodeAccount(supernode ag_solanago.PublicKey) *InitRewardAccount {
 	inst.AccountMetaSlice[0] = ag_solanago.Meta(supernode).WRITE()
 	return inst
 }
 
-func (inst *InitRewardAccount) findFindSupernodeAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernode returns the seeds of the Supernode account address, without the bump.
+func (inst *InitRewardAccount) seedsSupernode() (seeds [][]byte) {
 	// const: supernode
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeAddressWithBumpSeed calculates Supernode account address with given seeds and a known bump seed.
 func (inst *InitRewardAccount) FindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernode(), bumpSeed, inst.program.ID())
 }
 
 func (inst *InitRewardAccount) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(bumpSeed)
+	pda, err := inst.FindSupernodeAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -74,12 +82,11 @@ func (inst *InitRewardAccount) MustFindSupernodeAddressWithBumpSeed(bumpSeed uin
 
 // FindSupernodeAddress finds Supernode account address with given seeds.
 func (inst *InitRewardAccount) FindSupernodeAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernode(), inst.program.ID())
 }
 
 func (inst *InitRewardAccount) MustFindSupernodeAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(0)
+	pda, _, err := inst.FindSupernodeAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -97,28 +104,20 @@ func (inst *InitRewardAccount) SetSupernodeRewardAccountAccount(supernodeRewardA
 	return inst
 }
 
-func (inst *InitRewardAccount) findFindSupernodeRewardAccountAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernodeRewardAccount returns the seeds of the SupernodeRewardAccount account address, without the bump.
+func (inst *InitRewardAccount) seedsSupernodeRewardAccount() (seeds [][]byte) {
 	// const: supernode_reward_account
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65), byte(0x5f), byte(0x72), byte(0x65), byte(0x77), byte(0x61), byte(0x72), byte(0x64), byte(0x5f), byte(0x61), byte(0x63), byte(0x63), byte(0x6f), byte(0x75), byte(0x6e), byte(0x74)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeRewardAccountAddressWithBumpSeed calculates SupernodeRewardAccount account address with given seeds and a known bump seed.
 func (inst *InitRewardAccount) FindSupernodeRewardAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeRewardAccountAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernodeRewardAccount(), bumpSeed, inst.program.ID())
 }
 
 func (inst *InitRewardAccount) MustFindSupernodeRewardAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeRewardAccountAddress(bumpSeed)
+	pda, err := inst.FindSupernodeRewardAccountAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -127,12 +126,11 @@ func (inst *InitRewardAccount) MustFindSupernodeRewardAccountAddressWithBumpSeed
 
 // FindSupernodeRewardAccountAddress finds SupernodeRewardAccount account address with given seeds.
 func (inst *InitRewardAccount) FindSupernodeRewardAccountAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeRewardAccountAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernodeRewardAccount(), inst.program.ID())
 }
 
 func (inst *InitRewardAccount) MustFindSupernodeRewardAccountAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeRewardAccountAddress(0)
+	pda, _, err := inst.FindSupernodeRewardAccountAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -203,7 +201,7 @@ func (inst InitRewardAccount) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_InitRewardAccount,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -244,8 +242,39 @@ func (inst *InitRewardAccount) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *InitRewardAccount) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	errs = append(errs, checkFlags("init_reward_account", "supernode", accounts[0], false, true))
+	{
+		pda, _, err := inst.FindSupernodeAddress()
+		errs = append(errs, checkAccount("init_reward_account", "supernode", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("init_reward_account", "supernode_reward_account", accounts[1], false, true))
+	{
+		pda, _, err := inst.FindSupernodeRewardAccountAddress()
+		errs = append(errs, checkAccount("init_reward_account", "supernode_reward_account", accounts[1].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("init_reward_account", "admin", accounts[3], true, true))
+	errs = append(errs, checkAccount("init_reward_account", "token_program", accounts[4].PublicKey, Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("init_reward_account", "system_program", accounts[5].PublicKey, Addresses["11111111111111111111111111111111"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("init_reward_account", "associated_token_program", accounts[6].PublicKey, Addresses["ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"], nil, ErrProgramMismatch))
+	return errors.Join(errs...)
+}
+
 func (inst *InitRewardAccount) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("InitRewardAccount")).
@@ -276,7 +305,8 @@ func (obj *InitRewardAccount) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (
 	return nil
 }
 
-// NewInitRewardAccountInstruction declares a new InitRewardAccount instruction with the provided parameters and accounts.
+// NewInitRewardAccountInstruction declares a new InitRewardAccount instruction of the default
+// program with the provided parameters and accounts.
 func NewInitRewardAccountInstruction(
 	// Accounts:
 	supernode ag_solanago.PublicKey,
@@ -286,7 +316,21 @@ func NewInitRewardAccountInstruction(
 	tokenProgram ag_solanago.PublicKey,
 	systemProgram ag_solanago.PublicKey,
 	associatedTokenProgram ag_solanago.PublicKey) *InitRewardAccount {
-	return NewInitRewardAccountInstructionBuilder().
+	return (*Program)(nil).NewInitRewardAccountInstruction(supernode, supernodeRewardAccount, token, admin, tokenProgram, systemProgram, associatedTokenProgram)
+}
+
+// NewInitRewardAccountInstruction declares a new InitRewardAccount instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewInitRewardAccountInstruction(
+	// Accounts:
+	supernode ag_solanago.PublicKey,
+	supernodeRewardAccount ag_solanago.PublicKey,
+	token ag_solanago.PublicKey,
+	admin ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	associatedTokenProgram ag_solanago.PublicKey) *InitRewardAccount {
+	return p.NewInitRewardAccountInstructionBuilder().
 		SetSupernodeAccount(supernode).
 		SetSupernodeRewardAccountAccount(supernodeRewardAccount).
 		SetTokenAccount(token).
@@ -1,3 +1,5 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
@@ -10,18 +12,22 @@ import (
 	ag_treeout "github.com/gagliardetto/treeout"
 )
 
+// ProgramID is the program ID of the default program, which the
+// package-level builders and decoders use.
 var ProgramID ag_solanago.PublicKey
 
+// SetProgramID sets the program ID of the default program and registers its
+// instruction decoder.
 func SetProgramID(PublicKey ag_solanago.PublicKey) {
 	ProgramID = PublicKey
-	ag_solanago.RegisterInstructionDecoder(ProgramID, registryDecodeInstruction)
+	(*Program)(nil).Register()
 }
 
-const ProgramName = "Dummy"
+const ProgramName = "Supernode"
 
 func init() {
 	if !ProgramID.IsZero() {
-		ag_solanago.RegisterInstructionDecoder(ProgramID, registryDecodeInstruction)
+		(*Program)(nil).Register()
 	}
 }
 
@@ -101,6 +107,8 @@ func InstructionIDToName(id ag_binary.TypeID) string {
 
 type Instruction struct {
 	ag_binary.BaseVariant
+
+	program *Program
 }
 
 func (inst *Instruction) EncodeToTree(parent ag_treeout.Branches) {
@@ -166,7 +174,15 @@ var InstructionImplDef = ag_binary.NewVariantDefinition(
 )
 
 func (inst *Instruction) ProgramID() ag_solanago.PublicKey {
-	return ProgramID
+	return inst.program.ID()
+}
+
+// setProgram binds the instruction and its Impl to p.
+func (inst *Instruction) setProgram(p *Program) {
+	inst.program = p
+	if v, ok := inst.Impl.(interface{ setProgram(*Program) }); ok {
+		v.setProgram(p)
+	}
 }
 
 func (inst *Instruction) Accounts() (out []*ag_solanago.AccountMeta) {
@@ -197,14 +213,6 @@ func (inst *Instruction) MarshalWithEncoder(encoder *ag_binary.Encoder) error {
 	return encoder.Encode(inst.Impl)
 }
 
-func registryDecodeInstruction(accounts []*ag_solanago.AccountMeta, data []byte) (interface{}, error) {
-	inst, err := decodeInstruction(accounts, data)
-	if err != nil {
-		return nil, err
-	}
-	return inst, nil
-}
-
 func decodeInstruction(accounts []*ag_solanago.AccountMeta, data []byte) (*Instruction, error) {
 	inst := new(Instruction)
 	if err := ag_binary.NewBorshDecoder(data).Decode(inst); err != nil {
@@ -219,24 +227,9 @@ func decodeInstruction(accounts []*ag_solanago.AccountMeta, data []byte) (*Instr
 	return inst, nil
 }
 
+// DecodeInstructions decodes the instructions of the default program in
+// message. Lookups of v0 messages without address tables are resolved
+// through the getter installed with SetAddressTablesGetter.
 func DecodeInstructions(message *ag_solanago.Message) (instructions []*Instruction, err error) {
-	for _, ins := range message.Instructions {
-		var programID ag_solanago.PublicKey
-		if programID, err = message.Program(ins.ProgramIDIndex); err != nil {
-			return
-		}
-		if !programID.Equals(ProgramID) {
-			continue
-		}
-		var accounts []*ag_solanago.AccountMeta
-		if accounts, err = ins.ResolveInstructionAccounts(message); err != nil {
-			return
-		}
-		var insDecoded *Instruction
-		if insDecoded, err = decodeInstruction(accounts, ins.Data); err != nil {
-			return
-		}
-		instructions = append(instructions, insDecoded)
-	}
-	return
+	return (*Program)(nil).DecodeInstructions(message)
 }
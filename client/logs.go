@@ -0,0 +1,281 @@
+package client
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+)
+
+// ErrLogTruncated is returned by ParseLogs for logs the runtime cut short:
+// the invocations parsed up to the cut are returned with it.
+var ErrLogTruncated = errors.New("log truncated")
+
+// ErrMalformedLogs is wrapped by the errors of ParseLogs for logs that do
+// not follow the runtime's invoke, consumed and success or failed lines.
+var ErrMalformedLogs = errors.New("malformed logs")
+
+const logTruncated = "Log truncated"
+
+// Invocation is a program invocation traced in the log messages of a
+// transaction.
+type Invocation struct {
+	ProgramID ag_solanago.PublicKey
+	// Instruction is the index of the outer instruction the invocation runs
+	// under. Depth is 1 for the outer instruction itself and one more for
+	// every level of CPI.
+	Instruction int
+	Depth       int
+	// ComputeUnits is the compute units the invocation consumed, its calls
+	// included, and ComputeLimit the units it was given. Both are zero when
+	// the logs do not report them, as for the compute budget program.
+	ComputeUnits uint64
+	ComputeLimit uint64
+	// Completed is set once the invocation logged its success or failure,
+	// and Err is the reason of a failure as logged after "failed: ".
+	Completed bool
+	Err       string
+	// Logs are the other lines the invocation logged, in order, and Data the
+	// payloads of its "Program data: " lines.
+	Logs []string
+	Data [][]byte
+	// dataLines are the positions of the Data lines in the logs.
+	dataLines []int
+
+	Parent *Invocation
+	Calls  []*Invocation
+}
+
+// ParseLogs builds the call tree of a transaction from its log messages. It
+// returns the invocation of every outer instruction that ran, with the CPIs
+// it made under Calls, and attaches every log and data line to the program
+// that wrote it.
+func ParseLogs(logs []string) (instructions []*Invocation, err error) {
+	var (
+		w   logWalker
+		cur *Invocation
+		// programs caches the IDs decoded so far.
+		programs = map[string]ag_solanago.PublicKey{}
+	)
+	for i, line := range logs {
+		entry, err := w.step(i, line)
+		if err != nil {
+			return nil, err
+		}
+		switch entry.kind {
+		case logCut:
+			return instructions, ErrLogTruncated
+		case logOther:
+			if cur != nil {
+				cur.Logs = append(cur.Logs, line)
+			}
+		case logData:
+			binary, err := base64.StdEncoding.DecodeString(entry.text)
+			if err != nil {
+				return nil, fmt.Errorf("log %d: program data %q is not base64: %w", i, entry.text, ErrMalformedLogs)
+			}
+			cur.Data = append(cur.Data, binary)
+			cur.dataLines = append(cur.dataLines, i)
+		case logInvoke:
+			program, ok := programs[entry.program]
+			if !ok {
+				if program, err = ag_solanago.PublicKeyFromBase58(entry.program); err != nil {
+					return nil, fmt.Errorf("log %d: program %q: %w", i, entry.program, ErrMalformedLogs)
+				}
+				programs[entry.program] = program
+			}
+			inv := &Invocation{ProgramID: program, Instruction: w.instructions - 1, Depth: entry.depth, Parent: cur}
+			if cur == nil {
+				instructions = append(instructions, inv)
+			} else {
+				cur.Calls = append(cur.Calls, inv)
+			}
+			cur = inv
+		case logConsumed:
+			cur.ComputeUnits, cur.ComputeLimit = entry.units, entry.limit
+		case logSuccess:
+			cur.Completed = true
+			cur = cur.Parent
+		case logFailed:
+			cur.Completed, cur.Err = true, entry.text
+			cur = cur.Parent
+		}
+	}
+	return instructions, nil
+}
+
+// logKind classifies log lines.
+type logKind int
+
+const (
+	// logOther is a line a program logged, "Program log: " and the like.
+	logOther logKind = iota
+	// logData is "Program data: <base64>".
+	logData
+	// logInvoke is "Program <id> invoke [<depth>]".
+	logInvoke
+	// logConsumed is "Program <id> consumed <units> of <limit> compute units".
+	logConsumed
+	// logSuccess is "Program <id> success".
+	logSuccess
+	// logFailed is "Program <id> failed: <reason>".
+	logFailed
+	// logCut is the line the runtime ends truncated logs with.
+	logCut
+	// logUnexpected is any other "Program <id> " line.
+	logUnexpected
+)
+
+// logEntry is a parsed log line.
+type logEntry struct {
+	kind logKind
+	// program is the base58 ID of the program of a runtime line and text
+	// the payload of a data line or the reason of a failure.
+	program string
+	text    string
+	depth   int
+	units   uint64
+	limit   uint64
+}
+
+// parseLogEntry parses line without allocating.
+func parseLogEntry(line string) (e logEntry) {
+	if line == logTruncated {
+		return logEntry{kind: logCut}
+	}
+	if data, ok := strings.CutPrefix(line, eventLogPrefix); ok {
+		return logEntry{kind: logData, text: data}
+	}
+	rest, ok := strings.CutPrefix(line, "Program ")
+	if !ok {
+		return logEntry{kind: logOther}
+	}
+	id, rest, ok := strings.Cut(rest, " ")
+	// Lines logged by programs, "Program log: " and the like, do not start
+	// with a program ID.
+	if !ok || !isBase58ID(id) {
+		return logEntry{kind: logOther}
+	}
+	e = logEntry{kind: logUnexpected, program: id}
+	switch {
+	case rest == "success":
+		e.kind = logSuccess
+	case strings.HasPrefix(rest, "failed: "):
+		e.kind, e.text = logFailed, rest[len("failed: "):]
+	case strings.HasPrefix(rest, "invoke ["):
+		if depth, ok := cutInvoke(rest); ok {
+			e.kind, e.depth = logInvoke, depth
+		}
+	case strings.HasPrefix(rest, "consumed "):
+		if units, limit, ok := cutConsumed(rest); ok {
+			e.kind, e.units, e.limit = logConsumed, units, limit
+		}
+	}
+	return e
+}
+
+// isBase58ID reports whether id has the length and the alphabet of a
+// base58 public key.
+func isBase58ID(id string) bool {
+	if len(id) < 32 || len(id) > 44 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if !('1' <= c && c <= '9' || 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z') || c == 'I' || c == 'O' || c == 'l' {
+			return false
+		}
+	}
+	return true
+}
+
+// cutInvoke parses "invoke [<depth>]".
+func cutInvoke(rest string) (depth int, ok bool) {
+	rest, ok = strings.CutPrefix(rest, "invoke [")
+	if !ok {
+		return
+	}
+	rest, ok = strings.CutSuffix(rest, "]")
+	if !ok {
+		return
+	}
+	depth, err := strconv.Atoi(rest)
+	return depth, err == nil
+}
+
+// cutConsumed parses "consumed <units> of <limit> compute units".
+func cutConsumed(rest string) (units, limit uint64, ok bool) {
+	rest, ok = strings.CutPrefix(rest, "consumed ")
+	if !ok {
+		return
+	}
+	rest, ok = strings.CutSuffix(rest, " compute units")
+	if !ok {
+		return
+	}
+	u, l, ok := strings.Cut(rest, " of ")
+	if !ok {
+		return
+	}
+	units, err := strconv.ParseUint(u, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	limit, err = strconv.ParseUint(l, 10, 64)
+	return units, limit, err == nil
+}
+
+// logWalker follows the invocations of a transaction through its log lines
+// and checks each line against them.
+type logWalker struct {
+	// stack holds the programs of the running invocations, the outer one
+	// first.
+	stack []string
+	// instructions counts the outer instructions invoked so far.
+	instructions int
+}
+
+// step parses line i and checks it against the running invocations: an
+// invoke one level deeper than the current one, data, consumed, success and
+// failed lines inside the invocation of their program.
+func (w *logWalker) step(i int, line string) (logEntry, error) {
+	e := parseLogEntry(line)
+	switch e.kind {
+	case logInvoke:
+		if want := len(w.stack) + 1; e.depth != want {
+			return e, fmt.Errorf("log %d: %s invoked at depth %d, want %d: %w", i, e.program, e.depth, want, ErrMalformedLogs)
+		}
+		if e.depth == 1 {
+			w.instructions++
+		}
+		w.stack = append(w.stack, e.program)
+	case logData:
+		if len(w.stack) == 0 {
+			return e, fmt.Errorf("log %d: data outside of an invocation: %w", i, ErrMalformedLogs)
+		}
+	case logConsumed, logSuccess, logFailed:
+		if len(w.stack) == 0 || w.stack[len(w.stack)-1] != e.program {
+			return e, fmt.Errorf("log %d: %q outside of an invocation of %s: %w", i, line, e.program, ErrMalformedLogs)
+		}
+		if e.kind != logConsumed {
+			w.stack = w.stack[:len(w.stack)-1]
+		}
+	case logUnexpected:
+		return e, fmt.Errorf("log %d: unexpected %q: %w", i, line, ErrMalformedLogs)
+	}
+	return e, nil
+}
+
+// calls returns the invocations under inv in the order they ran, which is
+// the order of the inner instructions the RPC reports for them.
+func (inv *Invocation) calls() []*Invocation {
+	var out []*Invocation
+	for _, call := range inv.Calls {
+		out = append(out, call)
+		out = append(out, call.calls()...)
+	}
+	return out
+}
@@ -0,0 +1,209 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_rpc "github.com/gagliardetto/solana-go/rpc"
+	ag_require "github.com/stretchr/testify/require"
+)
+
+func logLine(program ag_solanago.PublicKey, rest string) string {
+	return "Program " + program.String() + " " + rest
+}
+
+func dataLine(t *testing.T, event roundTripper) string {
+	data, err := marshal(event)
+	ag_require.NoError(t, err)
+	return eventLogPrefix + base64.StdEncoding.EncodeToString(data)
+}
+
+// cpiTransaction is a transaction of three outer instructions: a compute
+// budget instruction, a supernode instruction transferring tokens and a
+// router calling the supernode program, which emits one event through a
+// self-CPI. The router logs an event of its own.
+func cpiTransaction(t *testing.T) *ag_rpc.GetTransactionResult {
+	program, router := testProgramID, ag_solanago.MemoProgramID
+	token, budget := ag_solanago.TokenProgramID, ag_solanago.ComputeBudget
+	payer := ag_solanago.NewWallet().PublicKey()
+	tx, err := ag_solanago.NewTransaction([]ag_solanago.Instruction{
+		ag_solanago.NewInstruction(budget, nil, []byte{2, 0, 0, 0, 0}),
+		ag_solanago.NewInstruction(program, ag_solanago.AccountMetaSlice{ag_solanago.Meta(token)}, make([]byte, 16)),
+		ag_solanago.NewInstruction(router, ag_solanago.AccountMetaSlice{ag_solanago.Meta(program)}, nil),
+	}, ag_solanago.Hash{}, ag_solanago.TransactionPayer(payer))
+	ag_require.NoError(t, err)
+
+	index := func(key ag_solanago.PublicKey) uint16 {
+		i, err := tx.Message.GetAccountIndex(key)
+		ag_require.NoError(t, err)
+		return i
+	}
+	claimed, err := marshal(&RewardClaimedEventEventData{Provider: payer, Amount: 3})
+	ag_require.NoError(t, err)
+	emitted := append(eventIxTag[:], claimed...)
+
+	logs := []string{
+		logLine(budget, "invoke [1]"),
+		logLine(budget, "success"),
+		logLine(program, "invoke [1]"),
+		"Program log: Instruction: StakeDevice",
+		logLine(token, "invoke [2]"),
+		"Program log: Instruction: Transfer",
+		logLine(token, "consumed 4645 of 190000 compute units"),
+		logLine(token, "success"),
+		dataLine(t, &DeviceStakedEventEventData{Provider: payer, DeviceId: 1, Amount: 10}),
+		logLine(program, "consumed 30000 of 200000 compute units"),
+		logLine(program, "success"),
+		logLine(router, "invoke [1]"),
+		dataLine(t, &DeviceStakedEventEventData{DeviceId: 99}),
+		logLine(program, "invoke [2]"),
+		dataLine(t, &WithdrawEventEventData{Tenant: payer, Amount: 5}),
+		logLine(program, "invoke [3]"),
+		logLine(program, "consumed 1000 of 140000 compute units"),
+		logLine(program, "success"),
+		logLine(program, "consumed 12000 of 150000 compute units"),
+		logLine(program, "success"),
+		logLine(router, "consumed 20000 of 170000 compute units"),
+		logLine(router, "success"),
+	}
+	inner := []ag_rpc.InnerInstruction{
+		{Index: 1, Instructions: []ag_solanago.CompiledInstruction{
+			{ProgramIDIndex: index(token), Data: make([]byte, 9)},
+		}},
+		{Index: 2, Instructions: []ag_solanago.CompiledInstruction{
+			{ProgramIDIndex: index(program), Data: make([]byte, 16)},
+			{ProgramIDIndex: index(program), Data: emitted},
+		}},
+	}
+	return transactionResult(t, tx, logs, inner)
+}
+
+func transactionResult(t *testing.T, tx *ag_solanago.Transaction, logs []string, inner []ag_rpc.InnerInstruction) *ag_rpc.GetTransactionResult {
+	raw, err := tx.MarshalBinary()
+	ag_require.NoError(t, err)
+	encoded, err := json.Marshal([]string{base64.StdEncoding.EncodeToString(raw), "base64"})
+	ag_require.NoError(t, err)
+	envelope := new(ag_rpc.TransactionResultEnvelope)
+	ag_require.NoError(t, json.Unmarshal(encoded, envelope))
+	return &ag_rpc.GetTransactionResult{
+		Transaction: envelope,
+		Meta:        &ag_rpc.TransactionMeta{LogMessages: logs, InnerInstructions: inner},
+	}
+}
+
+func TestParseLogs(t *testing.T) {
+	txData := cpiTransaction(t)
+	instructions, err := ParseLogs(txData.Meta.LogMessages)
+	ag_require.NoError(t, err)
+	ag_require.Len(t, instructions, 3)
+
+	budget, stake, router := instructions[0], instructions[1], instructions[2]
+	ag_require.Equal(t, ag_solanago.ComputeBudget, budget.ProgramID)
+	ag_require.True(t, budget.Completed)
+	ag_require.Zero(t, budget.ComputeUnits)
+
+	ag_require.Equal(t, 1, stake.Instruction)
+	ag_require.Equal(t, uint64(30000), stake.ComputeUnits)
+	ag_require.Equal(t, uint64(200000), stake.ComputeLimit)
+	ag_require.Equal(t, []string{"Program log: Instruction: StakeDevice"}, stake.Logs)
+	ag_require.Len(t, stake.Data, 1)
+	ag_require.Len(t, stake.Calls, 1)
+	transfer := stake.Calls[0]
+	ag_require.Equal(t, ag_solanago.TokenProgramID, transfer.ProgramID)
+	ag_require.Equal(t, 1, transfer.Instruction)
+	ag_require.Equal(t, 2, transfer.Depth)
+	ag_require.Equal(t, stake, transfer.Parent)
+	ag_require.Equal(t, []string{"Program log: Instruction: Transfer"}, transfer.Logs)
+	ag_require.Empty(t, transfer.Data)
+
+	ag_require.Len(t, router.Data, 1)
+	ag_require.Len(t, router.Calls, 1)
+	call := router.Calls[0]
+	ag_require.Equal(t, testProgramID, call.ProgramID)
+	ag_require.Equal(t, 2, call.Instruction)
+	ag_require.Equal(t, 2, call.Depth)
+	ag_require.Len(t, call.Data, 1)
+	ag_require.Len(t, call.Calls, 1)
+	ag_require.Equal(t, 3, call.Calls[0].Depth)
+	ag_require.Equal(t, []*Invocation{call, call.Calls[0]}, router.calls())
+}
+
+func TestParseLogs_Failed(t *testing.T) {
+	logs := []string{
+		logLine(testProgramID, "invoke [1]"),
+		logLine(ag_solanago.TokenProgramID, "invoke [2]"),
+		"Program log: Error: insufficient funds",
+		logLine(ag_solanago.TokenProgramID, "consumed 2000 of 190000 compute units"),
+		logLine(ag_solanago.TokenProgramID, "failed: custom program error: 0x1"),
+		logLine(testProgramID, "consumed 12000 of 200000 compute units"),
+		logLine(testProgramID, "failed: custom program error: 0x1"),
+	}
+	instructions, err := ParseLogs(logs)
+	ag_require.NoError(t, err)
+	ag_require.Len(t, instructions, 1)
+	ag_require.True(t, instructions[0].Completed)
+	ag_require.Equal(t, "custom program error: 0x1", instructions[0].Err)
+	ag_require.Equal(t, "custom program error: 0x1", instructions[0].Calls[0].Err)
+
+	// Logs cut by the runtime keep the invocations before the cut.
+	instructions, err = ParseLogs(append(logs[:3:3], logTruncated))
+	ag_require.ErrorIs(t, err, ErrLogTruncated)
+	ag_require.Len(t, instructions, 1)
+	ag_require.False(t, instructions[0].Completed)
+	ag_require.Len(t, instructions[0].Calls, 1)
+}
+
+func TestParseLogs_Malformed(t *testing.T) {
+	program, other := testProgramID, ag_solanago.TokenProgramID
+	for name, logs := range map[string][]string{
+		"depth skipped":   {logLine(program, "invoke [2]")},
+		"nested skipped":  {logLine(program, "invoke [1]"), logLine(other, "invoke [3]")},
+		"other succeeds":  {logLine(program, "invoke [1]"), logLine(other, "success")},
+		"success outside": {logLine(program, "success")},
+		"data outside":    {eventLogPrefix + "AAAA"},
+		"unknown line":    {logLine(program, "invoke [1]"), logLine(program, "exploded")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseLogs(logs)
+			ag_require.ErrorIs(t, err, ErrMalformedLogs)
+		})
+	}
+}
+
+func TestDecodeEvents_LogTree(t *testing.T) {
+	evts, err := DecodeEvents(cpiTransaction(t), testProgramID, nil)
+	ag_require.NoError(t, err)
+	// The router's event is not the supernode program's.
+	ag_require.Len(t, evts, 3)
+
+	ag_require.Equal(t, "DeviceStakedEvent", evts[0].Name)
+	ag_require.Equal(t, uint64(1), evts[0].Data.(*DeviceStakedEventEventData).DeviceId)
+	ag_require.Equal(t, []int{1, 1}, []int{evts[0].Instruction, evts[0].Depth})
+	ag_require.Equal(t, uint64(30000), evts[0].ComputeUnits)
+
+	ag_require.Equal(t, "WithdrawEvent", evts[1].Name)
+	ag_require.Equal(t, []int{2, 2}, []int{evts[1].Instruction, evts[1].Depth})
+	ag_require.Equal(t, uint64(12000), evts[1].ComputeUnits)
+
+	// The self-CPI event is attributed to the invocation that made it.
+	ag_require.Equal(t, "RewardClaimedEvent", evts[2].Name)
+	ag_require.Equal(t, uint64(3), evts[2].Data.(*RewardClaimedEventEventData).Amount)
+	ag_require.Equal(t, []int{2, 2}, []int{evts[2].Instruction, evts[2].Depth})
+	ag_require.Equal(t, uint64(12000), evts[2].ComputeUnits)
+}
+
+func TestDecodeEvents_TruncatedLogs(t *testing.T) {
+	txData := cpiTransaction(t)
+	// Cut the logs inside the router's instruction.
+	txData.Meta.LogMessages = append(txData.Meta.LogMessages[:15:15], logTruncated)
+	evts, err := DecodeEvents(txData, testProgramID, nil)
+	ag_require.NoError(t, err)
+	ag_require.Len(t, evts, 3)
+	ag_require.Equal(t, "WithdrawEvent", evts[1].Name)
+	// The self-CPI ran after the cut: its depth is unknown.
+	ag_require.Equal(t, 2, evts[2].Instruction)
+	ag_require.Zero(t, evts[2].Depth)
+	ag_require.Zero(t, evts[2].ComputeUnits)
+}
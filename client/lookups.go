@@ -0,0 +1,54 @@
+package client
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+)
+
+// AddressTablesGetter returns the contents of the given address lookup tables.
+type AddressTablesGetter func(altAddresses []ag_solanago.PublicKey) (tables map[ag_solanago.PublicKey]ag_solanago.PublicKeySlice, err error)
+
+var addressTablesGetter atomic.Pointer[AddressTablesGetter]
+
+// SetAddressTablesGetter installs the getter DecodeInstructions and DecodeEvents
+// use to resolve the lookups of v0 transactions when none is given.
+func SetAddressTablesGetter(getter AddressTablesGetter) {
+	if getter == nil {
+		addressTablesGetter.Store(nil)
+		return
+	}
+	addressTablesGetter.Store(&getter)
+}
+
+// withAddressTables returns message with the tables its lookups reference
+// loaded. When they have to be fetched, they are set on a copy: message
+// itself is left unchanged.
+func withAddressTables(message *ag_solanago.Message, getAddressTables AddressTablesGetter) (*ag_solanago.Message, error) {
+	if !message.IsVersioned() || message.NumLookups() == 0 || message.GetAddressTables() != nil {
+		return message, nil
+	}
+	if getAddressTables == nil {
+		if installed := addressTablesGetter.Load(); installed != nil {
+			getAddressTables = *installed
+		}
+	}
+	if getAddressTables == nil {
+		return nil, fmt.Errorf("message uses address lookup tables but no address tables getter is set")
+	}
+
+	altAddresses := make([]ag_solanago.PublicKey, len(message.AddressTableLookups))
+	for i, alt := range message.AddressTableLookups {
+		altAddresses[i] = alt.AccountKey
+	}
+	tables, err := getAddressTables(altAddresses)
+	if err != nil {
+		return nil, err
+	}
+	resolved := *message
+	if err := resolved.SetAddressTables(tables); err != nil {
+		return nil, err
+	}
+	return &resolved, nil
+}
@@ -1,3 +1,5 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
@@ -32,12 +34,22 @@ type PayRentalFee struct {
 	//
 	// [9] = [] associated_token_program
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewPayRentalFeeInstructionBuilder creates a new `PayRentalFee` instruction builder.
+// NewPayRentalFeeInstructionBuilder creates a new `PayRentalFee` instruction builder
+// for the default program.
 func NewPayRentalFeeInstructionBuilder() *PayRentalFee {
+	return (*Program)(nil).NewPayRentalFeeInstructionBuilder()
+}
+
+// NewPayRentalFeeInstructionBuilder creates a new `PayRentalFee` instruction builder
+// for the program.
+func (p *Program) NewPayRentalFeeInstructionBuilder() *PayRentalFee {
 	nd := &PayRentalFee{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 10),
+		program:          p,
 	}
 	nd.AccountMetaSlice[7] = ag_solanago.Meta(Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"])
 	nd.AccountMetaSlice[8] = ag_solanago.Meta(Addresses["11111111111111111111111111111111"])
@@ -45,6 +57,10 @@ func NewPayRentalFeeInstructionBuilder() *PayRentalFee {
 	return nd
 }
 
+func (inst *PayRentalFee) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetAmount sets the "amount" parameter.
 func (inst *PayRentalFee) SetAmount(amount uint64) *PayRentalFee {
 	inst.Amount = &amount
@@ -57,28 +73,20 @@ func (inst *PayRentalFee) SetSupernodeAccount(supernode ag_solanago.PublicKey) *
 	return inst
 }
 
-func (inst *PayRentalFee) findFindSupernodeAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernode returns the seeds of the Supernode account address, without the bump.
+func (inst *PayRentalFee) seedsSupernode() (seeds [][]byte) {
 	// const: supernode
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeAddressWithBumpSeed calculates Supernode account address with given seeds and a known bump seed.
 func (inst *PayRentalFee) FindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernode(), bumpSeed, inst.program.ID())
 }
 
 func (inst *PayRentalFee) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(bumpSeed)
+	pda, err := inst.FindSupernodeAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -87,12 +95,11 @@ func (inst *PayRentalFee) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (
 
 // FindSupernodeAddress finds Supernode account address with given seeds.
 func (inst *PayRentalFee) FindSupernodeAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernode(), inst.program.ID())
 }
 
 func (inst *PayRentalFee) MustFindSupernodeAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(0)
+	pda, _, err := inst.FindSupernodeAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -110,28 +117,20 @@ func (inst *PayRentalFee) SetSupernodeRentalAccountAccount(supernodeRentalAccoun
 	return inst
 }
 
-func (inst *PayRentalFee) findFindSupernodeRentalAccountAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernodeRentalAccount returns the seeds of the SupernodeRentalAccount account address, without the bump.
+func (inst *PayRentalFee) seedsSupernodeRentalAccount() (seeds [][]byte) {
 	// const: supernode_rental_account
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65), byte(0x5f), byte(0x72), byte(0x65), byte(0x6e), byte(0x74), byte(0x61), byte(0x6c), byte(0x5f), byte(0x61), byte(0x63), byte(0x63), byte(0x6f), byte(0x75), byte(0x6e), byte(0x74)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeRentalAccountAddressWithBumpSeed calculates SupernodeRentalAccount account address with given seeds and a known bump seed.
 func (inst *PayRentalFee) FindSupernodeRentalAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeRentalAccountAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernodeRentalAccount(), bumpSeed, inst.program.ID())
 }
 
 func (inst *PayRentalFee) MustFindSupernodeRentalAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeRentalAccountAddress(bumpSeed)
+	pda, err := inst.FindSupernodeRentalAccountAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -140,12 +139,11 @@ func (inst *PayRentalFee) MustFindSupernodeRentalAccountAddressWithBumpSeed(bump
 
 // FindSupernodeRentalAccountAddress finds SupernodeRentalAccount account address with given seeds.
 func (inst *PayRentalFee) FindSupernodeRentalAccountAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeRentalAccountAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernodeRentalAccount(), inst.program.ID())
 }
 
 func (inst *PayRentalFee) MustFindSupernodeRentalAccountAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeRentalAccountAddress(0)
+	pda, _, err := inst.FindSupernodeRentalAccountAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -174,30 +172,22 @@ func (inst *PayRentalFee) SetTenantInfoAccount(tenantInfo ag_solanago.PublicKey)
 	return inst
 }
 
-func (inst *PayRentalFee) findFindTenantInfoAddress(tenant ag_solanago.PublicKey, knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsTenantInfo returns the seeds of the TenantInfo account address, without the bump.
+func (inst *PayRentalFee) seedsTenantInfo(tenant ag_solanago.PublicKey) (seeds [][]byte) {
 	// const: tenant_info
 	seeds = append(seeds, []byte{byte(0x74), byte(0x65), byte(0x6e), byte(0x61), byte(0x6e), byte(0x74), byte(0x5f), byte(0x69), byte(0x6e), byte(0x66), byte(0x6f)})
 	// path: tenant
 	seeds = append(seeds, tenant.Bytes())
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindTenantInfoAddressWithBumpSeed calculates TenantInfo account address with given seeds and a known bump seed.
 func (inst *PayRentalFee) FindTenantInfoAddressWithBumpSeed(tenant ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindTenantInfoAddress(tenant, bumpSeed)
-	return
+	return createProgramAddress(inst.seedsTenantInfo(tenant), bumpSeed, inst.program.ID())
 }
 
 func (inst *PayRentalFee) MustFindTenantInfoAddressWithBumpSeed(tenant ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindTenantInfoAddress(tenant, bumpSeed)
+	pda, err := inst.FindTenantInfoAddressWithBumpSeed(tenant, bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -206,12 +196,11 @@ func (inst *PayRentalFee) MustFindTenantInfoAddressWithBumpSeed(tenant ag_solana
 
 // FindTenantInfoAddress finds TenantInfo account address with given seeds.
 func (inst *PayRentalFee) FindTenantInfoAddress(tenant ag_solanago.PublicKey) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindTenantInfoAddress(tenant, 0)
-	return
+	return findProgramAddress(inst.seedsTenantInfo(tenant), inst.program.ID())
 }
 
 func (inst *PayRentalFee) MustFindTenantInfoAddress(tenant ag_solanago.PublicKey) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindTenantInfoAddress(tenant, 0)
+	pda, _, err := inst.FindTenantInfoAddress(tenant)
 	if err != nil {
 		panic(err)
 	}
@@ -293,7 +282,7 @@ func (inst PayRentalFee) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_PayRentalFee,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -350,8 +339,52 @@ func (inst *PayRentalFee) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *PayRentalFee) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	{
+		pda, _, err := inst.FindSupernodeAddress()
+		errs = append(errs, checkAccount("pay_rental_fee", "supernode", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("pay_rental_fee", "supernode_rental_account", accounts[1], false, true))
+	{
+		pda, _, err := inst.FindSupernodeRentalAccountAddress()
+		errs = append(errs, checkAccount("pay_rental_fee", "supernode_rental_account", accounts[1].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("pay_rental_fee", "tenant_token_account", accounts[2], false, true))
+	{
+		ata, err := associatedTokenAddress(accounts[5].PublicKey, accounts[4].PublicKey)
+		errs = append(errs, checkAccount("pay_rental_fee", "tenant_token_account", accounts[2].PublicKey, ata, err, ErrNotAssociatedTokenAccount))
+	}
+	errs = append(errs, checkFlags("pay_rental_fee", "tenant_info", accounts[3], false, true))
+	{
+		pda, _, err := inst.FindTenantInfoAddress(accounts[5].PublicKey)
+		errs = append(errs, checkAccount("pay_rental_fee", "tenant_info", accounts[3].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("pay_rental_fee", "tenant", accounts[5], true, true))
+	errs = append(errs, checkFlags("pay_rental_fee", "admin", accounts[6], true, false))
+	errs = append(errs, checkAccount("pay_rental_fee", "token_program", accounts[7].PublicKey, Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("pay_rental_fee", "system_program", accounts[8].PublicKey, Addresses["11111111111111111111111111111111"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("pay_rental_fee", "associated_token_program", accounts[9].PublicKey, Addresses["ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"], nil, ErrProgramMismatch))
+	if *inst.Amount == 0 {
+		errs = append(errs, &ArgumentError{Instruction: "pay_rental_fee", Argument: "amount", Err: ErrZeroArgument})
+	}
+	return errors.Join(errs...)
+}
+
 func (inst *PayRentalFee) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("PayRentalFee")).
@@ -397,7 +430,8 @@ func (obj *PayRentalFee) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err e
 	return nil
 }
 
-// NewPayRentalFeeInstruction declares a new PayRentalFee instruction with the provided parameters and accounts.
+// NewPayRentalFeeInstruction declares a new PayRentalFee instruction of the default
+// program with the provided parameters and accounts.
 func NewPayRentalFeeInstruction(
 	// Parameters:
 	amount uint64,
@@ -412,7 +446,26 @@ func NewPayRentalFeeInstruction(
 	tokenProgram ag_solanago.PublicKey,
 	systemProgram ag_solanago.PublicKey,
 	associatedTokenProgram ag_solanago.PublicKey) *PayRentalFee {
-	return NewPayRentalFeeInstructionBuilder().
+	return (*Program)(nil).NewPayRentalFeeInstruction(amount, supernode, supernodeRentalAccount, tenantTokenAccount, tenantInfo, token, tenant, admin, tokenProgram, systemProgram, associatedTokenProgram)
+}
+
+// NewPayRentalFeeInstruction declares a new PayRentalFee instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewPayRentalFeeInstruction(
+	// Parameters:
+	amount uint64,
+	// Accounts:
+	supernode ag_solanago.PublicKey,
+	supernodeRentalAccount ag_solanago.PublicKey,
+	tenantTokenAccount ag_solanago.PublicKey,
+	tenantInfo ag_solanago.PublicKey,
+	token ag_solanago.PublicKey,
+	tenant ag_solanago.PublicKey,
+	admin ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	associatedTokenProgram ag_solanago.PublicKey) *PayRentalFee {
+	return p.NewPayRentalFeeInstructionBuilder().
 		SetAmount(amount).
 		SetSupernodeAccount(supernode).
 		SetSupernodeRentalAccountAccount(supernodeRentalAccount).
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"container/list"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+)
+
+// maxCachedPDAs bounds the PDAs cached, as per-provider and per-tenant seeds
+// make the set of derived addresses grow with the users a process serves.
+const maxCachedPDAs = 4096
+
+// pdaCache memoizes program derived addresses by program ID and seeds, so
+// builders do not repeat the bump search of FindProgramAddress.
+var pdaCache = newPDACache(maxCachedPDAs)
+
+type pdaEntry struct {
+	key  string
+	pda  ag_solanago.PublicKey
+	bump uint8
+}
+
+// pdaLRU holds up to max entries, evicting the least recently used.
+type pdaLRU struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // of pdaEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+func newPDACache(max int) *pdaLRU {
+	return &pdaLRU{max: max, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *pdaLRU) load(key string) (pdaEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return pdaEntry{}, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(pdaEntry), true
+}
+
+func (c *pdaLRU) store(e pdaEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[e.key]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[e.key] = c.order.PushFront(e)
+	if c.order.Len() > c.max {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(pdaEntry).key)
+	}
+}
+
+// pdaKey identifies seeds under programID. Every seed is length-prefixed so
+// different splits of the same bytes do not collide.
+func pdaKey(seeds [][]byte, programID ag_solanago.PublicKey) string {
+	key := append([]byte(nil), programID[:]...)
+	for _, seed := range seeds {
+		key = binary.AppendUvarint(key, uint64(len(seed)))
+		key = append(key, seed...)
+	}
+	return string(key)
+}
+
+// findProgramAddress is ag_solanago.FindProgramAddress with the result
+// cached per program ID, up to maxCachedPDAs results.
+func findProgramAddress(seeds [][]byte, programID ag_solanago.PublicKey) (ag_solanago.PublicKey, uint8, error) {
+	key := pdaKey(seeds, programID)
+	if e, ok := pdaCache.load(key); ok {
+		return e.pda, e.bump, nil
+	}
+	pda, bump, err := ag_solanago.FindProgramAddress(seeds, programID)
+	if err != nil {
+		return ag_solanago.PublicKey{}, 0, err
+	}
+	pdaCache.store(pdaEntry{key, pda, bump})
+	return pda, bump, nil
+}
+
+// createProgramAddress derives the address of seeds with a known bump. The
+// bump is checked with ag_solanago.CreateProgramAddress, which fails when
+// the derived address is on the ed25519 curve; a bump matching the cached
+// canonical one returns the cached address.
+func createProgramAddress(seeds [][]byte, bump uint8, programID ag_solanago.PublicKey) (ag_solanago.PublicKey, error) {
+	if e, ok := pdaCache.load(pdaKey(seeds, programID)); ok && e.bump == bump {
+		return e.pda, nil
+	}
+	withBump := append(append([][]byte(nil), seeds...), []byte{bump})
+	pda, err := ag_solanago.CreateProgramAddress(withBump, programID)
+	if err != nil {
+		return ag_solanago.PublicKey{}, fmt.Errorf("bump seed %d: %w", bump, err)
+	}
+	return pda, nil
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_require "github.com/stretchr/testify/require"
+)
+
+var testProgramID = ag_solanago.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy")
+
+// builders returns one of every instruction builder of p.
+func builders(p *Program) []any {
+	return []any{
+		p.NewAddExtraControllerInstructionBuilder(),
+		p.NewClaimRentalFeeInstructionBuilder(),
+		p.NewClaimRewardInstructionBuilder(),
+		p.NewInitRewardAccountInstructionBuilder(),
+		p.NewInitializeInstructionBuilder(),
+		p.NewPayRentalFeeInstructionBuilder(),
+		p.NewReleasableInstructionBuilder(),
+		p.NewReleaseInstructionBuilder(),
+		p.NewRemoveExtraControllerInstructionBuilder(),
+		p.NewReplaceExtraControllerInstructionBuilder(),
+		p.NewStakeDeviceInstructionBuilder(),
+		p.NewUnstakeDeviceInstructionBuilder(),
+		p.NewUpdateKValueInstructionBuilder(),
+		p.NewUpdateRewardLockTimeInstructionBuilder(),
+		p.NewUpdateStakingCoefficientInstructionBuilder(),
+		p.NewWithdrawRentalFeeInstructionBuilder(),
+	}
+}
+
+var publicKeyType = reflect.TypeOf(ag_solanago.PublicKey{})
+
+type derivation struct {
+	builder string
+	pda     ag_solanago.PublicKey
+	bump    uint8
+}
+
+// derivePDAs calls every Find<Account>Address method of the builders, with
+// the same key for the n-th path account of every account, and groups the
+// results by account.
+func derivePDAs(t *testing.T, p *Program) map[string][]derivation {
+	paths := []ag_solanago.PublicKey{
+		ag_solanago.MustPublicKeyFromBase58("9R8bHte4xYauxEm3pGrAzniWcfKMuCRcbGzqrDvf4zzV"),
+		ag_solanago.MustPublicKeyFromBase58("4vSVTKJtE1Fmr5z9HgDuh4d7yw93PUQXXVUDp23vXtG5"),
+	}
+	out := map[string][]derivation{}
+	for _, b := range builders(p) {
+		v := reflect.ValueOf(b)
+		name := v.Elem().Type().Name()
+		for i := 0; i < v.NumMethod(); i++ {
+			m := v.Type().Method(i)
+			account, ok := strings.CutPrefix(m.Name, "Find")
+			if !ok || !strings.HasSuffix(account, "Address") {
+				continue
+			}
+			account = strings.TrimSuffix(account, "Address")
+			var args []reflect.Value
+			for j := 1; j < m.Type.NumIn(); j++ {
+				ag_require.Equal(t, publicKeyType, m.Type.In(j), "%s.%s", name, m.Name)
+				args = append(args, reflect.ValueOf(paths[j-1]))
+			}
+			res := v.Method(i).Call(args)
+			ag_require.Nil(t, res[2].Interface(), "%s.%s", name, m.Name)
+			out[account] = append(out[account], derivation{
+				builder: name,
+				pda:     res[0].Interface().(ag_solanago.PublicKey),
+				bump:    uint8(res[1].Uint()),
+			})
+
+			withBump := v.MethodByName(m.Name + "WithBumpSeed")
+			ag_require.True(t, withBump.IsValid(), "%s.%sWithBumpSeed", name, m.Name)
+			res = withBump.Call(append(args, reflect.ValueOf(uint8(res[1].Uint()))))
+			ag_require.Nil(t, res[1].Interface(), "%s.%sWithBumpSeed", name, m.Name)
+			ag_require.Equal(t, out[account][len(out[account])-1].pda, res[0].Interface(), "%s.%sWithBumpSeed", name, m.Name)
+		}
+	}
+	return out
+}
+
+// TestPDAConsistency checks that every builder deriving an account derives
+// the same address: a seed differing in one builder sends its instruction
+// to another account than the program expects.
+func TestPDAConsistency(t *testing.T) {
+	p := NewProgram(testProgramID)
+	derived := derivePDAs(t, p)
+	ag_require.Contains(t, derived, "SupernodeStakeAccount")
+	for account, ds := range derived {
+		for _, d := range ds[1:] {
+			ag_require.Equal(t, ds[0].pda, d.pda, "%s: %s and %s disagree", account, ds[0].builder, d.builder)
+		}
+	}
+	releaseStake, _, err := p.NewReleaseInstructionBuilder().FindSupernodeStakeAccountAddress()
+	ag_require.NoError(t, err)
+	want, _, err := ag_solanago.FindProgramAddress([][]byte{[]byte("supernode_stake_account")}, testProgramID)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, want, releaseStake)
+}
+
+func TestFindAddressWithBumpSeed(t *testing.T) {
+	b := NewProgram(testProgramID).NewInitializeInstructionBuilder()
+	pda, bump, err := b.FindSupernodeAddress()
+	ag_require.NoError(t, err)
+	want, wantBump, err := ag_solanago.FindProgramAddress([][]byte{[]byte("supernode")}, testProgramID)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, want, pda)
+	ag_require.Equal(t, wantBump, bump)
+
+	got, err := b.FindSupernodeAddressWithBumpSeed(bump)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, pda, got)
+	ag_require.Equal(t, pda, b.MustFindSupernodeAddressWithBumpSeed(bump))
+
+	// Lower bumps derive other addresses, or fail when the address is on
+	// the curve; none of them is the canonical address.
+	var failed bool
+	for other := int(bump) - 1; other >= 0; other-- {
+		got, err := b.FindSupernodeAddressWithBumpSeed(uint8(other))
+		if err != nil {
+			failed = true
+			continue
+		}
+		ag_require.NotEqual(t, pda, got)
+		want, err := ag_solanago.CreateProgramAddress([][]byte{[]byte("supernode"), {uint8(other)}}, testProgramID)
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, want, got)
+	}
+	ag_require.True(t, failed, "no bump below %d derives an on-curve address", bump)
+	ag_require.Panics(t, func() {
+		for other := 255; other >= 0; other-- {
+			b.MustFindSupernodeAddressWithBumpSeed(uint8(other))
+		}
+	})
+
+	// The cache is per program ID.
+	other := ag_solanago.NewWallet().PublicKey()
+	otherPDA, _, err := NewProgram(other).NewInitializeInstructionBuilder().FindSupernodeAddress()
+	ag_require.NoError(t, err)
+	want, _, err = ag_solanago.FindProgramAddress([][]byte{[]byte("supernode")}, other)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, want, otherPDA)
+	ag_require.NotEqual(t, pda, otherPDA)
+}
+
+func TestPDAKey(t *testing.T) {
+	ag_require.NotEqual(t,
+		pdaKey([][]byte{[]byte("ab"), []byte("c")}, testProgramID),
+		pdaKey([][]byte{[]byte("a"), []byte("bc")}, testProgramID))
+}
+
+func TestPDACache(t *testing.T) {
+	c := newPDACache(2)
+	key := func(i int) string { return pdaKey([][]byte{{byte(i)}}, testProgramID) }
+	for i := 0; i < 3; i++ {
+		c.store(pdaEntry{key: key(i), bump: uint8(i)})
+		// Keep the first entry the most recently used.
+		_, ok := c.load(key(0))
+		ag_require.True(t, ok)
+	}
+	ag_require.Equal(t, 2, c.order.Len())
+	ag_require.Len(t, c.entries, 2)
+	_, ok := c.load(key(1))
+	ag_require.False(t, ok, "least recently used entry kept")
+	e, ok := c.load(key(2))
+	ag_require.True(t, ok)
+	ag_require.Equal(t, uint8(2), e.bump)
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_rpc "github.com/gagliardetto/solana-go/rpc"
+)
+
+// Program is a deployment of the supernode program. It carries the program
+// ID its builders derive PDAs under and its decoders match, so one process
+// can work with several deployments:
+//
+//	devnet := client.NewProgram(devnetID)
+//	ix := devnet.NewStakeDeviceInstructionBuilder().SetDeviceId(1) // ...
+//	instructions, err := devnet.DecodeInstructions(&tx.Message)
+//
+// The package-level builders and decoders use the default program, the nil
+// *Program, which follows ProgramID and SetProgramID.
+type Program struct {
+	id               ag_solanago.PublicKey
+	getAddressTables AddressTablesGetter
+}
+
+// NewProgram returns the program deployed at id.
+func NewProgram(id ag_solanago.PublicKey) *Program {
+	return &Program{id: id}
+}
+
+// ID returns the program ID, ProgramID for the default program.
+func (p *Program) ID() ag_solanago.PublicKey {
+	if p == nil {
+		return ProgramID
+	}
+	return p.id
+}
+
+// WithAddressTablesGetter returns a copy of p resolving the lookups of v0
+// messages through getter instead of the getter installed with
+// SetAddressTablesGetter. A copy of the default program keeps the current
+// ProgramID.
+func (p *Program) WithAddressTablesGetter(getter AddressTablesGetter) *Program {
+	return &Program{id: p.ID(), getAddressTables: getter}
+}
+
+func (p *Program) addressTables() AddressTablesGetter {
+	if p == nil {
+		return nil
+	}
+	return p.getAddressTables
+}
+
+// FindAddress derives the PDA of seeds under the program, cached like the
+// Find<Account>Address methods of the builders.
+func (p *Program) FindAddress(seeds ...[]byte) (ag_solanago.PublicKey, uint8, error) {
+	return findProgramAddress(seeds, p.ID())
+}
+
+// Register installs the instruction decoder of p in solana-go's registry,
+// so ag_solanago.DecodeInstruction and the tree encoders of transactions
+// decode its instructions. The registry holds one decoder per program ID:
+// the first program registered for an ID keeps it.
+func (p *Program) Register() {
+	ag_solanago.RegisterInstructionDecoder(p.ID(), func(accounts []*ag_solanago.AccountMeta, data []byte) (interface{}, error) {
+		return p.DecodeInstruction(accounts, data)
+	})
+}
+
+// DecodeInstruction decodes the instruction data with its accounts. The
+// instruction builds, derives its PDAs and validates against p.
+func (p *Program) DecodeInstruction(accounts []*ag_solanago.AccountMeta, data []byte) (*Instruction, error) {
+	inst, err := decodeInstruction(accounts, data)
+	if err != nil {
+		return nil, err
+	}
+	inst.setProgram(p)
+	return inst, nil
+}
+
+// DecodeInstructions decodes the instructions of p in message. Lookups of v0
+// messages without address tables are resolved through the getter of p,
+// without setting the tables on message.
+func (p *Program) DecodeInstructions(message *ag_solanago.Message) (instructions []*Instruction, err error) {
+	if message, err = withAddressTables(message, p.addressTables()); err != nil {
+		return
+	}
+	id := p.ID()
+	for _, ins := range message.Instructions {
+		var programID ag_solanago.PublicKey
+		if programID, err = message.Program(ins.ProgramIDIndex); err != nil {
+			return
+		}
+		if !programID.Equals(id) {
+			continue
+		}
+		var accounts []*ag_solanago.AccountMeta
+		if accounts, err = ins.ResolveInstructionAccounts(message); err != nil {
+			return
+		}
+		var insDecoded *Instruction
+		if insDecoded, err = p.DecodeInstruction(accounts, ins.Data); err != nil {
+			return
+		}
+		instructions = append(instructions, insDecoded)
+	}
+	return
+}
+
+// DecodeEvents decodes the events p emitted in txData. Lookups of v0
+// transactions are resolved through the getter of p.
+func (p *Program) DecodeEvents(txData *ag_rpc.GetTransactionResult) ([]*Event, error) {
+	return DecodeEvents(txData, p.ID(), p.addressTables())
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"sync"
+	"testing"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_treeout "github.com/gagliardetto/treeout"
+	ag_require "github.com/stretchr/testify/require"
+)
+
+var otherProgramID = ag_solanago.MustPublicKeyFromBase58("B8YWYgxzsxGDuua6qsXZAvxL3huy5qy9AtL6AEmAVCCM")
+
+// TestProgram_TwoDeployments builds, derives and decodes for two programs
+// side by side, without touching ProgramID.
+func TestProgram_TwoDeployments(t *testing.T) {
+	saved := ProgramID
+	programs := []*Program{NewProgram(testProgramID), NewProgram(otherProgramID)}
+	provider, mint := ag_solanago.NewWallet().PublicKey(), ag_solanago.NewWallet().PublicKey()
+
+	var ixs []ag_solanago.Instruction
+	for _, p := range programs {
+		inst := stakeDevice(t, p, provider, mint)
+		want, _, err := ag_solanago.FindProgramAddress([][]byte{[]byte("provider_stake_info"), provider[:]}, p.ID())
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, want, inst.GetProviderStakeInfoAccount().PublicKey)
+		got, _, err := p.FindAddress([]byte("provider_stake_info"), provider[:])
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, want, got)
+
+		ix := inst.Build()
+		ag_require.Equal(t, p.ID(), ix.ProgramID())
+		ixs = append(ixs, ix)
+	}
+	ag_require.NotEqual(t, ixs[0].Accounts()[2].PublicKey, ixs[1].Accounts()[2].PublicKey)
+
+	tx, err := ag_solanago.NewTransaction(ixs, ag_solanago.Hash{}, ag_solanago.TransactionPayer(provider))
+	ag_require.NoError(t, err)
+	for i, p := range programs {
+		decoded, err := p.DecodeInstructions(&tx.Message)
+		ag_require.NoError(t, err)
+		ag_require.Len(t, decoded, 1)
+		ag_require.Equal(t, p.ID(), decoded[0].ProgramID())
+		ag_require.Equal(t, ixs[i].Accounts()[2].PublicKey, decoded[0].Accounts()[2].PublicKey)
+		// Decoded instructions derive their PDAs under p.
+		ag_require.NoError(t, decoded[0].Impl.(*StakeDevice).ValidateDeep())
+	}
+	ag_require.Equal(t, saved, ProgramID)
+}
+
+func TestProgram_Default(t *testing.T) {
+	var p *Program
+	ag_require.Equal(t, ProgramID, p.ID())
+	ag_require.Equal(t, ProgramID, NewInitializeInstructionBuilder().Build().ProgramID())
+	ag_require.Equal(t,
+		NewInitializeInstructionBuilder().MustFindSupernodeAddress(),
+		p.NewInitializeInstructionBuilder().MustFindSupernodeAddress())
+}
+
+func TestProgram_Register(t *testing.T) {
+	p := NewProgram(ag_solanago.NewWallet().PublicKey())
+	p.Register()
+	p.Register()
+
+	ix := p.NewUpdateRewardLockTimeInstruction(7, p.NewUpdateRewardLockTimeInstructionBuilder().MustFindSupernodeAddress(),
+		ag_solanago.NewWallet().PublicKey(),
+		ag_solanago.TokenProgramID,
+		ag_solanago.SystemProgramID,
+		ag_solanago.SPLAssociatedTokenAccountProgramID,
+	).Build()
+	data, err := ix.Data()
+	ag_require.NoError(t, err)
+	decoded, err := ag_solanago.DecodeInstruction(p.ID(), ix.Accounts(), data)
+	ag_require.NoError(t, err)
+	inst := decoded.(*Instruction)
+	ag_require.Equal(t, p.ID(), inst.ProgramID())
+	ag_require.Equal(t, uint64(7), *inst.Impl.(*UpdateRewardLockTime).New)
+
+	tree := ag_treeout.New("tx")
+	inst.EncodeToTree(tree)
+	ag_require.Contains(t, tree.String(), p.ID().String())
+}
+
+// TestProgram_Concurrent derives with several programs at once; run it with
+// -race.
+func TestProgram_Concurrent(t *testing.T) {
+	programs := []*Program{NewProgram(testProgramID), NewProgram(otherProgramID)}
+	want := make([]ag_solanago.PublicKey, len(programs))
+	for i, p := range programs {
+		want[i], _, _ = ag_solanago.FindProgramAddress([][]byte{[]byte("supernode")}, p.ID())
+	}
+	var wg sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		for i, p := range programs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ag_require.Equal(t, want[i], p.NewStakeDeviceInstructionBuilder().MustFindSupernodeAddress())
+			}()
+		}
+	}
+	wg.Wait()
+}
@@ -1,3 +1,5 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
@@ -25,12 +27,22 @@ type Releasable struct {
 	//
 	// [6] = [] associated_token_program
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewReleasableInstructionBuilder creates a new `Releasable` instruction builder.
+// NewReleasableInstructionBuilder creates a new `Releasable` instruction builder
+// for the default program.
 func NewReleasableInstructionBuilder() *Releasable {
+	return (*Program)(nil).NewReleasableInstructionBuilder()
+}
+
+// NewReleasableInstructionBuilder creates a new `Releasable` instruction builder
+// for the program.
+func (p *Program) NewReleasableInstructionBuilder() *Releasable {
 	nd := &Releasable{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 7),
+		program:          p,
 	}
 	nd.AccountMetaSlice[4] = ag_solanago.Meta(Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"])
 	nd.AccountMetaSlice[5] = ag_solanago.Meta(Addresses["11111111111111111111111111111111"])
@@ -38,36 +50,32 @@ func NewReleasableInstructionBuilder() *Releasable {
 	return nd
 }
 
+func (inst *Releasable) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetProviderVestingInfoAccount sets the "provider_vesting_info" account.
 func (inst *Releasable) SetProviderVestingInfoAccount(providerVestingInfo ag_solanago.PublicKey) *Releasable {
 	inst.AccountMetaSlice[0] = ag_solanago.Meta(providerVestingInfo).WRITE()
 	return inst
 }
 
-func (inst *Releasable) findFindProviderVestingInfoAddress(provider ag_solanago.PublicKey, knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsProviderVestingInfo returns the seeds of the ProviderVestingInfo account address, without the bump.
+func (inst *Releasable) seedsProviderVestingInfo(provider ag_solanago.PublicKey) (seeds [][]byte) {
 	// const: provider_vesting_info
 	seeds = append(seeds, []byte{byte(0x70), byte(0x72), byte(0x6f), byte(0x76), byte(0x69), byte(0x64), byte(0x65), byte(0x72), byte(0x5f), byte(0x76), byte(0x65), byte(0x73), byte(0x74), byte(0x69), byte(0x6e), byte(0x67), byte(0x5f), byte(0x69), byte(0x6e), byte(0x66), byte(0x6f)})
 	// path: provider
 	seeds = append(seeds, provider.Bytes())
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindProviderVestingInfoAddressWithBumpSeed calculates ProviderVestingInfo account address with given seeds and a known bump seed.
 func (inst *Releasable) FindProviderVestingInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindProviderVestingInfoAddress(provider, bumpSeed)
-	return
+	return createProgramAddress(inst.seedsProviderVestingInfo(provider), bumpSeed, inst.program.ID())
 }
 
 func (inst *Releasable) MustFindProviderVestingInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderVestingInfoAddress(provider, bumpSeed)
+	pda, err := inst.FindProviderVestingInfoAddressWithBumpSeed(provider, bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -76,12 +84,11 @@ func (inst *Releasable) MustFindProviderVestingInfoAddressWithBumpSeed(provider
 
 // FindProviderVestingInfoAddress finds ProviderVestingInfo account address with given seeds.
 func (inst *Releasable) FindProviderVestingInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindProviderVestingInfoAddress(provider, 0)
-	return
+	return findProgramAddress(inst.seedsProviderVestingInfo(provider), inst.program.ID())
 }
 
 func (inst *Releasable) MustFindProviderVestingInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderVestingInfoAddress(provider, 0)
+	pda, _, err := inst.FindProviderVestingInfoAddress(provider)
 	if err != nil {
 		panic(err)
 	}
@@ -99,30 +106,22 @@ func (inst *Releasable) SetProviderStakeInfoAccount(providerStakeInfo ag_solanag
 	return inst
 }
 
-func (inst *Releasable) findFindProviderStakeInfoAddress(provider ag_solanago.PublicKey, knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsProviderStakeInfo returns the seeds of the ProviderStakeInfo account address, without the bump.
+func (inst *Releasable) seedsProviderStakeInfo(provider ag_solanago.PublicKey) (seeds [][]byte) {
 	// const: provider_stake_info
 	seeds = append(seeds, []byte{byte(0x70), byte(0x72), byte(0x6f), byte(0x76), byte(0x69), byte(0x64), byte(0x65), byte(0x72), byte(0x5f), byte(0x73), byte(0x74), byte(0x61), byte(0x6b), byte(0x65), byte(0x5f), byte(0x69), byte(0x6e), byte(0x66), byte(0x6f)})
 	// path: provider
 	seeds = append(seeds, provider.Bytes())
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindProviderStakeInfoAddressWithBumpSeed calculates ProviderStakeInfo account address with given seeds and a known bump seed.
 func (inst *Releasable) FindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
-	return
+	return createProgramAddress(inst.seedsProviderStakeInfo(provider), bumpSeed, inst.program.ID())
 }
 
 func (inst *Releasable) MustFindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
+	pda, err := inst.FindProviderStakeInfoAddressWithBumpSeed(provider, bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -131,12 +130,11 @@ func (inst *Releasable) MustFindProviderStakeInfoAddressWithBumpSeed(provider ag
 
 // FindProviderStakeInfoAddress finds ProviderStakeInfo account address with given seeds.
 func (inst *Releasable) FindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindProviderStakeInfoAddress(provider, 0)
-	return
+	return findProgramAddress(inst.seedsProviderStakeInfo(provider), inst.program.ID())
 }
 
 func (inst *Releasable) MustFindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, 0)
+	pda, _, err := inst.FindProviderStakeInfoAddress(provider)
 	if err != nil {
 		panic(err)
 	}
@@ -207,7 +205,7 @@ func (inst Releasable) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_Releasable,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -248,8 +246,38 @@ func (inst *Releasable) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *Releasable) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	errs = append(errs, checkFlags("releasable", "provider_vesting_info", accounts[0], false, true))
+	{
+		pda, _, err := inst.FindProviderVestingInfoAddress(accounts[2].PublicKey)
+		errs = append(errs, checkAccount("releasable", "provider_vesting_info", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	{
+		pda, _, err := inst.FindProviderStakeInfoAddress(accounts[2].PublicKey)
+		errs = append(errs, checkAccount("releasable", "provider_stake_info", accounts[1].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("releasable", "controller", accounts[3], true, true))
+	errs = append(errs, checkAccount("releasable", "token_program", accounts[4].PublicKey, Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("releasable", "system_program", accounts[5].PublicKey, Addresses["11111111111111111111111111111111"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("releasable", "associated_token_program", accounts[6].PublicKey, Addresses["ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"], nil, ErrProgramMismatch))
+	return errors.Join(errs...)
+}
+
 func (inst *Releasable) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("Releasable")).
@@ -280,7 +308,8 @@ func (obj *Releasable) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err err
 	return nil
 }
 
-// NewReleasableInstruction declares a new Releasable instruction with the provided parameters and accounts.
+// NewReleasableInstruction declares a new Releasable instruction of the default
+// program with the provided parameters and accounts.
 func NewReleasableInstruction(
 	// Accounts:
 	providerVestingInfo ag_solanago.PublicKey,
@@ -290,7 +319,21 @@ func NewReleasableInstruction(
 	tokenProgram ag_solanago.PublicKey,
 	systemProgram ag_solanago.PublicKey,
 	associatedTokenProgram ag_solanago.PublicKey) *Releasable {
-	return NewReleasableInstructionBuilder().
+	return (*Program)(nil).NewReleasableInstruction(providerVestingInfo, providerStakeInfo, provider, controller, tokenProgram, systemProgram, associatedTokenProgram)
+}
+
+// NewReleasableInstruction declares a new Releasable instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewReleasableInstruction(
+	// Accounts:
+	providerVestingInfo ag_solanago.PublicKey,
+	providerStakeInfo ag_solanago.PublicKey,
+	provider ag_solanago.PublicKey,
+	controller ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	associatedTokenProgram ag_solanago.PublicKey) *Releasable {
+	return p.NewReleasableInstructionBuilder().
 		SetProviderVestingInfoAccount(providerVestingInfo).
 		SetProviderStakeInfoAccount(providerStakeInfo).
 		SetProviderAccount(provider).
@@ -1,3 +1,5 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
@@ -35,12 +37,22 @@ type Release struct {
 	//
 	// [11] = [] associated_token_program
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewReleaseInstructionBuilder creates a new `Release` instruction builder.
+// NewReleaseInstructionBuilder creates a new `Release` instruction builder
+// for the default program.
 func NewReleaseInstructionBuilder() *Release {
+	return (*Program)(nil).NewReleaseInstructionBuilder()
+}
+
+// NewReleaseInstructionBuilder creates a new `Release` instruction builder
+// for the program.
+func (p *Program) NewReleaseInstructionBuilder() *Release {
 	nd := &Release{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 12),
+		program:          p,
 	}
 	nd.AccountMetaSlice[9] = ag_solanago.Meta(Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"])
 	nd.AccountMetaSlice[10] = ag_solanago.Meta(Addresses["11111111111111111111111111111111"])
@@ -48,34 +60,30 @@ func NewReleaseInstructionBuilder() *Release {
 	return nd
 }
 
+func (inst *Release) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetSupernodeAccount sets the "supernode" account.
 func (inst *Release) SetSupernodeAccount(supernode ag_solanago.PublicKey) *Release {
 	inst.AccountMetaSlice[0] = ag_solanago.Meta(supernode)
 	return inst
 }
 
-func (inst *Release) findFindSupernodeAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernode returns the seeds of the Supernode account address, without the bump.
+func (inst *Release) seedsSupernode() (seeds [][]byte) {
 	// const: supernode
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeAddressWithBumpSeed calculates Supernode account address with given seeds and a known bump seed.
 func (inst *Release) FindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernode(), bumpSeed, inst.program.ID())
 }
 
 func (inst *Release) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(bumpSeed)
+	pda, err := inst.FindSupernodeAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -84,12 +92,11 @@ func (inst *Release) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda a
 
 // FindSupernodeAddress finds Supernode account address with given seeds.
 func (inst *Release) FindSupernodeAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernode(), inst.program.ID())
 }
 
 func (inst *Release) MustFindSupernodeAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(0)
+	pda, _, err := inst.FindSupernodeAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -107,28 +114,20 @@ func (inst *Release) SetSupernodeStakeAccountAccount(supernodeStakeAccount ag_so
 	return inst
 }
 
-func (inst *Release) findFindSupernodeStakeAccountAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
-	// const: supernode_token_account
-	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65), byte(0x5f), byte(0x74), byte(0x6f), byte(0x6b), byte(0x65), byte(0x6e), byte(0x5f), byte(0x61), byte(0x63), byte(0x63), byte(0x6f), byte(0x75), byte(0x6e), byte(0x74)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
+// seedsSupernodeStakeAccount returns the seeds of the SupernodeStakeAccount account address, without the bump.
+func (inst *Release) seedsSupernodeStakeAccount() (seeds [][]byte) {
+	// const: supernode_stake_account
+	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65), byte(0x5f), byte(0x73), byte(0x74), byte(0x61), byte(0x6b), byte(0x65), byte(0x5f), byte(0x61), byte(0x63), byte(0x63), byte(0x6f), byte(0x75), byte(0x6e), byte(0x74)})
 	return
 }
 
 // FindSupernodeStakeAccountAddressWithBumpSeed calculates SupernodeStakeAccount account address with given seeds and a known bump seed.
 func (inst *Release) FindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeStakeAccountAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernodeStakeAccount(), bumpSeed, inst.program.ID())
 }
 
 func (inst *Release) MustFindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeStakeAccountAddress(bumpSeed)
+	pda, err := inst.FindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -137,12 +136,11 @@ func (inst *Release) MustFindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed u
 
 // FindSupernodeStakeAccountAddress finds SupernodeStakeAccount account address with given seeds.
 func (inst *Release) FindSupernodeStakeAccountAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeStakeAccountAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernodeStakeAccount(), inst.program.ID())
 }
 
 func (inst *Release) MustFindSupernodeStakeAccountAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeStakeAccountAddress(0)
+	pda, _, err := inst.FindSupernodeStakeAccountAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -160,30 +158,22 @@ func (inst *Release) SetProviderStakeInfoAccount(providerStakeInfo ag_solanago.P
 	return inst
 }
 
-func (inst *Release) findFindProviderStakeInfoAddress(provider ag_solanago.PublicKey, knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsProviderStakeInfo returns the seeds of the ProviderStakeInfo account address, without the bump.
+func (inst *Release) seedsProviderStakeInfo(provider ag_solanago.PublicKey) (seeds [][]byte) {
 	// const: provider_stake_info
 	seeds = append(seeds, []byte{byte(0x70), byte(0x72), byte(0x6f), byte(0x76), byte(0x69), byte(0x64), byte(0x65), byte(0x72), byte(0x5f), byte(0x73), byte(0x74), byte(0x61), byte(0x6b), byte(0x65), byte(0x5f), byte(0x69), byte(0x6e), byte(0x66), byte(0x6f)})
 	// path: provider
 	seeds = append(seeds, provider.Bytes())
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindProviderStakeInfoAddressWithBumpSeed calculates ProviderStakeInfo account address with given seeds and a known bump seed.
 func (inst *Release) FindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
-	return
+	return createProgramAddress(inst.seedsProviderStakeInfo(provider), bumpSeed, inst.program.ID())
 }
 
 func (inst *Release) MustFindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
+	pda, err := inst.FindProviderStakeInfoAddressWithBumpSeed(provider, bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -192,12 +182,11 @@ func (inst *Release) MustFindProviderStakeInfoAddressWithBumpSeed(provider ag_so
 
 // FindProviderStakeInfoAddress finds ProviderStakeInfo account address with given seeds.
 func (inst *Release) FindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindProviderStakeInfoAddress(provider, 0)
-	return
+	return findProgramAddress(inst.seedsProviderStakeInfo(provider), inst.program.ID())
 }
 
 func (inst *Release) MustFindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, 0)
+	pda, _, err := inst.FindProviderStakeInfoAddress(provider)
 	if err != nil {
 		panic(err)
 	}
@@ -215,30 +204,22 @@ func (inst *Release) SetProviderVestingInfoAccount(providerVestingInfo ag_solana
 	return inst
 }
 
-func (inst *Release) findFindProviderVestingInfoAddress(provider ag_solanago.PublicKey, knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsProviderVestingInfo returns the seeds of the ProviderVestingInfo account address, without the bump.
+func (inst *Release) seedsProviderVestingInfo(provider ag_solanago.PublicKey) (seeds [][]byte) {
 	// const: provider_vesting_info
 	seeds = append(seeds, []byte{byte(0x70), byte(0x72), byte(0x6f), byte(0x76), byte(0x69), byte(0x64), byte(0x65), byte(0x72), byte(0x5f), byte(0x76), byte(0x65), byte(0x73), byte(0x74), byte(0x69), byte(0x6e), byte(0x67), byte(0x5f), byte(0x69), byte(0x6e), byte(0x66), byte(0x6f)})
 	// path: provider
 	seeds = append(seeds, provider.Bytes())
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindProviderVestingInfoAddressWithBumpSeed calculates ProviderVestingInfo account address with given seeds and a known bump seed.
 func (inst *Release) FindProviderVestingInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindProviderVestingInfoAddress(provider, bumpSeed)
-	return
+	return createProgramAddress(inst.seedsProviderVestingInfo(provider), bumpSeed, inst.program.ID())
 }
 
 func (inst *Release) MustFindProviderVestingInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderVestingInfoAddress(provider, bumpSeed)
+	pda, err := inst.FindProviderVestingInfoAddressWithBumpSeed(provider, bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -247,12 +228,11 @@ func (inst *Release) MustFindProviderVestingInfoAddressWithBumpSeed(provider ag_
 
 // FindProviderVestingInfoAddress finds ProviderVestingInfo account address with given seeds.
 func (inst *Release) FindProviderVestingInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindProviderVestingInfoAddress(provider, 0)
-	return
+	return findProgramAddress(inst.seedsProviderVestingInfo(provider), inst.program.ID())
 }
 
 func (inst *Release) MustFindProviderVestingInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderVestingInfoAddress(provider, 0)
+	pda, _, err := inst.FindProviderVestingInfoAddress(provider)
 	if err != nil {
 		panic(err)
 	}
@@ -356,7 +336,7 @@ func (inst Release) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_Release,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -412,8 +392,53 @@ func (inst *Release) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *Release) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	{
+		pda, _, err := inst.FindSupernodeAddress()
+		errs = append(errs, checkAccount("release", "supernode", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("release", "supernode_stake_account", accounts[1], false, true))
+	{
+		pda, _, err := inst.FindSupernodeStakeAccountAddress()
+		errs = append(errs, checkAccount("release", "supernode_stake_account", accounts[1].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	{
+		pda, _, err := inst.FindProviderStakeInfoAddress(accounts[6].PublicKey)
+		errs = append(errs, checkAccount("release", "provider_stake_info", accounts[2].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("release", "provider_vesting_info", accounts[3], false, true))
+	{
+		pda, _, err := inst.FindProviderVestingInfoAddress(accounts[6].PublicKey)
+		errs = append(errs, checkAccount("release", "provider_vesting_info", accounts[3].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("release", "provider_token_account", accounts[4], false, true))
+	{
+		ata, err := associatedTokenAddress(accounts[6].PublicKey, accounts[5].PublicKey)
+		errs = append(errs, checkAccount("release", "provider_token_account", accounts[4].PublicKey, ata, err, ErrNotAssociatedTokenAccount))
+	}
+	errs = append(errs, checkFlags("release", "controller", accounts[7], true, true))
+	errs = append(errs, checkFlags("release", "admin", accounts[8], true, false))
+	errs = append(errs, checkAccount("release", "token_program", accounts[9].PublicKey, Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("release", "system_program", accounts[10].PublicKey, Addresses["11111111111111111111111111111111"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("release", "associated_token_program", accounts[11].PublicKey, Addresses["ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"], nil, ErrProgramMismatch))
+	return errors.Join(errs...)
+}
+
 func (inst *Release) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("Release")).
@@ -449,7 +474,8 @@ func (obj *Release) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error)
 	return nil
 }
 
-// NewReleaseInstruction declares a new Release instruction with the provided parameters and accounts.
+// NewReleaseInstruction declares a new Release instruction of the default
+// program with the provided parameters and accounts.
 func NewReleaseInstruction(
 	// Accounts:
 	supernode ag_solanago.PublicKey,
@@ -464,7 +490,26 @@ func NewReleaseInstruction(
 	tokenProgram ag_solanago.PublicKey,
 	systemProgram ag_solanago.PublicKey,
 	associatedTokenProgram ag_solanago.PublicKey) *Release {
-	return NewReleaseInstructionBuilder().
+	return (*Program)(nil).NewReleaseInstruction(supernode, supernodeStakeAccount, providerStakeInfo, providerVestingInfo, providerTokenAccount, token, provider, controller, admin, tokenProgram, systemProgram, associatedTokenProgram)
+}
+
+// NewReleaseInstruction declares a new Release instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewReleaseInstruction(
+	// Accounts:
+	supernode ag_solanago.PublicKey,
+	supernodeStakeAccount ag_solanago.PublicKey,
+	providerStakeInfo ag_solanago.PublicKey,
+	providerVestingInfo ag_solanago.PublicKey,
+	providerTokenAccount ag_solanago.PublicKey,
+	token ag_solanago.PublicKey,
+	provider ag_solanago.PublicKey,
+	controller ag_solanago.PublicKey,
+	admin ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	associatedTokenProgram ag_solanago.PublicKey) *Release {
+	return p.NewReleaseInstructionBuilder().
 		SetSupernodeAccount(supernode).
 		SetSupernodeStakeAccountAccount(supernodeStakeAccount).
 		SetProviderStakeInfoAccount(providerStakeInfo).
@@ -1,3 +1,5 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
@@ -25,44 +27,50 @@ type ReplaceExtraController struct {
 	//
 	// [6] = [] new_controller
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewReplaceExtraControllerInstructionBuilder creates a new `ReplaceExtraController` instruction builder.
+// NewReplaceExtraControllerInstructionBuilder creates a new `ReplaceExtraController` instruction builder
+// for the default program.
 func NewReplaceExtraControllerInstructionBuilder() *ReplaceExtraController {
+	return (*Program)(nil).NewReplaceExtraControllerInstructionBuilder()
+}
+
+// NewReplaceExtraControllerInstructionBuilder creates a new `ReplaceExtraController` instruction builder
+// for the program.
+func (p *Program) NewReplaceExtraControllerInstructionBuilder() *ReplaceExtraController {
 	nd := &ReplaceExtraController{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 7),
+		program:          p,
 	}
 	return nd
 }
 
+func (inst *ReplaceExtraController) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetSupernodeAccount sets the "supernode" account.
 func (inst *ReplaceExtraController) SetSupernodeAccount(supernode ag_solanago.PublicKey) *ReplaceExtraController {
 	inst.AccountMetaSlice[0] = ag_solanago.Meta(supernode)
 	return inst
 }
 
-func (inst *ReplaceExtraController) findFindSupernodeAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernode returns the seeds of the Supernode account address, without the bump.
+func (inst *ReplaceExtraController) seedsSupernode() (seeds [][]byte) {
 	// const: supernode
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeAddressWithBumpSeed calculates Supernode account address with given seeds and a known bump seed.
 func (inst *ReplaceExtraController) FindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernode(), bumpSeed, inst.program.ID())
 }
 
 func (inst *ReplaceExtraController) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(bumpSeed)
+	pda, err := inst.FindSupernodeAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -71,12 +79,11 @@ func (inst *ReplaceExtraController) MustFindSupernodeAddressWithBumpSeed(bumpSee
 
 // FindSupernodeAddress finds Supernode account address with given seeds.
 func (inst *ReplaceExtraController) FindSupernodeAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernode(), inst.program.ID())
 }
 
 func (inst *ReplaceExtraController) MustFindSupernodeAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(0)
+	pda, _, err := inst.FindSupernodeAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -94,30 +101,22 @@ func (inst *ReplaceExtraController) SetProviderStakeInfoAccount(providerStakeInf
 	return inst
 }
 
-func (inst *ReplaceExtraController) findFindProviderStakeInfoAddress(provider ag_solanago.PublicKey, knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsProviderStakeInfo returns the seeds of the ProviderStakeInfo account address, without the bump.
+func (inst *ReplaceExtraController) seedsProviderStakeInfo(provider ag_solanago.PublicKey) (seeds [][]byte) {
 	// const: provider_stake_info
 	seeds = append(seeds, []byte{byte(0x70), byte(0x72), byte(0x6f), byte(0x76), byte(0x69), byte(0x64), byte(0x65), byte(0x72), byte(0x5f), byte(0x73), byte(0x74), byte(0x61), byte(0x6b), byte(0x65), byte(0x5f), byte(0x69), byte(0x6e), byte(0x66), byte(0x6f)})
 	// path: provider
 	seeds = append(seeds, provider.Bytes())
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindProviderStakeInfoAddressWithBumpSeed calculates ProviderStakeInfo account address with given seeds and a known bump seed.
 func (inst *ReplaceExtraController) FindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
-	return
+	return createProgramAddress(inst.seedsProviderStakeInfo(provider), bumpSeed, inst.program.ID())
 }
 
 func (inst *ReplaceExtraController) MustFindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
+	pda, err := inst.FindProviderStakeInfoAddressWithBumpSeed(provider, bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -126,12 +125,11 @@ func (inst *ReplaceExtraController) MustFindProviderStakeInfoAddressWithBumpSeed
 
 // FindProviderStakeInfoAddress finds ProviderStakeInfo account address with given seeds.
 func (inst *ReplaceExtraController) FindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindProviderStakeInfoAddress(provider, 0)
-	return
+	return findProgramAddress(inst.seedsProviderStakeInfo(provider), inst.program.ID())
 }
 
 func (inst *ReplaceExtraController) MustFindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, 0)
+	pda, _, err := inst.FindProviderStakeInfoAddress(provider)
 	if err != nil {
 		panic(err)
 	}
@@ -202,7 +200,7 @@ func (inst ReplaceExtraController) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_ReplaceExtraController,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -243,8 +241,36 @@ func (inst *ReplaceExtraController) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *ReplaceExtraController) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	{
+		pda, _, err := inst.FindSupernodeAddress()
+		errs = append(errs, checkAccount("replace_extra_controller", "supernode", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("replace_extra_controller", "provider_stake_info", accounts[1], false, true))
+	{
+		pda, _, err := inst.FindProviderStakeInfoAddress(accounts[2].PublicKey)
+		errs = append(errs, checkAccount("replace_extra_controller", "provider_stake_info", accounts[1].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("replace_extra_controller", "operator", accounts[3], true, false))
+	errs = append(errs, checkFlags("replace_extra_controller", "admin", accounts[5], true, false))
+	return errors.Join(errs...)
+}
+
 func (inst *ReplaceExtraController) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("ReplaceExtraController")).
@@ -275,7 +301,8 @@ func (obj *ReplaceExtraController) UnmarshalWithDecoder(decoder *ag_binary.Decod
 	return nil
 }
 
-// NewReplaceExtraControllerInstruction declares a new ReplaceExtraController instruction with the provided parameters and accounts.
+// NewReplaceExtraControllerInstruction declares a new ReplaceExtraController instruction of the default
+// program with the provided parameters and accounts.
 func NewReplaceExtraControllerInstruction(
 	// Accounts:
 	supernode ag_solanago.PublicKey,
@@ -285,7 +312,21 @@ func NewReplaceExtraControllerInstruction(
 	oldController ag_solanago.PublicKey,
 	admin ag_solanago.PublicKey,
 	newController ag_solanago.PublicKey) *ReplaceExtraController {
-	return NewReplaceExtraControllerInstructionBuilder().
+	return (*Program)(nil).NewReplaceExtraControllerInstruction(supernode, providerStakeInfo, provider, operator, oldController, admin, newController)
+}
+
+// NewReplaceExtraControllerInstruction declares a new ReplaceExtraController instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewReplaceExtraControllerInstruction(
+	// Accounts:
+	supernode ag_solanago.PublicKey,
+	providerStakeInfo ag_solanago.PublicKey,
+	provider ag_solanago.PublicKey,
+	operator ag_solanago.PublicKey,
+	oldController ag_solanago.PublicKey,
+	admin ag_solanago.PublicKey,
+	newController ag_solanago.PublicKey) *ReplaceExtraController {
+	return p.NewReplaceExtraControllerInstructionBuilder().
 		SetSupernodeAccount(supernode).
 		SetProviderStakeInfoAccount(providerStakeInfo).
 		SetProviderAccount(provider).
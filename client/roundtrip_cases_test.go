@@ -0,0 +1,41 @@
+// Code generated by roundtrip_gen.go. DO NOT EDIT.
+
+package client
+
+var roundTripCases = []roundTripCase{
+	{name: "ClaimRentalFeeEvent", new: func() roundTripper { return new(ClaimRentalFeeEvent) }, discriminator: nil},
+	{name: "ClaimRentalFeeEventEventData", new: func() roundTripper { return new(ClaimRentalFeeEventEventData) }, discriminator: ClaimRentalFeeEventEventDataDiscriminator[:]},
+	{name: "DeviceKValueUpdated", new: func() roundTripper { return new(DeviceKValueUpdated) }, discriminator: nil},
+	{name: "DeviceKValueUpdatedEventData", new: func() roundTripper { return new(DeviceKValueUpdatedEventData) }, discriminator: DeviceKValueUpdatedEventDataDiscriminator[:]},
+	{name: "DeviceStakedEvent", new: func() roundTripper { return new(DeviceStakedEvent) }, discriminator: nil},
+	{name: "DeviceStakedEventEventData", new: func() roundTripper { return new(DeviceStakedEventEventData) }, discriminator: DeviceStakedEventEventDataDiscriminator[:]},
+	{name: "DeviceState", new: func() roundTripper { return new(DeviceState) }, discriminator: nil},
+	{name: "DeviceUnstakeEvent", new: func() roundTripper { return new(DeviceUnstakeEvent) }, discriminator: nil},
+	{name: "DeviceUnstakeEventEventData", new: func() roundTripper { return new(DeviceUnstakeEventEventData) }, discriminator: DeviceUnstakeEventEventDataDiscriminator[:]},
+	{name: "PayRentalEvent", new: func() roundTripper { return new(PayRentalEvent) }, discriminator: nil},
+	{name: "PayRentalEventEventData", new: func() roundTripper { return new(PayRentalEventEventData) }, discriminator: PayRentalEventEventDataDiscriminator[:]},
+	{name: "Policy", new: func() roundTripper { return new(Policy) }, discriminator: nil},
+	{name: "ProviderControllerChangedEvent", new: func() roundTripper { return new(ProviderControllerChangedEvent) }, discriminator: nil},
+	{name: "ProviderControllerChangedEventEventData", new: func() roundTripper { return new(ProviderControllerChangedEventEventData) }, discriminator: ProviderControllerChangedEventEventDataDiscriminator[:]},
+	{name: "ProviderStakeInfo", new: func() roundTripper { return new(ProviderStakeInfo) }, discriminator: nil},
+	{name: "ProviderStakeInfoAccount", new: func() roundTripper { return new(ProviderStakeInfoAccount) }, discriminator: ProviderStakeInfoAccountDiscriminator[:]},
+	{name: "ProviderVestingInfo", new: func() roundTripper { return new(ProviderVestingInfo) }, discriminator: nil},
+	{name: "ProviderVestingInfoAccount", new: func() roundTripper { return new(ProviderVestingInfoAccount) }, discriminator: ProviderVestingInfoAccountDiscriminator[:]},
+	{name: "RewardClaimedEvent", new: func() roundTripper { return new(RewardClaimedEvent) }, discriminator: nil},
+	{name: "RewardClaimedEventEventData", new: func() roundTripper { return new(RewardClaimedEventEventData) }, discriminator: RewardClaimedEventEventDataDiscriminator[:]},
+	{name: "RewardLockedTimeUpdated", new: func() roundTripper { return new(RewardLockedTimeUpdated) }, discriminator: nil},
+	{name: "RewardLockedTimeUpdatedEventData", new: func() roundTripper { return new(RewardLockedTimeUpdatedEventData) }, discriminator: RewardLockedTimeUpdatedEventDataDiscriminator[:]},
+	{name: "Schedule", new: func() roundTripper { return new(Schedule) }, discriminator: nil},
+	{name: "StakingCoefficientUpdated", new: func() roundTripper { return new(StakingCoefficientUpdated) }, discriminator: nil},
+	{name: "StakingCoefficientUpdatedEventData", new: func() roundTripper { return new(StakingCoefficientUpdatedEventData) }, discriminator: StakingCoefficientUpdatedEventDataDiscriminator[:]},
+	{name: "SupernodeState", new: func() roundTripper { return new(SupernodeState) }, discriminator: nil},
+	{name: "SupernodeStateAccount", new: func() roundTripper { return new(SupernodeStateAccount) }, discriminator: SupernodeStateAccountDiscriminator[:]},
+	{name: "TenantInfo", new: func() roundTripper { return new(TenantInfo) }, discriminator: nil},
+	{name: "TenantInfoAccount", new: func() roundTripper { return new(TenantInfoAccount) }, discriminator: TenantInfoAccountDiscriminator[:]},
+	{name: "TokenReleasedEvent", new: func() roundTripper { return new(TokenReleasedEvent) }, discriminator: nil},
+	{name: "TokenReleasedEventEventData", new: func() roundTripper { return new(TokenReleasedEventEventData) }, discriminator: TokenReleasedEventEventDataDiscriminator[:]},
+	{name: "VestingScheduledEvent", new: func() roundTripper { return new(VestingScheduledEvent) }, discriminator: nil},
+	{name: "VestingScheduledEventEventData", new: func() roundTripper { return new(VestingScheduledEventEventData) }, discriminator: VestingScheduledEventEventDataDiscriminator[:]},
+	{name: "WithdrawEvent", new: func() roundTripper { return new(WithdrawEvent) }, discriminator: nil},
+	{name: "WithdrawEventEventData", new: func() roundTripper { return new(WithdrawEventEventData) }, discriminator: WithdrawEventEventDataDiscriminator[:]},
+}
@@ -0,0 +1,90 @@
+//go:build ignore
+
+// roundtrip_gen writes roundtrip_cases_test.go: one round-trip property case
+// per Borsh-serializable account, type and event declared in accounts.go,
+// types.go and events.go, with its discriminator when it has one.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+)
+
+var sources = []string{"accounts.go", "types.go", "events.go"}
+
+const output = "roundtrip_cases_test.go"
+
+func main() {
+	fset := token.NewFileSet()
+	marshalers := map[string]bool{}
+	unmarshalers := map[string]bool{}
+	discriminators := map[string]bool{}
+	for _, name := range sources {
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil || len(decl.Recv.List) != 1 {
+					continue
+				}
+				switch recv := decl.Recv.List[0].Type.(type) {
+				case *ast.Ident:
+					if decl.Name.Name == "MarshalWithEncoder" {
+						marshalers[recv.Name] = true
+					}
+				case *ast.StarExpr:
+					if ident, ok := recv.X.(*ast.Ident); ok && decl.Name.Name == "UnmarshalWithDecoder" {
+						unmarshalers[ident.Name] = true
+					}
+				}
+			case *ast.GenDecl:
+				if decl.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					for _, ident := range spec.(*ast.ValueSpec).Names {
+						discriminators[ident.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	var types []string
+	for name := range marshalers {
+		if unmarshalers[name] {
+			types = append(types, name)
+		}
+	}
+	sort.Strings(types)
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "// Code generated by roundtrip_gen.go. DO NOT EDIT.\n\npackage client\n\n")
+	fmt.Fprintf(buf, "var roundTripCases = []roundTripCase{\n")
+	for _, name := range types {
+		discriminator := "nil"
+		if discriminators[name+"Discriminator"] {
+			discriminator = name + "Discriminator[:]"
+		}
+		fmt.Fprintf(buf, "\t{name: %q, new: func() roundTripper { return new(%s) }, discriminator: %s},\n", name, name, discriminator)
+	}
+	fmt.Fprintf(buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
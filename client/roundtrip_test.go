@@ -0,0 +1,173 @@
+package client
+
+//go:generate go run roundtrip_gen.go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	ag_binary "github.com/gagliardetto/binary"
+	ag_gofuzz "github.com/gagliardetto/gofuzz"
+	ag_require "github.com/stretchr/testify/require"
+)
+
+// roundTripper is implemented by every Borsh-serializable account, type and
+// event; roundtrip_gen.go lists them in roundTripCases.
+type roundTripper interface {
+	MarshalWithEncoder(*ag_binary.Encoder) error
+	UnmarshalWithDecoder(*ag_binary.Decoder) error
+}
+
+type roundTripCase struct {
+	name          string
+	new           func() roundTripper
+	discriminator []byte
+}
+
+func roundTripIterations() int {
+	if testing.Short() {
+		return 200
+	}
+	return 2000
+}
+
+func marshal(v roundTripper) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := v.MarshalWithEncoder(ag_binary.NewBorshEncoder(buf))
+	return buf.Bytes(), err
+}
+
+// unmarshal decodes data into a fresh value, turning a panic into an error.
+func unmarshal(c roundTripCase, data []byte) (v roundTripper, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			v = nil
+		}
+	}()
+	v = c.new()
+	return v, v.UnmarshalWithDecoder(ag_binary.NewBorshDecoder(data))
+}
+
+func TestRoundTrip_Identity(t *testing.T) {
+	for i, c := range roundTripCases {
+		t.Run(c.name, func(t *testing.T) {
+			fu := ag_gofuzz.NewWithSeed(int64(i)).NilChance(0)
+			for n := 0; n < roundTripIterations(); n++ {
+				want := c.new()
+				fu.Fuzz(want)
+				data, err := marshal(want)
+				ag_require.NoError(t, err)
+				if c.discriminator != nil {
+					ag_require.Equal(t, c.discriminator, data[:8])
+				}
+
+				got, err := unmarshal(c, data)
+				ag_require.NoError(t, err)
+				ag_require.Equal(t, want, got)
+				again, err := marshal(got)
+				ag_require.NoError(t, err)
+				ag_require.Equal(t, data, again)
+			}
+		})
+	}
+}
+
+func TestRoundTrip_JSON(t *testing.T) {
+	for i, c := range roundTripCases {
+		t.Run(c.name, func(t *testing.T) {
+			fu := ag_gofuzz.NewWithSeed(int64(i)).NilChance(0)
+			for n := 0; n < roundTripIterations(); n++ {
+				want := c.new()
+				fu.Fuzz(want)
+				var data []byte
+				var err error
+				if v, ok := want.(AmountsMarshaler); ok {
+					data, err = json.Marshal(WithAmountDecimals(v, 9))
+				} else {
+					data, err = json.Marshal(want)
+				}
+				ag_require.NoError(t, err)
+				got := c.new()
+				ag_require.NoError(t, json.Unmarshal(data, got), "%s", data)
+				ag_require.Equal(t, want, got)
+			}
+		})
+	}
+}
+
+func TestRoundTrip_RejectsDiscriminator(t *testing.T) {
+	for i, c := range roundTripCases {
+		if c.discriminator == nil {
+			continue
+		}
+		t.Run(c.name, func(t *testing.T) {
+			fu := ag_gofuzz.NewWithSeed(int64(i)).NilChance(0)
+			rng := rand.New(rand.NewSource(int64(i)))
+			for n := 0; n < roundTripIterations(); n++ {
+				v := c.new()
+				fu.Fuzz(v)
+				data, err := marshal(v)
+				ag_require.NoError(t, err)
+				data[rng.Intn(8)] ^= byte(1 + rng.Intn(255))
+				_, err = unmarshal(c, data)
+				ag_require.Error(t, err)
+				ag_require.Contains(t, err.Error(), "wrong discriminator")
+			}
+
+			// Every other account or event is rejected too.
+			for _, other := range roundTripCases {
+				if other.discriminator == nil || other.name == c.name {
+					continue
+				}
+				v := other.new()
+				fu.Fuzz(v)
+				data, err := marshal(v)
+				ag_require.NoError(t, err)
+				_, err = unmarshal(c, data)
+				ag_require.Error(t, err, "%s decoded as %s", other.name, c.name)
+			}
+		})
+	}
+}
+
+func TestRoundTrip_Truncated(t *testing.T) {
+	for i, c := range roundTripCases {
+		t.Run(c.name, func(t *testing.T) {
+			fu := ag_gofuzz.NewWithSeed(int64(i)).NilChance(0)
+			for n := 0; n < roundTripIterations()/10; n++ {
+				v := c.new()
+				fu.Fuzz(v)
+				data, err := marshal(v)
+				ag_require.NoError(t, err)
+				for size := 0; size < len(data); size++ {
+					_, err := unmarshal(c, data[:size])
+					ag_require.Error(t, err, "decoded %d of %d bytes", size, len(data))
+					ag_require.NotContains(t, err.Error(), "panic")
+				}
+			}
+		})
+	}
+}
+
+func TestRoundTrip_Garbage(t *testing.T) {
+	for i, c := range roundTripCases {
+		t.Run(c.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(int64(i)))
+			for n := 0; n < roundTripIterations(); n++ {
+				data := make([]byte, rng.Intn(256))
+				rng.Read(data)
+				if c.discriminator != nil && rng.Intn(2) == 0 && len(data) >= 8 {
+					copy(data, c.discriminator)
+				}
+				_, err := unmarshal(c, data)
+				if err != nil {
+					ag_require.NotContains(t, err.Error(), "panic")
+				}
+			}
+		})
+	}
+}
@@ -1,3 +1,5 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
@@ -35,12 +37,22 @@ type StakeDevice struct {
 	//
 	// [10] = [] associated_token_program
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewStakeDeviceInstructionBuilder creates a new `StakeDevice` instruction builder.
+// NewStakeDeviceInstructionBuilder creates a new `StakeDevice` instruction builder
+// for the default program.
 func NewStakeDeviceInstructionBuilder() *StakeDevice {
+	return (*Program)(nil).NewStakeDeviceInstructionBuilder()
+}
+
+// NewStakeDeviceInstructionBuilder creates a new `StakeDevice` instruction builder
+// for the program.
+func (p *Program) NewStakeDeviceInstructionBuilder() *StakeDevice {
 	nd := &StakeDevice{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 11),
+		program:          p,
 	}
 	nd.AccountMetaSlice[8] = ag_solanago.Meta(Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"])
 	nd.AccountMetaSlice[9] = ag_solanago.Meta(Addresses["11111111111111111111111111111111"])
@@ -48,6 +60,10 @@ func NewStakeDeviceInstructionBuilder() *StakeDevice {
 	return nd
 }
 
+func (inst *StakeDevice) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetDeviceId sets the "device_id" parameter.
 func (inst *StakeDevice) SetDeviceId(device_id uint64) *StakeDevice {
 	inst.DeviceId = &device_id
@@ -66,28 +82,20 @@ func (inst *StakeDevice) SetSupernodeAccount(supernode ag_solanago.PublicKey) *S
 	return inst
 }
 
-func (inst *StakeDevice) findFindSupernodeAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernode returns the seeds of the Supernode account address, without the bump.
+func (inst *StakeDevice) seedsSupernode() (seeds [][]byte) {
 	// const: supernode
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeAddressWithBumpSeed calculates Supernode account address with given seeds and a known bump seed.
 func (inst *StakeDevice) FindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernode(), bumpSeed, inst.program.ID())
 }
 
 func (inst *StakeDevice) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(bumpSeed)
+	pda, err := inst.FindSupernodeAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -96,12 +104,11 @@ func (inst *StakeDevice) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (p
 
 // FindSupernodeAddress finds Supernode account address with given seeds.
 func (inst *StakeDevice) FindSupernodeAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernode(), inst.program.ID())
 }
 
 func (inst *StakeDevice) MustFindSupernodeAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(0)
+	pda, _, err := inst.FindSupernodeAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -119,28 +126,20 @@ func (inst *StakeDevice) SetSupernodeStakeAccountAccount(supernodeStakeAccount a
 	return inst
 }
 
-func (inst *StakeDevice) findFindSupernodeStakeAccountAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernodeStakeAccount returns the seeds of the SupernodeStakeAccount account address, without the bump.
+func (inst *StakeDevice) seedsSupernodeStakeAccount() (seeds [][]byte) {
 	// const: supernode_stake_account
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65), byte(0x5f), byte(0x73), byte(0x74), byte(0x61), byte(0x6b), byte(0x65), byte(0x5f), byte(0x61), byte(0x63), byte(0x63), byte(0x6f), byte(0x75), byte(0x6e), byte(0x74)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeStakeAccountAddressWithBumpSeed calculates SupernodeStakeAccount account address with given seeds and a known bump seed.
 func (inst *StakeDevice) FindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeStakeAccountAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernodeStakeAccount(), bumpSeed, inst.program.ID())
 }
 
 func (inst *StakeDevice) MustFindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeStakeAccountAddress(bumpSeed)
+	pda, err := inst.FindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -149,12 +148,11 @@ func (inst *StakeDevice) MustFindSupernodeStakeAccountAddressWithBumpSeed(bumpSe
 
 // FindSupernodeStakeAccountAddress finds SupernodeStakeAccount account address with given seeds.
 func (inst *StakeDevice) FindSupernodeStakeAccountAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeStakeAccountAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernodeStakeAccount(), inst.program.ID())
 }
 
 func (inst *StakeDevice) MustFindSupernodeStakeAccountAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeStakeAccountAddress(0)
+	pda, _, err := inst.FindSupernodeStakeAccountAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -172,30 +170,22 @@ func (inst *StakeDevice) SetProviderStakeInfoAccount(providerStakeInfo ag_solana
 	return inst
 }
 
-func (inst *StakeDevice) findFindProviderStakeInfoAddress(provider ag_solanago.PublicKey, knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsProviderStakeInfo returns the seeds of the ProviderStakeInfo account address, without the bump.
+func (inst *StakeDevice) seedsProviderStakeInfo(provider ag_solanago.PublicKey) (seeds [][]byte) {
 	// const: provider_stake_info
 	seeds = append(seeds, []byte{byte(0x70), byte(0x72), byte(0x6f), byte(0x76), byte(0x69), byte(0x64), byte(0x65), byte(0x72), byte(0x5f), byte(0x73), byte(0x74), byte(0x61), byte(0x6b), byte(0x65), byte(0x5f), byte(0x69), byte(0x6e), byte(0x66), byte(0x6f)})
 	// path: provider
 	seeds = append(seeds, provider.Bytes())
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindProviderStakeInfoAddressWithBumpSeed calculates ProviderStakeInfo account address with given seeds and a known bump seed.
 func (inst *StakeDevice) FindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
-	return
+	return createProgramAddress(inst.seedsProviderStakeInfo(provider), bumpSeed, inst.program.ID())
 }
 
 func (inst *StakeDevice) MustFindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
+	pda, err := inst.FindProviderStakeInfoAddressWithBumpSeed(provider, bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -204,12 +194,11 @@ func (inst *StakeDevice) MustFindProviderStakeInfoAddressWithBumpSeed(provider a
 
 // FindProviderStakeInfoAddress finds ProviderStakeInfo account address with given seeds.
 func (inst *StakeDevice) FindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindProviderStakeInfoAddress(provider, 0)
-	return
+	return findProgramAddress(inst.seedsProviderStakeInfo(provider), inst.program.ID())
 }
 
 func (inst *StakeDevice) MustFindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, 0)
+	pda, _, err := inst.FindProviderStakeInfoAddress(provider)
 	if err != nil {
 		panic(err)
 	}
@@ -313,7 +302,7 @@ func (inst StakeDevice) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_StakeDevice,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -376,8 +365,49 @@ func (inst *StakeDevice) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *StakeDevice) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	{
+		pda, _, err := inst.FindSupernodeAddress()
+		errs = append(errs, checkAccount("stake_device", "supernode", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("stake_device", "supernode_stake_account", accounts[1], false, true))
+	{
+		pda, _, err := inst.FindSupernodeStakeAccountAddress()
+		errs = append(errs, checkAccount("stake_device", "supernode_stake_account", accounts[1].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("stake_device", "provider_stake_info", accounts[2], false, true))
+	{
+		pda, _, err := inst.FindProviderStakeInfoAddress(accounts[5].PublicKey)
+		errs = append(errs, checkAccount("stake_device", "provider_stake_info", accounts[2].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("stake_device", "provider_token_account", accounts[3], false, true))
+	{
+		ata, err := associatedTokenAddress(accounts[5].PublicKey, accounts[4].PublicKey)
+		errs = append(errs, checkAccount("stake_device", "provider_token_account", accounts[3].PublicKey, ata, err, ErrNotAssociatedTokenAccount))
+	}
+	errs = append(errs, checkFlags("stake_device", "controller", accounts[6], true, true))
+	errs = append(errs, checkFlags("stake_device", "admin", accounts[7], true, false))
+	errs = append(errs, checkAccount("stake_device", "token_program", accounts[8].PublicKey, Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("stake_device", "system_program", accounts[9].PublicKey, Addresses["11111111111111111111111111111111"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("stake_device", "associated_token_program", accounts[10].PublicKey, Addresses["ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"], nil, ErrProgramMismatch))
+	return errors.Join(errs...)
+}
+
 func (inst *StakeDevice) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("StakeDevice")).
@@ -435,7 +465,8 @@ func (obj *StakeDevice) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err er
 	return nil
 }
 
-// NewStakeDeviceInstruction declares a new StakeDevice instruction with the provided parameters and accounts.
+// NewStakeDeviceInstruction declares a new StakeDevice instruction of the default
+// program with the provided parameters and accounts.
 func NewStakeDeviceInstruction(
 	// Parameters:
 	device_id uint64,
@@ -452,7 +483,28 @@ func NewStakeDeviceInstruction(
 	tokenProgram ag_solanago.PublicKey,
 	systemProgram ag_solanago.PublicKey,
 	associatedTokenProgram ag_solanago.PublicKey) *StakeDevice {
-	return NewStakeDeviceInstructionBuilder().
+	return (*Program)(nil).NewStakeDeviceInstruction(device_id, spec_id, supernode, supernodeStakeAccount, providerStakeInfo, providerTokenAccount, token, provider, controller, admin, tokenProgram, systemProgram, associatedTokenProgram)
+}
+
+// NewStakeDeviceInstruction declares a new StakeDevice instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewStakeDeviceInstruction(
+	// Parameters:
+	device_id uint64,
+	spec_id uint64,
+	// Accounts:
+	supernode ag_solanago.PublicKey,
+	supernodeStakeAccount ag_solanago.PublicKey,
+	providerStakeInfo ag_solanago.PublicKey,
+	providerTokenAccount ag_solanago.PublicKey,
+	token ag_solanago.PublicKey,
+	provider ag_solanago.PublicKey,
+	controller ag_solanago.PublicKey,
+	admin ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	associatedTokenProgram ag_solanago.PublicKey) *StakeDevice {
+	return p.NewStakeDeviceInstructionBuilder().
 		SetDeviceId(device_id).
 		SetSpecId(spec_id).
 		SetSupernodeAccount(supernode).
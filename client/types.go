@@ -1,8 +1,14 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
+	"encoding/json"
 	ag_binary "github.com/gagliardetto/binary"
 	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_text "github.com/gagliardetto/solana-go/text"
+	ag_format "github.com/gagliardetto/solana-go/text/format"
+	ag_treeout "github.com/gagliardetto/treeout"
 )
 
 type ClaimRentalFeeEvent struct {
@@ -32,23 +38,77 @@ func (obj ClaimRentalFeeEvent) MarshalWithEncoder(encoder *ag_binary.Encoder) (e
 
 func (obj *ClaimRentalFeeEvent) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Controller`:
-	err = decoder.Decode(&obj.Controller)
+	err = readPublicKey(decoder, &obj.Controller)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of ClaimRentalFeeEvent under their IDL names. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj ClaimRentalFeeEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of ClaimRentalFeeEvent, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj ClaimRentalFeeEvent) jsonValue(decimals int) interface{} {
+	return struct {
+		Provider   ag_solanago.PublicKey `json:"provider"`
+		Controller ag_solanago.PublicKey `json:"controller"`
+		Amount     jsonUint64            `json:"amount"`
+		AmountUI   *string               `json:"amount_ui,omitempty"`
+	}{
+		Provider:   obj.Provider,
+		Controller: obj.Controller,
+		Amount:     jsonUint64(obj.Amount),
+		AmountUI:   formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *ClaimRentalFeeEvent) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Provider   ag_solanago.PublicKey `json:"provider"`
+		Controller ag_solanago.PublicKey `json:"controller"`
+		Amount     jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Provider = in.Provider
+	obj.Controller = in.Controller
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of ClaimRentalFeeEvent for solana-go's text encoder.
+func (obj ClaimRentalFeeEvent) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj ClaimRentalFeeEvent) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Account("Provider", obj.Provider))
+	parent.Child(ag_format.Account("Controller", obj.Controller))
+	parent.Child(ag_format.Param("Amount", obj.Amount))
+}
+
 type DeviceKValueUpdated struct {
 	SpecId uint16
 	Old    uint64
@@ -82,28 +142,84 @@ func (obj DeviceKValueUpdated) MarshalWithEncoder(encoder *ag_binary.Encoder) (e
 
 func (obj *DeviceKValueUpdated) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `SpecId`:
-	err = decoder.Decode(&obj.SpecId)
+	obj.SpecId, err = decoder.ReadUint16(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Old`:
-	err = decoder.Decode(&obj.Old)
+	obj.Old, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `New`:
-	err = decoder.Decode(&obj.New)
+	obj.New, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Admin`:
-	err = decoder.Decode(&obj.Admin)
+	err = readPublicKey(decoder, &obj.Admin)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of DeviceKValueUpdated under their IDL names. 64-bit integers are strings.
+func (obj DeviceKValueUpdated) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of DeviceKValueUpdated, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj DeviceKValueUpdated) jsonValue(decimals int) interface{} {
+	return struct {
+		SpecId uint16                `json:"spec_id"`
+		Old    jsonUint64            `json:"old"`
+		New    jsonUint64            `json:"new"`
+		Admin  ag_solanago.PublicKey `json:"admin"`
+	}{
+		SpecId: obj.SpecId,
+		Old:    jsonUint64(obj.Old),
+		New:    jsonUint64(obj.New),
+		Admin:  obj.Admin,
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *DeviceKValueUpdated) UnmarshalJSON(data []byte) error {
+	var in struct {
+		SpecId uint16                `json:"spec_id"`
+		Old    jsonUint64            `json:"old"`
+		New    jsonUint64            `json:"new"`
+		Admin  ag_solanago.PublicKey `json:"admin"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.SpecId = in.SpecId
+	obj.Old = uint64(in.Old)
+	obj.New = uint64(in.New)
+	obj.Admin = in.Admin
+	return nil
+}
+
+// TextEncode writes the JSON of DeviceKValueUpdated for solana-go's text encoder.
+func (obj DeviceKValueUpdated) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj DeviceKValueUpdated) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Param("SpecId", obj.SpecId))
+	parent.Child(ag_format.Param("Old", obj.Old))
+	parent.Child(ag_format.Param("New", obj.New))
+	parent.Child(ag_format.Account("Admin", obj.Admin))
+}
+
 type DeviceStakedEvent struct {
 	Provider ag_solanago.PublicKey
 	DeviceId uint64
@@ -137,28 +253,87 @@ func (obj DeviceStakedEvent) MarshalWithEncoder(encoder *ag_binary.Encoder) (err
 
 func (obj *DeviceStakedEvent) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `DeviceId`:
-	err = decoder.Decode(&obj.DeviceId)
+	obj.DeviceId, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `SpecId`:
-	err = decoder.Decode(&obj.SpecId)
+	obj.SpecId, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of DeviceStakedEvent under their IDL names. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj DeviceStakedEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of DeviceStakedEvent, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj DeviceStakedEvent) jsonValue(decimals int) interface{} {
+	return struct {
+		Provider ag_solanago.PublicKey `json:"provider"`
+		DeviceId jsonUint64            `json:"device_id"`
+		SpecId   jsonUint64            `json:"spec_id"`
+		Amount   jsonUint64            `json:"amount"`
+		AmountUI *string               `json:"amount_ui,omitempty"`
+	}{
+		Provider: obj.Provider,
+		DeviceId: jsonUint64(obj.DeviceId),
+		SpecId:   jsonUint64(obj.SpecId),
+		Amount:   jsonUint64(obj.Amount),
+		AmountUI: formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *DeviceStakedEvent) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Provider ag_solanago.PublicKey `json:"provider"`
+		DeviceId jsonUint64            `json:"device_id"`
+		SpecId   jsonUint64            `json:"spec_id"`
+		Amount   jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Provider = in.Provider
+	obj.DeviceId = uint64(in.DeviceId)
+	obj.SpecId = uint64(in.SpecId)
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of DeviceStakedEvent for solana-go's text encoder.
+func (obj DeviceStakedEvent) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj DeviceStakedEvent) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Account("Provider", obj.Provider))
+	parent.Child(ag_format.Param("DeviceId", obj.DeviceId))
+	parent.Child(ag_format.Param("SpecId", obj.SpecId))
+	parent.Child(ag_format.Param("Amount", obj.Amount))
+}
+
 type DeviceState struct {
 	State              uint16
 	SpecId             uint16
@@ -192,28 +367,84 @@ func (obj DeviceState) MarshalWithEncoder(encoder *ag_binary.Encoder) (err error
 
 func (obj *DeviceState) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `State`:
-	err = decoder.Decode(&obj.State)
+	obj.State, err = decoder.ReadUint16(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `SpecId`:
-	err = decoder.Decode(&obj.SpecId)
+	obj.SpecId, err = decoder.ReadUint16(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `StakingCoefficient`:
-	err = decoder.Decode(&obj.StakingCoefficient)
+	obj.StakingCoefficient, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Kvalue`:
-	err = decoder.Decode(&obj.Kvalue)
+	obj.Kvalue, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of DeviceState under their IDL names. 64-bit integers are strings.
+func (obj DeviceState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of DeviceState, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj DeviceState) jsonValue(decimals int) interface{} {
+	return struct {
+		State              uint16     `json:"state"`
+		SpecId             uint16     `json:"spec_id"`
+		StakingCoefficient jsonUint64 `json:"staking_coefficient"`
+		Kvalue             jsonUint64 `json:"kvalue"`
+	}{
+		State:              obj.State,
+		SpecId:             obj.SpecId,
+		StakingCoefficient: jsonUint64(obj.StakingCoefficient),
+		Kvalue:             jsonUint64(obj.Kvalue),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *DeviceState) UnmarshalJSON(data []byte) error {
+	var in struct {
+		State              uint16     `json:"state"`
+		SpecId             uint16     `json:"spec_id"`
+		StakingCoefficient jsonUint64 `json:"staking_coefficient"`
+		Kvalue             jsonUint64 `json:"kvalue"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.State = in.State
+	obj.SpecId = in.SpecId
+	obj.StakingCoefficient = uint64(in.StakingCoefficient)
+	obj.Kvalue = uint64(in.Kvalue)
+	return nil
+}
+
+// TextEncode writes the JSON of DeviceState for solana-go's text encoder.
+func (obj DeviceState) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj DeviceState) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Param("State", obj.State))
+	parent.Child(ag_format.Param("SpecId", obj.SpecId))
+	parent.Child(ag_format.Param("StakingCoefficient", obj.StakingCoefficient))
+	parent.Child(ag_format.Param("Kvalue", obj.Kvalue))
+}
+
 type DeviceUnstakeEvent struct {
 	Provider               ag_solanago.PublicKey
 	DeviceId               uint64
@@ -247,28 +478,87 @@ func (obj DeviceUnstakeEvent) MarshalWithEncoder(encoder *ag_binary.Encoder) (er
 
 func (obj *DeviceUnstakeEvent) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `DeviceId`:
-	err = decoder.Decode(&obj.DeviceId)
+	obj.DeviceId, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `ProviderVestingInfoKey`:
-	err = decoder.Decode(&obj.ProviderVestingInfoKey)
+	err = readPublicKey(decoder, &obj.ProviderVestingInfoKey)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of DeviceUnstakeEvent under their IDL names. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj DeviceUnstakeEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of DeviceUnstakeEvent, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj DeviceUnstakeEvent) jsonValue(decimals int) interface{} {
+	return struct {
+		Provider               ag_solanago.PublicKey `json:"provider"`
+		DeviceId               jsonUint64            `json:"device_id"`
+		Amount                 jsonUint64            `json:"amount"`
+		AmountUI               *string               `json:"amount_ui,omitempty"`
+		ProviderVestingInfoKey ag_solanago.PublicKey `json:"provider_vesting_info_key"`
+	}{
+		Provider:               obj.Provider,
+		DeviceId:               jsonUint64(obj.DeviceId),
+		Amount:                 jsonUint64(obj.Amount),
+		AmountUI:               formatAmount(obj.Amount, decimals),
+		ProviderVestingInfoKey: obj.ProviderVestingInfoKey,
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *DeviceUnstakeEvent) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Provider               ag_solanago.PublicKey `json:"provider"`
+		DeviceId               jsonUint64            `json:"device_id"`
+		Amount                 jsonUint64            `json:"amount"`
+		ProviderVestingInfoKey ag_solanago.PublicKey `json:"provider_vesting_info_key"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Provider = in.Provider
+	obj.DeviceId = uint64(in.DeviceId)
+	obj.Amount = uint64(in.Amount)
+	obj.ProviderVestingInfoKey = in.ProviderVestingInfoKey
+	return nil
+}
+
+// TextEncode writes the JSON of DeviceUnstakeEvent for solana-go's text encoder.
+func (obj DeviceUnstakeEvent) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj DeviceUnstakeEvent) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Account("Provider", obj.Provider))
+	parent.Child(ag_format.Param("DeviceId", obj.DeviceId))
+	parent.Child(ag_format.Param("Amount", obj.Amount))
+	parent.Child(ag_format.Account("ProviderVestingInfoKey", obj.ProviderVestingInfoKey))
+}
+
 type PayRentalEvent struct {
 	Tenant ag_solanago.PublicKey
 	Amount uint64
@@ -290,18 +580,67 @@ func (obj PayRentalEvent) MarshalWithEncoder(encoder *ag_binary.Encoder) (err er
 
 func (obj *PayRentalEvent) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Tenant`:
-	err = decoder.Decode(&obj.Tenant)
+	err = readPublicKey(decoder, &obj.Tenant)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of PayRentalEvent under their IDL names. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj PayRentalEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of PayRentalEvent, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj PayRentalEvent) jsonValue(decimals int) interface{} {
+	return struct {
+		Tenant   ag_solanago.PublicKey `json:"tenant"`
+		Amount   jsonUint64            `json:"amount"`
+		AmountUI *string               `json:"amount_ui,omitempty"`
+	}{
+		Tenant:   obj.Tenant,
+		Amount:   jsonUint64(obj.Amount),
+		AmountUI: formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *PayRentalEvent) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Tenant ag_solanago.PublicKey `json:"tenant"`
+		Amount jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Tenant = in.Tenant
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of PayRentalEvent for solana-go's text encoder.
+func (obj PayRentalEvent) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj PayRentalEvent) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Account("Tenant", obj.Tenant))
+	parent.Child(ag_format.Param("Amount", obj.Amount))
+}
+
 type Policy struct {
 	Decimals           uint8
 	RewardLockedTime   uint64
@@ -335,17 +674,17 @@ func (obj Policy) MarshalWithEncoder(encoder *ag_binary.Encoder) (err error) {
 
 func (obj *Policy) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Decimals`:
-	err = decoder.Decode(&obj.Decimals)
+	obj.Decimals, err = decoder.ReadUint8()
 	if err != nil {
 		return err
 	}
 	// Deserialize `RewardLockedTime`:
-	err = decoder.Decode(&obj.RewardLockedTime)
+	obj.RewardLockedTime, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `StakingCoefficient`:
-	err = decoder.Decode(&obj.StakingCoefficient)
+	obj.StakingCoefficient, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
@@ -357,6 +696,62 @@ func (obj *Policy) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error)
 	return nil
 }
 
+// MarshalJSON encodes the fields of Policy under their IDL names. 64-bit integers are strings.
+func (obj Policy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of Policy, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj Policy) jsonValue(decimals int) interface{} {
+	return struct {
+		Decimals           uint8        `json:"decimals"`
+		RewardLockedTime   jsonUint64   `json:"reward_locked_time"`
+		StakingCoefficient jsonUint64   `json:"staking_coefficient"`
+		KValues            []jsonUint64 `json:"k_values"`
+	}{
+		Decimals:           obj.Decimals,
+		RewardLockedTime:   jsonUint64(obj.RewardLockedTime),
+		StakingCoefficient: jsonUint64(obj.StakingCoefficient),
+		KValues:            convertInts[jsonUint64](obj.KValues),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *Policy) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Decimals           uint8        `json:"decimals"`
+		RewardLockedTime   jsonUint64   `json:"reward_locked_time"`
+		StakingCoefficient jsonUint64   `json:"staking_coefficient"`
+		KValues            []jsonUint64 `json:"k_values"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Decimals = in.Decimals
+	obj.RewardLockedTime = uint64(in.RewardLockedTime)
+	obj.StakingCoefficient = uint64(in.StakingCoefficient)
+	obj.KValues = convertInts[uint64](in.KValues)
+	return nil
+}
+
+// TextEncode writes the JSON of Policy for solana-go's text encoder.
+func (obj Policy) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj Policy) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Param("Decimals", obj.Decimals))
+	parent.Child(ag_format.Param("RewardLockedTime", obj.RewardLockedTime))
+	parent.Child(ag_format.Param("StakingCoefficient", obj.StakingCoefficient))
+	parent.Child(ag_format.Param("KValues", obj.KValues))
+}
+
 type ProviderControllerChangedEvent struct {
 	Provider      ag_solanago.PublicKey
 	Action        string
@@ -396,33 +791,94 @@ func (obj ProviderControllerChangedEvent) MarshalWithEncoder(encoder *ag_binary.
 
 func (obj *ProviderControllerChangedEvent) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Action`:
-	err = decoder.Decode(&obj.Action)
+	obj.Action, err = decoder.ReadString()
 	if err != nil {
 		return err
 	}
 	// Deserialize `NewController`:
-	err = decoder.Decode(&obj.NewController)
+	err = readPublicKey(decoder, &obj.NewController)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Operator`:
-	err = decoder.Decode(&obj.Operator)
+	err = readPublicKey(decoder, &obj.Operator)
 	if err != nil {
 		return err
 	}
 	// Deserialize `OldController`:
-	err = decoder.Decode(&obj.OldController)
+	err = readPublicKey(decoder, &obj.OldController)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of ProviderControllerChangedEvent under their IDL names. 64-bit integers are strings.
+func (obj ProviderControllerChangedEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of ProviderControllerChangedEvent, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj ProviderControllerChangedEvent) jsonValue(decimals int) interface{} {
+	return struct {
+		Provider      ag_solanago.PublicKey `json:"provider"`
+		Action        string                `json:"action"`
+		NewController ag_solanago.PublicKey `json:"new_controller"`
+		Operator      ag_solanago.PublicKey `json:"operator"`
+		OldController ag_solanago.PublicKey `json:"old_controller"`
+	}{
+		Provider:      obj.Provider,
+		Action:        obj.Action,
+		NewController: obj.NewController,
+		Operator:      obj.Operator,
+		OldController: obj.OldController,
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *ProviderControllerChangedEvent) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Provider      ag_solanago.PublicKey `json:"provider"`
+		Action        string                `json:"action"`
+		NewController ag_solanago.PublicKey `json:"new_controller"`
+		Operator      ag_solanago.PublicKey `json:"operator"`
+		OldController ag_solanago.PublicKey `json:"old_controller"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Provider = in.Provider
+	obj.Action = in.Action
+	obj.NewController = in.NewController
+	obj.Operator = in.Operator
+	obj.OldController = in.OldController
+	return nil
+}
+
+// TextEncode writes the JSON of ProviderControllerChangedEvent for solana-go's text encoder.
+func (obj ProviderControllerChangedEvent) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj ProviderControllerChangedEvent) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Account("Provider", obj.Provider))
+	parent.Child(ag_format.Param("Action", obj.Action))
+	parent.Child(ag_format.Account("NewController", obj.NewController))
+	parent.Child(ag_format.Account("Operator", obj.Operator))
+	parent.Child(ag_format.Account("OldController", obj.OldController))
+}
+
 type ProviderStakeInfo struct {
 	ExtraControllers [2]ag_solanago.PublicKey
 	Devices          []DeviceState
@@ -456,6 +912,56 @@ func (obj *ProviderStakeInfo) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (
 	return nil
 }
 
+// MarshalJSON encodes the fields of ProviderStakeInfo under their IDL names. 64-bit integers are strings.
+func (obj ProviderStakeInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of ProviderStakeInfo, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj ProviderStakeInfo) jsonValue(decimals int) interface{} {
+	return struct {
+		ExtraControllers [2]ag_solanago.PublicKey `json:"extra_controllers"`
+		Devices          []interface{}            `json:"devices"`
+	}{
+		ExtraControllers: obj.ExtraControllers,
+		Devices:          jsonValues(obj.Devices, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *ProviderStakeInfo) UnmarshalJSON(data []byte) error {
+	var in struct {
+		ExtraControllers [2]ag_solanago.PublicKey `json:"extra_controllers"`
+		Devices          []DeviceState            `json:"devices"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.ExtraControllers = in.ExtraControllers
+	obj.Devices = in.Devices
+	return nil
+}
+
+// TextEncode writes the JSON of ProviderStakeInfo for solana-go's text encoder.
+func (obj ProviderStakeInfo) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj ProviderStakeInfo) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Param("ExtraControllers", obj.ExtraControllers))
+	parent.Child(listLabel("Devices", len(obj.Devices))).ParentFunc(func(items ag_treeout.Branches) {
+		for i := range obj.Devices {
+			obj.Devices[i].EncodeToTree(items.Child(itemLabel(i)))
+		}
+	})
+}
+
 type ProviderVestingInfo struct {
 	EndIdx         uint8
 	LastReleaseDay uint16
@@ -489,17 +995,17 @@ func (obj ProviderVestingInfo) MarshalWithEncoder(encoder *ag_binary.Encoder) (e
 
 func (obj *ProviderVestingInfo) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `EndIdx`:
-	err = decoder.Decode(&obj.EndIdx)
+	obj.EndIdx, err = decoder.ReadUint8()
 	if err != nil {
 		return err
 	}
 	// Deserialize `LastReleaseDay`:
-	err = decoder.Decode(&obj.LastReleaseDay)
+	obj.LastReleaseDay, err = decoder.ReadUint16(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `ReleasedAmount`:
-	err = decoder.Decode(&obj.ReleasedAmount)
+	obj.ReleasedAmount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
@@ -511,6 +1017,69 @@ func (obj *ProviderVestingInfo) UnmarshalWithDecoder(decoder *ag_binary.Decoder)
 	return nil
 }
 
+// MarshalJSON encodes the fields of ProviderVestingInfo under their IDL names. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj ProviderVestingInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of ProviderVestingInfo, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj ProviderVestingInfo) jsonValue(decimals int) interface{} {
+	return struct {
+		EndIdx           uint8         `json:"end_idx"`
+		LastReleaseDay   uint16        `json:"last_release_day"`
+		ReleasedAmount   jsonUint64    `json:"released_amount"`
+		ReleasedAmountUI *string       `json:"released_amount_ui,omitempty"`
+		Schedules        []interface{} `json:"schedules"`
+	}{
+		EndIdx:           obj.EndIdx,
+		LastReleaseDay:   obj.LastReleaseDay,
+		ReleasedAmount:   jsonUint64(obj.ReleasedAmount),
+		ReleasedAmountUI: formatAmount(obj.ReleasedAmount, decimals),
+		Schedules:        jsonValues(obj.Schedules, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *ProviderVestingInfo) UnmarshalJSON(data []byte) error {
+	var in struct {
+		EndIdx         uint8      `json:"end_idx"`
+		LastReleaseDay uint16     `json:"last_release_day"`
+		ReleasedAmount jsonUint64 `json:"released_amount"`
+		Schedules      []Schedule `json:"schedules"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.EndIdx = in.EndIdx
+	obj.LastReleaseDay = in.LastReleaseDay
+	obj.ReleasedAmount = uint64(in.ReleasedAmount)
+	obj.Schedules = in.Schedules
+	return nil
+}
+
+// TextEncode writes the JSON of ProviderVestingInfo for solana-go's text encoder.
+func (obj ProviderVestingInfo) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj ProviderVestingInfo) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Param("EndIdx", obj.EndIdx))
+	parent.Child(ag_format.Param("LastReleaseDay", obj.LastReleaseDay))
+	parent.Child(ag_format.Param("ReleasedAmount", obj.ReleasedAmount))
+	parent.Child(listLabel("Schedules", len(obj.Schedules))).ParentFunc(func(items ag_treeout.Branches) {
+		for i := range obj.Schedules {
+			obj.Schedules[i].EncodeToTree(items.Child(itemLabel(i)))
+		}
+	})
+}
+
 type RewardClaimedEvent struct {
 	Provider ag_solanago.PublicKey
 	Amount   uint64
@@ -532,18 +1101,67 @@ func (obj RewardClaimedEvent) MarshalWithEncoder(encoder *ag_binary.Encoder) (er
 
 func (obj *RewardClaimedEvent) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of RewardClaimedEvent under their IDL names. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj RewardClaimedEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of RewardClaimedEvent, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj RewardClaimedEvent) jsonValue(decimals int) interface{} {
+	return struct {
+		Provider ag_solanago.PublicKey `json:"provider"`
+		Amount   jsonUint64            `json:"amount"`
+		AmountUI *string               `json:"amount_ui,omitempty"`
+	}{
+		Provider: obj.Provider,
+		Amount:   jsonUint64(obj.Amount),
+		AmountUI: formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *RewardClaimedEvent) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Provider ag_solanago.PublicKey `json:"provider"`
+		Amount   jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Provider = in.Provider
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of RewardClaimedEvent for solana-go's text encoder.
+func (obj RewardClaimedEvent) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj RewardClaimedEvent) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Account("Provider", obj.Provider))
+	parent.Child(ag_format.Param("Amount", obj.Amount))
+}
+
 type RewardLockedTimeUpdated struct {
 	Old   uint64
 	New   uint64
@@ -571,23 +1189,74 @@ func (obj RewardLockedTimeUpdated) MarshalWithEncoder(encoder *ag_binary.Encoder
 
 func (obj *RewardLockedTimeUpdated) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Old`:
-	err = decoder.Decode(&obj.Old)
+	obj.Old, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `New`:
-	err = decoder.Decode(&obj.New)
+	obj.New, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Admin`:
-	err = decoder.Decode(&obj.Admin)
+	err = readPublicKey(decoder, &obj.Admin)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of RewardLockedTimeUpdated under their IDL names. 64-bit integers are strings.
+func (obj RewardLockedTimeUpdated) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of RewardLockedTimeUpdated, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj RewardLockedTimeUpdated) jsonValue(decimals int) interface{} {
+	return struct {
+		Old   jsonUint64            `json:"old"`
+		New   jsonUint64            `json:"new"`
+		Admin ag_solanago.PublicKey `json:"admin"`
+	}{
+		Old:   jsonUint64(obj.Old),
+		New:   jsonUint64(obj.New),
+		Admin: obj.Admin,
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *RewardLockedTimeUpdated) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Old   jsonUint64            `json:"old"`
+		New   jsonUint64            `json:"new"`
+		Admin ag_solanago.PublicKey `json:"admin"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Old = uint64(in.Old)
+	obj.New = uint64(in.New)
+	obj.Admin = in.Admin
+	return nil
+}
+
+// TextEncode writes the JSON of RewardLockedTimeUpdated for solana-go's text encoder.
+func (obj RewardLockedTimeUpdated) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj RewardLockedTimeUpdated) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Param("Old", obj.Old))
+	parent.Child(ag_format.Param("New", obj.New))
+	parent.Child(ag_format.Account("Admin", obj.Admin))
+}
+
 type Schedule struct {
 	Day    uint16
 	Amount uint64
@@ -609,18 +1278,67 @@ func (obj Schedule) MarshalWithEncoder(encoder *ag_binary.Encoder) (err error) {
 
 func (obj *Schedule) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Day`:
-	err = decoder.Decode(&obj.Day)
+	obj.Day, err = decoder.ReadUint16(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of Schedule under their IDL names. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj Schedule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of Schedule, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj Schedule) jsonValue(decimals int) interface{} {
+	return struct {
+		Day      uint16     `json:"day"`
+		Amount   jsonUint64 `json:"amount"`
+		AmountUI *string    `json:"amount_ui,omitempty"`
+	}{
+		Day:      obj.Day,
+		Amount:   jsonUint64(obj.Amount),
+		AmountUI: formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *Schedule) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Day    uint16     `json:"day"`
+		Amount jsonUint64 `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Day = in.Day
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of Schedule for solana-go's text encoder.
+func (obj Schedule) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj Schedule) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Param("Day", obj.Day))
+	parent.Child(ag_format.Param("Amount", obj.Amount))
+}
+
 type StakingCoefficientUpdated struct {
 	Old   uint64
 	New   uint64
@@ -648,23 +1366,74 @@ func (obj StakingCoefficientUpdated) MarshalWithEncoder(encoder *ag_binary.Encod
 
 func (obj *StakingCoefficientUpdated) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Old`:
-	err = decoder.Decode(&obj.Old)
+	obj.Old, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `New`:
-	err = decoder.Decode(&obj.New)
+	obj.New, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Admin`:
-	err = decoder.Decode(&obj.Admin)
+	err = readPublicKey(decoder, &obj.Admin)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of StakingCoefficientUpdated under their IDL names. 64-bit integers are strings.
+func (obj StakingCoefficientUpdated) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of StakingCoefficientUpdated, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj StakingCoefficientUpdated) jsonValue(decimals int) interface{} {
+	return struct {
+		Old   jsonUint64            `json:"old"`
+		New   jsonUint64            `json:"new"`
+		Admin ag_solanago.PublicKey `json:"admin"`
+	}{
+		Old:   jsonUint64(obj.Old),
+		New:   jsonUint64(obj.New),
+		Admin: obj.Admin,
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *StakingCoefficientUpdated) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Old   jsonUint64            `json:"old"`
+		New   jsonUint64            `json:"new"`
+		Admin ag_solanago.PublicKey `json:"admin"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Old = uint64(in.Old)
+	obj.New = uint64(in.New)
+	obj.Admin = in.Admin
+	return nil
+}
+
+// TextEncode writes the JSON of StakingCoefficientUpdated for solana-go's text encoder.
+func (obj StakingCoefficientUpdated) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj StakingCoefficientUpdated) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Param("Old", obj.Old))
+	parent.Child(ag_format.Param("New", obj.New))
+	parent.Child(ag_format.Account("Admin", obj.Admin))
+}
+
 type SupernodeState struct {
 	Admin  ag_solanago.PublicKey
 	Token  ag_solanago.PublicKey
@@ -692,12 +1461,12 @@ func (obj SupernodeState) MarshalWithEncoder(encoder *ag_binary.Encoder) (err er
 
 func (obj *SupernodeState) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Admin`:
-	err = decoder.Decode(&obj.Admin)
+	err = readPublicKey(decoder, &obj.Admin)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Token`:
-	err = decoder.Decode(&obj.Token)
+	err = readPublicKey(decoder, &obj.Token)
 	if err != nil {
 		return err
 	}
@@ -709,6 +1478,57 @@ func (obj *SupernodeState) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err
 	return nil
 }
 
+// MarshalJSON encodes the fields of SupernodeState under their IDL names. 64-bit integers are strings.
+func (obj SupernodeState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of SupernodeState, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj SupernodeState) jsonValue(decimals int) interface{} {
+	return struct {
+		Admin  ag_solanago.PublicKey `json:"admin"`
+		Token  ag_solanago.PublicKey `json:"token"`
+		Policy interface{}           `json:"policy"`
+	}{
+		Admin:  obj.Admin,
+		Token:  obj.Token,
+		Policy: obj.Policy.jsonValue(decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *SupernodeState) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Admin  ag_solanago.PublicKey `json:"admin"`
+		Token  ag_solanago.PublicKey `json:"token"`
+		Policy Policy                `json:"policy"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Admin = in.Admin
+	obj.Token = in.Token
+	obj.Policy = in.Policy
+	return nil
+}
+
+// TextEncode writes the JSON of SupernodeState for solana-go's text encoder.
+func (obj SupernodeState) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj SupernodeState) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Account("Admin", obj.Admin))
+	parent.Child(ag_format.Account("Token", obj.Token))
+	obj.Policy.EncodeToTree(parent.Child("Policy"))
+}
+
 type TenantInfo struct {
 	Funds     uint64
 	Withdrawn uint64
@@ -730,18 +1550,69 @@ func (obj TenantInfo) MarshalWithEncoder(encoder *ag_binary.Encoder) (err error)
 
 func (obj *TenantInfo) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Funds`:
-	err = decoder.Decode(&obj.Funds)
+	obj.Funds, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Withdrawn`:
-	err = decoder.Decode(&obj.Withdrawn)
+	obj.Withdrawn, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of TenantInfo under their IDL names. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj TenantInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of TenantInfo, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj TenantInfo) jsonValue(decimals int) interface{} {
+	return struct {
+		Funds       jsonUint64 `json:"funds"`
+		FundsUI     *string    `json:"funds_ui,omitempty"`
+		Withdrawn   jsonUint64 `json:"withdrawn"`
+		WithdrawnUI *string    `json:"withdrawn_ui,omitempty"`
+	}{
+		Funds:       jsonUint64(obj.Funds),
+		FundsUI:     formatAmount(obj.Funds, decimals),
+		Withdrawn:   jsonUint64(obj.Withdrawn),
+		WithdrawnUI: formatAmount(obj.Withdrawn, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *TenantInfo) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Funds     jsonUint64 `json:"funds"`
+		Withdrawn jsonUint64 `json:"withdrawn"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Funds = uint64(in.Funds)
+	obj.Withdrawn = uint64(in.Withdrawn)
+	return nil
+}
+
+// TextEncode writes the JSON of TenantInfo for solana-go's text encoder.
+func (obj TenantInfo) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj TenantInfo) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Param("Funds", obj.Funds))
+	parent.Child(ag_format.Param("Withdrawn", obj.Withdrawn))
+}
+
 type TokenReleasedEvent struct {
 	Controller ag_solanago.PublicKey
 	Provider   ag_solanago.PublicKey
@@ -769,23 +1640,77 @@ func (obj TokenReleasedEvent) MarshalWithEncoder(encoder *ag_binary.Encoder) (er
 
 func (obj *TokenReleasedEvent) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Controller`:
-	err = decoder.Decode(&obj.Controller)
+	err = readPublicKey(decoder, &obj.Controller)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of TokenReleasedEvent under their IDL names. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj TokenReleasedEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of TokenReleasedEvent, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj TokenReleasedEvent) jsonValue(decimals int) interface{} {
+	return struct {
+		Controller ag_solanago.PublicKey `json:"controller"`
+		Provider   ag_solanago.PublicKey `json:"provider"`
+		Amount     jsonUint64            `json:"amount"`
+		AmountUI   *string               `json:"amount_ui,omitempty"`
+	}{
+		Controller: obj.Controller,
+		Provider:   obj.Provider,
+		Amount:     jsonUint64(obj.Amount),
+		AmountUI:   formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *TokenReleasedEvent) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Controller ag_solanago.PublicKey `json:"controller"`
+		Provider   ag_solanago.PublicKey `json:"provider"`
+		Amount     jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Controller = in.Controller
+	obj.Provider = in.Provider
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of TokenReleasedEvent for solana-go's text encoder.
+func (obj TokenReleasedEvent) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj TokenReleasedEvent) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Account("Controller", obj.Controller))
+	parent.Child(ag_format.Account("Provider", obj.Provider))
+	parent.Child(ag_format.Param("Amount", obj.Amount))
+}
+
 type VestingScheduledEvent struct {
 	Provider ag_solanago.PublicKey
 	Day      uint16
@@ -813,23 +1738,77 @@ func (obj VestingScheduledEvent) MarshalWithEncoder(encoder *ag_binary.Encoder)
 
 func (obj *VestingScheduledEvent) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Provider`:
-	err = decoder.Decode(&obj.Provider)
+	err = readPublicKey(decoder, &obj.Provider)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Day`:
-	err = decoder.Decode(&obj.Day)
+	obj.Day, err = decoder.ReadUint16(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// MarshalJSON encodes the fields of VestingScheduledEvent under their IDL names. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj VestingScheduledEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of VestingScheduledEvent, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj VestingScheduledEvent) jsonValue(decimals int) interface{} {
+	return struct {
+		Provider ag_solanago.PublicKey `json:"provider"`
+		Day      uint16                `json:"day"`
+		Amount   jsonUint64            `json:"amount"`
+		AmountUI *string               `json:"amount_ui,omitempty"`
+	}{
+		Provider: obj.Provider,
+		Day:      obj.Day,
+		Amount:   jsonUint64(obj.Amount),
+		AmountUI: formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *VestingScheduledEvent) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Provider ag_solanago.PublicKey `json:"provider"`
+		Day      uint16                `json:"day"`
+		Amount   jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Provider = in.Provider
+	obj.Day = in.Day
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of VestingScheduledEvent for solana-go's text encoder.
+func (obj VestingScheduledEvent) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj VestingScheduledEvent) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Account("Provider", obj.Provider))
+	parent.Child(ag_format.Param("Day", obj.Day))
+	parent.Child(ag_format.Param("Amount", obj.Amount))
+}
+
 type WithdrawEvent struct {
 	Tenant ag_solanago.PublicKey
 	Amount uint64
@@ -851,14 +1830,63 @@ func (obj WithdrawEvent) MarshalWithEncoder(encoder *ag_binary.Encoder) (err err
 
 func (obj *WithdrawEvent) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
 	// Deserialize `Tenant`:
-	err = decoder.Decode(&obj.Tenant)
+	err = readPublicKey(decoder, &obj.Tenant)
 	if err != nil {
 		return err
 	}
 	// Deserialize `Amount`:
-	err = decoder.Decode(&obj.Amount)
+	obj.Amount, err = decoder.ReadUint64(ag_binary.LE)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// MarshalJSON encodes the fields of WithdrawEvent under their IDL names. 64-bit integers are strings; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals.
+func (obj WithdrawEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of WithdrawEvent, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj WithdrawEvent) jsonValue(decimals int) interface{} {
+	return struct {
+		Tenant   ag_solanago.PublicKey `json:"tenant"`
+		Amount   jsonUint64            `json:"amount"`
+		AmountUI *string               `json:"amount_ui,omitempty"`
+	}{
+		Tenant:   obj.Tenant,
+		Amount:   jsonUint64(obj.Amount),
+		AmountUI: formatAmount(obj.Amount, decimals),
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *WithdrawEvent) UnmarshalJSON(data []byte) error {
+	var in struct {
+		Tenant ag_solanago.PublicKey `json:"tenant"`
+		Amount jsonUint64            `json:"amount"`
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	obj.Tenant = in.Tenant
+	obj.Amount = uint64(in.Amount)
+	return nil
+}
+
+// TextEncode writes the JSON of WithdrawEvent for solana-go's text encoder.
+func (obj WithdrawEvent) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj WithdrawEvent) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Account("Tenant", obj.Tenant))
+	parent.Child(ag_format.Param("Amount", obj.Amount))
+}
@@ -1,3 +1,5 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
@@ -34,12 +36,22 @@ type UnstakeDevice struct {
 	//
 	// [10] = [] associated_token_program
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewUnstakeDeviceInstructionBuilder creates a new `UnstakeDevice` instruction builder.
+// NewUnstakeDeviceInstructionBuilder creates a new `UnstakeDevice` instruction builder
+// for the default program.
 func NewUnstakeDeviceInstructionBuilder() *UnstakeDevice {
+	return (*Program)(nil).NewUnstakeDeviceInstructionBuilder()
+}
+
+// NewUnstakeDeviceInstructionBuilder creates a new `UnstakeDevice` instruction builder
+// for the program.
+func (p *Program) NewUnstakeDeviceInstructionBuilder() *UnstakeDevice {
 	nd := &UnstakeDevice{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 11),
+		program:          p,
 	}
 	nd.AccountMetaSlice[8] = ag_solanago.Meta(Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"])
 	nd.AccountMetaSlice[9] = ag_solanago.Meta(Addresses["11111111111111111111111111111111"])
@@ -47,6 +59,10 @@ func NewUnstakeDeviceInstructionBuilder() *UnstakeDevice {
 	return nd
 }
 
+func (inst *UnstakeDevice) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetDeviceId sets the "device_id" parameter.
 func (inst *UnstakeDevice) SetDeviceId(device_id uint64) *UnstakeDevice {
 	inst.DeviceId = &device_id
@@ -59,28 +75,20 @@ func (inst *UnstakeDevice) SetSupernodeAccount(supernode ag_solanago.PublicKey)
 	return inst
 }
 
-func (inst *UnstakeDevice) findFindSupernodeAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernode returns the seeds of the Supernode account address, without the bump.
+func (inst *UnstakeDevice) seedsSupernode() (seeds [][]byte) {
 	// const: supernode
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeAddressWithBumpSeed calculates Supernode account address with given seeds and a known bump seed.
 func (inst *UnstakeDevice) FindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernode(), bumpSeed, inst.program.ID())
 }
 
 func (inst *UnstakeDevice) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(bumpSeed)
+	pda, err := inst.FindSupernodeAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -89,12 +97,11 @@ func (inst *UnstakeDevice) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8)
 
 // FindSupernodeAddress finds Supernode account address with given seeds.
 func (inst *UnstakeDevice) FindSupernodeAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernode(), inst.program.ID())
 }
 
 func (inst *UnstakeDevice) MustFindSupernodeAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(0)
+	pda, _, err := inst.FindSupernodeAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -112,28 +119,20 @@ func (inst *UnstakeDevice) SetSupernodeStakeAccountAccount(supernodeStakeAccount
 	return inst
 }
 
-func (inst *UnstakeDevice) findFindSupernodeStakeAccountAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernodeStakeAccount returns the seeds of the SupernodeStakeAccount account address, without the bump.
+func (inst *UnstakeDevice) seedsSupernodeStakeAccount() (seeds [][]byte) {
 	// const: supernode_stake_account
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65), byte(0x5f), byte(0x73), byte(0x74), byte(0x61), byte(0x6b), byte(0x65), byte(0x5f), byte(0x61), byte(0x63), byte(0x63), byte(0x6f), byte(0x75), byte(0x6e), byte(0x74)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeStakeAccountAddressWithBumpSeed calculates SupernodeStakeAccount account address with given seeds and a known bump seed.
 func (inst *UnstakeDevice) FindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeStakeAccountAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernodeStakeAccount(), bumpSeed, inst.program.ID())
 }
 
 func (inst *UnstakeDevice) MustFindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeStakeAccountAddress(bumpSeed)
+	pda, err := inst.FindSupernodeStakeAccountAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -142,12 +141,11 @@ func (inst *UnstakeDevice) MustFindSupernodeStakeAccountAddressWithBumpSeed(bump
 
 // FindSupernodeStakeAccountAddress finds SupernodeStakeAccount account address with given seeds.
 func (inst *UnstakeDevice) FindSupernodeStakeAccountAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeStakeAccountAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernodeStakeAccount(), inst.program.ID())
 }
 
 func (inst *UnstakeDevice) MustFindSupernodeStakeAccountAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeStakeAccountAddress(0)
+	pda, _, err := inst.FindSupernodeStakeAccountAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -165,28 +163,20 @@ func (inst *UnstakeDevice) SetSupernodeVestingAccountAccount(supernodeVestingAcc
 	return inst
 }
 
-func (inst *UnstakeDevice) findFindSupernodeVestingAccountAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernodeVestingAccount returns the seeds of the SupernodeVestingAccount account address, without the bump.
+func (inst *UnstakeDevice) seedsSupernodeVestingAccount() (seeds [][]byte) {
 	// const: supernode_vesting_account
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65), byte(0x5f), byte(0x76), byte(0x65), byte(0x73), byte(0x74), byte(0x69), byte(0x6e), byte(0x67), byte(0x5f), byte(0x61), byte(0x63), byte(0x63), byte(0x6f), byte(0x75), byte(0x6e), byte(0x74)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeVestingAccountAddressWithBumpSeed calculates SupernodeVestingAccount account address with given seeds and a known bump seed.
 func (inst *UnstakeDevice) FindSupernodeVestingAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeVestingAccountAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernodeVestingAccount(), bumpSeed, inst.program.ID())
 }
 
 func (inst *UnstakeDevice) MustFindSupernodeVestingAccountAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeVestingAccountAddress(bumpSeed)
+	pda, err := inst.FindSupernodeVestingAccountAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -195,12 +185,11 @@ func (inst *UnstakeDevice) MustFindSupernodeVestingAccountAddressWithBumpSeed(bu
 
 // FindSupernodeVestingAccountAddress finds SupernodeVestingAccount account address with given seeds.
 func (inst *UnstakeDevice) FindSupernodeVestingAccountAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeVestingAccountAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernodeVestingAccount(), inst.program.ID())
 }
 
 func (inst *UnstakeDevice) MustFindSupernodeVestingAccountAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeVestingAccountAddress(0)
+	pda, _, err := inst.FindSupernodeVestingAccountAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -218,30 +207,22 @@ func (inst *UnstakeDevice) SetProviderStakeInfoAccount(providerStakeInfo ag_sola
 	return inst
 }
 
-func (inst *UnstakeDevice) findFindProviderStakeInfoAddress(provider ag_solanago.PublicKey, knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsProviderStakeInfo returns the seeds of the ProviderStakeInfo account address, without the bump.
+func (inst *UnstakeDevice) seedsProviderStakeInfo(provider ag_solanago.PublicKey) (seeds [][]byte) {
 	// const: provider_stake_info
 	seeds = append(seeds, []byte{byte(0x70), byte(0x72), byte(0x6f), byte(0x76), byte(0x69), byte(0x64), byte(0x65), byte(0x72), byte(0x5f), byte(0x73), byte(0x74), byte(0x61), byte(0x6b), byte(0x65), byte(0x5f), byte(0x69), byte(0x6e), byte(0x66), byte(0x6f)})
 	// path: provider
 	seeds = append(seeds, provider.Bytes())
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindProviderStakeInfoAddressWithBumpSeed calculates ProviderStakeInfo account address with given seeds and a known bump seed.
 func (inst *UnstakeDevice) FindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
-	return
+	return createProgramAddress(inst.seedsProviderStakeInfo(provider), bumpSeed, inst.program.ID())
 }
 
 func (inst *UnstakeDevice) MustFindProviderStakeInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, bumpSeed)
+	pda, err := inst.FindProviderStakeInfoAddressWithBumpSeed(provider, bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -250,12 +231,11 @@ func (inst *UnstakeDevice) MustFindProviderStakeInfoAddressWithBumpSeed(provider
 
 // FindProviderStakeInfoAddress finds ProviderStakeInfo account address with given seeds.
 func (inst *UnstakeDevice) FindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindProviderStakeInfoAddress(provider, 0)
-	return
+	return findProgramAddress(inst.seedsProviderStakeInfo(provider), inst.program.ID())
 }
 
 func (inst *UnstakeDevice) MustFindProviderStakeInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderStakeInfoAddress(provider, 0)
+	pda, _, err := inst.FindProviderStakeInfoAddress(provider)
 	if err != nil {
 		panic(err)
 	}
@@ -273,30 +253,22 @@ func (inst *UnstakeDevice) SetProviderVestingInfoAccount(providerVestingInfo ag_
 	return inst
 }
 
-func (inst *UnstakeDevice) findFindProviderVestingInfoAddress(provider ag_solanago.PublicKey, knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsProviderVestingInfo returns the seeds of the ProviderVestingInfo account address, without the bump.
+func (inst *UnstakeDevice) seedsProviderVestingInfo(provider ag_solanago.PublicKey) (seeds [][]byte) {
 	// const: provider_vesting_info
 	seeds = append(seeds, []byte{byte(0x70), byte(0x72), byte(0x6f), byte(0x76), byte(0x69), byte(0x64), byte(0x65), byte(0x72), byte(0x5f), byte(0x76), byte(0x65), byte(0x73), byte(0x74), byte(0x69), byte(0x6e), byte(0x67), byte(0x5f), byte(0x69), byte(0x6e), byte(0x66), byte(0x6f)})
 	// path: provider
 	seeds = append(seeds, provider.Bytes())
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindProviderVestingInfoAddressWithBumpSeed calculates ProviderVestingInfo account address with given seeds and a known bump seed.
 func (inst *UnstakeDevice) FindProviderVestingInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindProviderVestingInfoAddress(provider, bumpSeed)
-	return
+	return createProgramAddress(inst.seedsProviderVestingInfo(provider), bumpSeed, inst.program.ID())
 }
 
 func (inst *UnstakeDevice) MustFindProviderVestingInfoAddressWithBumpSeed(provider ag_solanago.PublicKey, bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderVestingInfoAddress(provider, bumpSeed)
+	pda, err := inst.FindProviderVestingInfoAddressWithBumpSeed(provider, bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -305,12 +277,11 @@ func (inst *UnstakeDevice) MustFindProviderVestingInfoAddressWithBumpSeed(provid
 
 // FindProviderVestingInfoAddress finds ProviderVestingInfo account address with given seeds.
 func (inst *UnstakeDevice) FindProviderVestingInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindProviderVestingInfoAddress(provider, 0)
-	return
+	return findProgramAddress(inst.seedsProviderVestingInfo(provider), inst.program.ID())
 }
 
 func (inst *UnstakeDevice) MustFindProviderVestingInfoAddress(provider ag_solanago.PublicKey) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindProviderVestingInfoAddress(provider, 0)
+	pda, _, err := inst.FindProviderVestingInfoAddress(provider)
 	if err != nil {
 		panic(err)
 	}
@@ -392,7 +363,7 @@ func (inst UnstakeDevice) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_UnstakeDevice,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -452,8 +423,54 @@ func (inst *UnstakeDevice) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *UnstakeDevice) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	{
+		pda, _, err := inst.FindSupernodeAddress()
+		errs = append(errs, checkAccount("unstake_device", "supernode", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("unstake_device", "supernode_stake_account", accounts[1], false, true))
+	{
+		pda, _, err := inst.FindSupernodeStakeAccountAddress()
+		errs = append(errs, checkAccount("unstake_device", "supernode_stake_account", accounts[1].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("unstake_device", "supernode_vesting_account", accounts[2], false, true))
+	{
+		pda, _, err := inst.FindSupernodeVestingAccountAddress()
+		errs = append(errs, checkAccount("unstake_device", "supernode_vesting_account", accounts[2].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("unstake_device", "provider_stake_info", accounts[3], false, true))
+	{
+		pda, _, err := inst.FindProviderStakeInfoAddress(accounts[5].PublicKey)
+		errs = append(errs, checkAccount("unstake_device", "provider_stake_info", accounts[3].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("unstake_device", "provider_vesting_info", accounts[4], false, true))
+	{
+		pda, _, err := inst.FindProviderVestingInfoAddress(accounts[5].PublicKey)
+		errs = append(errs, checkAccount("unstake_device", "provider_vesting_info", accounts[4].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("unstake_device", "controller", accounts[6], true, true))
+	errs = append(errs, checkFlags("unstake_device", "admin", accounts[7], true, false))
+	errs = append(errs, checkAccount("unstake_device", "token_program", accounts[8].PublicKey, Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("unstake_device", "system_program", accounts[9].PublicKey, Addresses["11111111111111111111111111111111"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("unstake_device", "associated_token_program", accounts[10].PublicKey, Addresses["ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"], nil, ErrProgramMismatch))
+	return errors.Join(errs...)
+}
+
 func (inst *UnstakeDevice) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("UnstakeDevice")).
@@ -500,7 +517,8 @@ func (obj *UnstakeDevice) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err
 	return nil
 }
 
-// NewUnstakeDeviceInstruction declares a new UnstakeDevice instruction with the provided parameters and accounts.
+// NewUnstakeDeviceInstruction declares a new UnstakeDevice instruction of the default
+// program with the provided parameters and accounts.
 func NewUnstakeDeviceInstruction(
 	// Parameters:
 	device_id uint64,
@@ -516,7 +534,27 @@ func NewUnstakeDeviceInstruction(
 	tokenProgram ag_solanago.PublicKey,
 	systemProgram ag_solanago.PublicKey,
 	associatedTokenProgram ag_solanago.PublicKey) *UnstakeDevice {
-	return NewUnstakeDeviceInstructionBuilder().
+	return (*Program)(nil).NewUnstakeDeviceInstruction(device_id, supernode, supernodeStakeAccount, supernodeVestingAccount, providerStakeInfo, providerVestingInfo, provider, controller, admin, tokenProgram, systemProgram, associatedTokenProgram)
+}
+
+// NewUnstakeDeviceInstruction declares a new UnstakeDevice instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewUnstakeDeviceInstruction(
+	// Parameters:
+	device_id uint64,
+	// Accounts:
+	supernode ag_solanago.PublicKey,
+	supernodeStakeAccount ag_solanago.PublicKey,
+	supernodeVestingAccount ag_solanago.PublicKey,
+	providerStakeInfo ag_solanago.PublicKey,
+	providerVestingInfo ag_solanago.PublicKey,
+	provider ag_solanago.PublicKey,
+	controller ag_solanago.PublicKey,
+	admin ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	associatedTokenProgram ag_solanago.PublicKey) *UnstakeDevice {
+	return p.NewUnstakeDeviceInstructionBuilder().
 		SetDeviceId(device_id).
 		SetSupernodeAccount(supernode).
 		SetSupernodeStakeAccountAccount(supernodeStakeAccount).
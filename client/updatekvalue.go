@@ -1,3 +1,5 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
@@ -23,12 +25,22 @@ type UpdateKValue struct {
 	//
 	// [4] = [] associated_token_program
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewUpdateKValueInstructionBuilder creates a new `UpdateKValue` instruction builder.
+// NewUpdateKValueInstructionBuilder creates a new `UpdateKValue` instruction builder
+// for the default program.
 func NewUpdateKValueInstructionBuilder() *UpdateKValue {
+	return (*Program)(nil).NewUpdateKValueInstructionBuilder()
+}
+
+// NewUpdateKValueInstructionBuilder creates a new `UpdateKValue` instruction builder
+// for the program.
+func (p *Program) NewUpdateKValueInstructionBuilder() *UpdateKValue {
 	nd := &UpdateKValue{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 5),
+		program:          p,
 	}
 	nd.AccountMetaSlice[2] = ag_solanago.Meta(Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"])
 	nd.AccountMetaSlice[3] = ag_solanago.Meta(Addresses["11111111111111111111111111111111"])
@@ -36,6 +48,10 @@ func NewUpdateKValueInstructionBuilder() *UpdateKValue {
 	return nd
 }
 
+func (inst *UpdateKValue) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetSpecId sets the "spec_id" parameter.
 func (inst *UpdateKValue) SetSpecId(spec_id uint16) *UpdateKValue {
 	inst.SpecId = &spec_id
@@ -54,28 +70,20 @@ func (inst *UpdateKValue) SetSupernodeAccount(supernode ag_solanago.PublicKey) *
 	return inst
 }
 
-func (inst *UpdateKValue) findFindSupernodeAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernode returns the seeds of the Supernode account address, without the bump.
+func (inst *UpdateKValue) seedsSupernode() (seeds [][]byte) {
 	// const: supernode
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeAddressWithBumpSeed calculates Supernode account address with given seeds and a known bump seed.
 func (inst *UpdateKValue) FindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernode(), bumpSeed, inst.program.ID())
 }
 
 func (inst *UpdateKValue) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(bumpSeed)
+	pda, err := inst.FindSupernodeAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -84,12 +92,11 @@ func (inst *UpdateKValue) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (
 
 // FindSupernodeAddress finds Supernode account address with given seeds.
 func (inst *UpdateKValue) FindSupernodeAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernode(), inst.program.ID())
 }
 
 func (inst *UpdateKValue) MustFindSupernodeAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(0)
+	pda, _, err := inst.FindSupernodeAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -149,7 +156,7 @@ func (inst UpdateKValue) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_UpdateKValue,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -194,8 +201,34 @@ func (inst *UpdateKValue) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *UpdateKValue) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	errs = append(errs, checkFlags("update_k_value", "supernode", accounts[0], false, true))
+	{
+		pda, _, err := inst.FindSupernodeAddress()
+		errs = append(errs, checkAccount("update_k_value", "supernode", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("update_k_value", "admin", accounts[1], true, false))
+	errs = append(errs, checkAccount("update_k_value", "token_program", accounts[2].PublicKey, Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("update_k_value", "system_program", accounts[3].PublicKey, Addresses["11111111111111111111111111111111"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("update_k_value", "associated_token_program", accounts[4].PublicKey, Addresses["ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"], nil, ErrProgramMismatch))
+	return errors.Join(errs...)
+}
+
 func (inst *UpdateKValue) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("UpdateKValue")).
@@ -247,7 +280,8 @@ func (obj *UpdateKValue) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err e
 	return nil
 }
 
-// NewUpdateKValueInstruction declares a new UpdateKValue instruction with the provided parameters and accounts.
+// NewUpdateKValueInstruction declares a new UpdateKValue instruction of the default
+// program with the provided parameters and accounts.
 func NewUpdateKValueInstruction(
 	// Parameters:
 	spec_id uint16,
@@ -258,7 +292,22 @@ func NewUpdateKValueInstruction(
 	tokenProgram ag_solanago.PublicKey,
 	systemProgram ag_solanago.PublicKey,
 	associatedTokenProgram ag_solanago.PublicKey) *UpdateKValue {
-	return NewUpdateKValueInstructionBuilder().
+	return (*Program)(nil).NewUpdateKValueInstruction(spec_id, val, supernode, admin, tokenProgram, systemProgram, associatedTokenProgram)
+}
+
+// NewUpdateKValueInstruction declares a new UpdateKValue instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewUpdateKValueInstruction(
+	// Parameters:
+	spec_id uint16,
+	val uint64,
+	// Accounts:
+	supernode ag_solanago.PublicKey,
+	admin ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	associatedTokenProgram ag_solanago.PublicKey) *UpdateKValue {
+	return p.NewUpdateKValueInstructionBuilder().
 		SetSpecId(spec_id).
 		SetVal(val).
 		SetSupernodeAccount(supernode).
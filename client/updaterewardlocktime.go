@@ -1,3 +1,5 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
@@ -22,12 +24,22 @@ type UpdateRewardLockTime struct {
 	//
 	// [4] = [] associated_token_program
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewUpdateRewardLockTimeInstructionBuilder creates a new `UpdateRewardLockTime` instruction builder.
+// NewUpdateRewardLockTimeInstructionBuilder creates a new `UpdateRewardLockTime` instruction builder
+// for the default program.
 func NewUpdateRewardLockTimeInstructionBuilder() *UpdateRewardLockTime {
+	return (*Program)(nil).NewUpdateRewardLockTimeInstructionBuilder()
+}
+
+// NewUpdateRewardLockTimeInstructionBuilder creates a new `UpdateRewardLockTime` instruction builder
+// for the program.
+func (p *Program) NewUpdateRewardLockTimeInstructionBuilder() *UpdateRewardLockTime {
 	nd := &UpdateRewardLockTime{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 5),
+		program:          p,
 	}
 	nd.AccountMetaSlice[2] = ag_solanago.Meta(Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"])
 	nd.AccountMetaSlice[3] = ag_solanago.Meta(Addresses["11111111111111111111111111111111"])
@@ -35,6 +47,10 @@ func NewUpdateRewardLockTimeInstructionBuilder() *UpdateRewardLockTime {
 	return nd
 }
 
+func (inst *UpdateRewardLockTime) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetNew sets the "new" parameter.
 func (inst *UpdateRewardLockTime) SetNew(new uint64) *UpdateRewardLockTime {
 	inst.New = &new
@@ -47,28 +63,20 @@ func (inst *UpdateRewardLockTime) SetSupernodeAccount(supernode ag_solanago.Publ
 	return inst
 }
 
-func (inst *UpdateRewardLockTime) findFindSupernodeAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernode returns the seeds of the Supernode account address, without the bump.
+func (inst *UpdateRewardLockTime) seedsSupernode() (seeds [][]byte) {
 	// const: supernode
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeAddressWithBumpSeed calculates Supernode account address with given seeds and a known bump seed.
 func (inst *UpdateRewardLockTime) FindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernode(), bumpSeed, inst.program.ID())
 }
 
 func (inst *UpdateRewardLockTime) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(bumpSeed)
+	pda, err := inst.FindSupernodeAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -77,12 +85,11 @@ func (inst *UpdateRewardLockTime) MustFindSupernodeAddressWithBumpSeed(bumpSeed
 
 // FindSupernodeAddress finds Supernode account address with given seeds.
 func (inst *UpdateRewardLockTime) FindSupernodeAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernode(), inst.program.ID())
 }
 
 func (inst *UpdateRewardLockTime) MustFindSupernodeAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(0)
+	pda, _, err := inst.FindSupernodeAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -142,7 +149,7 @@ func (inst UpdateRewardLockTime) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_UpdateRewardLockTime,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -184,8 +191,34 @@ func (inst *UpdateRewardLockTime) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *UpdateRewardLockTime) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	errs = append(errs, checkFlags("update_reward_lock_time", "supernode", accounts[0], false, true))
+	{
+		pda, _, err := inst.FindSupernodeAddress()
+		errs = append(errs, checkAccount("update_reward_lock_time", "supernode", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("update_reward_lock_time", "admin", accounts[1], true, false))
+	errs = append(errs, checkAccount("update_reward_lock_time", "token_program", accounts[2].PublicKey, Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("update_reward_lock_time", "system_program", accounts[3].PublicKey, Addresses["11111111111111111111111111111111"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("update_reward_lock_time", "associated_token_program", accounts[4].PublicKey, Addresses["ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"], nil, ErrProgramMismatch))
+	return errors.Join(errs...)
+}
+
 func (inst *UpdateRewardLockTime) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("UpdateRewardLockTime")).
@@ -226,7 +259,8 @@ func (obj *UpdateRewardLockTime) UnmarshalWithDecoder(decoder *ag_binary.Decoder
 	return nil
 }
 
-// NewUpdateRewardLockTimeInstruction declares a new UpdateRewardLockTime instruction with the provided parameters and accounts.
+// NewUpdateRewardLockTimeInstruction declares a new UpdateRewardLockTime instruction of the default
+// program with the provided parameters and accounts.
 func NewUpdateRewardLockTimeInstruction(
 	// Parameters:
 	new uint64,
@@ -236,7 +270,21 @@ func NewUpdateRewardLockTimeInstruction(
 	tokenProgram ag_solanago.PublicKey,
 	systemProgram ag_solanago.PublicKey,
 	associatedTokenProgram ag_solanago.PublicKey) *UpdateRewardLockTime {
-	return NewUpdateRewardLockTimeInstructionBuilder().
+	return (*Program)(nil).NewUpdateRewardLockTimeInstruction(new, supernode, admin, tokenProgram, systemProgram, associatedTokenProgram)
+}
+
+// NewUpdateRewardLockTimeInstruction declares a new UpdateRewardLockTime instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewUpdateRewardLockTimeInstruction(
+	// Parameters:
+	new uint64,
+	// Accounts:
+	supernode ag_solanago.PublicKey,
+	admin ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	associatedTokenProgram ag_solanago.PublicKey) *UpdateRewardLockTime {
+	return p.NewUpdateRewardLockTimeInstructionBuilder().
 		SetNew(new).
 		SetSupernodeAccount(supernode).
 		SetAdminAccount(admin).
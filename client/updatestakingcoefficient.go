@@ -1,3 +1,5 @@
+// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT.
+
 package client
 
 import (
@@ -22,12 +24,22 @@ type UpdateStakingCoefficient struct {
 	//
 	// [4] = [] associated_token_program
 	ag_solanago.AccountMetaSlice `bin:"-"`
+
+	program *Program
 }
 
-// NewUpdateStakingCoefficientInstructionBuilder creates a new `UpdateStakingCoefficient` instruction builder.
+// NewUpdateStakingCoefficientInstructionBuilder creates a new `UpdateStakingCoefficient` instruction builder
+// for the default program.
 func NewUpdateStakingCoefficientInstructionBuilder() *UpdateStakingCoefficient {
+	return (*Program)(nil).NewUpdateStakingCoefficientInstructionBuilder()
+}
+
+// NewUpdateStakingCoefficientInstructionBuilder creates a new `UpdateStakingCoefficient` instruction builder
+// for the program.
+func (p *Program) NewUpdateStakingCoefficientInstructionBuilder() *UpdateStakingCoefficient {
 	nd := &UpdateStakingCoefficient{
 		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, 5),
+		program:          p,
 	}
 	nd.AccountMetaSlice[2] = ag_solanago.Meta(Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"])
 	nd.AccountMetaSlice[3] = ag_solanago.Meta(Addresses["11111111111111111111111111111111"])
@@ -35,6 +47,10 @@ func NewUpdateStakingCoefficientInstructionBuilder() *UpdateStakingCoefficient {
 	return nd
 }
 
+func (inst *UpdateStakingCoefficient) setProgram(p *Program) {
+	inst.program = p
+}
+
 // SetVal sets the "val" parameter.
 func (inst *UpdateStakingCoefficient) SetVal(val uint64) *UpdateStakingCoefficient {
 	inst.Val = &val
@@ -47,28 +63,20 @@ func (inst *UpdateStakingCoefficient) SetSupernodeAccount(supernode ag_solanago.
 	return inst
 }
 
-func (inst *UpdateStakingCoefficient) findFindSupernodeAddress(knownBumpSeed uint8) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	var seeds [][]byte
+// seedsSupernode returns the seeds of the Supernode account address, without the bump.
+func (inst *UpdateStakingCoefficient) seedsSupernode() (seeds [][]byte) {
 	// const: supernode
 	seeds = append(seeds, []byte{byte(0x73), byte(0x75), byte(0x70), byte(0x65), byte(0x72), byte(0x6e), byte(0x6f), byte(0x64), byte(0x65)})
-
-	if knownBumpSeed != 0 {
-		seeds = append(seeds, []byte{byte(bumpSeed)})
-		pda, err = ag_solanago.CreateProgramAddress(seeds, ProgramID)
-	} else {
-		pda, bumpSeed, err = ag_solanago.FindProgramAddress(seeds, ProgramID)
-	}
 	return
 }
 
 // FindSupernodeAddressWithBumpSeed calculates Supernode account address with given seeds and a known bump seed.
 func (inst *UpdateStakingCoefficient) FindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
-	pda, _, err = inst.findFindSupernodeAddress(bumpSeed)
-	return
+	return createProgramAddress(inst.seedsSupernode(), bumpSeed, inst.program.ID())
 }
 
 func (inst *UpdateStakingCoefficient) MustFindSupernodeAddressWithBumpSeed(bumpSeed uint8) (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(bumpSeed)
+	pda, err := inst.FindSupernodeAddressWithBumpSeed(bumpSeed)
 	if err != nil {
 		panic(err)
 	}
@@ -77,12 +85,11 @@ func (inst *UpdateStakingCoefficient) MustFindSupernodeAddressWithBumpSeed(bumpS
 
 // FindSupernodeAddress finds Supernode account address with given seeds.
 func (inst *UpdateStakingCoefficient) FindSupernodeAddress() (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
-	pda, bumpSeed, err = inst.findFindSupernodeAddress(0)
-	return
+	return findProgramAddress(inst.seedsSupernode(), inst.program.ID())
 }
 
 func (inst *UpdateStakingCoefficient) MustFindSupernodeAddress() (pda ag_solanago.PublicKey) {
-	pda, _, err := inst.findFindSupernodeAddress(0)
+	pda, _, err := inst.FindSupernodeAddress()
 	if err != nil {
 		panic(err)
 	}
@@ -142,7 +149,7 @@ func (inst UpdateStakingCoefficient) Build() *Instruction {
 	return &Instruction{BaseVariant: ag_binary.BaseVariant{
 		Impl:   inst,
 		TypeID: Instruction_UpdateStakingCoefficient,
-	}}
+	}, program: inst.program}
 }
 
 // ValidateAndBuild validates the instruction parameters and accounts;
@@ -184,8 +191,37 @@ func (inst *UpdateStakingCoefficient) Validate() error {
 	return nil
 }
 
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *UpdateStakingCoefficient) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+	errs = append(errs, checkFlags("update_staking_coefficient", "supernode", accounts[0], false, true))
+	{
+		pda, _, err := inst.FindSupernodeAddress()
+		errs = append(errs, checkAccount("update_staking_coefficient", "supernode", accounts[0].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+	errs = append(errs, checkFlags("update_staking_coefficient", "admin", accounts[1], true, false))
+	errs = append(errs, checkAccount("update_staking_coefficient", "token_program", accounts[2].PublicKey, Addresses["TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("update_staking_coefficient", "system_program", accounts[3].PublicKey, Addresses["11111111111111111111111111111111"], nil, ErrProgramMismatch))
+	errs = append(errs, checkAccount("update_staking_coefficient", "associated_token_program", accounts[4].PublicKey, Addresses["ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"], nil, ErrProgramMismatch))
+	if *inst.Val == 0 {
+		errs = append(errs, &ArgumentError{Instruction: "update_staking_coefficient", Argument: "val", Err: ErrZeroArgument})
+	}
+	return errors.Join(errs...)
+}
+
 func (inst *UpdateStakingCoefficient) EncodeToTree(parent ag_treeout.Branches) {
-	parent.Child(ag_format.Program(ProgramName, ProgramID)).
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
 		//
 		ParentFunc(func(programBranch ag_treeout.Branches) {
 			programBranch.Child(ag_format.Instruction("UpdateStakingCoefficient")).
@@ -226,7 +262,8 @@ func (obj *UpdateStakingCoefficient) UnmarshalWithDecoder(decoder *ag_binary.Dec
 	return nil
 }
 
-// NewUpdateStakingCoefficientInstruction declares a new UpdateStakingCoefficient instruction with the provided parameters and accounts.
+// NewUpdateStakingCoefficientInstruction declares a new UpdateStakingCoefficient instruction of the default
+// program with the provided parameters and accounts.
 func NewUpdateStakingCoefficientInstruction(
 	// Parameters:
 	val uint64,
@@ -236,7 +273,21 @@ func NewUpdateStakingCoefficientInstruction(
 	tokenProgram ag_solanago.PublicKey,
 	systemProgram ag_solanago.PublicKey,
 	associatedTokenProgram ag_solanago.PublicKey) *UpdateStakingCoefficient {
-	return NewUpdateStakingCoefficientInstructionBuilder().
+	return (*Program)(nil).NewUpdateStakingCoefficientInstruction(val, supernode, admin, tokenProgram, systemProgram, associatedTokenProgram)
+}
+
+// NewUpdateStakingCoefficientInstruction declares a new UpdateStakingCoefficient instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) NewUpdateStakingCoefficientInstruction(
+	// Parameters:
+	val uint64,
+	// Accounts:
+	supernode ag_solanago.PublicKey,
+	admin ag_solanago.PublicKey,
+	tokenProgram ag_solanago.PublicKey,
+	systemProgram ag_solanago.PublicKey,
+	associatedTokenProgram ag_solanago.PublicKey) *UpdateStakingCoefficient {
+	return p.NewUpdateStakingCoefficientInstructionBuilder().
 		SetVal(val).
 		SetSupernodeAccount(supernode).
 		SetAdminAccount(admin).
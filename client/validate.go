@@ -0,0 +1,100 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+)
+
+// Reasons of the errors ValidateDeep returns, wrapped in *AccountError and
+// *ArgumentError.
+var (
+	// ErrSeedsMismatch is reported for a PDA account other than the address
+	// derived from its seeds.
+	ErrSeedsMismatch = errors.New("is not the address derived from its seeds")
+	// ErrNotAssociatedTokenAccount is reported for a token account other
+	// than the associated token account of its owner for the mint.
+	ErrNotAssociatedTokenAccount = errors.New("is not the associated token account of its owner")
+	// ErrProgramMismatch is reported for a program account other than the
+	// program the IDL names.
+	ErrProgramMismatch = errors.New("is not the expected program")
+	// ErrNotSigner and ErrNotWritable are reported for accounts the IDL
+	// requires to sign or to be writable that are not.
+	ErrNotSigner   = errors.New("must be a signer")
+	ErrNotWritable = errors.New("must be writable")
+	// ErrZeroArgument is reported for arguments the program rejects when
+	// zero.
+	ErrZeroArgument = errors.New("must not be zero")
+)
+
+// AccountError is an account of an instruction failing ValidateDeep.
+type AccountError struct {
+	// Instruction and Account are IDL names, such as "stake_device" and
+	// "provider_stake_info".
+	Instruction string
+	Account     string
+	// Got and Want are the address set and the one expected, for address
+	// mismatches.
+	Got, Want ag_solanago.PublicKey
+	Err       error
+}
+
+func (e *AccountError) Error() string {
+	msg := fmt.Sprintf("%s: account %s %v", e.Instruction, e.Account, e.Err)
+	if !e.Want.IsZero() {
+		msg += fmt.Sprintf(" (got %s, want %s)", e.Got, e.Want)
+	}
+	return msg
+}
+
+func (e *AccountError) Unwrap() error {
+	return e.Err
+}
+
+// ArgumentError is an argument of an instruction failing ValidateDeep.
+type ArgumentError struct {
+	Instruction string
+	Argument    string
+	Err         error
+}
+
+func (e *ArgumentError) Error() string {
+	return fmt.Sprintf("%s: argument %s %v", e.Instruction, e.Argument, e.Err)
+}
+
+func (e *ArgumentError) Unwrap() error {
+	return e.Err
+}
+
+// checkAccount reports got differing from want, or err from deriving want.
+func checkAccount(instruction, account string, got, want ag_solanago.PublicKey, err, mismatch error) error {
+	if err != nil {
+		return &AccountError{Instruction: instruction, Account: account, Err: err}
+	}
+	if !got.Equals(want) {
+		return &AccountError{Instruction: instruction, Account: account, Got: got, Want: want, Err: mismatch}
+	}
+	return nil
+}
+
+// checkFlags reports a flag the IDL requires missing from meta. Extra flags
+// are fine: a transaction grants an account the flags of all its uses, so
+// the admin paying the fees is writable in every instruction.
+func checkFlags(instruction, account string, meta *ag_solanago.AccountMeta, signer, writable bool) error {
+	switch {
+	case signer && !meta.IsSigner:
+		return &AccountError{Instruction: instruction, Account: account, Err: ErrNotSigner}
+	case writable && !meta.IsWritable:
+		return &AccountError{Instruction: instruction, Account: account, Err: ErrNotWritable}
+	}
+	return nil
+}
+
+// associatedTokenAddress is ag_solanago.FindAssociatedTokenAddress, cached
+// like the PDAs of the builders.
+func associatedTokenAddress(owner, mint ag_solanago.PublicKey) (ag_solanago.PublicKey, error) {
+	seeds := [][]byte{owner[:], ag_solanago.TokenProgramID[:], mint[:]}
+	ata, _, err := findProgramAddress(seeds, ag_solanago.SPLAssociatedTokenAccountProgramID)
+	return ata, err
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_require "github.com/stretchr/testify/require"
+)
+
+// stakeDevice returns a StakeDevice passing ValidateDeep.
+func stakeDevice(t *testing.T, p *Program, provider, mint ag_solanago.PublicKey) *StakeDevice {
+	b := p.NewStakeDeviceInstructionBuilder()
+	ata, _, err := ag_solanago.FindAssociatedTokenAddress(provider, mint)
+	ag_require.NoError(t, err)
+	return p.NewStakeDeviceInstruction(1, 0,
+		b.MustFindSupernodeAddress(),
+		b.MustFindSupernodeStakeAccountAddress(),
+		b.MustFindProviderStakeInfoAddress(provider),
+		ata,
+		mint,
+		provider,
+		ag_solanago.NewWallet().PublicKey(),
+		ag_solanago.NewWallet().PublicKey(),
+		ag_solanago.TokenProgramID,
+		ag_solanago.SystemProgramID,
+		ag_solanago.SPLAssociatedTokenAccountProgramID,
+	)
+}
+
+func TestValidateDeep(t *testing.T) {
+	p := NewProgram(testProgramID)
+	provider, mint := ag_solanago.NewWallet().PublicKey(), ag_solanago.NewWallet().PublicKey()
+	ag_require.NoError(t, stakeDevice(t, p, provider, mint).ValidateDeep())
+
+	other := ag_solanago.NewWallet().PublicKey()
+	for _, c := range []struct {
+		name    string
+		change  func(inst *StakeDevice)
+		account string
+		reason  error
+	}{
+		{"stake info of another provider", func(inst *StakeDevice) {
+			inst.SetProviderStakeInfoAccount(inst.MustFindProviderStakeInfoAddress(other))
+		}, "provider_stake_info", ErrSeedsMismatch},
+		{"another provider", func(inst *StakeDevice) {
+			inst.AccountMetaSlice[5] = ag_solanago.Meta(other)
+		}, "provider_stake_info", ErrSeedsMismatch},
+		{"supernode", func(inst *StakeDevice) {
+			inst.AccountMetaSlice[0] = ag_solanago.Meta(other)
+		}, "supernode", ErrSeedsMismatch},
+		{"token account of another mint", func(inst *StakeDevice) {
+			inst.AccountMetaSlice[4] = ag_solanago.Meta(other)
+		}, "provider_token_account", ErrNotAssociatedTokenAccount},
+		{"token account not associated", func(inst *StakeDevice) {
+			inst.SetProviderTokenAccountAccount(other)
+		}, "provider_token_account", ErrNotAssociatedTokenAccount},
+		{"token program", func(inst *StakeDevice) {
+			inst.AccountMetaSlice[8] = ag_solanago.Meta(ag_solanago.Token2022ProgramID)
+		}, "token_program", ErrProgramMismatch},
+		{"admin not signing", func(inst *StakeDevice) {
+			inst.AccountMetaSlice[7].IsSigner = false
+		}, "admin", ErrNotSigner},
+		{"controller read-only", func(inst *StakeDevice) {
+			inst.AccountMetaSlice[6].IsWritable = false
+		}, "controller", ErrNotWritable},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			inst := stakeDevice(t, p, provider, mint)
+			c.change(inst)
+			err := inst.ValidateDeep()
+			ag_require.ErrorIs(t, err, c.reason)
+			var accountErr *AccountError
+			ag_require.True(t, errors.As(err, &accountErr))
+			ag_require.Equal(t, "stake_device", accountErr.Instruction)
+			ag_require.Equal(t, c.account, accountErr.Account)
+			ag_require.Contains(t, err.Error(), "stake_device: account "+c.account)
+			ag_require.NoError(t, inst.Validate())
+		})
+	}
+
+	t.Run("every failure", func(t *testing.T) {
+		inst := stakeDevice(t, p, provider, mint)
+		inst.AccountMetaSlice[0] = ag_solanago.Meta(other)
+		inst.AccountMetaSlice[7].IsSigner = false
+		err := inst.ValidateDeep()
+		ag_require.ErrorIs(t, err, ErrSeedsMismatch)
+		ag_require.ErrorIs(t, err, ErrNotSigner)
+	})
+
+	t.Run("extra flags", func(t *testing.T) {
+		inst := stakeDevice(t, p, provider, mint)
+		inst.AccountMetaSlice[7].IsWritable = true
+		inst.AccountMetaSlice[5].IsSigner = true
+		ag_require.NoError(t, inst.ValidateDeep())
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		inst := stakeDevice(t, p, provider, mint)
+		inst.AccountMetaSlice[3] = nil
+		ag_require.EqualError(t, inst.ValidateDeep(), "accounts.ProviderTokenAccount is not set")
+	})
+}
+
+func TestValidateDeep_ZeroArgument(t *testing.T) {
+	p := NewProgram(testProgramID)
+	b := p.NewUpdateStakingCoefficientInstructionBuilder()
+	inst := p.NewUpdateStakingCoefficientInstruction(0,
+		b.MustFindSupernodeAddress(),
+		ag_solanago.NewWallet().PublicKey(),
+		ag_solanago.TokenProgramID,
+		ag_solanago.SystemProgramID,
+		ag_solanago.SPLAssociatedTokenAccountProgramID,
+	)
+	err := inst.ValidateDeep()
+	ag_require.ErrorIs(t, err, ErrZeroArgument)
+	var argErr *ArgumentError
+	ag_require.True(t, errors.As(err, &argErr))
+	ag_require.Equal(t, "val", argErr.Argument)
+	ag_require.EqualError(t, err, "update_staking_coefficient: argument val must not be zero")
+
+	inst.SetVal(1)
+	ag_require.NoError(t, inst.ValidateDeep())
+}
@@ -0,0 +1,91 @@
+// Command idlgen generates the Go client of an Anchor program from its IDL.
+// It writes one file per generated source into the output directory and
+// removes generated files the IDL no longer produces:
+//
+//	idlgen -idl ../idl/supernode.json -out . -amounts amount,funds -nonzero amount
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"n3-solana-test/idl"
+	"n3-solana-test/idlgen"
+)
+
+func main() {
+	idlPath := flag.String("idl", "", "path of the Anchor IDL")
+	out := flag.String("out", ".", "output directory")
+	pkg := flag.String("package", "client", "package name of the generated files")
+	amounts := flag.String("amounts", "", "comma-separated u64 fields holding token amounts, such as amount or TenantInfo.funds")
+	nonZero := flag.String("nonzero", "", "comma-separated integer arguments the program rejects when zero, such as amount or update_staking_coefficient.val")
+	flag.Parse()
+	if *idlPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	opts := idlgen.Options{Package: *pkg}
+	if *amounts != "" {
+		opts.Amounts = strings.Split(*amounts, ",")
+	}
+	if *nonZero != "" {
+		opts.NonZero = strings.Split(*nonZero, ",")
+	}
+	if err := run(*idlPath, *out, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "idlgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(idlPath, out string, opts idlgen.Options) error {
+	data, err := os.ReadFile(idlPath)
+	if err != nil {
+		return err
+	}
+	program, err := idl.Parse(data)
+	if err != nil {
+		return err
+	}
+	files, err := idlgen.Generate(program, opts)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return err
+	}
+
+	existing, err := filepath.Glob(filepath.Join(out, "*.go"))
+	if err != nil {
+		return err
+	}
+	for _, path := range existing {
+		if _, ok := files[filepath.Base(path)]; ok {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if idlgen.IsGenerated(src) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(out, name), files[name], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/amount"
+	"n3-solana-test/bootstrap"
+	"n3-solana-test/config"
+	"n3-solana-test/keystore"
+)
+
+func runBootstrap(args []string) error {
+	flags := flag.NewFlagSet("bootstrap", flag.ExitOnError)
+	profilePath := flags.String("config", "supernode.json", "profile file, written with the deployment")
+	rpcURL := flags.String("rpc", "", "RPC endpoint (overrides the profile)")
+	programID := flags.String("program", "", "supernode program id (overrides the profile)")
+	storePath := flags.String("keystore", "", "keystore of the role keys, missing roles are generated into it (default: the profile's, else $"+keystore.EnvPath+", else "+keystore.DefaultPath+")")
+	providers := flags.Int("providers", 1, "number of provider wallets")
+	controllers := flags.Int("controllers", 2, "number of controller wallets")
+	tenants := flags.Int("tenants", 1, "number of tenant wallets")
+	decimals := flags.Uint("decimals", 9, "decimals of a new mint")
+	providerTokens := flags.String("provider-tokens", "1000000", "token balance of each provider")
+	tenantTokens := flags.String("tenant-tokens", "1000000", "token balance of each tenant")
+	rewardTokens := flags.String("reward-tokens", "10000000", "token balance of the reward vault")
+	coefficient := flags.String("staking-coefficient", "10", "tokens staked per unit of k-value")
+	lockTime := flags.Uint64("reward-lock-time", 90, "reward lock time")
+	fees := addFeeFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	profile, err := config.Load(*profilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		profile = &config.Profile{RPC: rpc.DevNet_RPC, ProgramID: solana.MustPublicKeyFromBase58(defaultProgramID)}
+	} else if err != nil {
+		return err
+	}
+	if *rpcURL != "" {
+		profile.RPC = *rpcURL
+	}
+	if *programID != "" {
+		if profile.ProgramID, err = solana.PublicKeyFromBase58(*programID); err != nil {
+			return fmt.Errorf("invalid program id: %w", err)
+		}
+	}
+	if *storePath != "" {
+		profile.Keystore = *storePath
+	}
+	profile.Keystore = keystorePath(profile.Keystore)
+	if err := setProgramID(profile.ProgramID.String()); err != nil {
+		return err
+	}
+	if *decimals > amount.MaxDecimals {
+		return fmt.Errorf("invalid decimals %d", *decimals)
+	}
+
+	opts := bootstrap.Options{
+		Providers:        *providers,
+		Controllers:      *controllers,
+		Tenants:          *tenants,
+		Decimals:         uint8(*decimals),
+		RewardLockedTime: *lockTime,
+	}
+	for _, a := range []struct {
+		flag  string
+		value string
+		out   *amount.Amount
+	}{
+		{"provider-tokens", *providerTokens, &opts.ProviderTokens},
+		{"tenant-tokens", *tenantTokens, &opts.TenantTokens},
+		{"reward-tokens", *rewardTokens, &opts.RewardTokens},
+		{"staking-coefficient", *coefficient, &opts.StakingCoefficient},
+	} {
+		if *a.out, err = amount.Parse(a.value, opts.Decimals); err != nil {
+			return fmt.Errorf("invalid -%s: %w", a.flag, err)
+		}
+	}
+
+	store, err := openKeystore(profile.Keystore, true)
+	if err != nil {
+		return err
+	}
+	wallets, err := bootstrap.StoreWallets(store, opts.Roles())
+	if err != nil {
+		return err
+	}
+	rpcClient := rpc.New(profile.RPC)
+	opts.Fees = fees.estimator(rpcClient)
+	d, err := bootstrap.Run(context.Background(), rpcClient, wallets, opts)
+	if err != nil {
+		return err
+	}
+	d.Record(profile)
+	if err := config.Save(*profilePath, profile); err != nil {
+		return err
+	}
+	fmt.Printf("Supernode %s deployed with mint %s (%d transactions), recorded in %s\n", d.Supernode, d.Mint, len(d.Signatures), *profilePath)
+	for _, role := range wallets.Roles() {
+		if key, ok := d.Wallets[role]; ok {
+			fmt.Printf("  %-14s %s\n", role, key)
+		}
+	}
+	return nil
+}
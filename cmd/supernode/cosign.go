@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/cosigner"
+)
+
+func runCosignServer(args []string) error {
+	fs := flag.NewFlagSet("cosign-server", flag.ExitOnError)
+	listen := fs.String("listen", "127.0.0.1:8899", "address to listen on")
+	rpcURL := fs.String("rpc", rpc.DevNet_RPC, "RPC endpoint")
+	programID := fs.String("program", defaultProgramID, "supernode program id")
+	key := addKeyFlags(fs, "admin")
+	keyPath := fs.String("admin-keypair", "", "admin keypair file (solana-keygen format), instead of the keystore")
+	policyPath := fs.String("policy", "policy.json", "co-signing policy file")
+	auditPath := fs.String("audit-log", "cosign-audit.log", "file the audit log is appended to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := setProgramID(*programID); err != nil {
+		return err
+	}
+	admin, err := key.load(*keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load admin key: %w", err)
+	}
+	policy, err := cosigner.LoadPolicy(context.Background(), rpc.New(*rpcURL), *policyPath)
+	if err != nil {
+		return err
+	}
+	auditFile, err := os.OpenFile(*auditPath, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+	defer auditFile.Close()
+
+	server, err := cosigner.NewServer(policy, admin, cosigner.NewAuditLog(auditFile))
+	if err != nil {
+		return err
+	}
+	// The claims approved before a restart still count against today's cap.
+	if err := server.Restore(auditFile); err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/cosign", server)
+
+	log.Printf("co-signing as %s on %s", admin.PublicKey(), *listen)
+	return http.ListenAndServe(*listen, mux)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/config"
+	"n3-solana-test/dynamic"
+	"n3-solana-test/idl"
+)
+
+func runDecode(args []string) error {
+	flags := flag.NewFlagSet("decode", flag.ExitOnError)
+	profilePath := flags.String("config", "supernode.json", "profile file")
+	rpcURL := flags.String("rpc", "", "RPC endpoint (overrides the profile)")
+	programID := flags.String("program", "", "supernode program id (overrides the profile)")
+	idlPath := flags.String("idl", "", "Anchor IDL file (default: the on-chain IDL, else the built-in one)")
+	signature := flags.String("tx", "", "signature of the transaction to decode")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	profile, err := config.Load(*profilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		profile = &config.Profile{RPC: rpc.DevNet_RPC, ProgramID: solana.MustPublicKeyFromBase58(defaultProgramID)}
+	} else if err != nil {
+		return err
+	}
+	if *rpcURL != "" {
+		profile.RPC = *rpcURL
+	}
+	if *programID != "" {
+		if profile.ProgramID, err = solana.PublicKeyFromBase58(*programID); err != nil {
+			return fmt.Errorf("invalid program id: %w", err)
+		}
+	}
+	sig, err := solana.SignatureFromBase58(*signature)
+	if err != nil {
+		return fmt.Errorf("invalid transaction signature: %w", err)
+	}
+	ctx := context.Background()
+	c := rpc.New(profile.RPC)
+
+	var program *idl.IDL
+	switch {
+	case *idlPath != "":
+		data, err := os.ReadFile(*idlPath)
+		if err != nil {
+			return err
+		}
+		if program, err = idl.Parse(data); err != nil {
+			return err
+		}
+	default:
+		program, err = dynamic.FetchIDL(ctx, c, profile.ProgramID)
+		if errors.Is(err, dynamic.ErrNoIDL) {
+			program, err = idl.Supernode(), nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	decoder := dynamic.New(program)
+
+	version := uint64(0)
+	result, err := c.GetTransaction(ctx, sig, &rpc.GetTransactionOpts{
+		Encoding:                       solana.EncodingBase64,
+		MaxSupportedTransactionVersion: &version,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch transaction %s: %w", sig, err)
+	}
+	tx, err := result.Transaction.GetTransaction()
+	if err != nil {
+		return err
+	}
+
+	type decodedInstruction struct {
+		Index int `json:"index"`
+		*dynamic.Instruction
+		Error string `json:"error,omitempty"`
+	}
+	out := struct {
+		IDL          string               `json:"idl"`
+		Instructions []decodedInstruction `json:"instructions"`
+		Events       []*dynamic.Value     `json:"events"`
+	}{IDL: program.Metadata.Name + " " + program.Metadata.Version}
+	for i, ins := range tx.Message.Instructions {
+		key, err := tx.Message.Program(ins.ProgramIDIndex)
+		if err != nil {
+			return err
+		}
+		if !key.Equals(profile.ProgramID) {
+			continue
+		}
+		// Accounts loaded through lookup tables are not resolved; such
+		// instructions are decoded without account names.
+		accounts, _ := ins.ResolveInstructionAccounts(&tx.Message)
+		decoded := decodedInstruction{Index: i}
+		if decoded.Instruction, err = decoder.DecodeInstruction(accounts, ins.Data); err != nil {
+			decoded.Error = err.Error()
+		}
+		out.Instructions = append(out.Instructions, decoded)
+	}
+	if result.Meta != nil {
+		if out.Events, err = decoder.DecodeEventLogs(result.Meta.LogMessages); err != nil {
+			return err
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
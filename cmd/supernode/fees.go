@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+
+	"n3-solana-test/priorityfee"
+	"n3-solana-test/rpcclient"
+)
+
+// feeFlags are the priority fee flags of the commands that send
+// transactions.
+type feeFlags struct {
+	enabled  *bool
+	minPrice *uint64
+	maxPrice *uint64
+	attempts *int
+}
+
+// addFeeFlags registers -priority-fee, -min-priority-fee, -max-priority-fee
+// and -max-attempts on fs.
+func addFeeFlags(fs *flag.FlagSet) feeFlags {
+	return feeFlags{
+		enabled:  fs.Bool("priority-fee", true, "price transactions from recent prioritization fees and rebroadcast them at a rising price until they land"),
+		minPrice: fs.Uint64("min-priority-fee", 0, "least compute unit price, in micro-lamports"),
+		maxPrice: fs.Uint64("max-priority-fee", 0, "greatest compute unit price, in micro-lamports (default: no limit)"),
+		attempts: fs.Int("max-attempts", 0, "broadcasts of a transaction before giving up (default 5)"),
+	}
+}
+
+// estimator returns the estimator the flags describe, nil when priority fees
+// are disabled.
+func (f feeFlags) estimator(rpcClient rpcclient.Client) *priorityfee.Estimator {
+	if !*f.enabled {
+		return nil
+	}
+	return priorityfee.New(rpcClient, priorityfee.Options{
+		MinPrice:    *f.minPrice,
+		MaxPrice:    *f.maxPrice,
+		MaxAttempts: *f.attempts,
+	})
+}
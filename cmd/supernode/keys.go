@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"golang.org/x/term"
+	"n3-solana-test/keystore"
+)
+
+// keyFlags are the -key and -keystore flags of the commands that sign.
+type keyFlags struct {
+	role     *string
+	keystore *string
+}
+
+// addKeyFlags registers -key, defaulting to role, and -keystore on fs.
+func addKeyFlags(fs *flag.FlagSet, role string) keyFlags {
+	return keyFlags{
+		role:     fs.String("key", role, "role of the signing key in the keystore"),
+		keystore: addKeystoreFlag(fs),
+	}
+}
+
+func addKeystoreFlag(fs *flag.FlagSet) *string {
+	return fs.String("keystore", "", "keystore file (default: $"+keystore.EnvPath+", else "+keystore.DefaultPath+")")
+}
+
+// load returns the key of the -key role, or the key of keypair, a file of
+// the Solana CLI, when it is set.
+func (k keyFlags) load(keypair string) (solana.PrivateKey, error) {
+	if keypair != "" {
+		return solana.PrivateKeyFromSolanaKeygenFile(keypair)
+	}
+	s, err := openKeystore(*k.keystore, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.Key(*k.role)
+}
+
+// keystorePath returns path, else $SUPERNODE_KEYSTORE, else the default.
+func keystorePath(path string) string {
+	if path != "" {
+		return path
+	}
+	if path = os.Getenv(keystore.EnvPath); path != "" {
+		return path
+	}
+	return keystore.DefaultPath
+}
+
+// openKeystore opens the keystore at keystorePath(path), unlocked when
+// unlock is set.
+func openKeystore(path string, unlock bool) (*keystore.Store, error) {
+	path = keystorePath(path)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no keystore at %s, create one with supernode keys init", path)
+	}
+	if !unlock {
+		return keystore.Open(path, nil)
+	}
+	passphrase, err := readPassphrase(fmt.Sprintf("Passphrase of %s: ", path))
+	if err != nil {
+		return nil, err
+	}
+	return keystore.Open(path, passphrase)
+}
+
+// readPassphrase returns $SUPERNODE_PASSPHRASE, else asks for it on the
+// terminal.
+func readPassphrase(prompt string) ([]byte, error) {
+	if p, ok := os.LookupEnv(keystore.EnvPassphrase); ok {
+		return []byte(p), nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("no terminal to read the passphrase from, set %s", keystore.EnvPassphrase)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	p, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	return p, err
+}
+
+// publicKeyOrRole parses value as a public key, else looks it up as a role
+// of the keystore at path, which does not need the passphrase.
+func publicKeyOrRole(value, path string) (solana.PublicKey, error) {
+	key, err := solana.PublicKeyFromBase58(value)
+	if err == nil || keystore.ValidRole(value) != nil {
+		return key, err
+	}
+	s, err := openKeystore(path, false)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	return s.PublicKey(value)
+}
+
+var keysCommands = map[string]func(args []string) error{
+	"init":     runKeysInit,
+	"new":      runKeysNew,
+	"list":     runKeysList,
+	"import":   runKeysImport,
+	"export":   runKeysExport,
+	"mnemonic": runKeysMnemonic,
+}
+
+func runKeys(args []string) error {
+	if len(args) == 0 || keysCommands[args[0]] == nil {
+		return errors.New("usage: supernode keys init|new|list|import|export|mnemonic [flags]")
+	}
+	return keysCommands[args[0]](args[1:])
+}
+
+func runKeysInit(args []string) error {
+	fs := flag.NewFlagSet("keys init", flag.ExitOnError)
+	path := addKeystoreFlag(fs)
+	mnemonic := fs.Bool("mnemonic", true, "derive the keys from a new BIP39 mnemonic")
+	restore := fs.Bool("restore", false, "derive the keys from a mnemonic read from stdin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var words string
+	var err error
+	switch {
+	case *restore:
+		fmt.Fprint(os.Stderr, "Mnemonic: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return err
+		}
+		words = strings.Join(strings.Fields(line), " ")
+	case *mnemonic:
+		if words, err = keystore.NewMnemonic(); err != nil {
+			return err
+		}
+	}
+	passphrase, err := readPassphrase("New passphrase: ")
+	if err != nil {
+		return err
+	}
+	if _, ok := os.LookupEnv(keystore.EnvPassphrase); !ok {
+		again, err := readPassphrase("Repeat passphrase: ")
+		if err != nil {
+			return err
+		}
+		if string(again) != string(passphrase) {
+			return errors.New("passphrases do not match")
+		}
+	}
+	s, err := keystore.Create(keystorePath(*path), passphrase, words, keystore.Options{})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Keystore created at %s\n", s.Path())
+	if words != "" && !*restore {
+		fmt.Printf("Write down the mnemonic, it restores every derived key:\n\n  %s\n", words)
+	}
+	return nil
+}
+
+func runKeysNew(args []string) error {
+	fs := flag.NewFlagSet("keys new", flag.ExitOnError)
+	path := addKeystoreFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: supernode keys new [flags] role...")
+	}
+	s, err := openKeystore(*path, true)
+	if err != nil {
+		return err
+	}
+	for _, role := range fs.Args() {
+		key, err := s.Generate(role)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-14s %s\n", role, key.PublicKey())
+	}
+	return nil
+}
+
+func runKeysList(args []string) error {
+	fs := flag.NewFlagSet("keys list", flag.ExitOnError)
+	path := addKeystoreFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s, err := openKeystore(*path, false)
+	if err != nil {
+		return err
+	}
+	for _, role := range s.Roles() {
+		key, _ := s.PublicKey(role)
+		derivation, _ := s.DerivationPath(role)
+		fmt.Printf("%-14s %-44s %s\n", role, key, derivation)
+	}
+	return nil
+}
+
+func runKeysImport(args []string) error {
+	fs := flag.NewFlagSet("keys import", flag.ExitOnError)
+	path := addKeystoreFlag(fs)
+	role := fs.String("key", "", "role to import the keypair as")
+	keypair := fs.String("keypair", "", "keypair file (solana-keygen format)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s, err := openKeystore(*path, true)
+	if err != nil {
+		return err
+	}
+	if err := s.Import(*role, *keypair); err != nil {
+		return err
+	}
+	key, _ := s.PublicKey(*role)
+	fmt.Printf("%-14s %s\n", *role, key)
+	return nil
+}
+
+func runKeysExport(args []string) error {
+	fs := flag.NewFlagSet("keys export", flag.ExitOnError)
+	path := addKeystoreFlag(fs)
+	role := fs.String("key", "", "role to export")
+	out := fs.String("out", "", "keypair file to write (solana-keygen format, default: <role>.json)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = *role + ".json"
+	}
+	s, err := openKeystore(*path, true)
+	if err != nil {
+		return err
+	}
+	if err := s.Export(*role, *out); err != nil {
+		return err
+	}
+	fmt.Printf("%s written to %s\n", *role, *out)
+	return nil
+}
+
+func runKeysMnemonic(args []string) error {
+	fs := flag.NewFlagSet("keys mnemonic", flag.ExitOnError)
+	path := addKeystoreFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s, err := openKeystore(*path, true)
+	if err != nil {
+		return err
+	}
+	m, err := s.Mnemonic()
+	if err != nil {
+		return err
+	}
+	fmt.Println(m)
+	return nil
+}
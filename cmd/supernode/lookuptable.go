@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/config"
+	"n3-solana-test/lookuptable"
+)
+
+func runLookupTable(args []string) error {
+	flags := flag.NewFlagSet("lookup-table", flag.ExitOnError)
+	profilePath := flags.String("config", "supernode.json", "profile file, updated with the table address")
+	rpcURL := flags.String("rpc", "", "RPC endpoint (overrides the profile)")
+	programID := flags.String("program", "", "supernode program id (overrides the profile)")
+	mint := flags.String("mint", "", "token mint (overrides the profile)")
+	key := addKeyFlags(flags, "admin")
+	keyPath := flags.String("keypair", "", "table authority and fee payer keypair file (solana-keygen format), instead of the keystore")
+	fees := addFeeFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	profile, err := config.Load(*profilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		profile = &config.Profile{RPC: rpc.DevNet_RPC, ProgramID: solana.MustPublicKeyFromBase58(defaultProgramID)}
+	} else if err != nil {
+		return err
+	}
+	if *rpcURL != "" {
+		profile.RPC = *rpcURL
+	}
+	if *programID != "" {
+		if profile.ProgramID, err = solana.PublicKeyFromBase58(*programID); err != nil {
+			return fmt.Errorf("invalid program id: %w", err)
+		}
+	}
+	if *mint != "" {
+		if profile.Mint, err = solana.PublicKeyFromBase58(*mint); err != nil {
+			return fmt.Errorf("invalid mint: %w", err)
+		}
+	}
+	if profile.Mint.IsZero() {
+		return fmt.Errorf("token mint is not set")
+	}
+	if err := setProgramID(profile.ProgramID.String()); err != nil {
+		return err
+	}
+	if *key.keystore == "" {
+		*key.keystore = profile.Keystore
+	}
+	authority, err := key.load(*keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load authority key: %w", err)
+	}
+
+	static, err := lookuptable.StaticAccounts(profile.Mint)
+	if err != nil {
+		return err
+	}
+	var table solana.PublicKey
+	if profile.LookupTable != nil {
+		table = *profile.LookupTable
+	}
+	rpcClient := rpc.New(profile.RPC)
+	save := func(table solana.PublicKey) error {
+		profile.LookupTable = &table
+		return config.Save(*profilePath, profile)
+	}
+	table, err = lookuptable.Ensure(context.Background(), rpcClient, authority, table, static, lookuptable.Options{
+		Fees: fees.estimator(rpcClient),
+		// A new table is recorded before it is extended, so a failed
+		// extension is resumed on the same table.
+		Created: save,
+	})
+	if err != nil {
+		return err
+	}
+	if err := save(table); err != nil {
+		return err
+	}
+	fmt.Printf("Lookup table %s holds %d static accounts, recorded in %s\n", table, len(static), *profilePath)
+	return nil
+}
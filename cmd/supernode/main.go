@@ -0,0 +1,67 @@
+// Command supernode bundles the operational tooling for a supernode deployment.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+	"n3-solana-test/client"
+)
+
+// defaultProgramID is the devnet supernode deployment.
+const defaultProgramID = "549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy"
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"bootstrap":     {"bring up a deployment: mint, funded wallets, initialized program", runBootstrap},
+	"cosign-server": {"serve the admin co-signing endpoint", runCosignServer},
+	"decode":        {"decode a transaction with a runtime or on-chain IDL", runDecode},
+	"keys":          {"manage the encrypted role keystore: init, new, list, import, export, mnemonic", runKeys},
+	"lookup-table":  {"create or extend the address lookup table of static accounts", runLookupTable},
+	"nonce-build":   {"build an unsigned durable-nonce policy update envelope", runNonceBuild},
+	"sign":          {"review and sign an envelope offline", runSign},
+	"submit":        {"submit a signed envelope", runSubmit},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: supernode <command> [flags]")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, commands[name].usage)
+	}
+}
+
+func setProgramID(programID string) error {
+	key, err := solana.PublicKeyFromBase58(programID)
+	if err != nil {
+		return fmt.Errorf("invalid program id: %w", err)
+	}
+	client.SetProgramID(key)
+	return nil
+}
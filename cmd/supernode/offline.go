@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/amount"
+	"n3-solana-test/offline"
+	"n3-solana-test/rpcclient"
+)
+
+func runNonceBuild(args []string) error {
+	fs := flag.NewFlagSet("nonce-build", flag.ExitOnError)
+	rpcURL := fs.String("rpc", rpc.DevNet_RPC, "RPC endpoint")
+	programID := fs.String("program", defaultProgramID, "supernode program id")
+	nonceAccount := fs.String("nonce-account", "", "durable nonce account")
+	feePayer := fs.String("fee-payer", "", "fee payer public key, or its role in the keystore")
+	admin := fs.String("admin", "admin", "admin public key, or its role in the keystore")
+	keystorePath := addKeystoreFlag(fs)
+	update := fs.String("update", "", "policy update: kvalue, staking-coefficient or reward-lock-time")
+	specID := fs.Uint("spec", 0, "spec id (kvalue only)")
+	value := fs.String("value", "", "new value; the staking coefficient is in tokens, such as 12.5")
+	decimals := fs.Int("decimals", -1, "decimals of the staking coefficient (default: the decimals of the on-chain policy)")
+	out := fs.String("out", "envelope.json", "envelope output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := setProgramID(*programID); err != nil {
+		return err
+	}
+	nonceKey, err := solana.PublicKeyFromBase58(*nonceAccount)
+	if err != nil {
+		return fmt.Errorf("invalid nonce account: %w", err)
+	}
+	payerKey, err := publicKeyOrRole(*feePayer, *keystorePath)
+	if err != nil {
+		return fmt.Errorf("invalid fee payer: %w", err)
+	}
+	adminKey, err := publicKeyOrRole(*admin, *keystorePath)
+	if err != nil {
+		return fmt.Errorf("invalid admin: %w", err)
+	}
+
+	ctx := context.Background()
+	c := rpc.New(*rpcURL)
+	var inst solana.Instruction
+	description := fmt.Sprintf("%s -> %s", *update, *value)
+	switch *update {
+	case "kvalue", "reward-lock-time":
+		n, err := strconv.ParseUint(*value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %q", *value)
+		}
+		if *update == "kvalue" {
+			if *specID > math.MaxUint16 {
+				return fmt.Errorf("invalid spec id %d: above %d", *specID, math.MaxUint16)
+			}
+			inst, err = offline.UpdateKValue(adminKey, uint16(*specID), n)
+		} else {
+			inst, err = offline.UpdateRewardLockTime(adminKey, n)
+		}
+		if err != nil {
+			return err
+		}
+	case "staking-coefficient":
+		coefficient, err := parseAmount(ctx, c, *value, *decimals)
+		if err != nil {
+			return err
+		}
+		if inst, err = offline.UpdateStakingCoefficient(adminKey, coefficient); err != nil {
+			return err
+		}
+		description = fmt.Sprintf("%s -> %s (%d units)", *update, coefficient, coefficient.Units())
+	default:
+		return fmt.Errorf("unknown update %q", *update)
+	}
+
+	e, err := offline.Build(ctx, c, nonceKey, payerKey, description, inst)
+	if err != nil {
+		return err
+	}
+	if err := offline.SaveEnvelope(*out, e); err != nil {
+		return err
+	}
+	fmt.Printf("Envelope written to %s\n", *out)
+	return nil
+}
+
+func runSign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	programID := fs.String("program", defaultProgramID, "supernode program id")
+	in := fs.String("in", "envelope.json", "envelope file, signed in place")
+	key := addKeyFlags(fs, "admin")
+	keyPath := fs.String("keypair", "", "signer keypair file (solana-keygen format), instead of the keystore")
+	yes := fs.Bool("yes", false, "sign without asking for confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := setProgramID(*programID); err != nil {
+		return err
+	}
+	e, err := offline.LoadEnvelope(*in)
+	if err != nil {
+		return err
+	}
+	if err := offline.Describe(e, os.Stdout); err != nil {
+		return err
+	}
+	signer, err := key.load(*keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to load signer key: %w", err)
+	}
+
+	if !*yes {
+		fmt.Printf("\nSign as %s? [y/N] ", signer.PublicKey())
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(strings.ToLower(answer)) != "y" {
+			return fmt.Errorf("aborted")
+		}
+	}
+	if err := offline.Sign(e, signer); err != nil {
+		return err
+	}
+	if err := offline.SaveEnvelope(*in, e); err != nil {
+		return err
+	}
+	fmt.Printf("Signed as %s\n", signer.PublicKey())
+	return nil
+}
+
+func runSubmit(args []string) error {
+	fs := flag.NewFlagSet("submit", flag.ExitOnError)
+	rpcURL := fs.String("rpc", rpc.DevNet_RPC, "RPC endpoint")
+	in := fs.String("in", "envelope.json", "signed envelope file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	e, err := offline.LoadEnvelope(*in)
+	if err != nil {
+		return err
+	}
+	sig, err := offline.Submit(context.Background(), rpc.New(*rpcURL), e)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Transaction sent! Signature: %s\n", sig)
+	return nil
+}
+
+// parseAmount parses a token amount with decimals, or with the decimals of
+// the on-chain policy when decimals is negative.
+func parseAmount(ctx context.Context, c rpcclient.Client, value string, decimals int) (amount.Amount, error) {
+	if decimals < 0 {
+		d, err := amount.PolicyDecimals(ctx, c)
+		if err != nil {
+			return amount.Amount{}, fmt.Errorf("failed to read the token decimals (set -decimals): %w", err)
+		}
+		decimals = int(d)
+	}
+	if decimals > amount.MaxDecimals {
+		return amount.Amount{}, fmt.Errorf("invalid decimals %d", decimals)
+	}
+	return amount.Parse(value, uint8(decimals))
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	ag_require "github.com/stretchr/testify/require"
+)
+
+// TestNonceBuild_SpecID rejects spec ids UpdateKValue cannot carry rather
+// than building the update for another spec.
+func TestNonceBuild_SpecID(t *testing.T) {
+	key := func() string { return solana.NewWallet().PublicKey().String() }
+	out := filepath.Join(t.TempDir(), "envelope.json")
+	for _, spec := range []string{"65536", "65537"} {
+		err := runNonceBuild([]string{
+			"-rpc", "http://127.0.0.1:0",
+			"-nonce-account", key(), "-fee-payer", key(), "-admin", key(),
+			"-update", "kvalue", "-spec", spec, "-value", "5", "-out", out,
+		})
+		ag_require.Error(t, err)
+		ag_require.Contains(t, err.Error(), "invalid spec id "+spec)
+	}
+	ag_require.NoFileExists(t, out)
+}
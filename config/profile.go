@@ -0,0 +1,50 @@
+// Package config persists the cluster-specific settings of a supernode
+// deployment shared by the supernode commands.
+package config
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Profile describes one supernode deployment.
+type Profile struct {
+	RPC       string           `json:"rpc"`
+	ProgramID solana.PublicKey `json:"programId"`
+	Admin     solana.PublicKey `json:"admin"`
+	Mint      solana.PublicKey `json:"mint"`
+	// Decimals are the decimals of the token mint.
+	Decimals uint8 `json:"decimals,omitempty"`
+	// Keystore is the keystore file holding the keys of the role wallets
+	// (see package keystore), and Wallets their public keys by role:
+	// "admin", "provider-0", ...
+	Keystore string                      `json:"keystore,omitempty"`
+	Wallets  map[string]solana.PublicKey `json:"wallets,omitempty"`
+	// LookupTable holds the deployment's static accounts, see package
+	// lookuptable. Nil until the table is created.
+	LookupTable *solana.PublicKey `json:"lookupTable,omitempty"`
+}
+
+// Load reads a profile from a JSON file.
+func Load(path string) (*Profile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Profile
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Save writes p to path as indented JSON.
+func Save(path string, p *Profile) error {
+	raw, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
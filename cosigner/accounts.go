@@ -0,0 +1,129 @@
+package cosigner
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"n3-solana-test/client"
+)
+
+// instructionAccounts lists the account names of every supernode instruction,
+// in the order they appear in the instruction's AccountMetaSlice.
+var instructionAccounts = map[string][]string{
+	"AddExtraController":       {"supernode", "provider_stake_info", "provider", "operator", "admin", "new_controller"},
+	"ClaimRentalFee":           {"supernode", "supernode_rental_account", "provider_stake_info", "provider_token_account", "token", "provider", "controller", "admin", "token_program", "system_program", "associated_token_program"},
+	"ClaimReward":              {"supernode", "supernode_reward_account", "provider_stake_info", "provider_token_account", "token", "provider", "controller", "admin", "token_program", "system_program", "associated_token_program"},
+	"InitRewardAccount":        {"supernode", "supernode_reward_account", "token", "admin", "token_program", "system_program", "associated_token_program"},
+	"Initialize":               {"supernode", "supernode_stake_account", "supernode_vesting_account", "token", "supernode_rental_account", "admin", "system_program", "token_program", "associated_token_program"},
+	"PayRentalFee":             {"supernode", "supernode_rental_account", "tenant_token_account", "tenant_info", "token", "tenant", "admin", "token_program", "system_program", "associated_token_program"},
+	"Releasable":               {"provider_vesting_info", "provider_stake_info", "provider", "controller", "token_program", "system_program", "associated_token_program"},
+	"Release":                  {"supernode", "supernode_stake_account", "provider_stake_info", "provider_vesting_info", "provider_token_account", "token", "provider", "controller", "admin", "token_program", "system_program", "associated_token_program"},
+	"RemoveExtraController":    {"supernode", "provider_stake_info", "provider", "operator", "admin", "old_controller"},
+	"ReplaceExtraController":   {"supernode", "provider_stake_info", "provider", "operator", "old_controller", "admin", "new_controller"},
+	"StakeDevice":              {"supernode", "supernode_stake_account", "provider_stake_info", "provider_token_account", "token", "provider", "controller", "admin", "token_program", "system_program", "associated_token_program"},
+	"UnstakeDevice":            {"supernode", "supernode_stake_account", "supernode_vesting_account", "provider_stake_info", "provider_vesting_info", "provider", "controller", "admin", "token_program", "system_program", "associated_token_program"},
+	"UpdateKValue":             {"supernode", "admin", "token_program", "system_program", "associated_token_program"},
+	"UpdateRewardLockTime":     {"supernode", "admin", "token_program", "system_program", "associated_token_program"},
+	"UpdateStakingCoefficient": {"supernode", "admin", "token_program", "system_program", "associated_token_program"},
+	"WithdrawRentalFee":        {"supernode", "supernode_rental_account", "tenant_token_account", "tenant_info", "token", "tenant", "admin", "token_program", "system_program", "associated_token_program"},
+}
+
+// staticAccounts holds the addresses that are the same for every instruction
+// of one supernode deployment.
+type staticAccounts struct {
+	byName map[string]solana.PublicKey
+}
+
+func newStaticAccounts(admin, mint solana.PublicKey) (*staticAccounts, error) {
+	b := client.NewInitializeInstructionBuilder()
+	supernode, _, err := b.FindSupernodeAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supernode PDA: %w", err)
+	}
+	stake, _, err := b.FindSupernodeStakeAccountAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supernode stake PDA: %w", err)
+	}
+	vesting, _, err := b.FindSupernodeVestingAccountAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supernode vesting PDA: %w", err)
+	}
+	rental, _, err := b.FindSupernodeRentalAccountAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supernode rental PDA: %w", err)
+	}
+	reward, _, err := client.NewInitRewardAccountInstructionBuilder().FindSupernodeRewardAccountAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supernode reward PDA: %w", err)
+	}
+	return &staticAccounts{byName: map[string]solana.PublicKey{
+		"supernode":                 supernode,
+		"supernode_stake_account":   stake,
+		"supernode_vesting_account": vesting,
+		"supernode_rental_account":  rental,
+		"supernode_reward_account":  reward,
+		"token":                     mint,
+		"admin":                     admin,
+		"token_program":             solana.TokenProgramID,
+		"system_program":            solana.SystemProgramID,
+		"associated_token_program":  solana.SPLAssociatedTokenAccountProgramID,
+	}}, nil
+}
+
+// checkAccounts verifies that every account of a decoded supernode instruction
+// is either a free role account (provider, controller, tenant, ...) or the
+// exact address derived for this deployment.
+func (s *staticAccounts) checkAccounts(name string, accounts []*solana.AccountMeta) error {
+	names, ok := instructionAccounts[name]
+	if !ok {
+		return fmt.Errorf("unknown instruction %q", name)
+	}
+	if len(accounts) != len(names) {
+		return fmt.Errorf("%s: expected %d accounts, got %d", name, len(names), len(accounts))
+	}
+	byName := make(map[string]solana.PublicKey, len(names))
+	for i, n := range names {
+		if accounts[i] == nil {
+			return fmt.Errorf("%s: account %s is not set", name, n)
+		}
+		byName[n] = accounts[i].PublicKey
+	}
+
+	mint := s.byName["token"]
+	for _, n := range names {
+		want, derived, err := s.expected(n, byName, mint)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if derived && !byName[n].Equals(want) {
+			return fmt.Errorf("%s: unexpected %s account %s, want %s", name, n, byName[n], want)
+		}
+	}
+	return nil
+}
+
+// expected returns the address an account must hold; derived is false for
+// role accounts that may hold any key.
+func (s *staticAccounts) expected(name string, byName map[string]solana.PublicKey, mint solana.PublicKey) (want solana.PublicKey, derived bool, err error) {
+	if key, ok := s.byName[name]; ok {
+		return key, true, nil
+	}
+	switch name {
+	case "provider_stake_info":
+		want, _, err = client.NewStakeDeviceInstructionBuilder().FindProviderStakeInfoAddress(byName["provider"])
+	case "provider_vesting_info":
+		want, _, err = client.NewUnstakeDeviceInstructionBuilder().FindProviderVestingInfoAddress(byName["provider"])
+	case "tenant_info":
+		want, _, err = client.NewPayRentalFeeInstructionBuilder().FindTenantInfoAddress(byName["tenant"])
+	case "provider_token_account":
+		want, _, err = solana.FindAssociatedTokenAddress(byName["provider"], mint)
+	case "tenant_token_account":
+		want, _, err = solana.FindAssociatedTokenAddress(byName["tenant"], mint)
+	default:
+		return solana.PublicKey{}, false, nil
+	}
+	if err != nil {
+		return solana.PublicKey{}, false, fmt.Errorf("failed to derive %s: %w", name, err)
+	}
+	return want, true, nil
+}
@@ -0,0 +1,40 @@
+package cosigner
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"n3-solana-test/amount"
+)
+
+// AuditEntry records one co-signing decision.
+type AuditEntry struct {
+	Time         time.Time `json:"time"`
+	Approved     bool      `json:"approved"`
+	Reason       string    `json:"reason,omitempty"`
+	FeePayer     string    `json:"feePayer,omitempty"`
+	Instructions []string  `json:"instructions,omitempty"`
+	Signature    string    `json:"signature,omitempty"`
+	// Claims are the ClaimReward amounts per provider an approved
+	// transaction commits to, which Server.Restore counts against the
+	// daily cap after a restart.
+	Claims map[string]amount.Amount `json:"claims,omitempty"`
+}
+
+// AuditLog appends one JSON line per decision to an io.Writer.
+type AuditLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{enc: json.NewEncoder(w)}
+}
+
+func (l *AuditLog) Record(entry AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.enc.Encode(entry)
+}
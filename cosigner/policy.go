@@ -0,0 +1,169 @@
+package cosigner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"os"
+
+	"github.com/gagliardetto/solana-go"
+	"n3-solana-test/amount"
+	"n3-solana-test/client"
+	"n3-solana-test/rpcclient"
+)
+
+// ErrRejected is wrapped by every error returned for a transaction that the
+// policy refuses to co-sign.
+var ErrRejected = errors.New("rejected by co-signing policy")
+
+// Policy decides which transactions the admin key may co-sign.
+type Policy struct {
+	// Admin is the key the server signs with.
+	Admin solana.PublicKey
+	// Mint is the supernode token mint.
+	Mint solana.PublicKey
+	// Instructions is the allow-list of instruction names, as returned by
+	// client.InstructionIDToName.
+	Instructions map[string]bool
+	// SpecIDs lists the device spec IDs StakeDevice may be co-signed for.
+	SpecIDs map[uint64]bool
+	// DailyClaimCap caps the ClaimReward amount per provider and UTC day,
+	// in the decimals of Mint. Zero disables ClaimReward entirely.
+	DailyClaimCap amount.Amount
+	// AllowAdminFeePayer permits transactions where the admin pays the fees.
+	AllowAdminFeePayer bool
+
+	accounts *staticAccounts
+}
+
+// PolicyConfig is the JSON form of a Policy. DailyClaimCap is in tokens of
+// the mint, such as "250.5"; empty means zero.
+type PolicyConfig struct {
+	Admin              string   `json:"admin"`
+	Mint               string   `json:"mint"`
+	Instructions       []string `json:"instructions"`
+	SpecIDs            []uint64 `json:"specIds"`
+	DailyClaimCap      string   `json:"dailyClaimCap"`
+	AllowAdminFeePayer bool     `json:"allowAdminFeePayer"`
+}
+
+// NewPolicy builds a policy for the deployment identified by client.ProgramID.
+func NewPolicy(admin, mint solana.PublicKey, instructions []string, specIDs []uint64, dailyClaimCap amount.Amount) (*Policy, error) {
+	accounts, err := newStaticAccounts(admin, mint)
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{
+		Admin:         admin,
+		Mint:          mint,
+		Instructions:  make(map[string]bool, len(instructions)),
+		SpecIDs:       make(map[uint64]bool, len(specIDs)),
+		DailyClaimCap: dailyClaimCap,
+		accounts:      accounts,
+	}
+	for _, name := range instructions {
+		if _, ok := instructionAccounts[name]; !ok {
+			return nil, fmt.Errorf("unknown instruction %q in policy", name)
+		}
+		p.Instructions[name] = true
+	}
+	for _, id := range specIDs {
+		p.SpecIDs[id] = true
+	}
+	return p, nil
+}
+
+// LoadPolicy reads a PolicyConfig JSON file, parsing the daily claim cap in
+// the decimals of the mint read through c.
+func LoadPolicy(ctx context.Context, c rpcclient.Client, path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg PolicyConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+	admin, err := solana.PublicKeyFromBase58(cfg.Admin)
+	if err != nil {
+		return nil, fmt.Errorf("invalid admin: %w", err)
+	}
+	mint, err := solana.PublicKeyFromBase58(cfg.Mint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mint: %w", err)
+	}
+	decimals, err := amount.MintDecimals(ctx, c, mint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the mint decimals: %w", err)
+	}
+	dailyClaimCap := amount.New(0, decimals)
+	if cfg.DailyClaimCap != "" {
+		if dailyClaimCap, err = amount.Parse(cfg.DailyClaimCap, decimals); err != nil {
+			return nil, fmt.Errorf("invalid dailyClaimCap: %w", err)
+		}
+	}
+	p, err := NewPolicy(admin, mint, cfg.Instructions, cfg.SpecIDs, dailyClaimCap)
+	if err != nil {
+		return nil, err
+	}
+	p.AllowAdminFeePayer = cfg.AllowAdminFeePayer
+	return p, nil
+}
+
+// Check decodes the transaction and verifies it against the policy. It
+// returns the names of the supernode instructions and the ClaimReward amounts
+// per provider that co-signing would commit to.
+func (p *Policy) Check(tx *solana.Transaction) (names []string, claims map[solana.PublicKey]uint64, err error) {
+	msg := &tx.Message
+	if !msg.IsSigner(p.Admin) {
+		return nil, nil, fmt.Errorf("%w: admin %s is not a signer", ErrRejected, p.Admin)
+	}
+	if !p.AllowAdminFeePayer && len(msg.AccountKeys) > 0 && msg.AccountKeys[0].Equals(p.Admin) {
+		return nil, nil, fmt.Errorf("%w: admin must not be the fee payer", ErrRejected)
+	}
+	for i, ins := range msg.Instructions {
+		programID, err := msg.Program(ins.ProgramIDIndex)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrRejected, err)
+		}
+		if !programID.Equals(client.ProgramID) && !programID.Equals(solana.ComputeBudget) {
+			return nil, nil, fmt.Errorf("%w: instruction %d targets program %s", ErrRejected, i, programID)
+		}
+	}
+
+	instructions, err := client.DecodeInstructions(msg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	if len(instructions) == 0 {
+		return nil, nil, fmt.Errorf("%w: no supernode instructions", ErrRejected)
+	}
+
+	claims = make(map[solana.PublicKey]uint64)
+	for _, inst := range instructions {
+		name := client.InstructionIDToName(inst.TypeID)
+		if !p.Instructions[name] {
+			return nil, nil, fmt.Errorf("%w: instruction %s is not allowed", ErrRejected, name)
+		}
+		if err := p.accounts.checkAccounts(name, inst.Accounts()); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrRejected, err)
+		}
+		switch impl := inst.Impl.(type) {
+		case *client.StakeDevice:
+			if !p.SpecIDs[*impl.SpecId] {
+				return nil, nil, fmt.Errorf("%w: spec id %d is not allowed", ErrRejected, *impl.SpecId)
+			}
+		case *client.ClaimReward:
+			provider := impl.GetProviderAccount().PublicKey
+			sum, carry := bits.Add64(claims[provider], *impl.Amount, 0)
+			if carry != 0 {
+				return nil, nil, fmt.Errorf("%w: claims of %s overflow", ErrRejected, provider)
+			}
+			claims[provider] = sum
+		}
+		names = append(names, name)
+	}
+	return names, claims, nil
+}
@@ -0,0 +1,202 @@
+package cosigner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"n3-solana-test/amount"
+)
+
+// Server co-signs partially signed supernode transactions as admin after
+// checking them against a Policy.
+type Server struct {
+	policy *Policy
+	admin  solana.PrivateKey
+	audit  *AuditLog
+
+	// Now returns the current time; it decides which day a claim counts for.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	claimed map[string]uint64 // provider/day -> claimed base units
+}
+
+// maxRequestSize bounds the body of a co-signing request, far above the
+// base64 form of the largest transaction.
+const maxRequestSize = 64 << 10
+
+func NewServer(policy *Policy, admin solana.PrivateKey, audit *AuditLog) (*Server, error) {
+	if !admin.PublicKey().Equals(policy.Admin) {
+		return nil, fmt.Errorf("admin key %s does not match policy admin %s", admin.PublicKey(), policy.Admin)
+	}
+	return &Server{
+		policy:  policy,
+		admin:   admin,
+		audit:   audit,
+		Now:     time.Now,
+		claimed: make(map[string]uint64),
+	}, nil
+}
+
+// Restore counts the claims approved in an audit log against the daily cap,
+// so a restarted server does not grant a provider's cap twice. The claimed
+// totals are otherwise kept in memory only.
+func (s *Server) Restore(auditLog io.Reader) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dec := json.NewDecoder(auditLog)
+	for {
+		var entry AuditEntry
+		if err := dec.Decode(&entry); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read audit log: %w", err)
+		}
+		if !entry.Approved {
+			continue
+		}
+		day := entry.Time.UTC().Format("2006-01-02")
+		for provider, claim := range entry.Claims {
+			if claim.Decimals() != s.policy.DailyClaimCap.Decimals() {
+				return fmt.Errorf("claim %s of %s has %d decimals, the mint %d: %w",
+					claim, provider, claim.Decimals(), s.policy.DailyClaimCap.Decimals(), amount.ErrDecimals)
+			}
+			key := provider + "/" + day
+			total, carry := bits.Add64(s.claimed[key], claim.Units(), 0)
+			if carry != 0 {
+				total = math.MaxUint64
+			}
+			s.claimed[key] = total
+		}
+	}
+}
+
+// CoSign checks the transaction and adds the admin signature to it.
+func (s *Server) CoSign(tx *solana.Transaction) (sig solana.Signature, err error) {
+	entry := AuditEntry{Time: s.Now().UTC()}
+	if len(tx.Message.AccountKeys) > 0 {
+		entry.FeePayer = tx.Message.AccountKeys[0].String()
+	}
+	defer func() {
+		entry.Approved = err == nil
+		if err != nil {
+			entry.Reason = err.Error()
+		} else {
+			entry.Signature = sig.String()
+		}
+		if auditErr := s.audit.Record(entry); auditErr != nil && err == nil {
+			err = fmt.Errorf("failed to write audit log: %w", auditErr)
+		}
+	}()
+
+	names, claims, err := s.policy.Check(tx)
+	entry.Instructions = names
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	day := entry.Time.Format("2006-01-02")
+	for provider, units := range claims {
+		key := provider.String() + "/" + day
+		if total := s.claimed[key] + units; total > s.policy.DailyClaimCap.Units() || total < units {
+			err = fmt.Errorf("%w: claim of %s for %s exceeds daily cap %s (already claimed %s)",
+				ErrRejected, s.tokens(units), provider, s.policy.DailyClaimCap, s.tokens(s.claimed[key]))
+			return
+		}
+	}
+
+	if _, err = tx.PartialSign(func(key solana.PublicKey) *solana.PrivateKey {
+		if key.Equals(s.admin.PublicKey()) {
+			return &s.admin
+		}
+		return nil
+	}); err != nil {
+		return
+	}
+	for provider, units := range claims {
+		s.claimed[provider.String()+"/"+day] += units
+		if entry.Claims == nil {
+			entry.Claims = make(map[string]amount.Amount, len(claims))
+		}
+		entry.Claims[provider.String()] = s.tokens(units)
+	}
+
+	for i, key := range tx.Message.Signers() {
+		if key.Equals(s.admin.PublicKey()) {
+			sig = tx.Signatures[i]
+		}
+	}
+	return
+}
+
+// tokens is units base units of the policy mint.
+func (s *Server) tokens(units uint64) amount.Amount {
+	return amount.New(units, s.policy.DailyClaimCap.Decimals())
+}
+
+type coSignRequest struct {
+	Transaction string `json:"transaction"`
+}
+
+type coSignResponse struct {
+	Transaction string `json:"transaction,omitempty"`
+	Signature   string `json:"signature,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ServeHTTP accepts POST requests with a base64 transaction and replies with
+// the co-signed transaction.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, coSignResponse{Error: "method not allowed"})
+		return
+	}
+	var req coSignRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeJSON(w, status, coSignResponse{Error: err.Error()})
+		return
+	}
+	tx, err := solana.TransactionFromBase64(req.Transaction)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, coSignResponse{Error: err.Error()})
+		return
+	}
+
+	sig, err := s.CoSign(tx)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrRejected) {
+			status = http.StatusForbidden
+		}
+		writeJSON(w, status, coSignResponse{Error: err.Error()})
+		return
+	}
+	out, err := tx.ToBase64()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, coSignResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, coSignResponse{Transaction: out, Signature: sig.String()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
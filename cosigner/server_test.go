@@ -0,0 +1,219 @@
+package cosigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/amount"
+	"n3-solana-test/client"
+	"n3-solana-test/simulator"
+)
+
+var (
+	testAdmin    = solana.NewWallet().PrivateKey
+	testProvider = solana.NewWallet().PrivateKey
+	testMint     = solana.NewWallet().PublicKey()
+)
+
+// testDecimals are the decimals of testMint; the daily cap of the test
+// policy is 100 base units, 1 token.
+const testDecimals = 2
+
+func init() {
+	client.SetProgramID(solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy"))
+}
+
+func newTestServer(t *testing.T) (*Server, *bytes.Buffer) {
+	policy, err := NewPolicy(testAdmin.PublicKey(), testMint, []string{"StakeDevice", "ClaimReward"}, []uint64{1}, amount.New(100, testDecimals))
+	ag_require.NoError(t, err)
+	audit := new(bytes.Buffer)
+	server, err := NewServer(policy, testAdmin, NewAuditLog(audit))
+	ag_require.NoError(t, err)
+	server.Now = func() time.Time { return time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC) }
+	return server, audit
+}
+
+func stakeDevice(t *testing.T, specID uint64) *client.StakeDevice {
+	b := client.NewStakeDeviceInstructionBuilder()
+	supernode, _, err := b.FindSupernodeAddress()
+	ag_require.NoError(t, err)
+	stake, _, err := b.FindSupernodeStakeAccountAddress()
+	ag_require.NoError(t, err)
+	stakeInfo, _, err := b.FindProviderStakeInfoAddress(testProvider.PublicKey())
+	ag_require.NoError(t, err)
+	ata, _, err := solana.FindAssociatedTokenAddress(testProvider.PublicKey(), testMint)
+	ag_require.NoError(t, err)
+	return b.SetDeviceId(7).SetSpecId(specID).
+		SetSupernodeAccount(supernode).
+		SetSupernodeStakeAccountAccount(stake).
+		SetProviderStakeInfoAccount(stakeInfo).
+		SetProviderTokenAccountAccount(ata).
+		SetTokenAccount(testMint).
+		SetProviderAccount(testProvider.PublicKey()).
+		SetControllerAccount(testProvider.PublicKey()).
+		SetAdminAccount(testAdmin.PublicKey())
+}
+
+func claimReward(t *testing.T, amount uint64) *client.ClaimReward {
+	b := client.NewClaimRewardInstructionBuilder()
+	supernode, _, err := b.FindSupernodeAddress()
+	ag_require.NoError(t, err)
+	reward, _, err := b.FindSupernodeRewardAccountAddress()
+	ag_require.NoError(t, err)
+	stakeInfo, _, err := b.FindProviderStakeInfoAddress(testProvider.PublicKey())
+	ag_require.NoError(t, err)
+	ata, _, err := solana.FindAssociatedTokenAddress(testProvider.PublicKey(), testMint)
+	ag_require.NoError(t, err)
+	return b.SetAmount(amount).
+		SetSupernodeAccount(supernode).
+		SetSupernodeRewardAccountAccount(reward).
+		SetProviderStakeInfoAccount(stakeInfo).
+		SetProviderTokenAccountAccount(ata).
+		SetTokenAccount(testMint).
+		SetProviderAccount(testProvider.PublicKey()).
+		SetControllerAccount(testProvider.PublicKey()).
+		SetAdminAccount(testAdmin.PublicKey())
+}
+
+func partiallySigned(t *testing.T, instructions ...solana.Instruction) *solana.Transaction {
+	tx, err := solana.NewTransaction(instructions, solana.Hash{1}, solana.TransactionPayer(testProvider.PublicKey()))
+	ag_require.NoError(t, err)
+	_, err = tx.PartialSign(func(key solana.PublicKey) *solana.PrivateKey {
+		if key.Equals(testProvider.PublicKey()) {
+			return &testProvider
+		}
+		return nil
+	})
+	ag_require.NoError(t, err)
+	return tx
+}
+
+func TestCoSign_StakeDevice(t *testing.T) {
+	server, audit := newTestServer(t)
+
+	tx := partiallySigned(t, stakeDevice(t, 1).Build())
+	_, err := server.CoSign(tx)
+	ag_require.NoError(t, err)
+	ag_require.NoError(t, tx.VerifySignatures())
+
+	var entry AuditEntry
+	ag_require.NoError(t, json.Unmarshal(audit.Bytes(), &entry))
+	ag_require.True(t, entry.Approved)
+	ag_require.Equal(t, []string{"StakeDevice"}, entry.Instructions)
+}
+
+func TestCoSign_Rejections(t *testing.T) {
+	wrongSupernode := stakeDevice(t, 1).SetSupernodeAccount(solana.NewWallet().PublicKey())
+	transfer := system.NewTransferInstruction(1, testAdmin.PublicKey(), testProvider.PublicKey()).Build()
+
+	cases := map[string]*solana.Transaction{
+		"unknown spec":      partiallySigned(t, stakeDevice(t, 2).Build()),
+		"foreign program":   partiallySigned(t, stakeDevice(t, 1).Build(), transfer),
+		"unexpected PDA":    partiallySigned(t, wrongSupernode.Build()),
+		"over daily cap":    partiallySigned(t, claimReward(t, 60).Build(), claimReward(t, 41).Build()),
+		"not allowed instr": partiallySigned(t, client.NewUpdateKValueInstructionBuilder().SetSpecId(1).SetVal(1).SetSupernodeAccount(solana.NewWallet().PublicKey()).SetAdminAccount(testAdmin.PublicKey()).Build()),
+	}
+	for name, tx := range cases {
+		t.Run(name, func(t *testing.T) {
+			server, audit := newTestServer(t)
+			_, err := server.CoSign(tx)
+			ag_require.ErrorIs(t, err, ErrRejected)
+			ag_require.Contains(t, audit.String(), `"approved":false`)
+		})
+	}
+}
+
+func TestCoSign_DailyClaimCap(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	_, err := server.CoSign(partiallySigned(t, claimReward(t, 60).Build()))
+	ag_require.NoError(t, err)
+	_, err = server.CoSign(partiallySigned(t, claimReward(t, 41).Build()))
+	ag_require.ErrorIs(t, err, ErrRejected)
+	ag_require.EqualError(t, err, fmt.Sprintf("%v: claim of 0.41 for %s exceeds daily cap 1 (already claimed 0.6)", ErrRejected, testProvider.PublicKey()))
+
+	server.Now = func() time.Time { return time.Date(2026, 1, 2, 0, 0, 1, 0, time.UTC) }
+	_, err = server.CoSign(partiallySigned(t, claimReward(t, 41).Build()))
+	ag_require.NoError(t, err)
+}
+
+// TestCoSign_ClaimOverflow sums the claims of one transaction without
+// wrapping around under the cap.
+func TestCoSign_ClaimOverflow(t *testing.T) {
+	server, _ := newTestServer(t)
+	_, err := server.CoSign(partiallySigned(t, claimReward(t, 1<<63).Build(), claimReward(t, 1<<63).Build()))
+	ag_require.ErrorIs(t, err, ErrRejected)
+	ag_require.Contains(t, err.Error(), "overflow")
+}
+
+// TestCoSign_Restore rebuilds the claimed totals from the audit log of a
+// previous server.
+func TestCoSign_Restore(t *testing.T) {
+	server, audit := newTestServer(t)
+	_, err := server.CoSign(partiallySigned(t, claimReward(t, 60).Build()))
+	ag_require.NoError(t, err)
+	_, err = server.CoSign(partiallySigned(t, claimReward(t, 50).Build()))
+	ag_require.ErrorIs(t, err, ErrRejected)
+
+	var entry AuditEntry
+	ag_require.NoError(t, json.NewDecoder(bytes.NewReader(audit.Bytes())).Decode(&entry))
+	ag_require.Equal(t, map[string]amount.Amount{testProvider.PublicKey().String(): amount.New(60, testDecimals)}, entry.Claims)
+	ag_require.Contains(t, audit.String(), `"ui":"0.6"`)
+
+	restarted, _ := newTestServer(t)
+	ag_require.NoError(t, restarted.Restore(bytes.NewReader(audit.Bytes())))
+	_, err = restarted.CoSign(partiallySigned(t, claimReward(t, 41).Build()))
+	ag_require.ErrorIs(t, err, ErrRejected)
+	_, err = restarted.CoSign(partiallySigned(t, claimReward(t, 40).Build()))
+	ag_require.NoError(t, err)
+
+	// A log written for a mint of other decimals does not count.
+	other, _ := newTestServer(t)
+	log := strings.ReplaceAll(audit.String(), `"decimals":2`, `"decimals":6`)
+	ag_require.ErrorIs(t, other.Restore(strings.NewReader(log)), amount.ErrDecimals)
+}
+
+func TestLoadPolicy(t *testing.T) {
+	ctx := context.Background()
+	sim := simulator.New(client.ProgramID)
+	buf := new(bytes.Buffer)
+	ag_require.NoError(t, ag_binary.NewBinEncoder(buf).Encode(token.Mint{Decimals: 6, IsInitialized: true}))
+	sim.SetAccount(testMint, &simulator.Account{Lamports: 1, Owner: solana.TokenProgramID, Data: buf.Bytes()})
+
+	load := func(dailyClaimCap string) (*Policy, error) {
+		path := filepath.Join(t.TempDir(), "policy.json")
+		cfg := fmt.Sprintf(`{"admin":%q,"mint":%q,"instructions":["ClaimReward"],"dailyClaimCap":%q}`, testAdmin.PublicKey(), testMint, dailyClaimCap)
+		ag_require.NoError(t, os.WriteFile(path, []byte(cfg), 0o600))
+		return LoadPolicy(ctx, sim, path)
+	}
+	p, err := load("250.5")
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, amount.New(250_500_000, 6), p.DailyClaimCap)
+	p, err = load("")
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, amount.New(0, 6), p.DailyClaimCap)
+	_, err = load("0.0000001")
+	ag_require.ErrorIs(t, err, amount.ErrPrecision)
+}
+
+func TestServeHTTP_RequestTooLarge(t *testing.T) {
+	server, _ := newTestServer(t)
+	body := `{"transaction":"` + strings.Repeat("A", maxRequestSize) + `"}`
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/cosign", strings.NewReader(body)))
+	ag_require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
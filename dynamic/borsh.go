@@ -0,0 +1,206 @@
+package dynamic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/gagliardetto/solana-go"
+	"n3-solana-test/idl"
+)
+
+// maxDepth bounds the nesting of defined types, so that a recursive IDL
+// cannot overflow the stack.
+const maxDepth = 32
+
+// reader decodes Borsh values described by IDL types.
+type reader struct {
+	idl   *idl.IDL
+	data  []byte
+	depth int
+}
+
+func (r *reader) next(n int) ([]byte, error) {
+	if n < 0 || n > len(r.data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b, nil
+}
+
+func (r *reader) fields(fields []idl.Field) (map[string]any, error) {
+	out := make(map[string]any, len(fields))
+	for _, f := range fields {
+		v, err := r.value(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		out[f.Name] = v
+	}
+	return out, nil
+}
+
+func (r *reader) typeDef(def *idl.TypeDef) (any, error) {
+	if r.depth++; r.depth > maxDepth {
+		return nil, fmt.Errorf("%s: types nested deeper than %d", def.Name, maxDepth)
+	}
+	defer func() { r.depth-- }()
+
+	switch def.Type.Kind {
+	case "struct":
+		return r.fields(def.Type.Fields)
+	case "enum":
+		b, err := r.next(1)
+		if err != nil {
+			return nil, err
+		}
+		if int(b[0]) >= len(def.Type.Variants) {
+			return nil, fmt.Errorf("%s: variant %d out of range", def.Name, b[0])
+		}
+		variant := def.Type.Variants[b[0]]
+		if len(variant.Fields) == 0 {
+			return variant.Name, nil
+		}
+		fields, err := r.fields(variant.Fields)
+		if err != nil {
+			return nil, fmt.Errorf("%s::%s: %w", def.Name, variant.Name, err)
+		}
+		return map[string]any{variant.Name: fields}, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported kind %q", def.Name, def.Type.Kind)
+}
+
+func (r *reader) value(t idl.Type) (any, error) {
+	switch {
+	case t.Primitive != "":
+		return r.primitive(t.Primitive)
+	case t.Vec != nil:
+		n, err := r.length()
+		if err != nil {
+			return nil, err
+		}
+		return r.sequence(*t.Vec, n)
+	case t.Array != nil:
+		return r.sequence(*t.Array, t.Len)
+	case t.Option != nil:
+		b, err := r.next(1)
+		if err != nil {
+			return nil, err
+		}
+		switch b[0] {
+		case 0:
+			return nil, nil
+		case 1:
+			return r.value(*t.Option)
+		}
+		return nil, fmt.Errorf("invalid option tag %d", b[0])
+	case t.Defined != "":
+		def, ok := r.idl.TypeDef(t.Defined)
+		if !ok {
+			return nil, fmt.Errorf("undefined type %s", t.Defined)
+		}
+		return r.typeDef(def)
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// length reads the u32 length of a vec, string or bytes, rejecting lengths
+// longer than the remaining data.
+func (r *reader) length() (int, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	n := binary.LittleEndian.Uint32(b)
+	if uint64(n) > uint64(len(r.data)) {
+		return 0, fmt.Errorf("length %d exceeds the %d remaining bytes", n, len(r.data))
+	}
+	return int(n), nil
+}
+
+func (r *reader) sequence(elem idl.Type, n int) ([]any, error) {
+	out := make([]any, 0, min(n, len(r.data)))
+	for i := 0; i < n; i++ {
+		v, err := r.value(elem)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+var primitiveSizes = map[string]int{
+	"bool": 1, "u8": 1, "i8": 1, "u16": 2, "i16": 2, "u32": 4, "i32": 4, "f32": 4,
+	"u64": 8, "i64": 8, "f64": 8, "u128": 16, "i128": 16, "pubkey": 32,
+}
+
+func (r *reader) primitive(name string) (any, error) {
+	switch name {
+	case "string", "bytes":
+		n, err := r.length()
+		if err != nil {
+			return nil, err
+		}
+		b, _ := r.next(n)
+		if name == "string" {
+			return string(b), nil
+		}
+		return append([]byte(nil), b...), nil
+	}
+	size, ok := primitiveSizes[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %q", name)
+	}
+	b, err := r.next(size)
+	if err != nil {
+		return nil, err
+	}
+	le := binary.LittleEndian
+	switch name {
+	case "bool":
+		if b[0] > 1 {
+			return nil, fmt.Errorf("invalid bool %d", b[0])
+		}
+		return b[0] == 1, nil
+	case "u8":
+		return b[0], nil
+	case "i8":
+		return int8(b[0]), nil
+	case "u16":
+		return le.Uint16(b), nil
+	case "i16":
+		return int16(le.Uint16(b)), nil
+	case "u32":
+		return le.Uint32(b), nil
+	case "i32":
+		return int32(le.Uint32(b)), nil
+	case "f32":
+		return math.Float32frombits(le.Uint32(b)), nil
+	case "u64":
+		return le.Uint64(b), nil
+	case "i64":
+		return int64(le.Uint64(b)), nil
+	case "f64":
+		return math.Float64frombits(le.Uint64(b)), nil
+	case "u128", "i128":
+		return int128(b, name == "i128"), nil
+	}
+	return solana.PublicKeyFromBytes(b), nil
+}
+
+// int128 converts 16 little-endian bytes to a big.Int.
+func int128(b []byte, signed bool) *big.Int {
+	be := make([]byte, 16)
+	for i := range b {
+		be[15-i] = b[i]
+	}
+	v := new(big.Int).SetBytes(be)
+	if signed && be[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+	return v
+}
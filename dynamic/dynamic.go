@@ -0,0 +1,176 @@
+// Package dynamic decodes supernode instructions, accounts and events with an
+// Anchor IDL loaded at runtime instead of the generated client types, for data
+// written under an IDL that is only known at runtime, such as transactions from
+// before a program upgrade. Values are decoded into generic trees:
+//
+//   - structs become map[string]any keyed by the IDL field names;
+//   - vecs and arrays become []any, options nil or their value;
+//   - enums become the variant name, or a one-entry map from the variant name
+//     to its fields;
+//   - u8 to u64 and i8 to i64 the Go integer of the same size, u128 and i128
+//     *big.Int, pubkey solana.PublicKey, bytes []byte.
+//
+// Instructions the IDL does not know are decoded with client.InstructionImplDef
+// and converted to the same tree.
+package dynamic
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"n3-solana-test/idl"
+)
+
+// ErrUnknownDiscriminator is returned for data whose discriminator neither
+// the IDL nor the generated client knows.
+var ErrUnknownDiscriminator = errors.New("unknown discriminator")
+
+// Decoder decodes the data of one program with its IDL.
+type Decoder struct {
+	idl          *idl.IDL
+	instructions map[idl.Discriminator]*idl.Instruction
+	accounts     map[idl.Discriminator]string
+	events       map[idl.Discriminator]string
+}
+
+// New returns a Decoder for program.
+func New(program *idl.IDL) *Decoder {
+	d := &Decoder{
+		idl:          program,
+		instructions: map[idl.Discriminator]*idl.Instruction{},
+		accounts:     map[idl.Discriminator]string{},
+		events:       map[idl.Discriminator]string{},
+	}
+	for i := range program.Instructions {
+		d.instructions[program.Instructions[i].Discriminator] = &program.Instructions[i]
+	}
+	for _, a := range program.Accounts {
+		d.accounts[a.Discriminator] = a.Name
+	}
+	for _, e := range program.Events {
+		d.events[e.Discriminator] = e.Name
+	}
+	return d
+}
+
+// IDL returns the IDL of d.
+func (d *Decoder) IDL() *idl.IDL {
+	return d.idl
+}
+
+// Instruction is a decoded instruction.
+type Instruction struct {
+	Name string         `json:"name"`
+	Args map[string]any `json:"args"`
+	// Accounts maps the IDL account names to the accounts the instruction
+	// was given; accounts beyond the IDL's are left out.
+	Accounts map[string]solana.PublicKey `json:"accounts"`
+	// Static is set when the instruction was decoded with the generated
+	// client rather than the IDL.
+	Static bool `json:"static,omitempty"`
+}
+
+// Value is a decoded account or event.
+type Value struct {
+	Name   string         `json:"name"`
+	Fields map[string]any `json:"fields"`
+}
+
+// DecodeInstruction decodes the data of an instruction given accounts, which
+// may be nil.
+func (d *Decoder) DecodeInstruction(accounts []*solana.AccountMeta, data []byte) (*Instruction, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("instruction data of %d bytes has no discriminator", len(data))
+	}
+	ix, ok := d.instructions[idl.Discriminator(data[:8])]
+	if !ok {
+		return decodeStatic(accounts, data)
+	}
+	r := &reader{idl: d.idl, data: data[8:]}
+	args, err := r.fields(ix.Args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode instruction %s: %w", ix.Name, err)
+	}
+	return &Instruction{Name: ix.Name, Args: args, Accounts: namedAccounts(ix, accounts)}, nil
+}
+
+func namedAccounts(ix *idl.Instruction, accounts []*solana.AccountMeta) map[string]solana.PublicKey {
+	out := map[string]solana.PublicKey{}
+	for i, a := range ix.Accounts {
+		if i < len(accounts) && accounts[i] != nil {
+			out[a.Name] = accounts[i].PublicKey
+		}
+	}
+	return out
+}
+
+// DecodeAccount decodes the data of a program account.
+func (d *Decoder) DecodeAccount(data []byte) (*Value, error) {
+	v, _, err := d.decodeDefinition("account", d.accounts, data)
+	return v, err
+}
+
+// DecodeAccountN is DecodeAccount that also returns the number of bytes the
+// layout spans, discriminator included; the rest of an account's data is
+// unused space.
+func (d *Decoder) DecodeAccountN(data []byte) (*Value, int, error) {
+	return d.decodeDefinition("account", d.accounts, data)
+}
+
+// DecodeEvent decodes the data of an event.
+func (d *Decoder) DecodeEvent(data []byte) (*Value, error) {
+	v, _, err := d.decodeDefinition("event", d.events, data)
+	return v, err
+}
+
+const eventLogPrefix = "Program data: "
+
+// DecodeEventLogs decodes the events logged in logs. Log lines of other
+// programs' events are skipped.
+func (d *Decoder) DecodeEventLogs(logs []string) (evts []*Value, err error) {
+	for _, log := range logs {
+		if !strings.HasPrefix(log, eventLogPrefix) {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(log[len(eventLogPrefix):])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode logMessage event: %w", err)
+		}
+		evt, err := d.DecodeEvent(data)
+		if errors.Is(err, ErrUnknownDiscriminator) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		evts = append(evts, evt)
+	}
+	return
+}
+
+func (d *Decoder) decodeDefinition(kind string, names map[idl.Discriminator]string, data []byte) (*Value, int, error) {
+	if len(data) < 8 {
+		return nil, 0, fmt.Errorf("%s data of %d bytes has no discriminator", kind, len(data))
+	}
+	name, ok := names[idl.Discriminator(data[:8])]
+	if !ok {
+		return nil, 0, fmt.Errorf("%s %v: %w", kind, data[:8], ErrUnknownDiscriminator)
+	}
+	def, ok := d.idl.TypeDef(name)
+	if !ok {
+		return nil, 0, fmt.Errorf("%s %s has no type definition", kind, name)
+	}
+	r := &reader{idl: d.idl, data: data[8:]}
+	v, err := r.typeDef(def)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode %s %s: %w", kind, name, err)
+	}
+	fields, ok := v.(map[string]any)
+	if !ok {
+		return nil, 0, fmt.Errorf("%s %s is not a struct", kind, name)
+	}
+	return &Value{Name: name, Fields: fields}, len(data) - len(r.data), nil
+}
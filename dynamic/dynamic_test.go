@@ -0,0 +1,232 @@
+package dynamic
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/client"
+	"n3-solana-test/idl"
+	"n3-solana-test/simulator"
+)
+
+const goldenDir = "../client/testdata/golden"
+
+var programID = solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy")
+
+func init() {
+	client.SetProgramID(programID)
+}
+
+// goldenCaptures returns the decoded captures of a golden corpus directory by
+// name.
+func goldenCaptures(t *testing.T, dir, ext string) map[string][]byte {
+	paths, err := filepath.Glob(filepath.Join(goldenDir, dir, "*"+ext))
+	ag_require.NoError(t, err)
+	ag_require.NotEmpty(t, paths)
+	out := map[string][]byte{}
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		ag_require.NoError(t, err)
+		capture := strings.TrimSpace(string(raw))
+		capture = strings.TrimPrefix(capture, eventLogPrefix)
+		data, err := base64.StdEncoding.DecodeString(capture)
+		ag_require.NoError(t, err)
+		out[strings.TrimSuffix(filepath.Base(path), ext)] = data
+	}
+	return out
+}
+
+func legacyIDL(t *testing.T) *idl.IDL {
+	raw, err := os.ReadFile("testdata/supernode_legacy.json")
+	ag_require.NoError(t, err)
+	program, err := idl.Parse(raw)
+	ag_require.NoError(t, err)
+	return program
+}
+
+func TestDecodeInstruction(t *testing.T) {
+	legacy := New(legacyIDL(t))
+	fallback := New(&idl.IDL{Metadata: idl.Metadata{Name: "supernode"}})
+	for name, data := range goldenCaptures(t, "instructions", ".b64") {
+		t.Run(name, func(t *testing.T) {
+			accounts := make([]*solana.AccountMeta, 12)
+			for i := range accounts {
+				accounts[i] = solana.Meta(solana.NewWallet().PublicKey())
+			}
+			got, err := New(idl.Supernode()).DecodeInstruction(accounts, data)
+			ag_require.NoError(t, err)
+			ag_require.False(t, got.Static)
+			ag_require.Equal(t, name, client.InstructionIDToName(ag_binary.TypeID(data[:8])))
+
+			static, err := fallback.DecodeInstruction(accounts, data)
+			ag_require.NoError(t, err)
+			ag_require.True(t, static.Static)
+			static.Static = false
+			ag_require.Equal(t, got, static)
+
+			old, err := legacy.DecodeInstruction(accounts, data)
+			ag_require.NoError(t, err)
+			ag_require.Equal(t, got, old)
+		})
+	}
+}
+
+func TestDecodeAccount(t *testing.T) {
+	static := map[string]interface {
+		UnmarshalWithDecoder(*ag_binary.Decoder) error
+	}{
+		"ProviderStakeInfoAccount":   new(client.ProviderStakeInfoAccount),
+		"ProviderVestingInfoAccount": new(client.ProviderVestingInfoAccount),
+		"SupernodeStateAccount":      new(client.SupernodeStateAccount),
+		"TenantInfoAccount":          new(client.TenantInfoAccount),
+	}
+	program := idl.Supernode()
+	legacy := New(legacyIDL(t))
+	for name, data := range goldenCaptures(t, "accounts", ".b64") {
+		t.Run(name, func(t *testing.T) {
+			got, err := New(program).DecodeAccount(data)
+			ag_require.NoError(t, err)
+			ag_require.Equal(t, strings.TrimSuffix(name, "Account"), got.Name)
+
+			want := static[name]
+			ag_require.NoError(t, want.UnmarshalWithDecoder(ag_binary.NewBorshDecoder(data)))
+			fields, err := staticValue(program, reflect.ValueOf(want), idl.Type{Defined: got.Name})
+			ag_require.NoError(t, err)
+			ag_require.Equal(t, fields, got.Fields)
+
+			old, err := legacy.DecodeAccount(data)
+			ag_require.NoError(t, err)
+			ag_require.Equal(t, got, old)
+
+			_, err = New(program).DecodeEvent(data)
+			ag_require.ErrorIs(t, err, ErrUnknownDiscriminator)
+		})
+	}
+}
+
+func TestDecodeEventLogs(t *testing.T) {
+	program := idl.Supernode()
+	logs := []string{"Program " + programID.String() + " invoke [1]"}
+	for _, data := range goldenCaptures(t, "events", ".log") {
+		logs = append(logs, "Program log: Instruction: X", eventLogPrefix+base64.StdEncoding.EncodeToString(data))
+	}
+	// Events of other programs are skipped.
+	memo := solana.MemoProgramID.String()
+	logs = append(logs,
+		"Program "+memo+" invoke [2]",
+		eventLogPrefix+base64.StdEncoding.EncodeToString(make([]byte, 16)),
+		"Program "+memo+" success",
+		"Program "+programID.String()+" success",
+	)
+
+	got, err := New(program).DecodeEventLogs(logs)
+	ag_require.NoError(t, err)
+	want, err := client.DecodeEvents(transactionWithLogs(t, logs), programID, nil)
+	ag_require.NoError(t, err)
+	ag_require.Len(t, got, len(want))
+	for i, evt := range want {
+		fields, err := staticValue(program, reflect.ValueOf(evt.Data), idl.Type{Defined: evt.Name})
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, &Value{Name: evt.Name, Fields: fields.(map[string]any)}, got[i])
+	}
+
+	old, err := New(legacyIDL(t)).DecodeEventLogs(logs)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, got, old)
+}
+
+func transactionWithLogs(t *testing.T, logs []string) *rpc.GetTransactionResult {
+	payer := solana.NewWallet().PublicKey()
+	tx, err := solana.NewTransaction([]solana.Instruction{
+		solana.NewInstruction(programID, solana.AccountMetaSlice{solana.Meta(payer).SIGNER()}, []byte{0}),
+	}, solana.Hash{}, solana.TransactionPayer(payer))
+	ag_require.NoError(t, err)
+	raw, err := tx.MarshalBinary()
+	ag_require.NoError(t, err)
+	encoded, err := json.Marshal([]string{base64.StdEncoding.EncodeToString(raw), "base64"})
+	ag_require.NoError(t, err)
+	envelope := new(rpc.TransactionResultEnvelope)
+	ag_require.NoError(t, json.Unmarshal(encoded, envelope))
+	return &rpc.GetTransactionResult{Transaction: envelope, Meta: &rpc.TransactionMeta{LogMessages: logs}}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	d := New(idl.Supernode())
+	for _, kind := range []struct {
+		dir, ext string
+		decode   func([]byte) error
+	}{
+		{"instructions", ".b64", func(data []byte) error { _, err := d.DecodeInstruction(nil, data); return err }},
+		{"accounts", ".b64", func(data []byte) error { _, err := d.DecodeAccount(data); return err }},
+		{"events", ".log", func(data []byte) error { _, err := d.DecodeEvent(data); return err }},
+	} {
+		for name, data := range goldenCaptures(t, kind.dir, kind.ext) {
+			for size := 0; size < len(data); size++ {
+				// Instructions without arguments decode from their discriminator.
+				if size == 8 && kind.dir == "instructions" {
+					continue
+				}
+				ag_require.Error(t, kind.decode(data[:size]), "%s/%s: %d of %d bytes", kind.dir, name, size, len(data))
+			}
+		}
+	}
+}
+
+func TestDecodeUnknown(t *testing.T) {
+	_, err := New(idl.Supernode()).DecodeInstruction(nil, []byte{1, 2, 3, 4, 5, 6, 7, 8})
+	ag_require.ErrorIs(t, err, ErrUnknownDiscriminator)
+	_, err = New(idl.Supernode()).DecodeAccount([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	ag_require.ErrorIs(t, err, ErrUnknownDiscriminator)
+}
+
+func TestLegacyIDL(t *testing.T) {
+	current, legacy := idl.Supernode(), legacyIDL(t)
+	ag_require.Equal(t, current.Address, legacy.Address)
+	ag_require.Equal(t, current.Metadata.Name, legacy.Metadata.Name)
+	ag_require.Len(t, legacy.Instructions, len(current.Instructions))
+	args := func(fields []idl.Field) []idl.Field {
+		if len(fields) == 0 {
+			return nil
+		}
+		return fields
+	}
+	for i, ix := range current.Instructions {
+		ag_require.Equal(t, ix.Name, legacy.Instructions[i].Name)
+		ag_require.Equal(t, ix.Discriminator, legacy.Instructions[i].Discriminator, ix.Name)
+		ag_require.Equal(t, args(ix.Args), args(legacy.Instructions[i].Args), ix.Name)
+	}
+	ag_require.ElementsMatch(t, current.Accounts, legacy.Accounts)
+	ag_require.ElementsMatch(t, current.Events, legacy.Events)
+}
+
+func TestFetchIDL(t *testing.T) {
+	sim := simulator.New(programID)
+	_, err := FetchIDL(context.Background(), sim, programID)
+	ag_require.ErrorIs(t, err, ErrNoIDL)
+
+	address, err := IDLAddress(programID)
+	ag_require.NoError(t, err)
+	data, err := EncodeIDLAccount(solana.NewWallet().PublicKey(), idl.SupernodeJSON())
+	ag_require.NoError(t, err)
+	sim.SetAccount(address, &simulator.Account{Lamports: 1, Owner: programID, Data: data})
+
+	got, err := FetchIDL(context.Background(), sim, programID)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, idl.Supernode(), got)
+
+	data[0] ^= 1
+	sim.SetAccount(address, &simulator.Account{Lamports: 1, Owner: programID, Data: data})
+	_, err = FetchIDL(context.Background(), sim, programID)
+	ag_require.Error(t, err)
+	ag_require.Contains(t, err.Error(), "not an IDL account")
+}
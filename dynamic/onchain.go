@@ -0,0 +1,112 @@
+package dynamic
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/idl"
+	"n3-solana-test/rpcclient"
+)
+
+// ErrNoIDL is returned by FetchIDL when the program has no IDL account.
+var ErrNoIDL = errors.New("program has no on-chain IDL")
+
+// idlAccountSeed is the seed Anchor derives the IDL account with.
+const idlAccountSeed = "anchor:idl"
+
+// maxIDLSize bounds the inflated IDL, so that a corrupt account cannot
+// exhaust memory.
+const maxIDLSize = 16 << 20
+
+var idlAccountDiscriminator = func() []byte {
+	sum := sha256.Sum256([]byte("account:IdlAccount"))
+	return sum[:8]
+}()
+
+// IDLAddress returns the address of the account `anchor idl init` stores the
+// IDL of programID in.
+func IDLAddress(programID solana.PublicKey) (solana.PublicKey, error) {
+	base, _, err := solana.FindProgramAddress(nil, programID)
+	if err != nil {
+		return solana.PublicKey{}, err
+	}
+	return solana.CreateWithSeed(base, idlAccountSeed, programID)
+}
+
+// FetchIDL reads the IDL Anchor stores on-chain for programID.
+func FetchIDL(ctx context.Context, c rpcclient.Client, programID solana.PublicKey) (*idl.IDL, error) {
+	address, err := IDLAddress(programID)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.GetAccountInfo(ctx, address)
+	if errors.Is(err, rpc.ErrNotFound) {
+		return nil, fmt.Errorf("%s: %w", programID, ErrNoIDL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch IDL account %s: %w", address, err)
+	}
+	if out == nil || out.Value == nil {
+		return nil, fmt.Errorf("%s: %w", programID, ErrNoIDL)
+	}
+	program, err := ParseIDLAccount(out.Value.Data.GetBinary())
+	if err != nil {
+		return nil, fmt.Errorf("IDL account %s: %w", address, err)
+	}
+	return program, nil
+}
+
+// ParseIDLAccount parses the data of an Anchor IDL account: the account
+// discriminator, the authority, and the zlib-compressed IDL JSON prefixed
+// with its u32 length.
+func ParseIDLAccount(data []byte) (*idl.IDL, error) {
+	const header = 8 + 32 + 4
+	if len(data) < header {
+		return nil, fmt.Errorf("IDL account of %d bytes is too short", len(data))
+	}
+	if !bytes.Equal(data[:8], idlAccountDiscriminator) {
+		return nil, fmt.Errorf("not an IDL account")
+	}
+	n := binary.LittleEndian.Uint32(data[40:header])
+	if uint64(n) > uint64(len(data)-header) {
+		return nil, fmt.Errorf("IDL length %d exceeds the account", n)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(data[header : header+int(n)]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to inflate IDL: %w", err)
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(io.LimitReader(zr, maxIDLSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to inflate IDL: %w", err)
+	}
+	if len(raw) > maxIDLSize {
+		return nil, fmt.Errorf("IDL exceeds %d bytes", maxIDLSize)
+	}
+	return idl.Parse(raw)
+}
+
+// EncodeIDLAccount lays out an IDL account holding the IDL JSON data, as
+// `anchor idl init` writes it.
+func EncodeIDLAccount(authority solana.PublicKey, data []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	out := append([]byte(nil), idlAccountDiscriminator...)
+	out = append(out, authority[:]...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(compressed.Len()))
+	return append(out, compressed.Bytes()...), nil
+}
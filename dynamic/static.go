@@ -0,0 +1,97 @@
+package dynamic
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"n3-solana-test/client"
+	"n3-solana-test/idl"
+)
+
+// staticIDL is the IDL the client was generated from.
+var staticIDL = sync.OnceValue(idl.Supernode)
+
+// decodeStatic decodes an instruction with client.InstructionImplDef and
+// converts it to the tree the IDL decoder produces. Names come from the IDL
+// the client was generated from, whose fields are in the order of the
+// generated structs.
+func decodeStatic(accounts []*solana.AccountMeta, data []byte) (*Instruction, error) {
+	program := staticIDL()
+	var ix *idl.Instruction
+	for i := range program.Instructions {
+		if program.Instructions[i].Discriminator == idl.Discriminator(data[:8]) {
+			ix = &program.Instructions[i]
+		}
+	}
+	if ix == nil {
+		return nil, fmt.Errorf("instruction %v: %w", data[:8], ErrUnknownDiscriminator)
+	}
+	inst := new(client.Instruction)
+	if err := inst.UnmarshalWithDecoder(ag_binary.NewBorshDecoder(data)); err != nil {
+		return nil, fmt.Errorf("failed to decode instruction %s: %w", ix.Name, err)
+	}
+
+	impl := reflect.Indirect(reflect.ValueOf(inst.Impl))
+	args := make(map[string]any, len(ix.Args))
+	for i, arg := range ix.Args {
+		v, err := staticValue(program, impl.Field(i), arg.Type)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %s: %s: %w", ix.Name, arg.Name, err)
+		}
+		args[arg.Name] = v
+	}
+	return &Instruction{Name: ix.Name, Args: args, Accounts: namedAccounts(ix, accounts), Static: true}, nil
+}
+
+func staticValue(program *idl.IDL, v reflect.Value, t idl.Type) (any, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	switch {
+	case t.Primitive != "":
+		switch x := v.Interface().(type) {
+		case ag_binary.Uint128:
+			return x.BigInt(), nil
+		case ag_binary.Int128:
+			return x.BigInt(), nil
+		case []byte:
+			return append([]byte(nil), x...), nil
+		}
+		return v.Interface(), nil
+	case t.Vec != nil, t.Array != nil:
+		elem := t.Vec
+		if elem == nil {
+			elem = t.Array
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			e, err := staticValue(program, v.Index(i), *elem)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = e
+		}
+		return out, nil
+	case t.Defined != "":
+		def, ok := program.TypeDef(t.Defined)
+		if !ok || def.Type.Kind != "struct" {
+			return nil, fmt.Errorf("unsupported type %s", t.Defined)
+		}
+		out := make(map[string]any, len(def.Type.Fields))
+		for i, f := range def.Type.Fields {
+			e, err := staticValue(program, v.Field(i), f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.Name, err)
+			}
+			out[f.Name] = e
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
@@ -0,0 +1,270 @@
+// Package idl models Anchor IDLs in the format Anchor 0.30 emits and embeds
+// the IDL of the supernode program. The client package is generated from it
+// by cmd/idlgen; after a program upgrade, replace supernode.json and run
+//
+//	go generate ./client
+package idl
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+//go:embed supernode.json
+var supernodeJSON []byte
+
+// Supernode returns the embedded IDL of the supernode program.
+func Supernode() *IDL {
+	idl, err := Parse(supernodeJSON)
+	if err != nil {
+		panic(err)
+	}
+	return idl
+}
+
+// SupernodeJSON returns the embedded IDL of the supernode program as checked
+// in.
+func SupernodeJSON() []byte {
+	return append([]byte(nil), supernodeJSON...)
+}
+
+// Parse parses an Anchor IDL. IDLs in the format of Anchor before 0.30 are
+// converted, with their names in snake_case and discriminators computed.
+func Parse(data []byte) (*IDL, error) {
+	var probe struct {
+		Name     string `json:"name"`
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse IDL: %w", err)
+	}
+	if probe.Metadata.Name == "" && probe.Name != "" {
+		idl, err := parseLegacy(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse legacy IDL: %w", err)
+		}
+		return idl, nil
+	}
+	idl := new(IDL)
+	if err := json.Unmarshal(data, idl); err != nil {
+		return nil, fmt.Errorf("failed to parse IDL: %w", err)
+	}
+	if idl.Metadata.Name == "" {
+		return nil, fmt.Errorf("failed to parse IDL: missing metadata.name")
+	}
+	return idl, nil
+}
+
+// Hash identifies the contents of program regardless of the formatting of
+// the JSON it was parsed from. It panics if program holds an empty Type.
+func Hash(program *IDL) [32]byte {
+	data, err := json.Marshal(program)
+	if err != nil {
+		panic(err)
+	}
+	return sha256.Sum256(data)
+}
+
+// IDL is an Anchor program interface.
+type IDL struct {
+	Address      string        `json:"address"`
+	Metadata     Metadata      `json:"metadata"`
+	Instructions []Instruction `json:"instructions"`
+	Accounts     []Definition  `json:"accounts,omitempty"`
+	Events       []Definition  `json:"events,omitempty"`
+	Errors       []Error       `json:"errors,omitempty"`
+	Types        []TypeDef     `json:"types,omitempty"`
+}
+
+// Metadata names and versions the program.
+type Metadata struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Spec        string `json:"spec"`
+	Description string `json:"description,omitempty"`
+}
+
+// Discriminator is the 8-byte prefix of instruction, account and event data.
+type Discriminator [8]byte
+
+// Instruction is a program instruction.
+type Instruction struct {
+	Name          string               `json:"name"`
+	Discriminator Discriminator        `json:"discriminator"`
+	Accounts      []InstructionAccount `json:"accounts"`
+	Args          []Field              `json:"args"`
+}
+
+// InstructionAccount is an account an instruction takes. Address is set for
+// accounts with a fixed address, PDA for program derived ones.
+type InstructionAccount struct {
+	Name     string `json:"name"`
+	Writable bool   `json:"writable,omitempty"`
+	Signer   bool   `json:"signer,omitempty"`
+	Address  string `json:"address,omitempty"`
+	PDA      *PDA   `json:"pda,omitempty"`
+}
+
+// PDA lists the seeds a program derived address is found with.
+type PDA struct {
+	Seeds []Seed `json:"seeds"`
+}
+
+// Seed is a PDA seed: a constant ("const") or the address of another account
+// of the instruction ("account").
+type Seed struct {
+	Kind  string `json:"kind"`
+	Value Bytes  `json:"value,omitempty"`
+	Path  string `json:"path,omitempty"`
+}
+
+// Bytes is a byte string written as a JSON array of numbers.
+type Bytes []byte
+
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	out := make([]int, len(b))
+	for i, v := range b {
+		out[i] = int(v)
+	}
+	return json.Marshal(out)
+}
+
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var in []uint8
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*b = in
+	return nil
+}
+
+// Definition names an account or event type and its discriminator. The
+// layout is the TypeDef of the same name.
+type Definition struct {
+	Name          string        `json:"name"`
+	Discriminator Discriminator `json:"discriminator"`
+}
+
+// Error is a custom program error.
+type Error struct {
+	Code int    `json:"code"`
+	Name string `json:"name"`
+	Msg  string `json:"msg,omitempty"`
+}
+
+// Field is a named struct field or instruction argument.
+type Field struct {
+	Name string `json:"name"`
+	Type Type   `json:"type"`
+}
+
+// TypeDef is a user defined type.
+type TypeDef struct {
+	Name string      `json:"name"`
+	Type TypeDefKind `json:"type"`
+}
+
+// TypeDefKind is the layout of a user defined type: a struct with Fields or
+// an enum with Variants.
+type TypeDefKind struct {
+	Kind     string    `json:"kind"`
+	Fields   []Field   `json:"fields,omitempty"`
+	Variants []Variant `json:"variants,omitempty"`
+}
+
+// Variant is an enum variant, optionally carrying named fields.
+type Variant struct {
+	Name   string  `json:"name"`
+	Fields []Field `json:"fields,omitempty"`
+}
+
+// Type is a field type: a primitive such as "u64" or "pubkey", or a vec,
+// option, fixed array or user defined type.
+type Type struct {
+	Primitive string
+	Vec       *Type
+	Option    *Type
+	Array     *Type
+	Len       int
+	Defined   string
+}
+
+func (t Type) MarshalJSON() ([]byte, error) {
+	switch {
+	case t.Primitive != "":
+		return json.Marshal(t.Primitive)
+	case t.Vec != nil:
+		return json.Marshal(map[string]*Type{"vec": t.Vec})
+	case t.Option != nil:
+		return json.Marshal(map[string]*Type{"option": t.Option})
+	case t.Array != nil:
+		return json.Marshal(map[string][]interface{}{"array": {t.Array, t.Len}})
+	case t.Defined != "":
+		return json.Marshal(map[string]map[string]string{"defined": {"name": t.Defined}})
+	}
+	return nil, fmt.Errorf("empty IDL type")
+}
+
+func (t *Type) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &t.Primitive); err == nil {
+		return nil
+	}
+	var obj struct {
+		Vec     *Type             `json:"vec"`
+		Option  *Type             `json:"option"`
+		Array   []json.RawMessage `json:"array"`
+		Defined *struct {
+			Name string `json:"name"`
+		} `json:"defined"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	switch {
+	case obj.Vec != nil:
+		t.Vec = obj.Vec
+	case obj.Option != nil:
+		t.Option = obj.Option
+	case len(obj.Array) == 2:
+		t.Array = new(Type)
+		if err := json.Unmarshal(obj.Array[0], t.Array); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(obj.Array[1], &t.Len); err != nil {
+			return err
+		}
+	case obj.Defined != nil:
+		t.Defined = obj.Defined.Name
+	default:
+		return fmt.Errorf("unsupported IDL type %s", data)
+	}
+	return nil
+}
+
+func (t Type) String() string {
+	switch {
+	case t.Primitive != "":
+		return t.Primitive
+	case t.Vec != nil:
+		return "vec<" + t.Vec.String() + ">"
+	case t.Option != nil:
+		return "option<" + t.Option.String() + ">"
+	case t.Array != nil:
+		return fmt.Sprintf("[%s; %d]", t.Array, t.Len)
+	}
+	return t.Defined
+}
+
+// TypeDef returns the user defined type called name.
+func (idl *IDL) TypeDef(name string) (*TypeDef, bool) {
+	for i := range idl.Types {
+		if idl.Types[i].Name == name {
+			return &idl.Types[i], true
+		}
+	}
+	return nil, false
+}
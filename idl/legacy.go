@@ -0,0 +1,212 @@
+package idl
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// legacyIDL is the IDL format of Anchor before 0.30, which on-chain IDLs and
+// IDLs of old deployments still use: camelCase names, no discriminators and
+// account and event layouts declared inline.
+type legacyIDL struct {
+	Version      string `json:"version"`
+	Name         string `json:"name"`
+	Instructions []struct {
+		Name     string          `json:"name"`
+		Accounts []legacyAccount `json:"accounts"`
+		Args     []legacyField   `json:"args"`
+	} `json:"instructions"`
+	Accounts []struct {
+		Name string           `json:"name"`
+		Type legacyTypeDefine `json:"type"`
+	} `json:"accounts"`
+	Events []struct {
+		Name   string        `json:"name"`
+		Fields []legacyField `json:"fields"`
+	} `json:"events"`
+	Errors []Error `json:"errors"`
+	Types  []struct {
+		Name string           `json:"name"`
+		Type legacyTypeDefine `json:"type"`
+	} `json:"types"`
+	Metadata struct {
+		Address string `json:"address"`
+	} `json:"metadata"`
+}
+
+type legacyAccount struct {
+	Name     string          `json:"name"`
+	IsMut    bool            `json:"isMut"`
+	IsSigner bool            `json:"isSigner"`
+	Accounts []legacyAccount `json:"accounts"`
+}
+
+type legacyField struct {
+	Name string          `json:"name"`
+	Type json.RawMessage `json:"type"`
+}
+
+type legacyTypeDefine struct {
+	Kind     string        `json:"kind"`
+	Fields   []legacyField `json:"fields"`
+	Variants []struct {
+		Name   string        `json:"name"`
+		Fields []legacyField `json:"fields"`
+	} `json:"variants"`
+}
+
+// parseLegacy converts a pre-0.30 IDL. Names are converted to snake_case as
+// Anchor 0.30 does, and discriminators are computed from them.
+func parseLegacy(data []byte) (*IDL, error) {
+	var legacy legacyIDL
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return nil, err
+	}
+	out := &IDL{
+		Address:  legacy.Metadata.Address,
+		Metadata: Metadata{Name: snake(legacy.Name), Version: legacy.Version},
+		Errors:   legacy.Errors,
+	}
+	for _, ix := range legacy.Instructions {
+		name := snake(ix.Name)
+		inst := Instruction{Name: name, Discriminator: discriminator("global:" + name)}
+		inst.Accounts = flattenAccounts(ix.Accounts)
+		args, err := legacyFields(ix.Args)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %s: %w", ix.Name, err)
+		}
+		inst.Args = args
+		out.Instructions = append(out.Instructions, inst)
+	}
+	for _, t := range legacy.Types {
+		def, err := legacyTypeDef(t.Name, t.Type)
+		if err != nil {
+			return nil, err
+		}
+		out.Types = append(out.Types, def)
+	}
+	for _, a := range legacy.Accounts {
+		def, err := legacyTypeDef(a.Name, a.Type)
+		if err != nil {
+			return nil, err
+		}
+		out.Accounts = append(out.Accounts, Definition{Name: a.Name, Discriminator: discriminator("account:" + a.Name)})
+		out.Types = append(out.Types, def)
+	}
+	for _, e := range legacy.Events {
+		fields, err := legacyFields(e.Fields)
+		if err != nil {
+			return nil, fmt.Errorf("event %s: %w", e.Name, err)
+		}
+		out.Events = append(out.Events, Definition{Name: e.Name, Discriminator: discriminator("event:" + e.Name)})
+		out.Types = append(out.Types, TypeDef{Name: e.Name, Type: TypeDefKind{Kind: "struct", Fields: fields}})
+	}
+	return out, nil
+}
+
+func flattenAccounts(accounts []legacyAccount) (out []InstructionAccount) {
+	for _, a := range accounts {
+		if len(a.Accounts) > 0 {
+			out = append(out, flattenAccounts(a.Accounts)...)
+			continue
+		}
+		out = append(out, InstructionAccount{Name: snake(a.Name), Writable: a.IsMut, Signer: a.IsSigner})
+	}
+	return
+}
+
+func legacyTypeDef(name string, t legacyTypeDefine) (TypeDef, error) {
+	def := TypeDef{Name: name, Type: TypeDefKind{Kind: t.Kind}}
+	fields, err := legacyFields(t.Fields)
+	if err != nil {
+		return def, fmt.Errorf("type %s: %w", name, err)
+	}
+	def.Type.Fields = fields
+	for _, v := range t.Variants {
+		fields, err := legacyFields(v.Fields)
+		if err != nil {
+			return def, fmt.Errorf("type %s: %w", name, err)
+		}
+		def.Type.Variants = append(def.Type.Variants, Variant{Name: v.Name, Fields: fields})
+	}
+	return def, nil
+}
+
+func legacyFields(fields []legacyField) (out []Field, err error) {
+	for _, f := range fields {
+		t, err := legacyType(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		out = append(out, Field{Name: snake(f.Name), Type: t})
+	}
+	return
+}
+
+// legacyType parses a pre-0.30 type, which spells pubkey "publicKey" and
+// names defined types without the {"name": ...} wrapper.
+func legacyType(data json.RawMessage) (Type, error) {
+	var primitive string
+	if err := json.Unmarshal(data, &primitive); err == nil {
+		if primitive == "publicKey" {
+			primitive = "pubkey"
+		}
+		return Type{Primitive: primitive}, nil
+	}
+	var obj struct {
+		Vec     json.RawMessage   `json:"vec"`
+		Option  json.RawMessage   `json:"option"`
+		Array   []json.RawMessage `json:"array"`
+		Defined *string           `json:"defined"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return Type{}, err
+	}
+	elem := func(raw json.RawMessage) (*Type, error) {
+		t, err := legacyType(raw)
+		return &t, err
+	}
+	var (
+		t   Type
+		err error
+	)
+	switch {
+	case obj.Vec != nil:
+		t.Vec, err = elem(obj.Vec)
+	case obj.Option != nil:
+		t.Option, err = elem(obj.Option)
+	case len(obj.Array) == 2:
+		if t.Array, err = elem(obj.Array[0]); err == nil {
+			err = json.Unmarshal(obj.Array[1], &t.Len)
+		}
+	case obj.Defined != nil:
+		t.Defined = *obj.Defined
+	default:
+		err = fmt.Errorf("unsupported IDL type %s", data)
+	}
+	return t, err
+}
+
+func discriminator(preimage string) (d Discriminator) {
+	sum := sha256.Sum256([]byte(preimage))
+	copy(d[:], sum[:8])
+	return
+}
+
+// snake converts a camelCase legacy name to snake_case.
+func snake(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,546 @@
+// Package idlgen generates the Go client of an Anchor program from its IDL:
+// one file per instruction with its builder, PDA finders, validation and
+// Borsh codec, a round-trip test per instruction, and the accounts, types,
+// events, errors and instruction registry shared by all of them. The output
+// is gofmt'd and deterministic, so a checked-in client can be compared with a
+// fresh generation to detect drift from the IDL.
+package idlgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"n3-solana-test/idl"
+)
+
+// Header is the first line of every generated file.
+const Header = "// Code generated by n3-solana-test/cmd/idlgen. DO NOT EDIT."
+
+// Options configure Generate.
+type Options struct {
+	// Package is the package name of the generated files, "client" if empty.
+	Package string
+	// Amounts names the u64 fields holding token amounts, which the JSON of
+	// a value wrapped by WithAmountDecimals complements with their decimal
+	// value.
+	// A name is an IDL field name, such as "amount", or one qualified by its
+	// type, such as "TenantInfo.funds".
+	Amounts []string
+	// NonZero names the integer instruction arguments the program rejects
+	// when zero, which ValidateDeep checks. A name is an IDL argument name,
+	// such as "amount", or one qualified by its instruction, such as
+	// "update_staking_coefficient.val".
+	NonZero []string
+}
+
+// Generate renders the client of program as a map from file name to
+// contents.
+func Generate(program *idl.IDL, opts Options) (map[string][]byte, error) {
+	if opts.Package == "" {
+		opts.Package = "client"
+	}
+	g := &generator{idl: program, opts: opts, amounts: map[string]bool{}, nonZero: map[string]bool{}, files: map[string][]byte{}}
+	for _, name := range opts.Amounts {
+		g.amounts[name] = true
+	}
+	for _, name := range opts.NonZero {
+		g.nonZero[name] = true
+	}
+	if err := g.run(); err != nil {
+		return nil, err
+	}
+	return g.files, nil
+}
+
+// IsGenerated reports whether src is a file Generate writes.
+func IsGenerated(src []byte) bool {
+	return bytes.HasPrefix(src, []byte(Header+"\n"))
+}
+
+type generator struct {
+	idl     *idl.IDL
+	opts    Options
+	amounts map[string]bool
+	nonZero map[string]bool
+	files   map[string][]byte
+}
+
+func (g *generator) run() error {
+	var instructions []*instruction
+	addresses := map[string]bool{}
+	for _, ix := range g.idl.Instructions {
+		inst, err := g.instruction(ix)
+		if err != nil {
+			return fmt.Errorf("instruction %s: %w", ix.Name, err)
+		}
+		for _, account := range inst.Accounts {
+			if account.Address != "" {
+				addresses[account.Address] = true
+			}
+		}
+		instructions = append(instructions, inst)
+	}
+	sort.Slice(instructions, func(i, j int) bool { return instructions[i].Raw < instructions[j].Raw })
+
+	types, err := g.structs(g.idl.Types, "")
+	if err != nil {
+		return err
+	}
+	accounts, err := g.definitions(g.idl.Accounts, "Account", "account")
+	if err != nil {
+		return fmt.Errorf("accounts: %w", err)
+	}
+	events, err := g.definitions(g.idl.Events, "EventData", "event")
+	if err != nil {
+		return fmt.Errorf("events: %w", err)
+	}
+	var addressList []string
+	for address := range addresses {
+		addressList = append(addressList, address)
+	}
+	sort.Strings(addressList)
+
+	for _, inst := range instructions {
+		name := strings.ToLower(inst.Name)
+		if err := g.render(name+".go", "instruction", inst); err != nil {
+			return err
+		}
+		if err := g.render(name+"_test.go", "instructionTest", inst); err != nil {
+			return err
+		}
+	}
+	data := map[string]interface{}{
+		"ProgramName":  camel(g.idl.Metadata.Name),
+		"Instructions": instructions,
+		"Types":        types,
+		"Accounts":     accounts,
+		"Events":       events,
+		"Errors":       g.idl.Errors,
+		"Addresses":    addressList,
+	}
+	for file, tmpl := range map[string]string{
+		"instructions.go":  "instructions",
+		"types.go":         "types",
+		"accounts.go":      "accounts",
+		"events.go":        "events",
+		"errors.go":        "errors",
+		"addresses.go":     "addresses",
+		"constants.go":     "constants",
+		"testing_utils.go": "testingUtils",
+	} {
+		if err := g.render(file, tmpl, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) render(file, name string, data interface{}) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "%s\n\npackage %s\n", Header, g.opts.Package)
+	if err := templates.ExecuteTemplate(buf, name, data); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s: %w\n%s", file, err, buf.Bytes())
+	}
+	g.files[file] = src
+	return nil
+}
+
+// instruction is the template data of an instruction.
+type instruction struct {
+	Name          string
+	Raw           string
+	Discriminator string
+	Args          []*field
+	Accounts      []*account
+	// ArgWidth and AccountWidth align the labels of EncodeToTree.
+	ArgWidth     int
+	AccountWidth int
+}
+
+type account struct {
+	Index   int
+	Name    string
+	Raw     string
+	Param   string
+	Label   string
+	Flags   string
+	Meta    string
+	Address string
+	Seeds   []seed
+	// Paths are the accounts the PDA is derived from, as Go parameters, and
+	// PathIndexes their indexes in the instruction.
+	Paths        []string
+	PathIndexes  []int
+	pathAccounts []string
+	// Signer and Writable are the flags the IDL requires.
+	Signer, Writable bool
+	// ATA is set for token accounts named <owner>_token_account, the
+	// associated token accounts of the owner account for the mint account.
+	ATA *ata
+}
+
+type ata struct {
+	Owner, Mint int
+}
+
+type seed struct {
+	Comment string
+	Expr    string
+}
+
+type field struct {
+	Name   string
+	Raw    string
+	GoType string
+	// JSONType is the type the field unmarshals from JSON as, MarshalType
+	// the type it marshals as; ToJSON and FromJSON convert between them and
+	// the field, ToJSON passing decimals on to nested types.
+	JSONType    string
+	MarshalType string
+	ToJSON      string
+	FromJSON    string
+	// Amount is set for token amounts.
+	Amount bool
+	// NonZero is set for instruction arguments the program rejects when
+	// zero.
+	NonZero bool
+	// Read is the statement reading the field from a decoder without
+	// reflection, empty for fields read with Decode.
+	Read string
+	// Tree is how EncodeToTree renders the field: "pubkey", "defined",
+	// "definedList" or "param".
+	Tree string
+}
+
+// structType is the template data of a struct, account or event.
+type structType struct {
+	Name          string
+	Fields        []*field
+	Discriminator string
+	// DiscriminatorText is the discriminator as fmt.Sprint prints it.
+	DiscriminatorText string
+	// IDLName is the IDL name of an account or event.
+	IDLName string
+	// Tag is the JSON key naming an account or event: "account" or "event".
+	Tag string
+	// Handler is the Dispatcher method registering handlers of an event.
+	Handler string
+}
+
+func (g *generator) instruction(ix idl.Instruction) (*instruction, error) {
+	inst := &instruction{Name: camel(ix.Name), Raw: ix.Name, Discriminator: joinBytes(ix.Discriminator)}
+	for _, arg := range ix.Args {
+		typ, err := g.goType(arg.Type)
+		if err != nil {
+			return nil, err
+		}
+		nonZero := g.nonZero[arg.Name] || g.nonZero[ix.Name+"."+arg.Name]
+		if nonZero && !integers[arg.Type.Primitive] {
+			return nil, fmt.Errorf("non-zero argument %s of type %s, want an integer", arg.Name, arg.Type)
+		}
+		inst.Args = append(inst.Args, &field{Name: camel(arg.Name), Raw: arg.Name, GoType: typ, NonZero: nonZero})
+		inst.ArgWidth = max(inst.ArgWidth, len(arg.Name))
+	}
+	for i, a := range ix.Accounts {
+		acc := &account{
+			Index:    i,
+			Name:     camel(a.Name),
+			Raw:      a.Name,
+			Param:    lowerCamel(a.Name),
+			Label:    strings.TrimSuffix(a.Name, "account"),
+			Address:  a.Address,
+			Signer:   a.Signer,
+			Writable: a.Writable,
+		}
+		var flags []string
+		if a.Writable {
+			flags = append(flags, "WRITE")
+			acc.Meta += ".WRITE()"
+		}
+		if a.Signer {
+			flags = append(flags, "SIGNER")
+			acc.Meta += ".SIGNER()"
+		}
+		acc.Flags = strings.Join(flags, ", ")
+		if a.PDA != nil {
+			for _, s := range a.PDA.Seeds {
+				switch s.Kind {
+				case "const":
+					var bs []string
+					for _, b := range s.Value {
+						bs = append(bs, fmt.Sprintf("byte(0x%02x)", b))
+					}
+					acc.Seeds = append(acc.Seeds, seed{
+						Comment: "const: " + string(s.Value),
+						Expr:    "[]byte{" + strings.Join(bs, ", ") + "}",
+					})
+				case "account":
+					param := lowerCamel(s.Path)
+					acc.Seeds = append(acc.Seeds, seed{Comment: "path: " + s.Path, Expr: param + ".Bytes()"})
+					acc.Paths = append(acc.Paths, param)
+					acc.pathAccounts = append(acc.pathAccounts, s.Path)
+				default:
+					return nil, fmt.Errorf("account %s: unsupported seed kind %q", a.Name, s.Kind)
+				}
+			}
+		}
+		inst.Accounts = append(inst.Accounts, acc)
+		inst.AccountWidth = max(inst.AccountWidth, len(acc.Label))
+	}
+	index := map[string]int{}
+	for _, acc := range inst.Accounts {
+		index[acc.Raw] = acc.Index
+	}
+	for _, acc := range inst.Accounts {
+		for _, path := range acc.pathAccounts {
+			i, ok := index[path]
+			if !ok {
+				return nil, fmt.Errorf("account %s: seed path %s is not an account of %s", acc.Raw, path, ix.Name)
+			}
+			acc.PathIndexes = append(acc.PathIndexes, i)
+		}
+		owner, ok := strings.CutSuffix(acc.Raw, "_token_account")
+		if !ok || acc.Seeds != nil {
+			continue
+		}
+		if i, ok := index[owner]; ok {
+			mint, ok := index["token"]
+			if !ok {
+				mint, ok = index["mint"]
+			}
+			if ok {
+				acc.ATA = &ata{Owner: i, Mint: mint}
+			}
+		}
+	}
+	return inst, nil
+}
+
+// structs renders type definitions with suffix appended to their names.
+func (g *generator) structs(defs []idl.TypeDef, suffix string) ([]*structType, error) {
+	var out []*structType
+	for _, def := range defs {
+		if def.Type.Kind != "struct" {
+			return nil, fmt.Errorf("type %s: unsupported kind %q", def.Name, def.Type.Kind)
+		}
+		s := &structType{Name: def.Name + suffix}
+		for _, f := range def.Type.Fields {
+			typ, err := g.goType(f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("type %s: %w", def.Name, err)
+			}
+			fd := &field{Name: camel(f.Name), Raw: f.Name, GoType: typ}
+			if read, ok := reads[f.Type.Primitive]; ok {
+				fd.Read = fmt.Sprintf(read, "obj."+fd.Name)
+			}
+			if err := g.encoding(fd, f.Type, def.Name); err != nil {
+				return nil, fmt.Errorf("type %s: %s: %w", def.Name, f.Name, err)
+			}
+			s.Fields = append(s.Fields, fd)
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// definitions renders accounts or events: the type of the same name with its
+// discriminator, named in JSON under tag.
+func (g *generator) definitions(defs []idl.Definition, suffix, tag string) ([]*structType, error) {
+	var out []*structType
+	for _, def := range defs {
+		typeDef, ok := g.idl.TypeDef(def.Name)
+		if !ok {
+			return nil, fmt.Errorf("%s has no type definition", def.Name)
+		}
+		structs, err := g.structs([]idl.TypeDef{*typeDef}, suffix)
+		if err != nil {
+			return nil, err
+		}
+		s := structs[0]
+		s.Discriminator = joinBytes(def.Discriminator)
+		s.DiscriminatorText = fmt.Sprint(def.Discriminator[:])
+		s.IDLName = def.Name
+		s.Tag = tag
+		if tag == "event" {
+			s.Handler = "On" + strings.TrimSuffix(def.Name, "Event")
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// joinBytes renders a discriminator as the elements of a Go array literal.
+func joinBytes(d idl.Discriminator) string {
+	var bs []string
+	for _, b := range d {
+		bs = append(bs, fmt.Sprint(b))
+	}
+	return strings.Join(bs, ", ")
+}
+
+// reads are the statements reading fixed-size primitives, given the field
+// to read into, without the reflection of Decode.
+var reads = map[string]string{
+	"bool":   "%s, err = decoder.ReadBool()",
+	"u8":     "%s, err = decoder.ReadUint8()",
+	"i8":     "%s, err = decoder.ReadInt8()",
+	"u16":    "%s, err = decoder.ReadUint16(ag_binary.LE)",
+	"i16":    "%s, err = decoder.ReadInt16(ag_binary.LE)",
+	"u32":    "%s, err = decoder.ReadUint32(ag_binary.LE)",
+	"i32":    "%s, err = decoder.ReadInt32(ag_binary.LE)",
+	"u64":    "%s, err = decoder.ReadUint64(ag_binary.LE)",
+	"i64":    "%s, err = decoder.ReadInt64(ag_binary.LE)",
+	"string": "%s, err = decoder.ReadString()",
+	"pubkey": "err = readPublicKey(decoder, &%s)",
+}
+
+var primitives = map[string]string{
+	"bool":   "bool",
+	"u8":     "uint8",
+	"i8":     "int8",
+	"u16":    "uint16",
+	"i16":    "int16",
+	"u32":    "uint32",
+	"i32":    "int32",
+	"u64":    "uint64",
+	"i64":    "int64",
+	"u128":   "ag_binary.Uint128",
+	"i128":   "ag_binary.Int128",
+	"f32":    "float32",
+	"f64":    "float64",
+	"string": "string",
+	"bytes":  "[]byte",
+	"pubkey": "ag_solanago.PublicKey",
+}
+
+func (g *generator) goType(t idl.Type) (string, error) {
+	switch {
+	case t.Primitive != "":
+		if typ, ok := primitives[t.Primitive]; ok {
+			return typ, nil
+		}
+		return "", fmt.Errorf("unsupported type %q", t.Primitive)
+	case t.Vec != nil:
+		elem, err := g.goType(*t.Vec)
+		return "[]" + elem, err
+	case t.Array != nil:
+		elem, err := g.goType(*t.Array)
+		return fmt.Sprintf("[%d]%s", t.Len, elem), err
+	case t.Defined != "":
+		if _, ok := g.idl.TypeDef(t.Defined); !ok {
+			return "", fmt.Errorf("undefined type %s", t.Defined)
+		}
+		return t.Defined, nil
+	}
+	return "", fmt.Errorf("unsupported type %s", t)
+}
+
+var jsonInts = map[string]string{"u64": "jsonUint64", "i64": "jsonInt64"}
+
+// integers are the primitive types compared with zero by ValidateDeep.
+var integers = map[string]bool{"u8": true, "i8": true, "u16": true, "i16": true, "u32": true, "i32": true, "u64": true, "i64": true}
+
+// encoding fills in how f, of IDL type t in type owner, is encoded to JSON
+// and to a tree. 64-bit integers marshal as strings, which JavaScript reads
+// without losing precision.
+func (g *generator) encoding(f *field, t idl.Type, owner string) error {
+	f.JSONType, f.ToJSON, f.FromJSON = f.GoType, "obj."+f.Name, "in."+f.Name
+	f.Tree = "param"
+	switch {
+	case jsonInts[t.Primitive] != "":
+		f.JSONType = jsonInts[t.Primitive]
+		f.ToJSON = f.JSONType + "(obj." + f.Name + ")"
+		f.FromJSON = f.GoType + "(in." + f.Name + ")"
+		f.Amount = t.Primitive == "u64" && (g.amounts[f.Raw] || g.amounts[owner+"."+f.Raw])
+	case t.Primitive == "pubkey":
+		f.Tree = "pubkey"
+	case t.Defined != "":
+		f.Tree = "defined"
+		f.ToJSON = "obj." + f.Name + ".jsonValue(decimals)"
+	case t.Vec != nil && jsonInts[t.Vec.Primitive] != "":
+		f.JSONType = "[]" + jsonInts[t.Vec.Primitive]
+		f.ToJSON = "convertInts[" + jsonInts[t.Vec.Primitive] + "](obj." + f.Name + ")"
+		f.FromJSON = "convertInts[" + primitives[t.Vec.Primitive] + "](in." + f.Name + ")"
+	case t.Array != nil && jsonInts[t.Array.Primitive] != "":
+		return fmt.Errorf("unsupported type %s", t)
+	case t.Vec != nil && t.Vec.Defined != "":
+		f.Tree = "definedList"
+		f.ToJSON = "jsonValues(obj." + f.Name + ", decimals)"
+	case t.Array != nil && t.Array.Defined != "":
+		f.Tree = "definedList"
+		f.ToJSON = "jsonValues(obj." + f.Name + "[:], decimals)"
+	}
+	f.MarshalType = f.JSONType
+	if f.Tree == "defined" {
+		f.MarshalType = "interface{}"
+	} else if f.Tree == "definedList" {
+		f.MarshalType = "[]interface{}"
+	}
+	// Unqualified names only mark the u64 fields of that name.
+	if g.amounts[owner+"."+f.Raw] && !f.Amount {
+		return fmt.Errorf("amount of type %s, want u64", t)
+	}
+	return nil
+}
+
+// camel converts a snake_case IDL name to CamelCase.
+func camel(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// lowerCamel converts a snake_case IDL name to lowerCamelCase.
+func lowerCamel(s string) string {
+	c := []rune(camel(s))
+	if len(c) > 0 {
+		c[0] = unicode.ToLower(c[0])
+	}
+	return string(c)
+}
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+	"hasAmounts": func(t *structType) bool {
+		for _, f := range t.Fields {
+			if f.Amount {
+				return true
+			}
+		}
+		return false
+	},
+	"title": func(s string) string { return strings.ToUpper(s[:1]) + s[1:] },
+	"pad":   func(width int, s string) string { return fmt.Sprintf("%*s", width, s) },
+	"usesPublicKey": func(types []*structType) bool {
+		for _, t := range types {
+			for _, f := range t.Fields {
+				if strings.Contains(f.GoType, "ag_solanago.") {
+					return true
+				}
+			}
+		}
+		return false
+	},
+}).Parse(templateText))
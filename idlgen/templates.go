@@ -0,0 +1,945 @@
+package idlgen
+
+const templateText = `
+{{- define "instruction"}}
+import (
+	"errors"
+	ag_binary "github.com/gagliardetto/binary"
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_format "github.com/gagliardetto/solana-go/text/format"
+	ag_treeout "github.com/gagliardetto/treeout"
+)
+
+// {{.Name}} is the ` + "`{{.Raw}}`" + ` instruction.
+type {{.Name}} struct {
+{{- range .Args}}
+	{{.Name}} *{{.GoType}}
+{{- end}}
+
+{{range $i, $a := .Accounts}}{{if $i}}	//
+{{end}}	// [{{.Index}}] = [{{.Flags}}] {{.Raw}}
+{{end}}	ag_solanago.AccountMetaSlice ` + "`bin:\"-\"`" + `
+
+	program *Program
+}
+
+// New{{.Name}}InstructionBuilder creates a new ` + "`{{.Name}}`" + ` instruction builder
+// for the default program.
+func New{{.Name}}InstructionBuilder() *{{.Name}} {
+	return (*Program)(nil).New{{.Name}}InstructionBuilder()
+}
+
+// New{{.Name}}InstructionBuilder creates a new ` + "`{{.Name}}`" + ` instruction builder
+// for the program.
+func (p *Program) New{{.Name}}InstructionBuilder() *{{.Name}} {
+	nd := &{{.Name}}{
+		AccountMetaSlice: make(ag_solanago.AccountMetaSlice, {{len .Accounts}}),
+		program:          p,
+	}
+{{- range .Accounts}}{{if .Address}}
+	nd.AccountMetaSlice[{{.Index}}] = ag_solanago.Meta(Addresses["{{.Address}}"]){{.Meta}}
+{{- end}}{{end}}
+	return nd
+}
+
+func (inst *{{.Name}}) setProgram(p *Program) {
+	inst.program = p
+}
+{{range .Args}}
+// Set{{.Name}} sets the "{{.Raw}}" parameter.
+func (inst *{{$.Name}}) Set{{.Name}}({{.Raw}} {{.GoType}}) *{{$.Name}} {
+	inst.{{.Name}} = &{{.Raw}}
+	return inst
+}
+{{end}}
+{{- range .Accounts}}
+// Set{{.Name}}Account sets the "{{.Raw}}" account.
+func (inst *{{$.Name}}) Set{{.Name}}Account({{.Param}} ag_solanago.PublicKey) *{{$.Name}} {
+	inst.AccountMetaSlice[{{.Index}}] = ag_solanago.Meta({{.Param}}){{.Meta}}
+	return inst
+}
+{{if .Seeds}}
+// seeds{{.Name}} returns the seeds of the {{.Name}} account address, without the bump.
+func (inst *{{$.Name}}) seeds{{.Name}}({{range $i, $p := .Paths}}{{if $i}}, {{end}}{{$p}} ag_solanago.PublicKey{{end}}) (seeds [][]byte) {
+{{- range .Seeds}}
+	// {{.Comment}}
+	seeds = append(seeds, {{.Expr}})
+{{- end}}
+	return
+}
+
+// Find{{.Name}}AddressWithBumpSeed calculates {{.Name}} account address with given seeds and a known bump seed.
+func (inst *{{$.Name}}) Find{{.Name}}AddressWithBumpSeed({{range .Paths}}{{.}} ag_solanago.PublicKey, {{end}}bumpSeed uint8) (pda ag_solanago.PublicKey, err error) {
+	return createProgramAddress(inst.seeds{{.Name}}({{range $i, $p := .Paths}}{{if $i}}, {{end}}{{$p}}{{end}}), bumpSeed, inst.program.ID())
+}
+
+func (inst *{{$.Name}}) MustFind{{.Name}}AddressWithBumpSeed({{range .Paths}}{{.}} ag_solanago.PublicKey, {{end}}bumpSeed uint8) (pda ag_solanago.PublicKey) {
+	pda, err := inst.Find{{.Name}}AddressWithBumpSeed({{range .Paths}}{{.}}, {{end}}bumpSeed)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// Find{{.Name}}Address finds {{.Name}} account address with given seeds.
+func (inst *{{$.Name}}) Find{{.Name}}Address({{range $i, $p := .Paths}}{{if $i}}, {{end}}{{$p}} ag_solanago.PublicKey{{end}}) (pda ag_solanago.PublicKey, bumpSeed uint8, err error) {
+	return findProgramAddress(inst.seeds{{.Name}}({{range $i, $p := .Paths}}{{if $i}}, {{end}}{{$p}}{{end}}), inst.program.ID())
+}
+
+func (inst *{{$.Name}}) MustFind{{.Name}}Address({{range $i, $p := .Paths}}{{if $i}}, {{end}}{{$p}} ag_solanago.PublicKey{{end}}) (pda ag_solanago.PublicKey) {
+	pda, _, err := inst.Find{{.Name}}Address({{range $i, $p := .Paths}}{{if $i}}, {{end}}{{$p}}{{end}})
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+{{end}}
+// Get{{.Name}}Account gets the "{{.Raw}}" account.
+func (inst *{{$.Name}}) Get{{.Name}}Account() *ag_solanago.AccountMeta {
+	return inst.AccountMetaSlice.Get({{.Index}})
+}
+{{end}}
+func (inst {{.Name}}) Build() *Instruction {
+	return &Instruction{BaseVariant: ag_binary.BaseVariant{
+		Impl:   inst,
+		TypeID: Instruction_{{.Name}},
+	}, program: inst.program}
+}
+
+// ValidateAndBuild validates the instruction parameters and accounts;
+// if there is a validation error, it returns the error.
+// Otherwise, it builds and returns the instruction.
+func (inst {{.Name}}) ValidateAndBuild() (*Instruction, error) {
+	if err := inst.Validate(); err != nil {
+		return nil, err
+	}
+	return inst.Build(), nil
+}
+
+func (inst *{{.Name}}) Validate() error {
+{{- if .Args}}
+	// Check whether all (required) parameters are set:
+	{
+{{- range .Args}}
+		if inst.{{.Name}} == nil {
+			return errors.New("{{.Name}} parameter is not set")
+		}
+{{- end}}
+	}
+{{end}}
+	// Check whether all (required) accounts are set:
+	{
+{{- range .Accounts}}
+		if inst.AccountMetaSlice[{{.Index}}] == nil {
+			return errors.New("accounts.{{.Name}} is not set")
+		}
+{{- end}}
+	}
+	return nil
+}
+
+// ValidateDeep runs Validate, then checks the accounts against the IDL and
+// each other: PDAs are derived from their seeds, token accounts are the
+// associated token accounts of their owner for the mint, program accounts
+// hold the program IDs and accounts have the signer and writable flags the
+// IDL requires.
+// Arguments the program requires to be non-zero are checked too. It returns
+// every failed check, as *AccountError and *ArgumentError values joined with
+// errors.Join.
+func (inst *{{.Name}}) ValidateDeep() error {
+	if err := inst.Validate(); err != nil {
+		return err
+	}
+	accounts := inst.AccountMetaSlice
+	var errs []error
+{{- range $a := .Accounts}}
+{{- if or .Signer .Writable}}
+	errs = append(errs, checkFlags("{{$.Raw}}", "{{.Raw}}", accounts[{{.Index}}], {{.Signer}}, {{.Writable}}))
+{{- end}}
+{{- if .Address}}
+	errs = append(errs, checkAccount("{{$.Raw}}", "{{.Raw}}", accounts[{{.Index}}].PublicKey, Addresses["{{.Address}}"], nil, ErrProgramMismatch))
+{{- end}}
+{{- if .Seeds}}
+	{
+		pda, _, err := inst.Find{{.Name}}Address({{range $i, $p := .PathIndexes}}{{if $i}}, {{end}}accounts[{{$p}}].PublicKey{{end}})
+		errs = append(errs, checkAccount("{{$.Raw}}", "{{.Raw}}", accounts[{{.Index}}].PublicKey, pda, err, ErrSeedsMismatch))
+	}
+{{- end}}
+{{- with .ATA}}
+	{
+		ata, err := associatedTokenAddress(accounts[{{.Owner}}].PublicKey, accounts[{{.Mint}}].PublicKey)
+		errs = append(errs, checkAccount("{{$.Raw}}", "{{$a.Raw}}", accounts[{{$a.Index}}].PublicKey, ata, err, ErrNotAssociatedTokenAccount))
+	}
+{{- end}}
+{{- end}}
+{{- range .Args}}{{if .NonZero}}
+	if *inst.{{.Name}} == 0 {
+		errs = append(errs, &ArgumentError{Instruction: "{{$.Raw}}", Argument: "{{.Raw}}", Err: ErrZeroArgument})
+	}
+{{- end}}{{end}}
+	return errors.Join(errs...)
+}
+
+func (inst *{{.Name}}) EncodeToTree(parent ag_treeout.Branches) {
+	parent.Child(ag_format.Program(ProgramName, inst.program.ID())).
+		//
+		ParentFunc(func(programBranch ag_treeout.Branches) {
+			programBranch.Child(ag_format.Instruction("{{.Name}}")).
+				//
+				ParentFunc(func(instructionBranch ag_treeout.Branches) {
+
+					// Parameters of the instruction:
+{{- if .Args}}
+					instructionBranch.Child("Params[len={{len .Args}}]").ParentFunc(func(paramsBranch ag_treeout.Branches) {
+{{- range .Args}}
+						paramsBranch.Child(ag_format.Param("{{pad $.ArgWidth .Name}}", *inst.{{.Name}}))
+{{- end}}
+					})
+{{- else}}
+					instructionBranch.Child("Params[len=0]").ParentFunc(func(paramsBranch ag_treeout.Branches) {})
+{{- end}}
+
+					// Accounts of the instruction:
+					instructionBranch.Child("Accounts[len={{len .Accounts}}]").ParentFunc(func(accountsBranch ag_treeout.Branches) {
+{{- range .Accounts}}
+						accountsBranch.Child(ag_format.Meta("{{pad $.AccountWidth .Label}}", inst.AccountMetaSlice.Get({{.Index}})))
+{{- end}}
+					})
+				})
+		})
+}
+
+func (obj {{.Name}}) MarshalWithEncoder(encoder *ag_binary.Encoder) (err error) {
+{{- range .Args}}
+	// Serialize ` + "`{{.Name}}`" + ` param:
+	err = encoder.Encode(obj.{{.Name}})
+	if err != nil {
+		return err
+	}
+{{- end}}
+	return nil
+}
+func (obj *{{.Name}}) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
+{{- range .Args}}
+	// Deserialize ` + "`{{.Name}}`" + `:
+	err = decoder.Decode(&obj.{{.Name}})
+	if err != nil {
+		return err
+	}
+{{- end}}
+	return nil
+}
+
+// New{{.Name}}Instruction declares a new {{.Name}} instruction of the default
+// program with the provided parameters and accounts.
+func New{{.Name}}Instruction({{template "instructionParams" .}}
+	return (*Program)(nil).New{{.Name}}Instruction(
+{{- range .Args}}{{.Raw}}, {{end}}
+{{- range $i, $a := .Accounts}}{{if $i}}, {{end}}{{.Param}}{{end}})
+}
+
+// New{{.Name}}Instruction declares a new {{.Name}} instruction of the program
+// with the provided parameters and accounts.
+func (p *Program) New{{.Name}}Instruction({{template "instructionParams" .}}
+	return p.New{{.Name}}InstructionBuilder().
+{{- range $i, $a := .Args}}
+		Set{{.Name}}({{.Raw}}).
+{{- end}}
+{{- range $i, $a := .Accounts}}
+		Set{{.Name}}Account({{.Param}}){{if ne (len $.Accounts) (inc $i)}}.{{end}}
+{{- end}}
+}
+{{end}}
+
+{{- define "instructionParams"}}
+{{- if .Args}}
+	// Parameters:
+{{- range .Args}}
+	{{.Raw}} {{.GoType}},
+{{- end}}
+{{- end}}
+	// Accounts:
+{{- range $i, $a := .Accounts}}
+	{{.Param}} ag_solanago.PublicKey{{if eq (len $.Accounts) (inc $i)}}) *{{$.Name}} {{"{"}}{{else}},{{end}}
+{{- end}}
+{{- end}}
+
+{{- define "instructionTest"}}
+import (
+	"bytes"
+	ag_gofuzz "github.com/gagliardetto/gofuzz"
+	ag_require "github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+)
+
+func TestEncodeDecode_{{.Name}}(t *testing.T) {
+	fu := ag_gofuzz.New().NilChance(0)
+	for i := 0; i < 1; i++ {
+		t.Run("{{.Name}}"+strconv.Itoa(i), func(t *testing.T) {
+			{
+				params := new({{.Name}})
+				fu.Fuzz(params)
+				params.AccountMetaSlice = nil
+				buf := new(bytes.Buffer)
+				err := encodeT(*params, buf)
+				ag_require.NoError(t, err)
+				got := new({{.Name}})
+				err = decodeT(got, buf.Bytes())
+				got.AccountMetaSlice = nil
+				ag_require.NoError(t, err)
+				ag_require.Equal(t, params, got)
+			}
+		})
+	}
+}
+{{end}}
+
+{{- define "fields"}}
+{{- range .Fields}}
+	{{.Name}} {{.GoType}}
+{{- end}}
+{{- end}}
+
+{{- define "marshalFields"}}
+{{- range .Fields}}
+	// Serialize ` + "`{{.Name}}`" + ` param:
+	err = encoder.Encode(obj.{{.Name}})
+	if err != nil {
+		return err
+	}
+{{- end}}
+	return nil
+{{- end}}
+
+{{- define "unmarshalFields"}}
+{{- range .Fields}}
+	// Deserialize ` + "`{{.Name}}`" + `:
+{{- if .Read}}
+	{{.Read}}
+{{- else}}
+	err = decoder.Decode(&obj.{{.Name}})
+{{- end}}
+	if err != nil {
+		return err
+	}
+{{- end}}
+	return nil
+{{- end}}
+
+{{- define "discriminated"}}
+type {{.Name}} struct {
+{{- template "fields" .}}
+}
+
+var {{.Name}}Discriminator = [8]byte{ {{- .Discriminator -}} }
+
+func (obj {{.Name}}) MarshalWithEncoder(encoder *ag_binary.Encoder) (err error) {
+	// Write account discriminator:
+	err = encoder.WriteBytes({{.Name}}Discriminator[:], false)
+	if err != nil {
+		return err
+	}
+{{- template "marshalFields" .}}
+}
+
+func (obj *{{.Name}}) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
+	// Read and check account discriminator:
+	{
+		discriminator, err := decoder.ReadTypeID()
+		if err != nil {
+			return err
+		}
+		if !discriminator.Equal({{.Name}}Discriminator[:]) {
+			return fmt.Errorf(
+				"wrong discriminator: wanted %s, got %s",
+				"{{.DiscriminatorText}}",
+				fmt.Sprint([8]byte(discriminator)))
+		}
+	}
+{{- template "unmarshalFields" .}}
+}
+{{template "encoding" .}}
+{{- end}}
+
+{{- define "encoding"}}
+// MarshalJSON encodes the fields of {{.Name}} under their IDL names
+{{- if .Tag}}, next to
+// its "{{.Tag}}" name{{end}}. 64-bit integers are strings{{if hasAmounts .}}; token amounts
+// get a decimal "_ui" companion when wrapped by WithAmountDecimals{{end}}.
+func (obj {{.Name}}) MarshalJSON() ([]byte, error) {
+	return json.Marshal(obj.jsonValue(-1))
+}
+
+// jsonValue returns the JSON form of {{.Name}}, with decimal companions next
+// to its token amounts unless decimals is negative.
+func (obj {{.Name}}) jsonValue(decimals int) interface{} {
+	return struct {
+{{- if .Tag}}
+		JSONName string ` + "`json:\"{{.Tag}}\"`" + `
+{{- end}}
+{{- range .Fields}}
+		{{.Name}} {{.MarshalType}} ` + "`json:\"{{.Raw}}\"`" + `
+{{- if .Amount}}
+		{{.Name}}UI *string ` + "`json:\"{{.Raw}}_ui,omitempty\"`" + `
+{{- end}}
+{{- end}}
+	}{
+{{- if .Tag}}
+		JSONName: "{{.IDLName}}",
+{{- end}}
+{{- range .Fields}}
+		{{.Name}}: {{.ToJSON}},
+{{- if .Amount}}
+		{{.Name}}UI: formatAmount(obj.{{.Name}}, decimals),
+{{- end}}
+{{- end}}
+	}
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON; 64-bit integers may also
+// be numbers, and decimal companions are ignored.
+func (obj *{{.Name}}) UnmarshalJSON(data []byte) error {
+	var in struct {
+{{- if .Tag}}
+		JSONName string ` + "`json:\"{{.Tag}}\"`" + `
+{{- end}}
+{{- range .Fields}}
+		{{.Name}} {{.JSONType}} ` + "`json:\"{{.Raw}}\"`" + `
+{{- end}}
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+{{- if .Tag}}
+	if err := checkJSONName("{{.Tag}}", in.JSONName, "{{.IDLName}}"); err != nil {
+		return err
+	}
+{{- end}}
+{{- range .Fields}}
+	obj.{{.Name}} = {{.FromJSON}}
+{{- end}}
+	return nil
+}
+
+// TextEncode writes the JSON of {{.Name}} for solana-go's text encoder.
+func (obj {{.Name}}) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return encoder.ToWriter(string(data), false, nil)
+}
+
+func (obj {{.Name}}) EncodeToTree(parent ag_treeout.Branches) {
+{{- if .Tag}}
+	parent.Child(ag_text.Purple(ag_text.Bold("{{title .Tag}}")) + ": " + ag_text.Bold("{{.IDLName}}")).
+		//
+		ParentFunc(func(parent ag_treeout.Branches) {
+{{- template "treeFields" .}}
+		})
+{{- else}}
+{{- template "treeFields" .}}
+{{- end}}
+}
+{{- end}}
+
+{{- define "treeFields"}}
+{{- range .Fields}}
+{{- if eq .Tree "pubkey"}}
+	parent.Child(ag_format.Account("{{.Name}}", obj.{{.Name}}))
+{{- else if eq .Tree "defined"}}
+	obj.{{.Name}}.EncodeToTree(parent.Child("{{.Name}}"))
+{{- else if eq .Tree "definedList"}}
+	parent.Child(listLabel("{{.Name}}", len(obj.{{.Name}}))).ParentFunc(func(items ag_treeout.Branches) {
+		for i := range obj.{{.Name}} {
+			obj.{{.Name}}[i].EncodeToTree(items.Child(itemLabel(i)))
+		}
+	})
+{{- else}}
+	parent.Child(ag_format.Param("{{.Name}}", obj.{{.Name}}))
+{{- end}}
+{{- end}}
+{{- end}}
+
+{{- define "encodingImports"}}
+	"encoding/json"
+	ag_text "github.com/gagliardetto/solana-go/text"
+	ag_format "github.com/gagliardetto/solana-go/text/format"
+	ag_treeout "github.com/gagliardetto/treeout"
+{{- end}}
+
+{{- define "types"}}
+import (
+	ag_binary "github.com/gagliardetto/binary"
+{{- if usesPublicKey .Types}}
+	ag_solanago "github.com/gagliardetto/solana-go"
+{{- end}}
+{{- template "encodingImports"}}
+)
+{{range .Types}}
+type {{.Name}} struct {
+{{- template "fields" .}}
+}
+
+func (obj {{.Name}}) MarshalWithEncoder(encoder *ag_binary.Encoder) (err error) {
+{{- template "marshalFields" .}}
+}
+
+func (obj *{{.Name}}) UnmarshalWithDecoder(decoder *ag_binary.Decoder) (err error) {
+{{- template "unmarshalFields" .}}
+}
+{{template "encoding" .}}
+{{end}}
+{{- end}}
+
+{{- define "accounts"}}
+import (
+	"fmt"
+	ag_binary "github.com/gagliardetto/binary"
+{{- if usesPublicKey .Accounts}}
+	ag_solanago "github.com/gagliardetto/solana-go"
+{{- end}}
+{{- template "encodingImports"}}
+)
+{{range .Accounts}}
+{{template "discriminated" .}}
+{{end}}
+{{- end}}
+
+{{- define "events"}}
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	ag_binary "github.com/gagliardetto/binary"
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_rpc "github.com/gagliardetto/solana-go/rpc"
+{{- template "encodingImports"}}
+	ag_base58 "github.com/mr-tron/base58"
+	"strings"
+	"sync"
+)
+{{range .Events}}
+{{template "discriminated" .}}
+
+func (*{{.Name}}) isEventData() {}
+{{end}}
+// eventValues holds one value of every event, reused by decoders that hand
+// out one event at a time.
+type eventValues struct {
+{{- range .Events}}
+	{{.Name}} {{.Name}}
+{{- end}}
+}
+
+// get returns the name of the event of discriminator and a zero value of its
+// data: a value of values reset, or a new one when values is nil. ok is false
+// for an unknown discriminator.
+func (values *eventValues) get(discriminator [8]byte) (name string, data EventData, ok bool) {
+	switch discriminator {
+{{- range .Events}}
+	case {{.Name}}Discriminator:
+		if values == nil {
+			return "{{.IDLName}}", new({{.Name}}), true
+		}
+		values.{{.Name}} = {{.Name}}{}
+		return "{{.IDLName}}", &values.{{.Name}}, true
+{{- end}}
+	}
+	return "", nil, false
+}
+
+// eventDecoders pools the Borsh decoders of event payloads.
+var eventDecoders = sync.Pool{New: func() any { return ag_binary.NewDecoderWithEncoding(nil, ag_binary.EncodingBorsh) }}
+
+{{range .Events}}
+// {{.Handler}} registers handler for {{.IDLName}} events.
+func (d *Dispatcher) {{.Handler}}(handler func(ctx context.Context, data *{{.Name}}, meta EventMeta) error) {
+	d.on("{{.IDLName}}", func(ctx context.Context, data EventData, meta EventMeta) error {
+		return handler(ctx, data.(*{{.Name}}), meta)
+	})
+}
+{{end}}
+// eventProvider returns the provider of the events that name one.
+func eventProvider(data EventData) (provider ag_solanago.PublicKey, ok bool) {
+	switch data := data.(type) {
+{{- range $e := .Events}}{{range .Fields}}{{if and (eq .Raw "provider") (eq .GoType "ag_solanago.PublicKey")}}
+	case *{{$e.Name}}:
+		return data.Provider, true
+{{- end}}{{end}}{{end}}
+	}
+	return ag_solanago.PublicKey{}, false
+}
+
+var eventNames = map[[8]byte]string{
+{{- range .Events}}
+	{{.Name}}Discriminator: "{{.IDLName}}",
+{{- end}}
+}
+var (
+	_ *strings.Builder = nil
+)
+var (
+	_ *base64.Encoding = nil
+)
+var (
+	_ *ag_binary.Decoder = nil
+)
+var (
+	_ *ag_rpc.GetTransactionResult = nil
+)
+var (
+	_ *ag_base58.Alphabet = nil
+)
+
+type Event struct {
+	Name string
+	Data EventData
+	// Instruction is the index of the outer instruction the event was
+	// emitted under and Depth the invocation depth of the program that
+	// emitted it: 1 in the outer instruction, 2 and more under a CPI.
+	// ComputeUnits is the compute units that invocation consumed. Depth and
+	// ComputeUnits are zero when the logs do not cover the invocation.
+	Instruction  int
+	Depth        int
+	ComputeUnits uint64
+}
+
+type EventData interface {
+	UnmarshalWithDecoder(decoder *ag_binary.Decoder) error
+	isEventData()
+}
+
+const eventLogPrefix = "Program data: "
+
+// DecodeEvents decodes the events targetProgramId emitted in txData. Lookups of
+// v0 transactions are resolved through getAddressTables, or through the getter
+// installed with SetAddressTablesGetter when getAddressTables is nil.
+//
+// Logged events are read from the data lines the log tree attributes to
+// targetProgramId, so events of other programs in the transaction are left
+// out; events emitted through a self-CPI are read from the inner
+// instructions carrying Anchor's event tag, and dropped when the same
+// invocation also logged them. Payloads that do not decode are reported as
+// *EventError values joined into err, next to the events that did.
+func DecodeEvents(txData *ag_rpc.GetTransactionResult, targetProgramId ag_solanago.PublicKey, getAddressTables AddressTablesGetter) (evts []*Event, err error) {
+	var tx *ag_solanago.Transaction
+	if tx, err = txData.Transaction.GetTransaction(); err != nil {
+		return
+	}
+
+	if tx.Message.NumLookups() > 0 {
+		var message *ag_solanago.Message
+		if message, err = withAddressTables(&tx.Message, getAddressTables); err != nil {
+			return
+		}
+		if err = message.ResolveLookups(); err != nil {
+			return
+		}
+		tx.Message = *message
+	}
+
+	// Truncated logs still hold the invocations before the cut.
+	invocations, err := ParseLogs(txData.Meta.LogMessages)
+	if err != nil && !errors.Is(err, ErrLogTruncated) {
+		return
+	}
+	logged := eventsFromLogs(invocations, targetProgramId)
+
+	emitted, err := eventsFromEmitCPI(txData.Meta.InnerInstructions, tx.Message.AccountKeys, targetProgramId, invocations)
+	if err != nil {
+		return
+	}
+
+	evts, err = parseEvents(dedupeEvents(logged, emitted))
+	return
+}
+
+// parseEvents decodes payloads, returning the events that decode and the
+// *EventError of each payload that does not.
+func parseEvents(payloads []eventPayload) (evts []*Event, err error) {
+	decoder := eventDecoders.Get().(*ag_binary.Decoder)
+	defer eventDecoders.Put(decoder)
+
+	events := make([]Event, 0, len(payloads))
+	var errs []error
+	for _, payload := range payloads {
+		evt, err := payload.decode(decoder, nil)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		events = append(events, evt)
+	}
+	if len(events) > 0 {
+		evts = make([]*Event, len(events))
+		for i := range events {
+			evts[i] = &events[i]
+		}
+	}
+	return evts, errors.Join(errs...)
+}
+{{end}}
+
+{{- define "errors"}}
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	ag_jsonrpc "github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+var (
+	_ *json.Encoder        = nil
+	_ *ag_jsonrpc.RPCError = nil
+	_ fmt.Formatter        = nil
+	_                      = errors.ErrUnsupported
+)
+var (
+{{- range .Errors}}
+	Err{{.Name}} = &customErrorDef{
+		code: {{.Code}},
+		msg:  {{printf "%q" .Msg}},
+		name: {{printf "%q" .Name}},
+	}
+{{- end}}
+	Errors = map[int]CustomError{
+{{- range .Errors}}
+		{{.Code}}: Err{{.Name}},
+{{- end}}
+	}
+)
+
+type CustomError interface {
+	Code() int
+	Name() string
+	Error() string
+}
+
+type customErrorDef struct {
+	code int
+	name string
+	msg  string
+}
+
+func (e *customErrorDef) Code() int {
+	return e.code
+}
+
+func (e *customErrorDef) Name() string {
+	return e.name
+}
+
+func (e *customErrorDef) Error() string {
+	return fmt.Sprintf("%s(%d): %s", e.name, e.code, e.msg)
+}
+
+func DecodeCustomError(rpcErr error) (err error, ok bool) {
+	if errCode, o := decodeErrorCode(rpcErr); o {
+		if customErr, o := Errors[errCode]; o {
+			err = customErr
+			ok = true
+			return
+		}
+	}
+	return
+}
+
+func decodeErrorCode(rpcErr error) (errorCode int, ok bool) {
+	var jErr *ag_jsonrpc.RPCError
+	if errors.As(rpcErr, &jErr) && jErr.Data != nil {
+		if root, o := jErr.Data.(map[string]interface{}); o {
+			if rootErr, o := root["err"].(map[string]interface{}); o {
+				if rootErrInstructionError, o := rootErr["InstructionError"]; o {
+					if rootErrInstructionErrorItems, o := rootErrInstructionError.([]interface{}); o {
+						if len(rootErrInstructionErrorItems) == 2 {
+							if v, o := rootErrInstructionErrorItems[1].(map[string]interface{}); o {
+								if v2, o := v["Custom"].(json.Number); o {
+									if code, err := v2.Int64(); err == nil {
+										ok = true
+										errorCode = int(code)
+									}
+								} else if v2, o := v["Custom"].(float64); o {
+									ok = true
+									errorCode = int(v2)
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return
+}
+{{end}}
+
+{{- define "instructions"}}
+import (
+	"bytes"
+	"fmt"
+	ag_spew "github.com/davecgh/go-spew/spew"
+	ag_binary "github.com/gagliardetto/binary"
+	ag_solanago "github.com/gagliardetto/solana-go"
+	ag_text "github.com/gagliardetto/solana-go/text"
+	ag_treeout "github.com/gagliardetto/treeout"
+)
+
+// ProgramID is the program ID of the default program, which the
+// package-level builders and decoders use.
+var ProgramID ag_solanago.PublicKey
+
+// SetProgramID sets the program ID of the default program and registers its
+// instruction decoder.
+func SetProgramID(PublicKey ag_solanago.PublicKey) {
+	ProgramID = PublicKey
+	(*Program)(nil).Register()
+}
+
+const ProgramName = "{{.ProgramName}}"
+
+func init() {
+	if !ProgramID.IsZero() {
+		(*Program)(nil).Register()
+	}
+}
+
+var (
+{{- range $i, $ix := .Instructions}}{{if $i}}
+{{end}}
+	Instruction_{{.Name}} = ag_binary.TypeID([8]byte{ {{- .Discriminator -}} })
+{{- end}}
+)
+
+// InstructionIDToName returns the name of the instruction given its ID.
+func InstructionIDToName(id ag_binary.TypeID) string {
+	switch id {
+{{- range .Instructions}}
+	case Instruction_{{.Name}}:
+		return "{{.Name}}"
+{{- end}}
+	default:
+		return ""
+	}
+}
+
+type Instruction struct {
+	ag_binary.BaseVariant
+
+	program *Program
+}
+
+func (inst *Instruction) EncodeToTree(parent ag_treeout.Branches) {
+	if enToTree, ok := inst.Impl.(ag_text.EncodableToTree); ok {
+		enToTree.EncodeToTree(parent)
+	} else {
+		parent.Child(ag_spew.Sdump(inst))
+	}
+}
+
+var InstructionImplDef = ag_binary.NewVariantDefinition(
+	ag_binary.AnchorTypeIDEncoding,
+	[]ag_binary.VariantType{
+{{- range .Instructions}}
+		{
+			Name: "{{.Raw}}", Type: (*{{.Name}})(nil),
+		},
+{{- end}}
+	},
+)
+
+func (inst *Instruction) ProgramID() ag_solanago.PublicKey {
+	return inst.program.ID()
+}
+
+// setProgram binds the instruction and its Impl to p.
+func (inst *Instruction) setProgram(p *Program) {
+	inst.program = p
+	if v, ok := inst.Impl.(interface{ setProgram(*Program) }); ok {
+		v.setProgram(p)
+	}
+}
+
+func (inst *Instruction) Accounts() (out []*ag_solanago.AccountMeta) {
+	return inst.Impl.(ag_solanago.AccountsGettable).GetAccounts()
+}
+
+func (inst *Instruction) Data() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := ag_binary.NewBorshEncoder(buf).Encode(inst); err != nil {
+		return nil, fmt.Errorf("unable to encode instruction: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (inst *Instruction) TextEncode(encoder *ag_text.Encoder, option *ag_text.Option) error {
+	return encoder.Encode(inst.Impl, option)
+}
+
+func (inst *Instruction) UnmarshalWithDecoder(decoder *ag_binary.Decoder) error {
+	return inst.BaseVariant.UnmarshalBinaryVariant(decoder, InstructionImplDef)
+}
+
+func (inst *Instruction) MarshalWithEncoder(encoder *ag_binary.Encoder) error {
+	err := encoder.WriteBytes(inst.TypeID.Bytes(), false)
+	if err != nil {
+		return fmt.Errorf("unable to write variant type: %w", err)
+	}
+	return encoder.Encode(inst.Impl)
+}
+
+func decodeInstruction(accounts []*ag_solanago.AccountMeta, data []byte) (*Instruction, error) {
+	inst := new(Instruction)
+	if err := ag_binary.NewBorshDecoder(data).Decode(inst); err != nil {
+		return nil, fmt.Errorf("unable to decode instruction: %w", err)
+	}
+	if v, ok := inst.Impl.(ag_solanago.AccountsSettable); ok {
+		err := v.SetAccounts(accounts)
+		if err != nil {
+			return nil, fmt.Errorf("unable to set accounts for instruction: %w", err)
+		}
+	}
+	return inst, nil
+}
+
+// DecodeInstructions decodes the instructions of the default program in
+// message. Lookups of v0 messages without address tables are resolved
+// through the getter installed with SetAddressTablesGetter.
+func DecodeInstructions(message *ag_solanago.Message) (instructions []*Instruction, err error) {
+	return (*Program)(nil).DecodeInstructions(message)
+}
+{{end}}
+
+{{- define "addresses"}}
+import ag_solanago "github.com/gagliardetto/solana-go"
+
+var Addresses = map[string]ag_solanago.PublicKey{
+{{- range .Addresses}}
+	"{{.}}": ag_solanago.MustPublicKeyFromBase58("{{.}}"),
+{{- end}}
+}
+{{end}}
+
+{{- define "constants"}}{{end}}
+
+{{- define "testingUtils"}}
+import (
+	"bytes"
+	"fmt"
+	ag_binary "github.com/gagliardetto/binary"
+)
+
+func encodeT(data interface{}, buf *bytes.Buffer) error {
+	if err := ag_binary.NewBorshEncoder(buf).Encode(data); err != nil {
+		return fmt.Errorf("unable to encode instruction: %w", err)
+	}
+	return nil
+}
+
+func decodeT(dst interface{}, data []byte) error {
+	return ag_binary.NewBorshDecoder(data).Decode(dst)
+}
+{{end}}
+`
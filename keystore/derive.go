@@ -0,0 +1,107 @@
+package keystore
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// hardened marks a hardened child index.
+const hardened = 1 << 31
+
+// Account indexes of the roles in DerivationPath.
+const (
+	adminAccount       = 0
+	mintAccount        = 1
+	providerAccounts   = 1000
+	controllerAccounts = 2000
+	tenantAccounts     = 3000
+	// maxRoleIndex bounds N in provider-N, controller-N and tenant-N so the
+	// account ranges do not overlap.
+	maxRoleIndex = 999
+)
+
+// NewMnemonic returns a fresh 24-word BIP39 mnemonic.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// DerivationPath returns the path of account, m/44'/501'/account'/0', the
+// one the Solana CLI and wallets derive from a mnemonic.
+func DerivationPath(account uint32) string {
+	return fmt.Sprintf("m/44'/501'/%d'/0'", account)
+}
+
+// Derive returns the key of account derived from a BIP39 mnemonic, without
+// a BIP39 passphrase, on DerivationPath(account) with SLIP-0010.
+func Derive(mnemonic string, account uint32) (solana.PrivateKey, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+	seed := bip39.NewSeed(mnemonic, "")
+	return derive(seed, []uint32{44, 501, account, 0}), nil
+}
+
+// derive walks the hardened path from seed as SLIP-0010 does for ed25519.
+func derive(seed []byte, path []uint32) solana.PrivateKey {
+	sum := hmacSHA512([]byte("ed25519 seed"), seed)
+	key, chain := sum[:32], sum[32:]
+	for _, index := range path {
+		data := make([]byte, 1, 37)
+		data = append(data, key...)
+		data = binary.BigEndian.AppendUint32(data, index|hardened)
+		sum = hmacSHA512(chain, data)
+		key, chain = sum[:32], sum[32:]
+	}
+	return solana.PrivateKey(ed25519.NewKeyFromSeed(key))
+}
+
+func hmacSHA512(key, data []byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// ValidRole checks that role is admin, mint, provider-N, controller-N or
+// tenant-N.
+func ValidRole(role string) error {
+	_, err := RoleAccount(role)
+	return err
+}
+
+// RoleAccount returns the account index role is derived at: 0 for the
+// admin, 1 for the mint keypair and 1000+N, 2000+N and 3000+N for
+// provider-N, controller-N and tenant-N.
+func RoleAccount(role string) (uint32, error) {
+	switch role {
+	case "admin":
+		return adminAccount, nil
+	case "mint":
+		return mintAccount, nil
+	}
+	name, n, ok := strings.Cut(role, "-")
+	index, err := strconv.ParseUint(n, 10, 32)
+	if !ok || err != nil || index > maxRoleIndex || strconv.FormatUint(index, 10) != n {
+		return 0, fmt.Errorf("%w %q", ErrInvalidRole, role)
+	}
+	switch name {
+	case "provider":
+		return providerAccounts + uint32(index), nil
+	case "controller":
+		return controllerAccounts + uint32(index), nil
+	case "tenant":
+		return tenantAccounts + uint32(index), nil
+	}
+	return 0, fmt.Errorf("%w %q", ErrInvalidRole, role)
+}
@@ -0,0 +1,407 @@
+// Package keystore keeps the keys of a supernode deployment by role (admin,
+// provider-N, controller-N, tenant-N) in one file encrypted with a
+// passphrase: scrypt derives the key, AES-GCM seals each private key. Keys
+// are generated at random or derived from the store's BIP39 mnemonic on
+// m/44'/501'/n'/0', and can be imported from and exported to the JSON
+// keypair files of the Solana CLI.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	// ErrWrongPassphrase is returned by Open when the passphrase does not
+	// decrypt the store.
+	ErrWrongPassphrase = errors.New("wrong keystore passphrase")
+	// ErrLocked is returned for operations on private keys of a store opened
+	// without a passphrase.
+	ErrLocked = errors.New("keystore is locked")
+	// ErrNotFound is returned for roles the store does not hold.
+	ErrNotFound = errors.New("no key for role")
+	// ErrExists is returned when adding a role the store already holds, or
+	// creating a store over an existing file.
+	ErrExists = errors.New("already exists")
+	// ErrInvalidRole is returned for role names other than admin, mint,
+	// provider-N, controller-N and tenant-N.
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrInvalidMnemonic is returned for mnemonics failing the BIP39
+	// checksum.
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+	// ErrNoMnemonic is returned by Mnemonic for stores created without one.
+	ErrNoMnemonic = errors.New("keystore has no mnemonic")
+)
+
+const (
+	version = 1
+	// checkText is sealed in every store to tell a wrong passphrase from a
+	// corrupted key.
+	checkText = "supernode keystore"
+)
+
+// Options configure Create. Zero fields take the defaults.
+type Options struct {
+	// ScryptN, ScryptR and ScryptP are the scrypt cost parameters (default
+	// 1<<15, 8 and 1).
+	ScryptN, ScryptR, ScryptP int
+}
+
+type file struct {
+	Version  int              `json:"version"`
+	Scrypt   scryptParams     `json:"scrypt"`
+	Check    sealed           `json:"check"`
+	Mnemonic *sealed          `json:"mnemonic,omitempty"`
+	Keys     map[string]entry `json:"keys"`
+}
+
+type scryptParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+type sealed struct {
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type entry struct {
+	PublicKey solana.PublicKey `json:"publicKey"`
+	// Path is the derivation path of keys derived from the mnemonic.
+	Path string `json:"path,omitempty"`
+	sealed
+}
+
+// Store is an open keystore file. Every change is written to the file
+// before the method returns.
+type Store struct {
+	path string
+	file file
+	// aead is nil for stores opened without a passphrase.
+	aead cipher.AEAD
+}
+
+// Create writes a new store to path, encrypted with passphrase. mnemonic,
+// which may be empty, is kept encrypted for Generate to derive keys from.
+func Create(path string, passphrase []byte, mnemonic string, opts Options) (*Store, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("keystore %s: %w", path, ErrExists)
+	}
+	if mnemonic != "" && !bip39.IsMnemonicValid(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+	params := scryptParams{N: opts.ScryptN, R: opts.ScryptR, P: opts.ScryptP, Salt: make([]byte, 32)}
+	if params.N == 0 {
+		params.N = 1 << 15
+	}
+	if params.R == 0 {
+		params.R = 8
+	}
+	if params.P == 0 {
+		params.P = 1
+	}
+	if _, err := rand.Read(params.Salt); err != nil {
+		return nil, err
+	}
+	s := &Store{path: path, file: file{Version: version, Scrypt: params, Keys: map[string]entry{}}}
+	var err error
+	if s.aead, err = newAEAD(passphrase, params); err != nil {
+		return nil, err
+	}
+	if s.file.Check, err = s.seal("check", []byte(checkText)); err != nil {
+		return nil, err
+	}
+	if mnemonic != "" {
+		m, err := s.seal("mnemonic", []byte(mnemonic))
+		if err != nil {
+			return nil, err
+		}
+		s.file.Mnemonic = &m
+	}
+	return s, s.save()
+}
+
+// Open reads the store at path. With a nil passphrase the store is locked:
+// it lists roles and public keys but returns ErrLocked for private keys.
+func Open(path string, passphrase []byte) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{path: path}
+	if err := json.Unmarshal(data, &s.file); err != nil {
+		return nil, fmt.Errorf("keystore %s: %w", path, err)
+	}
+	if s.file.Version != version {
+		return nil, fmt.Errorf("keystore %s: unsupported version %d", path, s.file.Version)
+	}
+	if s.file.Keys == nil {
+		s.file.Keys = map[string]entry{}
+	}
+	if passphrase == nil {
+		return s, nil
+	}
+	if s.aead, err = newAEAD(passphrase, s.file.Scrypt); err != nil {
+		return nil, fmt.Errorf("keystore %s: %w", path, err)
+	}
+	if check, err := s.open("check", s.file.Check); err != nil || string(check) != checkText {
+		return nil, ErrWrongPassphrase
+	}
+	return s, nil
+}
+
+func newAEAD(passphrase []byte, params scryptParams) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, params.Salt, params.N, params.R, params.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext bound to name, so sealed values cannot be swapped
+// between roles.
+func (s *Store) seal(name string, plaintext []byte) (sealed, error) {
+	if s.aead == nil {
+		return sealed{}, ErrLocked
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return sealed{}, err
+	}
+	return sealed{Nonce: nonce, Ciphertext: s.aead.Seal(nil, nonce, plaintext, []byte(name))}, nil
+}
+
+func (s *Store) open(name string, v sealed) ([]byte, error) {
+	if s.aead == nil {
+		return nil, ErrLocked
+	}
+	if len(v.Nonce) != s.aead.NonceSize() {
+		return nil, fmt.Errorf("%s: invalid nonce", name)
+	}
+	return s.aead.Open(nil, v.Nonce, v.Ciphertext, []byte(name))
+}
+
+// save writes the store to a temporary file renamed over path, so an
+// interrupted write never loses keys.
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(&s.file, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Path returns the file of the store.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Roles returns the roles the store holds, sorted.
+func (s *Store) Roles() []string {
+	roles := make([]string, 0, len(s.file.Keys))
+	for role := range s.file.Keys {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// PublicKey returns the public key of role; it works on locked stores.
+func (s *Store) PublicKey(role string) (solana.PublicKey, error) {
+	e, ok := s.file.Keys[role]
+	if !ok {
+		return solana.PublicKey{}, fmt.Errorf("%w %s", ErrNotFound, role)
+	}
+	return e.PublicKey, nil
+}
+
+// DerivationPath returns the path role was derived at, empty for generated
+// and imported keys.
+func (s *Store) DerivationPath(role string) (string, error) {
+	e, ok := s.file.Keys[role]
+	if !ok {
+		return "", fmt.Errorf("%w %s", ErrNotFound, role)
+	}
+	return e.Path, nil
+}
+
+// Key decrypts the private key of role.
+func (s *Store) Key(role string) (solana.PrivateKey, error) {
+	e, ok := s.file.Keys[role]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrNotFound, role)
+	}
+	raw, err := s.open("role:"+role, e.sealed)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", role, err)
+	}
+	key := solana.PrivateKey(raw)
+	if !key.PublicKey().Equals(e.PublicKey) {
+		return nil, fmt.Errorf("%s: private key does not match public key %s", role, e.PublicKey)
+	}
+	return key, nil
+}
+
+// Add stores key as role.
+func (s *Store) Add(role string, key solana.PrivateKey) error {
+	return s.add(role, key, "")
+}
+
+func (s *Store) add(role string, key solana.PrivateKey, path string) error {
+	if err := ValidRole(role); err != nil {
+		return err
+	}
+	if _, ok := s.file.Keys[role]; ok {
+		return fmt.Errorf("%s: %w", role, ErrExists)
+	}
+	if len(key) != 64 {
+		return fmt.Errorf("%s: invalid private key length %d", role, len(key))
+	}
+	v, err := s.seal("role:"+role, key)
+	if err != nil {
+		return err
+	}
+	s.file.Keys[role] = entry{PublicKey: key.PublicKey(), Path: path, sealed: v}
+	return s.save()
+}
+
+// Generate adds a key for role, derived from the mnemonic on the role's
+// account (see RoleAccount) when the store has one, random otherwise.
+func (s *Store) Generate(role string) (solana.PrivateKey, error) {
+	account, err := RoleAccount(role)
+	if err != nil {
+		return nil, err
+	}
+	if s.file.Mnemonic == nil {
+		key := solana.NewWallet().PrivateKey
+		return key, s.add(role, key, "")
+	}
+	mnemonic, err := s.Mnemonic()
+	if err != nil {
+		return nil, err
+	}
+	key, err := Derive(mnemonic, account)
+	if err != nil {
+		return nil, err
+	}
+	return key, s.add(role, key, DerivationPath(account))
+}
+
+// Remove deletes role from the store.
+func (s *Store) Remove(role string) error {
+	if _, ok := s.file.Keys[role]; !ok {
+		return fmt.Errorf("%w %s", ErrNotFound, role)
+	}
+	if s.aead == nil {
+		return ErrLocked
+	}
+	delete(s.file.Keys, role)
+	return s.save()
+}
+
+// Mnemonic decrypts the mnemonic of the store.
+func (s *Store) Mnemonic() (string, error) {
+	if s.file.Mnemonic == nil {
+		return "", ErrNoMnemonic
+	}
+	m, err := s.open("mnemonic", *s.file.Mnemonic)
+	return string(m), err
+}
+
+// Import adds the keypair file of the Solana CLI at path as role.
+func (s *Store) Import(role, path string) error {
+	key, err := solana.PrivateKeyFromSolanaKeygenFile(path)
+	if err != nil {
+		return err
+	}
+	return s.Add(role, key)
+}
+
+// Export writes role to path as a keypair file of the Solana CLI. It does
+// not overwrite existing files.
+func (s *Store) Export(role, path string) error {
+	key, err := s.Key(role)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s: %w", path, ErrExists)
+	}
+	return WriteKeygenFile(path, key)
+}
+
+// WriteKeygenFile writes key as solana-keygen does: a JSON array of the 64
+// bytes of the keypair, readable by the owner only.
+func WriteKeygenFile(path string, key solana.PrivateKey) error {
+	raw := make([]int, len(key))
+	for i, b := range key {
+		raw[i] = int(b)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Environment variables read by FromEnv.
+const (
+	EnvPath       = "SUPERNODE_KEYSTORE"
+	EnvPassphrase = "SUPERNODE_PASSPHRASE"
+)
+
+// DefaultPath is the keystore file used when none is given.
+const DefaultPath = "keystore.json"
+
+// FromEnv returns the key of role from the keystore named by
+// $SUPERNODE_KEYSTORE (default keystore.json), unlocked with
+// $SUPERNODE_PASSPHRASE.
+func FromEnv(role string) (solana.PrivateKey, error) {
+	path := os.Getenv(EnvPath)
+	if path == "" {
+		path = DefaultPath
+	}
+	passphrase, ok := os.LookupEnv(EnvPassphrase)
+	if !ok {
+		return nil, fmt.Errorf("%s is not set", EnvPassphrase)
+	}
+	s, err := Open(path, []byte(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	return s.Key(role)
+}
@@ -0,0 +1,179 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	ag_require "github.com/stretchr/testify/require"
+)
+
+// fast keeps scrypt cheap in tests.
+var fast = Options{ScryptN: 1 << 10}
+
+const mnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// TestDeriveSLIP10 checks the ed25519 derivation against test vector 1 of
+// SLIP-0010.
+func TestDeriveSLIP10(t *testing.T) {
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	ag_require.NoError(t, err)
+	for _, c := range []struct {
+		path []uint32
+		want string
+	}{
+		{nil, "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7"},
+		{[]uint32{0}, "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3"},
+		{[]uint32{0, 1}, "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2"},
+	} {
+		ag_require.Equal(t, c.want, hex.EncodeToString(derive(seed, c.path)[:32]))
+	}
+}
+
+func TestRoleAccount(t *testing.T) {
+	for role, want := range map[string]uint32{"admin": 0, "mint": 1, "provider-0": 1000, "controller-2": 2002, "tenant-999": 3999} {
+		got, err := RoleAccount(role)
+		ag_require.NoError(t, err, role)
+		ag_require.Equal(t, want, got, role)
+	}
+	for _, role := range []string{"", "root", "provider", "provider-", "provider-x", "provider-01", "tenant-1000", "admin-0"} {
+		ag_require.ErrorIs(t, ValidRole(role), ErrInvalidRole, role)
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keystore.json")
+	passphrase := []byte("correct horse")
+	s, err := Create(path, passphrase, mnemonic, fast)
+	ag_require.NoError(t, err)
+	_, err = Create(path, passphrase, "", fast)
+	ag_require.ErrorIs(t, err, ErrExists)
+
+	admin, err := s.Generate("admin")
+	ag_require.NoError(t, err)
+	derived, err := Derive(mnemonic, 0)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, derived, admin)
+	provider, err := s.Generate("provider-1")
+	ag_require.NoError(t, err)
+	derived, err = Derive(mnemonic, 1001)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, derived, provider)
+	_, err = s.Generate("admin")
+	ag_require.ErrorIs(t, err, ErrExists)
+	_, err = s.Generate("operator")
+	ag_require.ErrorIs(t, err, ErrInvalidRole)
+
+	tenant := solana.NewWallet().PrivateKey
+	ag_require.NoError(t, s.Add("tenant-0", tenant))
+
+	info, err := os.Stat(path)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	raw, err := os.ReadFile(path)
+	ag_require.NoError(t, err)
+	ag_require.NotContains(t, string(raw), "abandon")
+	ag_require.NotContains(t, string(raw), tenant.String())
+
+	t.Run("reopen", func(t *testing.T) {
+		s, err := Open(path, passphrase)
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, []string{"admin", "provider-1", "tenant-0"}, s.Roles())
+		key, err := s.Key("admin")
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, admin, key)
+		key, err = s.Key("tenant-0")
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, tenant, key)
+		p, err := s.DerivationPath("provider-1")
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, "m/44'/501'/1001'/0'", p)
+		m, err := s.Mnemonic()
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, mnemonic, m)
+		_, err = s.Key("controller-0")
+		ag_require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		_, err := Open(path, []byte("wrong"))
+		ag_require.ErrorIs(t, err, ErrWrongPassphrase)
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		s, err := Open(path, nil)
+		ag_require.NoError(t, err)
+		key, err := s.PublicKey("admin")
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, admin.PublicKey(), key)
+		_, err = s.Key("admin")
+		ag_require.ErrorIs(t, err, ErrLocked)
+		_, err = s.Generate("controller-0")
+		ag_require.ErrorIs(t, err, ErrLocked)
+	})
+
+	t.Run("swapped ciphertexts", func(t *testing.T) {
+		s, err := Open(path, passphrase)
+		ag_require.NoError(t, err)
+		e := s.file.Keys["admin"]
+		e.sealed = s.file.Keys["tenant-0"].sealed
+		s.file.Keys["admin"] = e
+		_, err = s.Key("admin")
+		ag_require.Error(t, err)
+	})
+
+	t.Run("solana cli", func(t *testing.T) {
+		s, err := Open(path, passphrase)
+		ag_require.NoError(t, err)
+		out := filepath.Join(dir, "tenant.json")
+		ag_require.NoError(t, s.Export("tenant-0", out))
+		ag_require.ErrorIs(t, s.Export("tenant-0", out), ErrExists)
+		key, err := solana.PrivateKeyFromSolanaKeygenFile(out)
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, tenant, key)
+
+		ag_require.NoError(t, s.Remove("tenant-0"))
+		ag_require.NoError(t, s.Import("tenant-0", out))
+		key, err = s.Key("tenant-0")
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, tenant, key)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv(EnvPath, path)
+		t.Setenv(EnvPassphrase, string(passphrase))
+		key, err := FromEnv("admin")
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, admin, key)
+	})
+}
+
+func TestCreate_InvalidMnemonic(t *testing.T) {
+	_, err := Create(filepath.Join(t.TempDir(), "keystore.json"), []byte("x"), "abandon abandon", fast)
+	ag_require.ErrorIs(t, err, ErrInvalidMnemonic)
+	_, err = Derive("abandon abandon", 0)
+	ag_require.ErrorIs(t, err, ErrInvalidMnemonic)
+
+	m, err := NewMnemonic()
+	ag_require.NoError(t, err)
+	_, err = Derive(m, 0)
+	ag_require.NoError(t, err)
+}
+
+func TestStore_RandomKeys(t *testing.T) {
+	s, err := Create(filepath.Join(t.TempDir(), "keystore.json"), []byte("x"), "", fast)
+	ag_require.NoError(t, err)
+	key, err := s.Generate("controller-0")
+	ag_require.NoError(t, err)
+	p, err := s.DerivationPath("controller-0")
+	ag_require.NoError(t, err)
+	ag_require.Empty(t, p)
+	_, err = s.Mnemonic()
+	ag_require.ErrorIs(t, err, ErrNoMnemonic)
+	got, err := s.Key("controller-0")
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, key, got)
+}
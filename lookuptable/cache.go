@@ -0,0 +1,89 @@
+package lookuptable
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"n3-solana-test/client"
+	"n3-solana-test/rpcclient"
+)
+
+// Cache remembers the contents of lookup tables. Call Forget after extending a
+// cached table.
+type Cache struct {
+	rpcClient rpcclient.Client
+
+	mu     sync.Mutex
+	tables map[solana.PublicKey]solana.PublicKeySlice
+	// forgets counts the calls to Forget, so a fetch that raced one does not
+	// cache what it read.
+	forgets uint64
+}
+
+// NewCache returns an empty cache reading tables through rpcClient.
+func NewCache(rpcClient rpcclient.Client) *Cache {
+	return &Cache{rpcClient: rpcClient, tables: make(map[solana.PublicKey]solana.PublicKeySlice)}
+}
+
+// Get returns the contents of the given tables, fetching the ones not cached
+// with a single request. The cache is not locked during the request.
+func (c *Cache) Get(ctx context.Context, tables ...solana.PublicKey) (map[solana.PublicKey]solana.PublicKeySlice, error) {
+	result := make(map[solana.PublicKey]solana.PublicKeySlice, len(tables))
+	var missing []solana.PublicKey
+	c.mu.Lock()
+	for _, table := range tables {
+		if addresses, ok := c.tables[table]; ok {
+			result[table] = addresses
+		} else if !slices.Contains(missing, table) {
+			missing = append(missing, table)
+		}
+	}
+	forgets := c.forgets
+	c.mu.Unlock()
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	out, err := c.rpcClient.GetMultipleAccounts(ctx, missing...)
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Value) != len(missing) {
+		return nil, fmt.Errorf("requested %d lookup tables, got %d accounts", len(missing), len(out.Value))
+	}
+	for i, table := range missing {
+		addresses, err := decode(table, out.Value[i])
+		if err != nil {
+			return nil, err
+		}
+		result[table] = addresses
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.forgets == forgets {
+		for _, table := range missing {
+			c.tables[table] = result[table]
+		}
+	}
+	return result, nil
+}
+
+// Forget drops table from the cache.
+func (c *Cache) Forget(table solana.PublicKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tables, table)
+	c.forgets++
+}
+
+// Getter adapts the cache to client.DecodeEvents and
+// client.SetAddressTablesGetter.
+func (c *Cache) Getter(ctx context.Context) client.AddressTablesGetter {
+	return func(altAddresses []solana.PublicKey) (map[solana.PublicKey]solana.PublicKeySlice, error) {
+		return c.Get(ctx, altAddresses...)
+	}
+}
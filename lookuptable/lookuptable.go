@@ -0,0 +1,231 @@
+// Package lookuptable maintains the address lookup table holding the accounts
+// every supernode instruction references, and builds v0 transactions that load
+// those accounts through it.
+package lookuptable
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	addresslookuptable "github.com/gagliardetto/solana-go/programs/address-lookup-table"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/client"
+	"n3-solana-test/priorityfee"
+	"n3-solana-test/rpcclient"
+)
+
+// ProgramID is the native address lookup table program.
+var ProgramID = solana.MustPublicKeyFromBase58("AddressLookupTab1e1111111111111111111111111")
+
+// MaxExtendAddresses is the number of addresses appended per extend
+// instruction, which keeps each extend transaction well under the packet size.
+const MaxExtendAddresses = 20
+
+const (
+	instructionCreate uint32 = 0
+	instructionExtend uint32 = 2
+)
+
+// StaticAccounts returns the accounts shared by the supernode instructions:
+// the supernode, stake, vesting, rental and reward PDAs, the token mint and the
+// token, system and associated token programs.
+func StaticAccounts(mint solana.PublicKey) (solana.PublicKeySlice, error) {
+	b := client.NewInitializeInstructionBuilder()
+	supernode, _, err := b.FindSupernodeAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supernode PDA: %w", err)
+	}
+	stake, _, err := b.FindSupernodeStakeAccountAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supernode stake PDA: %w", err)
+	}
+	vesting, _, err := b.FindSupernodeVestingAccountAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supernode vesting PDA: %w", err)
+	}
+	rental, _, err := b.FindSupernodeRentalAccountAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supernode rental PDA: %w", err)
+	}
+	reward, _, err := client.NewInitRewardAccountInstructionBuilder().FindSupernodeRewardAccountAddress()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supernode reward PDA: %w", err)
+	}
+	return solana.PublicKeySlice{
+		supernode, stake, vesting, rental, reward, mint,
+		solana.TokenProgramID, solana.SystemProgramID, solana.SPLAssociatedTokenAccountProgramID,
+	}, nil
+}
+
+// DeriveAddress returns the table created by authority at recentSlot.
+func DeriveAddress(authority solana.PublicKey, recentSlot uint64) (solana.PublicKey, uint8, error) {
+	slot := make([]byte, 8)
+	binary.LittleEndian.PutUint64(slot, recentSlot)
+	return solana.FindProgramAddress([][]byte{authority[:], slot}, ProgramID)
+}
+
+// NewCreateInstruction creates the table derived from authority and
+// recentSlot, which must be a slot the cluster still remembers.
+func NewCreateInstruction(authority, payer solana.PublicKey, recentSlot uint64) (solana.Instruction, solana.PublicKey, error) {
+	table, bump, err := DeriveAddress(authority, recentSlot)
+	if err != nil {
+		return nil, solana.PublicKey{}, err
+	}
+	data := binary.LittleEndian.AppendUint32(nil, instructionCreate)
+	data = binary.LittleEndian.AppendUint64(data, recentSlot)
+	data = append(data, bump)
+	return solana.NewInstruction(ProgramID, solana.AccountMetaSlice{
+		solana.Meta(table).WRITE(),
+		solana.Meta(authority).SIGNER(),
+		solana.Meta(payer).WRITE().SIGNER(),
+		solana.Meta(solana.SystemProgramID),
+	}, data), table, nil
+}
+
+// NewExtendInstruction appends addresses to table.
+func NewExtendInstruction(table, authority, payer solana.PublicKey, addresses solana.PublicKeySlice) solana.Instruction {
+	data := binary.LittleEndian.AppendUint32(nil, instructionExtend)
+	data = binary.LittleEndian.AppendUint64(data, uint64(len(addresses)))
+	for _, address := range addresses {
+		data = append(data, address[:]...)
+	}
+	return solana.NewInstruction(ProgramID, solana.AccountMetaSlice{
+		solana.Meta(table).WRITE(),
+		solana.Meta(authority).SIGNER(),
+		solana.Meta(payer).WRITE().SIGNER(),
+		solana.Meta(solana.SystemProgramID),
+	}, data)
+}
+
+// Fetch returns the addresses stored in table.
+func Fetch(ctx context.Context, rpcClient rpcclient.Client, table solana.PublicKey) (solana.PublicKeySlice, error) {
+	account, err := rpcClient.GetAccountInfo(ctx, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch lookup table %s: %w", table, err)
+	}
+	return decode(table, account.Value)
+}
+
+func decode(table solana.PublicKey, account *rpc.Account) (solana.PublicKeySlice, error) {
+	if account == nil {
+		return nil, fmt.Errorf("lookup table %s not found", table)
+	}
+	if !account.Owner.Equals(ProgramID) {
+		return nil, fmt.Errorf("%s is not a lookup table", table)
+	}
+	state, err := addresslookuptable.DecodeAddressLookupTableState(account.Data.GetBinary())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode lookup table %s: %w", table, err)
+	}
+	if !state.IsActive() {
+		return nil, fmt.Errorf("lookup table %s is deactivated", table)
+	}
+	return state.Addresses, nil
+}
+
+// Options configure Ensure.
+type Options struct {
+	// Fees, when set, prices the transactions Ensure sends and rebroadcasts
+	// them at a rising price until they land.
+	Fees *priorityfee.Estimator
+	// Created, when set, is called with the address of a table Ensure
+	// creates as soon as the creation lands, before the table is extended
+	// further, so a caller can record the table even if an extension
+	// fails. An error of Created stops Ensure.
+	Created func(table solana.PublicKey) error
+}
+
+// Ensure makes table hold every one of addresses, creating the table when
+// table is the zero key, and returns the table address. authority owns the
+// table and pays for it. Each transaction is confirmed before the next is sent
+// so the table is never extended out of order.
+func Ensure(ctx context.Context, rpcClient rpcclient.Client, authority solana.PrivateKey, table solana.PublicKey, addresses solana.PublicKeySlice, opts Options) (solana.PublicKey, error) {
+	owner := authority.PublicKey()
+	var (
+		present  solana.PublicKeySlice
+		creation solana.Instruction
+	)
+	if table.IsZero() {
+		slot, err := rpcClient.GetSlot(ctx, rpc.CommitmentFinalized)
+		if err != nil {
+			return table, fmt.Errorf("failed to fetch slot: %w", err)
+		}
+		if creation, table, err = NewCreateInstruction(owner, owner, slot); err != nil {
+			return table, err
+		}
+	} else {
+		var err error
+		if present, err = Fetch(ctx, rpcClient, table); err != nil {
+			return table, err
+		}
+	}
+
+	var missing solana.PublicKeySlice
+	for _, address := range addresses {
+		if !present.Contains(address) && !missing.Contains(address) {
+			missing = append(missing, address)
+		}
+	}
+	if len(present)+len(missing) > addresslookuptable.LOOKUP_TABLE_MAX_ADDRESSES {
+		return table, fmt.Errorf("lookup table %s cannot hold %d more addresses", table, len(missing))
+	}
+
+	for creation != nil || len(missing) > 0 {
+		var instructions []solana.Instruction
+		created := creation != nil
+		if created {
+			instructions = append(instructions, creation)
+			creation = nil
+		}
+		if len(missing) > 0 {
+			n := min(len(missing), MaxExtendAddresses)
+			instructions = append(instructions, NewExtendInstruction(table, owner, owner, missing[:n]))
+			missing = missing[n:]
+		}
+		if err := send(ctx, rpcClient, authority, instructions, opts.Fees); err != nil {
+			return table, fmt.Errorf("failed to update lookup table %s: %w", table, err)
+		}
+		if created && opts.Created != nil {
+			if err := opts.Created(table); err != nil {
+				return table, err
+			}
+		}
+	}
+	return table, nil
+}
+
+func send(ctx context.Context, rpcClient rpcclient.Client, authority solana.PrivateKey, instructions []solana.Instruction, fees *priorityfee.Estimator) error {
+	if fees != nil {
+		_, err := fees.Send(ctx, []solana.PrivateKey{authority}, instructions...)
+		return err
+	}
+	recent, err := rpcClient.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return err
+	}
+	tx, err := solana.NewTransaction(instructions, recent.Value.Blockhash, solana.TransactionPayer(authority.PublicKey()))
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		if key.Equals(authority.PublicKey()) {
+			return &authority
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	sig, err := rpcClient.SendTransaction(ctx, tx)
+	if err != nil {
+		return err
+	}
+	return rpcclient.WaitForConfirmation(ctx, rpcClient, sig, rpc.CommitmentConfirmed)
+}
+
+// NewTransaction builds a v0 transaction that loads the accounts found in
+// tables through lookups. Signers and invoked programs always stay inline.
+func NewTransaction(instructions []solana.Instruction, blockhash solana.Hash, payer solana.PublicKey, tables map[solana.PublicKey]solana.PublicKeySlice) (*solana.Transaction, error) {
+	return solana.NewTransaction(instructions, blockhash, solana.TransactionPayer(payer), solana.TransactionAddressTables(tables))
+}
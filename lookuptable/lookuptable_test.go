@@ -0,0 +1,265 @@
+package lookuptable
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	addresslookuptable "github.com/gagliardetto/solana-go/programs/address-lookup-table"
+	"github.com/gagliardetto/solana-go/rpc"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/client"
+	"n3-solana-test/rpcclient"
+)
+
+func init() {
+	client.SetProgramID(solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy"))
+}
+
+// tableRPC executes lookup table instructions against an in-memory ledger.
+type tableRPC struct {
+	rpcclient.Client
+	t      *testing.T
+	tables map[solana.PublicKey]*addresslookuptable.AddressLookupTableState
+	sent   int
+}
+
+func newTableRPC(t *testing.T) *tableRPC {
+	return &tableRPC{t: t, tables: make(map[solana.PublicKey]*addresslookuptable.AddressLookupTableState)}
+}
+
+func (r *tableRPC) account(key solana.PublicKey) *rpc.Account {
+	state, ok := r.tables[key]
+	if !ok {
+		return nil
+	}
+	buf := new(bytes.Buffer)
+	ag_require.NoError(r.t, state.MarshalWithEncoder(bin.NewBinEncoder(buf)))
+	return &rpc.Account{Owner: ProgramID, Data: rpc.DataBytesOrJSONFromBytes(buf.Bytes())}
+}
+
+func (r *tableRPC) GetAccountInfo(_ context.Context, key solana.PublicKey) (*rpc.GetAccountInfoResult, error) {
+	return &rpc.GetAccountInfoResult{Value: r.account(key)}, nil
+}
+
+func (r *tableRPC) GetMultipleAccounts(_ context.Context, keys ...solana.PublicKey) (*rpc.GetMultipleAccountsResult, error) {
+	out := &rpc.GetMultipleAccountsResult{}
+	for _, key := range keys {
+		out.Value = append(out.Value, r.account(key))
+	}
+	return out, nil
+}
+
+func (r *tableRPC) GetSlot(context.Context, rpc.CommitmentType) (uint64, error) {
+	return 4242, nil
+}
+
+func (r *tableRPC) GetLatestBlockhash(context.Context, rpc.CommitmentType) (*rpc.GetLatestBlockhashResult, error) {
+	return &rpc.GetLatestBlockhashResult{Value: &rpc.LatestBlockhashResult{Blockhash: solana.Hash{7}}}, nil
+}
+
+func (r *tableRPC) SendTransaction(_ context.Context, tx *solana.Transaction) (solana.Signature, error) {
+	ag_require.NoError(r.t, tx.VerifySignatures())
+	r.sent++
+	for _, ins := range tx.Message.Instructions {
+		accounts, err := ins.ResolveInstructionAccounts(&tx.Message)
+		ag_require.NoError(r.t, err)
+		table := accounts[0].PublicKey
+		switch binary.LittleEndian.Uint32(ins.Data) {
+		case instructionCreate:
+			slot := binary.LittleEndian.Uint64(ins.Data[4:])
+			derived, _, err := DeriveAddress(accounts[1].PublicKey, slot)
+			ag_require.NoError(r.t, err)
+			ag_require.Equal(r.t, derived, table)
+			authority := accounts[1].PublicKey
+			r.tables[table] = &addresslookuptable.AddressLookupTableState{
+				TypeIndex:        1,
+				DeactivationSlot: math.MaxUint64,
+				Authority:        &authority,
+			}
+		case instructionExtend:
+			state := r.tables[table]
+			ag_require.NotNil(r.t, state)
+			n := binary.LittleEndian.Uint64(ins.Data[4:])
+			for i := uint64(0); i < n; i++ {
+				state.Addresses = append(state.Addresses, solana.PublicKeyFromBytes(ins.Data[12+32*i:44+32*i]))
+			}
+		}
+	}
+	return tx.Signatures[0], nil
+}
+
+func (r *tableRPC) GetSignatureStatuses(context.Context, bool, ...solana.Signature) (*rpc.GetSignatureStatusesResult, error) {
+	return &rpc.GetSignatureStatusesResult{Value: []*rpc.SignatureStatusesResult{{ConfirmationStatus: rpc.ConfirmationStatusFinalized}}}, nil
+}
+
+func TestEnsure(t *testing.T) {
+	ctx := context.Background()
+	r := newTableRPC(t)
+	authority := solana.NewWallet().PrivateKey
+	mint := solana.NewWallet().PublicKey()
+	static, err := StaticAccounts(mint)
+	ag_require.NoError(t, err)
+	ag_require.Len(t, static, 9)
+
+	table, err := Ensure(ctx, r, authority, solana.PublicKey{}, static, Options{})
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, 1, r.sent)
+	addresses, err := Fetch(ctx, r, table)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, static, addresses)
+
+	// Nothing to do once every address is present.
+	again, err := Ensure(ctx, r, authority, table, static, Options{})
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, table, again)
+	ag_require.Equal(t, 1, r.sent)
+
+	extra := make(solana.PublicKeySlice, MaxExtendAddresses+1)
+	for i := range extra {
+		extra[i] = solana.NewWallet().PublicKey()
+	}
+	_, err = Ensure(ctx, r, authority, table, append(static, extra...), Options{})
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, 3, r.sent)
+	addresses, err = Fetch(ctx, r, table)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, append(static, extra...), addresses)
+}
+
+func TestEnsure_Created(t *testing.T) {
+	ctx := context.Background()
+	r := newTableRPC(t)
+	authority := solana.NewWallet().PrivateKey
+	addresses := make(solana.PublicKeySlice, 2*MaxExtendAddresses)
+	for i := range addresses {
+		addresses[i] = solana.NewWallet().PublicKey()
+	}
+
+	var created []solana.PublicKey
+	var sentBefore int
+	table, err := Ensure(ctx, r, authority, solana.PublicKey{}, addresses, Options{Created: func(table solana.PublicKey) error {
+		created = append(created, table)
+		sentBefore = r.sent
+		return nil
+	}})
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, []solana.PublicKey{table}, created)
+	// Recorded after the creation, before the second extension.
+	ag_require.Equal(t, 1, sentBefore)
+	ag_require.Equal(t, 2, r.sent)
+
+	// An error of Created stops Ensure before it extends the table further.
+	stop := errors.New("profile not writable")
+	r = newTableRPC(t)
+	_, err = Ensure(ctx, r, authority, solana.PublicKey{}, addresses, Options{Created: func(solana.PublicKey) error { return stop }})
+	ag_require.ErrorIs(t, err, stop)
+	ag_require.Equal(t, 1, r.sent)
+}
+
+// shortRPC answers GetMultipleAccounts with one account less than asked.
+type shortRPC struct {
+	*tableRPC
+	calls int
+}
+
+func (r *shortRPC) GetMultipleAccounts(ctx context.Context, keys ...solana.PublicKey) (*rpc.GetMultipleAccountsResult, error) {
+	r.calls++
+	out, err := r.tableRPC.GetMultipleAccounts(ctx, keys...)
+	if err != nil || r.calls == 1 {
+		return out, err
+	}
+	out.Value = out.Value[:len(out.Value)-1]
+	return out, nil
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	r := &shortRPC{tableRPC: newTableRPC(t)}
+	authority := solana.NewWallet().PrivateKey
+	static, err := StaticAccounts(solana.NewWallet().PublicKey())
+	ag_require.NoError(t, err)
+	table, err := Ensure(ctx, r.tableRPC, authority, solana.PublicKey{}, static, Options{})
+	ag_require.NoError(t, err)
+
+	cache := NewCache(r)
+	got, err := cache.Get(ctx, table, table)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, static, got[table])
+	ag_require.Equal(t, 1, r.calls)
+
+	// Cached tables are not fetched again.
+	_, err = cache.Get(ctx, table)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, 1, r.calls)
+
+	cache.Forget(table)
+	_, err = cache.Get(ctx, table)
+	ag_require.EqualError(t, err, "requested 1 lookup tables, got 0 accounts")
+}
+
+func TestDecodeResolvesLookups(t *testing.T) {
+	ctx := context.Background()
+	r := newTableRPC(t)
+	authority := solana.NewWallet().PrivateKey
+	provider := solana.NewWallet().PublicKey()
+	admin := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+	static, err := StaticAccounts(mint)
+	ag_require.NoError(t, err)
+	table, err := Ensure(ctx, r, authority, solana.PublicKey{}, static, Options{})
+	ag_require.NoError(t, err)
+
+	b := client.NewStakeDeviceInstructionBuilder()
+	stakeInfo := b.MustFindProviderStakeInfoAddress(provider)
+	ata, _, err := solana.FindAssociatedTokenAddress(provider, mint)
+	ag_require.NoError(t, err)
+	stake := client.NewStakeDeviceInstruction(
+		7, 1,
+		static[0], static[1], stakeInfo, ata, mint, provider, provider, admin,
+		solana.TokenProgramID, solana.SystemProgramID, solana.SPLAssociatedTokenAccountProgramID,
+	).Build()
+	tx, err := NewTransaction([]solana.Instruction{stake}, solana.Hash{1}, provider, map[solana.PublicKey]solana.PublicKeySlice{table: static})
+	ag_require.NoError(t, err)
+	ag_require.True(t, tx.Message.IsVersioned())
+	ag_require.Len(t, tx.Message.AddressTableLookups, 1)
+	raw, err := tx.MarshalBinary()
+	ag_require.NoError(t, err)
+
+	client.SetAddressTablesGetter(NewCache(r).Getter(ctx))
+	defer client.SetAddressTablesGetter(nil)
+
+	decodedTx, err := solana.TransactionFromBytes(raw)
+	ag_require.NoError(t, err)
+	decoded, err := client.DecodeInstructions(&decodedTx.Message)
+	ag_require.NoError(t, err)
+	ag_require.Len(t, decoded, 1)
+	// The tables are resolved on a copy of the message.
+	ag_require.Nil(t, decodedTx.Message.GetAddressTables())
+	ag_require.Equal(t, stake.Accounts()[0].PublicKey, decoded[0].Accounts()[0].PublicKey)
+	ag_require.Equal(t, mint, decoded[0].Accounts()[4].PublicKey)
+
+	buf := new(bytes.Buffer)
+	ag_require.NoError(t, client.DeviceStakedEventEventData{Provider: provider, DeviceId: 7, SpecId: 1, Amount: 10}.MarshalWithEncoder(bin.NewBorshEncoder(buf)))
+	result := new(rpc.GetTransactionResult)
+	ag_require.NoError(t, json.Unmarshal([]byte(`{
+		"slot": 1,
+		"transaction": ["`+base64.StdEncoding.EncodeToString(raw)+`", "base64"],
+		"meta": {"logMessages": [
+			"Program `+client.ProgramID.String()+` invoke [1]",
+			"Program data: `+base64.StdEncoding.EncodeToString(buf.Bytes())+`",
+			"Program `+client.ProgramID.String()+` success"
+		]}
+	}`), result))
+	events, err := client.DecodeEvents(result, client.ProgramID, nil)
+	ag_require.NoError(t, err)
+	ag_require.Len(t, events, 1)
+	ag_require.Equal(t, provider, events[0].Data.(*client.DeviceStakedEventEventData).Provider)
+}
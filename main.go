@@ -7,10 +7,12 @@ import (
 	"github.com/gagliardetto/solana-go/programs/token"
 	"github.com/gagliardetto/solana-go/rpc"
 	"log"
+	"n3-solana-test/amount"
 	sol_client "n3-solana-test/client"
 )
 
-const BILLION = 1000000000
+// tokenDecimals are the decimals of the supernode token mint.
+const tokenDecimals = 9
 
 var (
 	client *rpc.Client
@@ -47,7 +49,7 @@ func createAccount() solana.PrivateKey {
 }
 
 func airdrop(pubKey solana.PublicKey) {
-	sig, err := client.RequestAirdrop(ctx, pubKey, 10000*BILLION, rpc.CommitmentFinalized)
+	sig, err := client.RequestAirdrop(ctx, pubKey, 10000*solana.LAMPORTS_PER_SOL, rpc.CommitmentFinalized)
 	if err != nil {
 		log.Fatalf("Airdrop failed: %v", err)
 	}
@@ -64,7 +66,7 @@ func createTokenAccount(owner solana.PrivateKey, mint solana.PublicKey) solana.P
 }
 
 func createMint(owner solana.PrivateKey) solana.PublicKey {
-	mint, err := token.CreateMint(ctx, client, owner.PublicKey(), nil, 9, owner, solana.ProgramToken)
+	mint, err := token.CreateMint(ctx, client, owner.PublicKey(), nil, tokenDecimals, owner, solana.ProgramToken)
 	if err != nil {
 		log.Fatalf("Mint creation failed: %v", err)
 	}
@@ -73,11 +75,12 @@ func createMint(owner solana.PrivateKey) solana.PublicKey {
 }
 
 func initializeSupernode() {
+	stakingCoefficient := amount.MustTokens(10, tokenDecimals)
 	inst := NewInitializeInstruction(
-		90,                // reward_locked_time
-		10*BILLION,        // staking_coefficient
-		mint,              // token mint
-		admin.PublicKey(), // admin
+		90,                         // reward_locked_time
+		stakingCoefficient.Units(), // staking_coefficient
+		mint,                       // token mint
+		admin.PublicKey(),          // admin
 	)
 
 	tx, err := solana.NewTransaction(
@@ -0,0 +1,114 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"n3-solana-test/amount"
+	"n3-solana-test/client"
+	"n3-solana-test/rpcclient"
+)
+
+// ErrNonceAdvanced is returned when the nonce stored in the envelope is no
+// longer the current value of the nonce account, i.e. the transaction (or
+// another one using the same nonce) has already been processed.
+var ErrNonceAdvanced = errors.New("nonce account has been advanced since the envelope was built")
+
+// FetchNonce loads and decodes a durable nonce account.
+func FetchNonce(ctx context.Context, rpcClient rpcclient.Client, nonceAccount solana.PublicKey) (*system.NonceAccount, error) {
+	info, err := rpcClient.GetAccountInfo(ctx, nonceAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch nonce account %s: %w", nonceAccount, err)
+	}
+	if info == nil || info.Value == nil {
+		return nil, fmt.Errorf("nonce account %s not found", nonceAccount)
+	}
+	if !info.Value.Owner.Equals(solana.SystemProgramID) {
+		return nil, fmt.Errorf("nonce account %s is not owned by the system program", nonceAccount)
+	}
+	nonce := new(system.NonceAccount)
+	if err := nonce.UnmarshalWithDecoder(bin.NewBinDecoder(info.Value.Data.GetBinary())); err != nil {
+		return nil, fmt.Errorf("failed to decode nonce account %s: %w", nonceAccount, err)
+	}
+	if nonce.State != 1 {
+		return nil, fmt.Errorf("nonce account %s is not initialized", nonceAccount)
+	}
+	return nonce, nil
+}
+
+// Build creates an unsigned durable-nonce transaction. The first instruction
+// advances the nonce account, and the transaction's blockhash is the nonce
+// value, so the transaction stays valid until the nonce is advanced.
+func Build(ctx context.Context, rpcClient rpcclient.Client, nonceAccount, feePayer solana.PublicKey, description string, instructions ...solana.Instruction) (*Envelope, error) {
+	nonce, err := FetchNonce(ctx, rpcClient, nonceAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	advance, err := system.NewAdvanceNonceAccountInstruction(
+		nonceAccount,
+		solana.SysVarRecentBlockHashesPubkey,
+		nonce.AuthorizedPubkey,
+	).ValidateAndBuild()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := solana.NewTransaction(
+		append([]solana.Instruction{advance}, instructions...),
+		solana.Hash(nonce.Nonce),
+		solana.TransactionPayer(feePayer),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	e := &Envelope{
+		Version:        EnvelopeVersion,
+		Description:    description,
+		CreatedAt:      time.Now().UTC(),
+		NonceAccount:   nonceAccount.String(),
+		NonceAuthority: nonce.AuthorizedPubkey.String(),
+		Nonce:          solana.Hash(nonce.Nonce).String(),
+		FeePayer:       feePayer.String(),
+	}
+	if err := e.SetTx(tx); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// UpdateKValue builds the UpdateKValue instruction for the given admin.
+func UpdateKValue(admin solana.PublicKey, specID uint16, val uint64) (solana.Instruction, error) {
+	b := client.NewUpdateKValueInstructionBuilder()
+	supernode, _, err := b.FindSupernodeAddress()
+	if err != nil {
+		return nil, err
+	}
+	return b.SetSpecId(specID).SetVal(val).SetSupernodeAccount(supernode).SetAdminAccount(admin).ValidateAndBuild()
+}
+
+// UpdateStakingCoefficient builds the UpdateStakingCoefficient instruction for the given admin.
+// The coefficient is the token amount staked per unit of k-value.
+func UpdateStakingCoefficient(admin solana.PublicKey, val amount.Amount) (solana.Instruction, error) {
+	b := client.NewUpdateStakingCoefficientInstructionBuilder()
+	supernode, _, err := b.FindSupernodeAddress()
+	if err != nil {
+		return nil, err
+	}
+	return b.SetVal(val.Units()).SetSupernodeAccount(supernode).SetAdminAccount(admin).ValidateAndBuild()
+}
+
+// UpdateRewardLockTime builds the UpdateRewardLockTime instruction for the given admin.
+func UpdateRewardLockTime(admin solana.PublicKey, lockTime uint64) (solana.Instruction, error) {
+	b := client.NewUpdateRewardLockTimeInstructionBuilder()
+	supernode, _, err := b.FindSupernodeAddress()
+	if err != nil {
+		return nil, err
+	}
+	return b.SetNew(lockTime).SetSupernodeAccount(supernode).SetAdminAccount(admin).ValidateAndBuild()
+}
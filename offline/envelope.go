@@ -0,0 +1,95 @@
+// Package offline builds supernode transactions on top of a durable nonce so
+// they can be carried to an air-gapped machine, signed there, and submitted
+// at any later time.
+package offline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// EnvelopeVersion is the current envelope format version.
+const EnvelopeVersion = 1
+
+// Envelope is the JSON document that travels between the online and the
+// offline machine. Transaction holds the (partially signed) transaction
+// encoded as base64.
+type Envelope struct {
+	Version        int       `json:"version"`
+	Description    string    `json:"description,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	NonceAccount   string    `json:"nonceAccount"`
+	NonceAuthority string    `json:"nonceAuthority"`
+	Nonce          string    `json:"nonce"`
+	FeePayer       string    `json:"feePayer"`
+	Transaction    string    `json:"transaction"`
+}
+
+// Tx decodes the envelope's transaction.
+func (e *Envelope) Tx() (*solana.Transaction, error) {
+	tx, err := solana.TransactionFromBase64(e.Transaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode envelope transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// SetTx stores tx in the envelope.
+func (e *Envelope) SetTx(tx *solana.Transaction) error {
+	out, err := tx.ToBase64()
+	if err != nil {
+		return fmt.Errorf("failed to encode envelope transaction: %w", err)
+	}
+	e.Transaction = out
+	return nil
+}
+
+// WriteTo writes the envelope as indented JSON.
+func (e *Envelope) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// ReadEnvelope parses an envelope and checks its version.
+func ReadEnvelope(r io.Reader) (*Envelope, error) {
+	var e Envelope
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, fmt.Errorf("failed to parse envelope: %w", err)
+	}
+	if e.Version != EnvelopeVersion {
+		return nil, fmt.Errorf("unsupported envelope version %d", e.Version)
+	}
+	return &e, nil
+}
+
+// LoadEnvelope reads an envelope from a file.
+func LoadEnvelope(path string) (*Envelope, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadEnvelope(f)
+}
+
+// SaveEnvelope writes an envelope to a file.
+func SaveEnvelope(path string, e *Envelope) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := e.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
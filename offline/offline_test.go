@@ -0,0 +1,89 @@
+package offline
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/amount"
+	"n3-solana-test/client"
+	"n3-solana-test/rpcclient"
+)
+
+// nonceRPC serves a single nonce account and records sent transactions.
+type nonceRPC struct {
+	rpcclient.Client
+	account solana.PublicKey
+	nonce   system.NonceAccount
+	sent    []*solana.Transaction
+}
+
+func (r *nonceRPC) GetAccountInfo(_ context.Context, account solana.PublicKey) (*rpc.GetAccountInfoResult, error) {
+	if !account.Equals(r.account) {
+		return nil, rpc.ErrNotFound
+	}
+	buf := new(bytes.Buffer)
+	if err := r.nonce.MarshalWithEncoder(bin.NewBinEncoder(buf)); err != nil {
+		return nil, err
+	}
+	return &rpc.GetAccountInfoResult{Value: &rpc.Account{
+		Owner: solana.SystemProgramID,
+		Data:  rpc.DataBytesOrJSONFromBytes(buf.Bytes()),
+	}}, nil
+}
+
+func (r *nonceRPC) SendTransaction(_ context.Context, tx *solana.Transaction) (solana.Signature, error) {
+	r.sent = append(r.sent, tx)
+	return tx.Signatures[0], nil
+}
+
+func TestBuildSignSubmit(t *testing.T) {
+	client.SetProgramID(solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy"))
+	admin := solana.NewWallet().PrivateKey
+	feePayer := solana.NewWallet().PrivateKey
+	r := &nonceRPC{
+		account: solana.NewWallet().PublicKey(),
+		nonce: system.NonceAccount{
+			State:            1,
+			AuthorizedPubkey: admin.PublicKey(),
+			Nonce:            solana.NewWallet().PublicKey(),
+		},
+	}
+	ctx := context.Background()
+
+	update, err := UpdateStakingCoefficient(admin.PublicKey(), amount.MustTokens(20, 9))
+	ag_require.NoError(t, err)
+	e, err := Build(ctx, r, r.account, feePayer.PublicKey(), "raise staking coefficient", update)
+	ag_require.NoError(t, err)
+
+	// The envelope survives a JSON round trip.
+	buf := new(bytes.Buffer)
+	_, err = e.WriteTo(buf)
+	ag_require.NoError(t, err)
+	e, err = ReadEnvelope(buf)
+	ag_require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	ag_require.NoError(t, Describe(e, out))
+	ag_require.Contains(t, out.String(), "UpdateStakingCoefficient")
+	ag_require.Contains(t, out.String(), "AdvanceNonceAccount")
+
+	ag_require.Error(t, Sign(e, solana.NewWallet().PrivateKey))
+	ag_require.NoError(t, Sign(e, admin))
+	_, err = Submit(ctx, r, e)
+	ag_require.Error(t, err, "fee payer signature is still missing")
+
+	ag_require.NoError(t, Sign(e, feePayer))
+	_, err = Submit(ctx, r, e)
+	ag_require.NoError(t, err)
+	ag_require.Len(t, r.sent, 1)
+
+	r.nonce.Nonce = solana.NewWallet().PublicKey()
+	_, err = Submit(ctx, r, e)
+	ag_require.ErrorIs(t, err, ErrNonceAdvanced)
+}
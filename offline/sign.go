@@ -0,0 +1,132 @@
+package offline
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/text"
+	"n3-solana-test/rpcclient"
+)
+
+// Verify checks that the envelope's transaction is a well-formed durable-nonce
+// transaction: it must start by advancing the envelope's nonce account and use
+// the envelope's nonce as its blockhash.
+func Verify(e *Envelope) (*solana.Transaction, error) {
+	tx, err := e.Tx()
+	if err != nil {
+		return nil, err
+	}
+	if tx.Message.RecentBlockhash.String() != e.Nonce {
+		return nil, fmt.Errorf("transaction blockhash %s does not match envelope nonce %s", tx.Message.RecentBlockhash, e.Nonce)
+	}
+	if len(tx.Message.AccountKeys) == 0 || tx.Message.AccountKeys[0].String() != e.FeePayer {
+		return nil, fmt.Errorf("transaction fee payer does not match envelope fee payer %s", e.FeePayer)
+	}
+	if len(tx.Message.Instructions) < 2 {
+		return nil, fmt.Errorf("transaction has no instructions besides the nonce advance")
+	}
+
+	first := tx.Message.Instructions[0]
+	programID, err := tx.Message.Program(first.ProgramIDIndex)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := first.ResolveInstructionAccounts(&tx.Message)
+	if err != nil {
+		return nil, err
+	}
+	if !programID.Equals(solana.SystemProgramID) {
+		return nil, fmt.Errorf("first instruction must advance the nonce account")
+	}
+	inst, err := system.DecodeInstruction(accounts, first.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode first instruction: %w", err)
+	}
+	advance, ok := inst.Impl.(*system.AdvanceNonceAccount)
+	if !ok {
+		return nil, fmt.Errorf("first instruction must advance the nonce account, got %s", system.InstructionIDToName(inst.TypeID.Uint32()))
+	}
+	if advance.GetNonceAccount().PublicKey.String() != e.NonceAccount {
+		return nil, fmt.Errorf("nonce advance targets %s, envelope nonce account is %s", advance.GetNonceAccount().PublicKey, e.NonceAccount)
+	}
+	if advance.GetNonceAuthorityAccount().PublicKey.String() != e.NonceAuthority {
+		return nil, fmt.Errorf("nonce advance is authorized by %s, envelope authority is %s", advance.GetNonceAuthorityAccount().PublicKey, e.NonceAuthority)
+	}
+	return tx, nil
+}
+
+// Describe pretty-prints the envelope and its decoded instructions.
+func Describe(e *Envelope, w io.Writer) error {
+	tx, err := Verify(e)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Description:     %s\n", e.Description)
+	fmt.Fprintf(w, "Created:         %s\n", e.CreatedAt)
+	fmt.Fprintf(w, "Nonce account:   %s\n", e.NonceAccount)
+	fmt.Fprintf(w, "Nonce authority: %s\n", e.NonceAuthority)
+	fmt.Fprintf(w, "Nonce:           %s\n", e.Nonce)
+	fmt.Fprintf(w, "Fee payer:       %s\n", e.FeePayer)
+	fmt.Fprintf(w, "Signers:\n")
+	for i, key := range tx.Message.Signers() {
+		state := "missing"
+		if i < len(tx.Signatures) && !tx.Signatures[i].IsZero() {
+			state = "signed"
+		}
+		fmt.Fprintf(w, "  %s (%s)\n", key, state)
+	}
+	_, err = tx.EncodeTree(text.NewTreeEncoder(w, "Transaction"))
+	return err
+}
+
+// Sign adds the signatures of keys to the envelope's transaction. Keys that
+// are not signers of the transaction are rejected.
+func Sign(e *Envelope, keys ...solana.PrivateKey) error {
+	tx, err := Verify(e)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if !tx.Message.IsSigner(key.PublicKey()) {
+			return fmt.Errorf("%s is not a signer of this transaction", key.PublicKey())
+		}
+	}
+	if _, err := tx.PartialSign(func(pub solana.PublicKey) *solana.PrivateKey {
+		for i := range keys {
+			if keys[i].PublicKey().Equals(pub) {
+				return &keys[i]
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return e.SetTx(tx)
+}
+
+// Submit sends a fully signed envelope. It refuses to send when the nonce has
+// already been advanced, since the transaction could never land.
+func Submit(ctx context.Context, rpcClient rpcclient.Client, e *Envelope) (solana.Signature, error) {
+	tx, err := Verify(e)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	if err := tx.VerifySignatures(); err != nil {
+		return solana.Signature{}, fmt.Errorf("transaction is not fully signed: %w", err)
+	}
+	nonceAccount, err := solana.PublicKeyFromBase58(e.NonceAccount)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	nonce, err := FetchNonce(ctx, rpcClient, nonceAccount)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	if solana.Hash(nonce.Nonce).String() != e.Nonce {
+		return solana.Signature{}, ErrNonceAdvanced
+	}
+	return rpcClient.SendTransaction(ctx, tx)
+}
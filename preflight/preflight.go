@@ -0,0 +1,260 @@
+// Package preflight predicts the supernode program errors a transaction
+// would fail with, from the accounts it reads, before the transaction is
+// sent: staking a device that is already staked, a controller the provider
+// did not authorize, a third extra controller, or moving more tokens than an
+// account holds.
+//
+// The checker replays the supernode instructions of a transaction against
+// the accounts they name, applying the effects of each so the instructions
+// that follow see them. It models what the program checks against account
+// state; the addresses, signers and arguments of an instruction are the
+// business of ValidateDeep of the generated client. Outcomes that depend on
+// the clock, such as the amount a Release pays, are not predicted.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/client"
+	"n3-solana-test/rpcclient"
+)
+
+// maxAccounts bounds the accounts of one getMultipleAccounts request.
+const maxAccounts = 100
+
+// Failure is an instruction predicted to fail.
+type Failure struct {
+	// Index is the position of the instruction in the transaction and
+	// Instruction its name, such as "StakeDevice".
+	Index       int
+	Instruction string
+	// Err is the error the program is expected to return. A stake or a
+	// rental payment above the balance of the payer fails in the token
+	// program the supernode program transfers with; it is reported as
+	// client.ErrInsufficientFunds all the same.
+	Err client.CustomError
+	// Reason explains the prediction from the account state.
+	Reason string
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("instruction %d (%s): %s: %s", f.Index, f.Instruction, f.Err.Name(), f.Reason)
+}
+
+// Unwrap returns Err, so errors.Is matches the client error values.
+func (f *Failure) Unwrap() error {
+	return f.Err
+}
+
+// fail returns a Failure with err, its reason formatted from format and
+// args. The caller fills in the instruction.
+func fail(err client.CustomError, format string, args ...any) *Failure {
+	return &Failure{Err: err, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Checker predicts the outcome of transactions from the accounts read
+// through an RPC client.
+type Checker struct {
+	c rpcclient.Client
+}
+
+// New returns a Checker reading accounts through c.
+func New(c rpcclient.Client) *Checker {
+	return &Checker{c: c}
+}
+
+// Check predicts the outcome of a transaction made of instructions. It
+// returns a *Failure for the first supernode instruction predicted to fail,
+// nil when none is, or the error of decoding the instructions or reading
+// their accounts. Instructions of other programs are skipped.
+func (ch *Checker) Check(ctx context.Context, instructions ...solana.Instruction) error {
+	insts, err := decode(instructions)
+	if err != nil {
+		return err
+	}
+	l, err := ch.load(ctx, insts)
+	if err != nil {
+		return err
+	}
+	for i, inst := range insts {
+		if inst == nil {
+			continue
+		}
+		if f := l.apply(inst); f != nil {
+			f.Index = i
+			f.Instruction = client.InstructionIDToName(inst.TypeID)
+			return f
+		}
+	}
+	return nil
+}
+
+// decode decodes the supernode instructions of instructions, leaving nil
+// at the positions of other programs. Built instructions are decoded from
+// their data too, which leaves every Impl a pointer as apply expects.
+func decode(instructions []solana.Instruction) ([]*client.Instruction, error) {
+	out := make([]*client.Instruction, len(instructions))
+	for i, ix := range instructions {
+		if !ix.ProgramID().Equals(client.ProgramID) {
+			continue
+		}
+		data, err := ix.Data()
+		if err != nil {
+			return nil, fmt.Errorf("instruction %d: %w", i, err)
+		}
+		inst := new(client.Instruction)
+		if err := ag_binary.NewBorshDecoder(data).Decode(inst); err != nil {
+			return nil, fmt.Errorf("instruction %d: unable to decode: %w", i, err)
+		}
+		if v, ok := inst.Impl.(solana.AccountsSettable); ok {
+			if err := v.SetAccounts(ix.Accounts()); err != nil {
+				return nil, fmt.Errorf("instruction %d: %w", i, err)
+			}
+		}
+		out[i] = inst
+	}
+	return out, nil
+}
+
+// load reads every account of insts.
+func (ch *Checker) load(ctx context.Context, insts []*client.Instruction) (*ledger, error) {
+	l := &ledger{raw: map[solana.PublicKey]*rpc.Account{}, cache: map[solana.PublicKey]any{}}
+	var keys []solana.PublicKey
+	seen := map[solana.PublicKey]bool{}
+	for _, inst := range insts {
+		if inst == nil {
+			continue
+		}
+		for _, meta := range inst.Accounts() {
+			if meta != nil && !seen[meta.PublicKey] {
+				seen[meta.PublicKey] = true
+				keys = append(keys, meta.PublicKey)
+			}
+		}
+	}
+	for len(keys) > 0 {
+		n := min(len(keys), maxAccounts)
+		out, err := ch.c.GetMultipleAccounts(ctx, keys[:n]...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read accounts: %w", err)
+		}
+		for i, key := range keys[:n] {
+			if i < len(out.Value) {
+				l.raw[key] = out.Value[i]
+			}
+		}
+		keys = keys[n:]
+	}
+	return l, nil
+}
+
+// decodable is an account type of the supernode or the token program.
+type decodable interface {
+	UnmarshalWithDecoder(*ag_binary.Decoder) error
+}
+
+// unknown marks an account an earlier instruction changed in a way the
+// checker does not model.
+type unknown struct{}
+
+// ledger holds the accounts of a transaction as its instructions leave
+// them.
+type ledger struct {
+	raw map[solana.PublicKey]*rpc.Account
+	// cache holds the accounts decoded so far, and changed by the
+	// instructions applied: a decoded account, nil for a missing one or
+	// unknown{}.
+	cache map[solana.PublicKey]any
+}
+
+// get returns the account at key decoded into fresh, or nil when there is
+// none. ok is false when the contents of the account are unknown: an
+// instruction changed it in a way the checker does not model, or it is not
+// an account of owner decoding as fresh.
+func (l *ledger) get(key, owner solana.PublicKey, fresh decodable, decoder func([]byte) *ag_binary.Decoder) (v any, ok bool) {
+	v, cached := l.cache[key]
+	if !cached {
+		v = fresh
+		acct := l.raw[key]
+		switch {
+		case acct == nil:
+			v = nil
+		case !acct.Owner.Equals(owner):
+			v = unknown{}
+		default:
+			if err := fresh.UnmarshalWithDecoder(decoder(acct.Data.GetBinary())); err != nil {
+				v = unknown{}
+			}
+		}
+		l.cache[key] = v
+	}
+	if v == nil {
+		return nil, true
+	}
+	if reflect.TypeOf(v) != reflect.TypeOf(fresh) {
+		return nil, false
+	}
+	return v, true
+}
+
+// forget marks the accounts at keys unknown.
+func (l *ledger) forget(keys ...solana.PublicKey) {
+	for _, key := range keys {
+		l.cache[key] = unknown{}
+	}
+}
+
+func (l *ledger) program(key solana.PublicKey, fresh decodable) (any, bool) {
+	return l.get(key, client.ProgramID, fresh, ag_binary.NewBorshDecoder)
+}
+
+func (l *ledger) state(key solana.PublicKey) (*client.SupernodeStateAccount, bool) {
+	v, ok := l.program(key, new(client.SupernodeStateAccount))
+	state, _ := v.(*client.SupernodeStateAccount)
+	return state, ok
+}
+
+func (l *ledger) stakeInfo(key solana.PublicKey) (*client.ProviderStakeInfoAccount, bool) {
+	v, ok := l.program(key, new(client.ProviderStakeInfoAccount))
+	info, _ := v.(*client.ProviderStakeInfoAccount)
+	return info, ok
+}
+
+func (l *ledger) vesting(key solana.PublicKey) (*client.ProviderVestingInfoAccount, bool) {
+	v, ok := l.program(key, new(client.ProviderVestingInfoAccount))
+	vesting, _ := v.(*client.ProviderVestingInfoAccount)
+	return vesting, ok
+}
+
+func (l *ledger) tenant(key solana.PublicKey) (*client.TenantInfoAccount, bool) {
+	v, ok := l.program(key, new(client.TenantInfoAccount))
+	info, _ := v.(*client.TenantInfoAccount)
+	return info, ok
+}
+
+func (l *ledger) token(key solana.PublicKey) (*token.Account, bool) {
+	v, ok := l.get(key, solana.TokenProgramID, new(token.Account), ag_binary.NewBinDecoder)
+	acct, _ := v.(*token.Account)
+	return acct, ok
+}
+
+// transfer moves amount between token accounts. An account it cannot
+// credit, one the transfer creates or of unknown contents, becomes unknown.
+func (l *ledger) transfer(from, to solana.PublicKey, amount uint64) {
+	if src, ok := l.token(from); ok && src != nil && src.Amount >= amount {
+		src.Amount -= amount
+	} else {
+		l.forget(from)
+	}
+	if dst, ok := l.token(to); ok && dst != nil {
+		dst.Amount += amount
+	} else {
+		l.forget(to)
+	}
+}
@@ -0,0 +1,292 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/amount"
+	"n3-solana-test/client"
+	"n3-solana-test/scenario"
+)
+
+var programID = solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy")
+
+func init() {
+	client.SetProgramID(programID)
+}
+
+const (
+	coefficient = 100
+	kvalue      = 3
+	paid        = 400
+)
+
+// fixture is an initialized program where provider 0 staked device 0 and
+// has two extra controllers, and provider 1, the tenant, paid a rental fee.
+type fixture struct {
+	env         *scenario.Env
+	controllers []solana.PrivateKey
+}
+
+func newFixture(t *testing.T) *fixture {
+	env, err := scenario.NewEnv(programID, scenario.Options{Providers: 2, ProviderTokens: amount.New(1_000, 9)})
+	ag_require.NoError(t, err)
+	f := &fixture{env: env, controllers: []solana.PrivateKey{solana.NewWallet().PrivateKey, solana.NewWallet().PrivateKey}}
+	for _, action := range []scenario.Action{
+		scenario.Initialize(30, amount.New(coefficient, 9)),
+		scenario.UpdateKValue(1, kvalue),
+		scenario.UpdateKValue(2, 10),
+		scenario.Stake(0, 0, 1),
+		scenario.AddController(0, f.controllers[0].PublicKey()),
+		scenario.AddController(0, f.controllers[1].PublicKey()),
+	} {
+		_, err := action.Run(env)
+		ag_require.NoError(t, err, action.Name)
+	}
+	_, err = env.Send([]solana.PrivateKey{env.Providers[1], env.Admin}, f.payRentalFee(paid))
+	ag_require.NoError(t, err)
+	return f
+}
+
+func (f *fixture) stake(controller solana.PublicKey, provider int, device, spec uint64) *client.Instruction {
+	env, owner := f.env, f.env.Provider(provider)
+	return client.NewStakeDeviceInstructionBuilder().
+		SetDeviceId(device).
+		SetSpecId(spec).
+		SetSupernodeAccount(env.Address([]byte("supernode"))).
+		SetSupernodeStakeAccountAccount(env.Address([]byte("supernode_stake_account"))).
+		SetProviderStakeInfoAccount(env.Address([]byte("provider_stake_info"), owner[:])).
+		SetProviderTokenAccountAccount(env.TokenAccount(owner)).
+		SetTokenAccount(env.Mint).
+		SetProviderAccount(owner).
+		SetControllerAccount(controller).
+		SetAdminAccount(env.Admin.PublicKey()).
+		Build()
+}
+
+func (f *fixture) addController(controller solana.PublicKey) *client.Instruction {
+	env, owner := f.env, f.env.Provider(0)
+	return client.NewAddExtraControllerInstructionBuilder().
+		SetSupernodeAccount(env.Address([]byte("supernode"))).
+		SetProviderStakeInfoAccount(env.Address([]byte("provider_stake_info"), owner[:])).
+		SetProviderAccount(owner).
+		SetOperatorAccount(owner).
+		SetAdminAccount(env.Admin.PublicKey()).
+		SetNewControllerAccount(controller).
+		Build()
+}
+
+func (f *fixture) removeController(controller solana.PublicKey) *client.Instruction {
+	env, owner := f.env, f.env.Provider(0)
+	return client.NewRemoveExtraControllerInstructionBuilder().
+		SetSupernodeAccount(env.Address([]byte("supernode"))).
+		SetProviderStakeInfoAccount(env.Address([]byte("provider_stake_info"), owner[:])).
+		SetProviderAccount(owner).
+		SetOperatorAccount(owner).
+		SetAdminAccount(env.Admin.PublicKey()).
+		SetOldControllerAccount(controller).
+		Build()
+}
+
+func (f *fixture) payRentalFee(amount uint64) *client.Instruction {
+	env, tenant := f.env, f.env.Provider(1)
+	return client.NewPayRentalFeeInstructionBuilder().
+		SetAmount(amount).
+		SetSupernodeAccount(env.Address([]byte("supernode"))).
+		SetSupernodeRentalAccountAccount(env.Address([]byte("supernode_rental_account"))).
+		SetTenantTokenAccountAccount(env.TokenAccount(tenant)).
+		SetTenantInfoAccount(env.Address([]byte("tenant_info"), tenant[:])).
+		SetTokenAccount(env.Mint).
+		SetTenantAccount(tenant).
+		SetAdminAccount(env.Admin.PublicKey()).
+		Build()
+}
+
+func (f *fixture) withdrawRentalFee(amount uint64) *client.Instruction {
+	env, tenant := f.env, f.env.Provider(1)
+	return client.NewWithdrawRentalFeeInstructionBuilder().
+		SetAmount(amount).
+		SetSupernodeAccount(env.Address([]byte("supernode"))).
+		SetSupernodeRentalAccountAccount(env.Address([]byte("supernode_rental_account"))).
+		SetTenantTokenAccountAccount(env.TokenAccount(tenant)).
+		SetTenantInfoAccount(env.Address([]byte("tenant_info"), tenant[:])).
+		SetTokenAccount(env.Mint).
+		SetTenantAccount(tenant).
+		SetAdminAccount(env.Admin.PublicKey()).
+		Build()
+}
+
+func TestCheck(t *testing.T) {
+	f := newFixture(t)
+	provider, tenant := f.env.Providers[0], f.env.Providers[1]
+	stranger := solana.NewWallet().PrivateKey
+
+	for _, c := range []struct {
+		name string
+		// signers pay for and sign ixs, with the admin.
+		signers []solana.PrivateKey
+		ixs     []solana.Instruction
+		index   int
+		want    client.CustomError
+		// tokenError is set when the program fails in the token transfer,
+		// with a token program error rather than want.
+		tokenError bool
+		reason     string
+	}{
+		{
+			name:    "device already staked",
+			signers: []solana.PrivateKey{provider},
+			ixs:     []solana.Instruction{f.stake(provider.PublicKey(), 0, 0, 1)},
+			want:    client.ErrDeviceStaked,
+			reason:  "device 0 of provider " + provider.PublicKey().String() + " is already staked",
+		},
+		{
+			name:    "controller not authorized",
+			signers: []solana.PrivateKey{provider, stranger},
+			ixs:     []solana.Instruction{f.stake(stranger.PublicKey(), 0, 1, 1)},
+			want:    client.ErrUnauthorizedUser,
+			reason:  "controller " + stranger.PublicKey().String() + " is neither provider",
+		},
+		{
+			name:    "controller slots full",
+			signers: []solana.PrivateKey{provider},
+			ixs:     []solana.Instruction{f.addController(stranger.PublicKey())},
+			want:    client.ErrTooManyControllers,
+			reason:  "already has 2 extra controllers",
+		},
+		{
+			name:    "controller already present",
+			signers: []solana.PrivateKey{provider},
+			ixs:     []solana.Instruction{f.addController(f.controllers[1].PublicKey())},
+			want:    client.ErrControllerAlreadyExist,
+		},
+		{
+			name:    "remove missing controller",
+			signers: []solana.PrivateKey{provider},
+			ixs:     []solana.Instruction{f.removeController(stranger.PublicKey())},
+			want:    client.ErrControllerNotExist,
+		},
+		{
+			name:    "withdraw above funds",
+			signers: []solana.PrivateKey{tenant},
+			ixs:     []solana.Instruction{f.withdrawRentalFee(paid + 1)},
+			want:    client.ErrInsufficientFunds,
+			reason:  "paid 0.0000004 and withdrew 0, it cannot withdraw 0.000000401",
+		},
+		{
+			name:    "stake above balance",
+			signers: []solana.PrivateKey{tenant},
+			ixs:     []solana.Instruction{f.stake(tenant.PublicKey(), 1, 0, 2)},
+			want:    client.ErrInsufficientFunds,
+			// The tenant holds 600 units after paying 400, the stake is
+			// 100 * 10.
+			reason:     "holds 0.0000006, the stake of device 0 needs 0.000001; the token transfer of the stake fails",
+			tokenError: true,
+		},
+		{
+			name:    "later instruction of a batch",
+			signers: []solana.PrivateKey{provider, f.controllers[0]},
+			ixs: []solana.Instruction{
+				f.stake(provider.PublicKey(), 0, 1, 1),
+				f.stake(f.controllers[0].PublicKey(), 0, 1, 1),
+			},
+			index:  1,
+			want:   client.ErrDeviceStaked,
+			reason: "device 1",
+		},
+		{
+			name:    "withdraw above funds after a payment",
+			signers: []solana.PrivateKey{tenant},
+			ixs: []solana.Instruction{
+				f.payRentalFee(50),
+				f.withdrawRentalFee(paid + 50),
+				f.withdrawRentalFee(1),
+			},
+			index: 2,
+			want:  client.ErrInsufficientFunds,
+		},
+		{
+			name:    "removed controller",
+			signers: []solana.PrivateKey{provider, f.controllers[0]},
+			ixs: []solana.Instruction{
+				f.removeController(f.controllers[0].PublicKey()),
+				f.stake(f.controllers[0].PublicKey(), 0, 1, 1),
+			},
+			index: 1,
+			want:  client.ErrUnauthorizedUser,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			err := New(f.env.Sim).Check(context.Background(), c.ixs...)
+			ag_require.ErrorIs(t, err, c.want)
+			var failure *Failure
+			ag_require.True(t, errors.As(err, &failure))
+			ag_require.Equal(t, c.index, failure.Index)
+			ag_require.Contains(t, failure.Reason, c.reason)
+
+			_, err = f.env.Send(append(c.signers, f.env.Admin), c.ixs...)
+			ag_require.Error(t, err)
+			decoded, ok := client.DecodeCustomError(err)
+			if c.tokenError {
+				ag_require.False(t, ok, "%v", err)
+				return
+			}
+			ag_require.True(t, ok, "%v", err)
+			ag_require.ErrorIs(t, decoded, c.want)
+		})
+	}
+}
+
+func TestCheck_Succeeds(t *testing.T) {
+	f := newFixture(t)
+	provider := f.env.Providers[0]
+	stranger := solana.NewWallet().PublicKey()
+	ixs := []solana.Instruction{
+		f.stake(provider.PublicKey(), 0, 1, 1),
+		f.removeController(f.controllers[1].PublicKey()),
+		f.addController(stranger),
+	}
+	ag_require.NoError(t, New(f.env.Sim).Check(context.Background(), ixs...))
+	_, err := f.env.Send([]solana.PrivateKey{provider, f.env.Admin}, ixs...)
+	ag_require.NoError(t, err)
+
+	// The same instructions fail once landed.
+	err = New(f.env.Sim).Check(context.Background(), ixs...)
+	ag_require.ErrorIs(t, err, client.ErrDeviceStaked)
+}
+
+func TestCheck_GenericInstruction(t *testing.T) {
+	f := newFixture(t)
+	inst := f.stake(f.env.Provider(0), 0, 0, 1)
+	data, err := inst.Data()
+	ag_require.NoError(t, err)
+	ixs := []solana.Instruction{
+		solana.NewInstruction(solana.MemoProgramID, nil, []byte("memo")),
+		solana.NewInstruction(programID, inst.Accounts(), data),
+	}
+	err = New(f.env.Sim).Check(context.Background(), ixs...)
+	ag_require.ErrorIs(t, err, client.ErrDeviceStaked)
+	ag_require.EqualError(t, err, "instruction 1 (StakeDevice): DeviceStaked: device 0 of provider "+f.env.Provider(0).String()+" is already staked")
+}
+
+// TestCheck_ProgramLimits checks that device and spec ids are not held to
+// limits the IDL does not state: the simulator's are its own.
+func TestCheck_ProgramLimits(t *testing.T) {
+	f := newFixture(t)
+	provider := f.env.Providers[0]
+	for _, ixs := range [][]solana.Instruction{
+		{f.stake(provider.PublicKey(), 0, 100, 1)},
+		{f.stake(provider.PublicKey(), 0, 1<<40, 1)},
+		{client.NewUpdateKValueInstructionBuilder().
+			SetSpecId(40).
+			SetVal(1).
+			SetSupernodeAccount(f.env.Address([]byte("supernode"))).
+			SetAdminAccount(f.env.Admin.PublicKey()).
+			Build()},
+	} {
+		ag_require.NoError(t, New(f.env.Sim).Check(context.Background(), ixs...))
+	}
+}
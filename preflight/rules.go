@@ -0,0 +1,518 @@
+package preflight
+
+import (
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"n3-solana-test/amount"
+	"n3-solana-test/client"
+)
+
+// Device states of the program. Limits on device ids, spec ids and vesting
+// schedules are not checked: neither the IDL nor the accounts state them.
+const (
+	deviceUnstaked uint16 = 0
+	deviceStaked   uint16 = 1
+
+	// maxAccountDevices is the most devices the 10 MiB an account can hold
+	// have room for, 20 bytes each, bounding the devices the checker grows.
+	maxAccountDevices = 10 << 20 / 20
+)
+
+// apply predicts the outcome of inst and, when it is expected to succeed,
+// applies its effects to the ledger. The checks run in the order the
+// program makes them, so the failure predicted is the one it returns.
+func (l *ledger) apply(inst *client.Instruction) *Failure {
+	switch impl := inst.Impl.(type) {
+	case *client.Initialize:
+		return l.initialize(impl)
+	case *client.InitRewardAccount:
+		return l.initRewardAccount(impl)
+	case *client.UpdateKValue:
+		return l.updateKValue(impl)
+	case *client.UpdateRewardLockTime:
+		return l.updateRewardLockTime(impl)
+	case *client.UpdateStakingCoefficient:
+		return l.updateStakingCoefficient(impl)
+	case *client.StakeDevice:
+		return l.stakeDevice(impl)
+	case *client.UnstakeDevice:
+		return l.unstakeDevice(impl)
+	case *client.Releasable:
+		return l.releasable(impl)
+	case *client.Release:
+		return l.release(impl)
+	case *client.ClaimReward:
+		return l.claimReward(impl)
+	case *client.ClaimRentalFee:
+		return l.claimRentalFee(impl)
+	case *client.PayRentalFee:
+		return l.payRentalFee(impl)
+	case *client.WithdrawRentalFee:
+		return l.withdrawRentalFee(impl)
+	case *client.AddExtraController:
+		return l.addExtraController(impl)
+	case *client.RemoveExtraController:
+		return l.removeExtraController(impl)
+	case *client.ReplaceExtraController:
+		return l.replaceExtraController(impl)
+	}
+	return nil
+}
+
+// admin returns the supernode state, nil when it is not known, and checks
+// admin is its admin.
+func (l *ledger) admin(supernode, admin *solana.AccountMeta) (*client.SupernodeStateAccount, *Failure) {
+	state, _ := l.state(supernode.PublicKey)
+	if state == nil {
+		return nil, nil
+	}
+	if !admin.PublicKey.Equals(state.Admin) {
+		return nil, fail(client.ErrUnauthorizedUser, "%s is not the supernode admin %s", admin.PublicKey, state.Admin)
+	}
+	return state, nil
+}
+
+func checkMint(state *client.SupernodeStateAccount, mint *solana.AccountMeta) *Failure {
+	if !mint.PublicKey.Equals(state.Token) {
+		return fail(client.ErrInvalidTokenAccount, "mint %s is not the supernode token %s", mint.PublicKey, state.Token)
+	}
+	return nil
+}
+
+// checkTokenAccount checks the token account at meta exists and holds the
+// supernode token for owner. Accounts of unknown contents pass.
+func (l *ledger) checkTokenAccount(state *client.SupernodeStateAccount, meta *solana.AccountMeta, owner solana.PublicKey) *Failure {
+	acct, ok := l.token(meta.PublicKey)
+	switch {
+	case !ok:
+		return nil
+	case acct == nil:
+		return fail(client.ErrInvalidTokenAccount, "token account %s of %s does not exist", meta.PublicKey, owner)
+	case !acct.Owner.Equals(owner) || !acct.Mint.Equals(state.Token):
+		return fail(client.ErrInvalidTokenAccount, "token account %s holds mint %s for %s, not the supernode token for %s", meta.PublicKey, acct.Mint, acct.Owner, owner)
+	}
+	return nil
+}
+
+// checkBalance checks the token account at key holds the units what needs.
+// Accounts of unknown contents pass.
+func (l *ledger) checkBalance(state *client.SupernodeStateAccount, key solana.PublicKey, units uint64, what string) *Failure {
+	acct, ok := l.token(key)
+	if !ok || acct == nil || acct.Amount >= units {
+		return nil
+	}
+	return fail(client.ErrInsufficientFunds, "token account %s holds %s, %s needs %s", key, tokens(state, acct.Amount), what, tokens(state, units))
+}
+
+func tokens(state *client.SupernodeStateAccount, units uint64) amount.Amount {
+	return amount.New(units, state.Policy.Decimals)
+}
+
+func controllerIndex(info *client.ProviderStakeInfoAccount, key solana.PublicKey) int {
+	if key.IsZero() {
+		return -1
+	}
+	for i, c := range info.ExtraControllers {
+		if c.Equals(key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkController checks controller is the provider or one of its extra
+// controllers.
+func checkController(info *client.ProviderStakeInfoAccount, provider, controller *solana.AccountMeta) *Failure {
+	if controller.PublicKey.Equals(provider.PublicKey) || controllerIndex(info, controller.PublicKey) >= 0 {
+		return nil
+	}
+	return fail(client.ErrUnauthorizedUser, "controller %s is neither provider %s nor one of its extra controllers %s", controller.PublicKey, provider.PublicKey, info.ExtraControllers)
+}
+
+// controller returns the stake info of provider, nil when it is not known,
+// and checks controller may act for it.
+func (l *ledger) controller(infoMeta, provider, controller *solana.AccountMeta) (*client.ProviderStakeInfoAccount, *Failure) {
+	info, _ := l.stakeInfo(infoMeta.PublicKey)
+	if info == nil {
+		return nil, nil
+	}
+	return info, checkController(info, provider, controller)
+}
+
+func (l *ledger) initialize(inst *client.Initialize) *Failure {
+	supernode := inst.GetSupernodeAccount().PublicKey
+	if state, ok := l.state(supernode); !ok || state != nil {
+		return nil
+	}
+	mint := inst.GetTokenAccount().PublicKey
+	l.cache[supernode] = &client.SupernodeStateAccount{
+		Admin: inst.GetAdminAccount().PublicKey,
+		Token: mint,
+		Policy: client.Policy{
+			RewardLockedTime:   *inst.RewardLockedTime,
+			StakingCoefficient: *inst.StakingCoefficient,
+		},
+	}
+	// The decimals of the mint are not read, so the reasons of the
+	// instructions that follow give amounts in base units.
+	for _, vault := range []*solana.AccountMeta{inst.GetSupernodeStakeAccountAccount(), inst.GetSupernodeVestingAccountAccount(), inst.GetSupernodeRentalAccountAccount()} {
+		l.cache[vault.PublicKey] = &token.Account{Mint: mint, Owner: vault.PublicKey, State: token.Initialized}
+	}
+	return nil
+}
+
+func (l *ledger) initRewardAccount(inst *client.InitRewardAccount) *Failure {
+	state, f := l.admin(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if state == nil {
+		return f
+	}
+	if f := checkMint(state, inst.GetTokenAccount()); f != nil {
+		return f
+	}
+	reward := inst.GetSupernodeRewardAccountAccount().PublicKey
+	l.cache[reward] = &token.Account{Mint: state.Token, Owner: reward, State: token.Initialized}
+	return nil
+}
+
+func (l *ledger) updateKValue(inst *client.UpdateKValue) *Failure {
+	state, f := l.admin(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if state == nil {
+		return f
+	}
+	spec := int(*inst.SpecId)
+	for len(state.Policy.KValues) <= spec {
+		state.Policy.KValues = append(state.Policy.KValues, 0)
+	}
+	state.Policy.KValues[spec] = *inst.Val
+	return nil
+}
+
+func (l *ledger) updateRewardLockTime(inst *client.UpdateRewardLockTime) *Failure {
+	state, f := l.admin(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if state == nil {
+		return f
+	}
+	state.Policy.RewardLockedTime = *inst.New
+	return nil
+}
+
+func (l *ledger) updateStakingCoefficient(inst *client.UpdateStakingCoefficient) *Failure {
+	state, f := l.admin(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if state == nil {
+		return f
+	}
+	if *inst.Val == 0 {
+		return fail(client.ErrInvalidArgument, "the staking coefficient must not be zero")
+	}
+	state.Policy.StakingCoefficient = *inst.Val
+	return nil
+}
+
+func (l *ledger) stakeDevice(inst *client.StakeDevice) *Failure {
+	state, f := l.admin(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if state == nil {
+		return f
+	}
+	if f := checkMint(state, inst.GetTokenAccount()); f != nil {
+		return f
+	}
+	provider, controller, infoMeta := inst.GetProviderAccount(), inst.GetControllerAccount(), inst.GetProviderStakeInfoAccount()
+	info, ok := l.stakeInfo(infoMeta.PublicKey)
+	if !ok {
+		return nil
+	}
+	if info == nil {
+		// The first stake creates the stake info, which lists no extra
+		// controllers yet.
+		info = new(client.ProviderStakeInfoAccount)
+	}
+	if f := checkController(info, provider, controller); f != nil {
+		return f
+	}
+	source := inst.GetProviderTokenAccountAccount()
+	if f := l.checkTokenAccount(state, source, provider.PublicKey); f != nil {
+		return f
+	}
+
+	deviceID, specID := *inst.DeviceId, *inst.SpecId
+	if specID >= uint64(len(state.Policy.KValues)) || state.Policy.KValues[specID] == 0 {
+		return fail(client.ErrSpecIDMismatch, "spec %d has no k value", specID)
+	}
+	if deviceID < uint64(len(info.Devices)) && info.Devices[deviceID].State == deviceStaked {
+		return fail(client.ErrDeviceStaked, "device %d of provider %s is already staked", deviceID, provider.PublicKey)
+	}
+	kvalue := state.Policy.KValues[specID]
+	units := state.Policy.StakingCoefficient * kvalue
+	if units/kvalue != state.Policy.StakingCoefficient {
+		return fail(client.ErrInvalidArgument, "the stake of spec %d overflows", specID)
+	}
+	if f := l.checkBalance(state, source.PublicKey, units, fmt.Sprintf("the stake of device %d", deviceID)); f != nil {
+		f.Reason += "; the token transfer of the stake fails"
+		return f
+	}
+
+	l.transfer(source.PublicKey, inst.GetSupernodeStakeAccountAccount().PublicKey, units)
+	if deviceID >= maxAccountDevices {
+		// No account holds the device: leave the outcome to the program.
+		l.forget(infoMeta.PublicKey)
+		return nil
+	}
+	for uint64(len(info.Devices)) <= deviceID {
+		info.Devices = append(info.Devices, client.DeviceState{})
+	}
+	info.Devices[deviceID] = client.DeviceState{
+		State:              deviceStaked,
+		SpecId:             uint16(specID),
+		StakingCoefficient: state.Policy.StakingCoefficient,
+		Kvalue:             kvalue,
+	}
+	l.cache[infoMeta.PublicKey] = info
+	return nil
+}
+
+func (l *ledger) unstakeDevice(inst *client.UnstakeDevice) *Failure {
+	if state, f := l.admin(inst.GetSupernodeAccount(), inst.GetAdminAccount()); state == nil {
+		return f
+	}
+	provider := inst.GetProviderAccount()
+	info, f := l.controller(inst.GetProviderStakeInfoAccount(), provider, inst.GetControllerAccount())
+	if info == nil || f != nil {
+		return f
+	}
+	vestingKey := inst.GetProviderVestingInfoAccount().PublicKey
+	vesting, ok := l.vesting(vestingKey)
+	if !ok {
+		return nil
+	}
+	if vesting == nil {
+		vesting = new(client.ProviderVestingInfoAccount)
+	}
+
+	deviceID := *inst.DeviceId
+	if deviceID >= uint64(len(info.Devices)) || info.Devices[deviceID].State != deviceStaked {
+		return fail(client.ErrInvalidArgument, "device %d of provider %s is not staked", deviceID, provider.PublicKey)
+	}
+	device := info.Devices[deviceID]
+	info.Devices[deviceID].State = deviceUnstaked
+	// The release day depends on the clock, which does not matter to the
+	// checks that follow.
+	vesting.Schedules = append(vesting.Schedules, client.Schedule{Amount: device.StakingCoefficient * device.Kvalue})
+	l.cache[vestingKey] = vesting
+	return nil
+}
+
+func (l *ledger) releasable(inst *client.Releasable) *Failure {
+	if _, f := l.controller(inst.GetProviderStakeInfoAccount(), inst.GetProviderAccount(), inst.GetControllerAccount()); f != nil {
+		return f
+	}
+	l.forget(inst.GetProviderVestingInfoAccount().PublicKey)
+	return nil
+}
+
+func (l *ledger) release(inst *client.Release) *Failure {
+	state, f := l.admin(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if state == nil {
+		return f
+	}
+	if f := checkMint(state, inst.GetTokenAccount()); f != nil {
+		return f
+	}
+	if _, f := l.controller(inst.GetProviderStakeInfoAccount(), inst.GetProviderAccount(), inst.GetControllerAccount()); f != nil {
+		return f
+	}
+	// What is released depends on the clock.
+	l.forget(inst.GetProviderVestingInfoAccount().PublicKey, inst.GetSupernodeStakeAccountAccount().PublicKey, inst.GetProviderTokenAccountAccount().PublicKey)
+	return nil
+}
+
+// payout predicts a transfer of units from a supernode vault to the
+// provider.
+func (l *ledger) payout(state *client.SupernodeStateAccount, vault, dest *solana.AccountMeta, units uint64, what string) *Failure {
+	if units == 0 {
+		return fail(client.ErrInvalidArgument, "the amount must not be zero")
+	}
+	if f := l.checkBalance(state, vault.PublicKey, units, what); f != nil {
+		return f
+	}
+	l.transfer(vault.PublicKey, dest.PublicKey, units)
+	return nil
+}
+
+func (l *ledger) claimReward(inst *client.ClaimReward) *Failure {
+	state, f := l.admin(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if state == nil {
+		return f
+	}
+	if f := checkMint(state, inst.GetTokenAccount()); f != nil {
+		return f
+	}
+	if _, f := l.controller(inst.GetProviderStakeInfoAccount(), inst.GetProviderAccount(), inst.GetControllerAccount()); f != nil {
+		return f
+	}
+	return l.payout(state, inst.GetSupernodeRewardAccountAccount(), inst.GetProviderTokenAccountAccount(), *inst.Amount, "the reward claimed")
+}
+
+func (l *ledger) claimRentalFee(inst *client.ClaimRentalFee) *Failure {
+	state, f := l.admin(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if state == nil {
+		return f
+	}
+	if f := checkMint(state, inst.GetTokenAccount()); f != nil {
+		return f
+	}
+	if _, f := l.controller(inst.GetProviderStakeInfoAccount(), inst.GetProviderAccount(), inst.GetControllerAccount()); f != nil {
+		return f
+	}
+	return l.payout(state, inst.GetSupernodeRentalAccountAccount(), inst.GetProviderTokenAccountAccount(), *inst.Amount, "the rental fee claimed")
+}
+
+func (l *ledger) payRentalFee(inst *client.PayRentalFee) *Failure {
+	state, f := l.admin(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if state == nil {
+		return f
+	}
+	if f := checkMint(state, inst.GetTokenAccount()); f != nil {
+		return f
+	}
+	tenant, infoKey := inst.GetTenantAccount(), inst.GetTenantInfoAccount().PublicKey
+	info, ok := l.tenant(infoKey)
+	if !ok {
+		return nil
+	}
+	if info == nil {
+		info = new(client.TenantInfoAccount)
+	}
+	source := inst.GetTenantTokenAccountAccount()
+	if f := l.checkTokenAccount(state, source, tenant.PublicKey); f != nil {
+		return f
+	}
+	units := *inst.Amount
+	if units == 0 {
+		return fail(client.ErrInvalidArgument, "the amount must not be zero")
+	}
+	if info.Funds+units < info.Funds {
+		return fail(client.ErrInvalidArgument, "the funds of tenant %s overflow", tenant.PublicKey)
+	}
+	if f := l.checkBalance(state, source.PublicKey, units, "the rental fee paid"); f != nil {
+		f.Reason += "; the token transfer of the fee fails"
+		return f
+	}
+	info.Funds += units
+	l.cache[infoKey] = info
+	l.transfer(source.PublicKey, inst.GetSupernodeRentalAccountAccount().PublicKey, units)
+	return nil
+}
+
+func (l *ledger) withdrawRentalFee(inst *client.WithdrawRentalFee) *Failure {
+	state, f := l.admin(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if state == nil {
+		return f
+	}
+	if f := checkMint(state, inst.GetTokenAccount()); f != nil {
+		return f
+	}
+	tenant := inst.GetTenantAccount()
+	info, _ := l.tenant(inst.GetTenantInfoAccount().PublicKey)
+	if info == nil {
+		return nil
+	}
+	dest := inst.GetTenantTokenAccountAccount()
+	if f := l.checkTokenAccount(state, dest, tenant.PublicKey); f != nil {
+		return f
+	}
+	units := *inst.Amount
+	if units == 0 {
+		return fail(client.ErrInvalidArgument, "the amount must not be zero")
+	}
+	if units > info.Funds-info.Withdrawn {
+		return fail(client.ErrInsufficientFunds, "tenant %s paid %s and withdrew %s, it cannot withdraw %s",
+			tenant.PublicKey, tokens(state, info.Funds), tokens(state, info.Withdrawn), tokens(state, units))
+	}
+	info.Withdrawn += units
+	l.transfer(inst.GetSupernodeRentalAccountAccount().PublicKey, dest.PublicKey, units)
+	return nil
+}
+
+// controllerChange returns the stake info a controller instruction edits,
+// nil when it is not known, and checks the operator is the provider.
+func (l *ledger) controllerChange(supernode, admin, infoMeta, provider, operator *solana.AccountMeta) (*client.ProviderStakeInfoAccount, *Failure) {
+	if state, f := l.admin(supernode, admin); state == nil {
+		return nil, f
+	}
+	info, _ := l.stakeInfo(infoMeta.PublicKey)
+	if info == nil {
+		return nil, nil
+	}
+	if !operator.PublicKey.Equals(provider.PublicKey) {
+		return nil, fail(client.ErrUnauthorizedUser, "only provider %s changes its controllers, not %s", provider.PublicKey, operator.PublicKey)
+	}
+	return info, nil
+}
+
+// checkNewController checks key may become an extra controller.
+func checkNewController(info *client.ProviderStakeInfoAccount, provider *solana.AccountMeta, key solana.PublicKey) *Failure {
+	if key.IsZero() {
+		return fail(client.ErrInvalidArgument, "the new controller must not be the zero key")
+	}
+	if key.Equals(provider.PublicKey) {
+		return fail(client.ErrControllerAlreadyExist, "%s is the provider itself", key)
+	}
+	if controllerIndex(info, key) >= 0 {
+		return fail(client.ErrControllerAlreadyExist, "%s is already an extra controller of provider %s", key, provider.PublicKey)
+	}
+	return nil
+}
+
+func (l *ledger) addExtraController(inst *client.AddExtraController) *Failure {
+	provider := inst.GetProviderAccount()
+	info, f := l.controllerChange(inst.GetSupernodeAccount(), inst.GetAdminAccount(), inst.GetProviderStakeInfoAccount(), provider, inst.GetOperatorAccount())
+	if info == nil {
+		return f
+	}
+	newController := inst.GetNewControllerAccount().PublicKey
+	if f := checkNewController(info, provider, newController); f != nil {
+		return f
+	}
+	for i, c := range info.ExtraControllers {
+		if c.IsZero() {
+			info.ExtraControllers[i] = newController
+			return nil
+		}
+	}
+	return fail(client.ErrTooManyControllers, "provider %s already has %d extra controllers %s", provider.PublicKey, len(info.ExtraControllers), info.ExtraControllers)
+}
+
+func (l *ledger) removeExtraController(inst *client.RemoveExtraController) *Failure {
+	provider := inst.GetProviderAccount()
+	info, f := l.controllerChange(inst.GetSupernodeAccount(), inst.GetAdminAccount(), inst.GetProviderStakeInfoAccount(), provider, inst.GetOperatorAccount())
+	if info == nil {
+		return f
+	}
+	oldController := inst.GetOldControllerAccount().PublicKey
+	slot := controllerIndex(info, oldController)
+	if slot < 0 {
+		return fail(client.ErrControllerNotExist, "%s is not an extra controller of provider %s", oldController, provider.PublicKey)
+	}
+	info.ExtraControllers[slot] = solana.PublicKey{}
+	return nil
+}
+
+func (l *ledger) replaceExtraController(inst *client.ReplaceExtraController) *Failure {
+	provider := inst.GetProviderAccount()
+	info, f := l.controllerChange(inst.GetSupernodeAccount(), inst.GetAdminAccount(), inst.GetProviderStakeInfoAccount(), provider, inst.GetOperatorAccount())
+	if info == nil {
+		return f
+	}
+	oldController, newController := inst.GetOldControllerAccount().PublicKey, inst.GetNewControllerAccount().PublicKey
+	slot := controllerIndex(info, oldController)
+	if slot < 0 {
+		return fail(client.ErrControllerNotExist, "%s is not an extra controller of provider %s", oldController, provider.PublicKey)
+	}
+	if f := checkNewController(info, provider, newController); f != nil {
+		return f
+	}
+	info.ExtraControllers[slot] = newController
+	return nil
+}
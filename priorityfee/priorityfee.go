@@ -0,0 +1,326 @@
+// Package priorityfee prices and sends supernode transactions during
+// congestion: it samples recent prioritization fees paid for the accounts a
+// transaction writes, sizes the compute unit limit from a simulation and
+// rebroadcasts with an escalating price until the transaction lands, pricing
+// a transaction again only once the previous one can no longer land.
+package priorityfee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/rpcclient"
+)
+
+// MaxComputeUnits is the per-transaction compute unit ceiling.
+const MaxComputeUnits = 1_400_000
+
+const (
+	defaultPercentile          = 75
+	defaultHeadroomPercent     = 10
+	defaultEscalationPercent   = 50
+	defaultFloorPrice          = 100
+	defaultMaxAttempts         = 5
+	defaultRebroadcastInterval = 5 * time.Second
+)
+
+// ErrNotConfirmed is returned by Send when no broadcast landed.
+var ErrNotConfirmed = errors.New("transaction not confirmed")
+
+// Options configures an Estimator. Zero fields take the documented default.
+type Options struct {
+	// Percentile of the sampled fees to pay, 1-100. Default 75.
+	Percentile int
+	// MinPrice and MaxPrice bound the compute unit price in micro-lamports.
+	// MaxPrice caps escalation; zero means no cap.
+	MinPrice uint64
+	MaxPrice uint64
+	// HeadroomPercent is added to the simulated compute units. Default 10.
+	HeadroomPercent int
+	// EscalationPercent raises the price of each new attempt. Default 50.
+	EscalationPercent int
+	// FloorPrice is the least price an escalation pays, so escalating from
+	// an estimate of zero, as on an idle cluster, raises the price at all.
+	// Default 100 micro-lamports.
+	FloorPrice uint64
+	// MaxAttempts is the number of prices Send tries, each until the
+	// blockhash of its transaction expires. Default 5.
+	MaxAttempts int
+	// RebroadcastInterval is how often Send sends a transaction again while
+	// it waits for it to land. Default 5s.
+	RebroadcastInterval time.Duration
+}
+
+// Estimator implements the fee strategy.
+type Estimator struct {
+	rpcClient rpcclient.Client
+	opts      Options
+}
+
+// New returns an Estimator querying rpcClient.
+func New(rpcClient rpcclient.Client, opts Options) *Estimator {
+	if opts.Percentile <= 0 || opts.Percentile > 100 {
+		opts.Percentile = defaultPercentile
+	}
+	if opts.HeadroomPercent <= 0 {
+		opts.HeadroomPercent = defaultHeadroomPercent
+	}
+	if opts.EscalationPercent <= 0 {
+		opts.EscalationPercent = defaultEscalationPercent
+	}
+	if opts.FloorPrice == 0 {
+		opts.FloorPrice = defaultFloorPrice
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.RebroadcastInterval <= 0 {
+		opts.RebroadcastInterval = defaultRebroadcastInterval
+	}
+	return &Estimator{rpcClient: rpcClient, opts: opts}
+}
+
+// WritableAccounts returns the accounts instructions write to, which are the
+// ones whose local fee market sets the price.
+func WritableAccounts(instructions []solana.Instruction) solana.PublicKeySlice {
+	var out solana.PublicKeySlice
+	for _, inst := range instructions {
+		for _, meta := range inst.Accounts() {
+			if meta != nil && meta.IsWritable && !out.Contains(meta.PublicKey) {
+				out = append(out, meta.PublicKey)
+			}
+		}
+	}
+	return out
+}
+
+// Price returns the configured percentile of the prioritization fees recently
+// paid for the accounts instructions write to, bounded by MinPrice and
+// MaxPrice.
+func (e *Estimator) Price(ctx context.Context, instructions []solana.Instruction) (uint64, error) {
+	samples, err := e.rpcClient.GetRecentPrioritizationFees(ctx, WritableAccounts(instructions))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch prioritization fees: %w", err)
+	}
+	fees := make([]uint64, len(samples))
+	for i, s := range samples {
+		fees[i] = s.PrioritizationFee
+	}
+	return e.clamp(percentile(fees, e.opts.Percentile)), nil
+}
+
+// percentile uses the nearest-rank method; it returns zero without samples.
+func percentile(values []uint64, p int) uint64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]uint64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func (e *Estimator) clamp(price uint64) uint64 {
+	if price < e.opts.MinPrice {
+		price = e.opts.MinPrice
+	}
+	if e.opts.MaxPrice > 0 && price > e.opts.MaxPrice {
+		price = e.opts.MaxPrice
+	}
+	return price
+}
+
+// Escalate returns the price of the next rebroadcast: price raised by
+// EscalationPercent, at least FloorPrice, saturating instead of overflowing.
+func (e *Estimator) Escalate(price uint64) uint64 {
+	next := uint64(math.MaxUint64)
+	hi, lo := bits.Mul64(price, uint64(e.opts.EscalationPercent))
+	if hi < 100 {
+		raise, _ := bits.Div64(hi, lo, 100)
+		if sum, carry := bits.Add64(price, raise, 0); carry == 0 {
+			next = sum
+		}
+	}
+	if next <= price && price < math.MaxUint64 {
+		next = price + 1
+	}
+	if next < e.opts.FloorPrice {
+		next = e.opts.FloorPrice
+	}
+	return e.clamp(next)
+}
+
+// ComputeUnitLimit simulates instructions once under the maximum limit, as
+// Send broadcasts them, and returns the units consumed plus headroom.
+func (e *Estimator) ComputeUnitLimit(ctx context.Context, payer solana.PublicKey, instructions []solana.Instruction) (uint32, error) {
+	recent, err := e.rpcClient.GetLatestBlockhash(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		return 0, err
+	}
+	// The budget instructions Send prepends consume units too.
+	ixs := append([]solana.Instruction{
+		computebudget.NewSetComputeUnitLimitInstruction(MaxComputeUnits).Build(),
+		computebudget.NewSetComputeUnitPriceInstruction(0).Build(),
+	}, instructions...)
+	tx, err := solana.NewTransaction(ixs, recent.Value.Blockhash, solana.TransactionPayer(payer))
+	if err != nil {
+		return 0, err
+	}
+	// Signatures are not verified by the simulation but must be present.
+	tx.Signatures = make([]solana.Signature, tx.Message.Header.NumRequiredSignatures)
+
+	out, err := e.rpcClient.SimulateTransaction(ctx, tx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to simulate: %w", err)
+	}
+	if out.Value.Err != nil {
+		return 0, fmt.Errorf("simulation failed: %v", out.Value.Err)
+	}
+	if out.Value.UnitsConsumed == nil {
+		return 0, fmt.Errorf("simulation did not report consumed units")
+	}
+	limit := *out.Value.UnitsConsumed * uint64(100+e.opts.HeadroomPercent) / 100
+	if limit > MaxComputeUnits {
+		limit = MaxComputeUnits
+	}
+	return uint32(limit), nil
+}
+
+// Send prices instructions, then broadcasts them with SetComputeUnitLimit and
+// SetComputeUnitPrice prepended and returns the signature of the transaction
+// that landed. The first signer pays the fees.
+//
+// A transaction is sent again every RebroadcastInterval, unchanged, until it
+// lands or its blockhash expires. Only then is a new transaction built, on a
+// fresh blockhash and at an escalated price: the transactions of Send exclude
+// each other, so the instructions run at most once, and Send can carry
+// transfers and mints.
+func (e *Estimator) Send(ctx context.Context, signers []solana.PrivateKey, instructions ...solana.Instruction) (solana.Signature, error) {
+	if len(signers) == 0 {
+		return solana.Signature{}, fmt.Errorf("no signers")
+	}
+	payer := signers[0].PublicKey()
+	price, err := e.Price(ctx, instructions)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	limit, err := e.ComputeUnitLimit(ctx, payer, instructions)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+
+	for attempt := 0; attempt < e.opts.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			price = e.Escalate(price)
+		}
+		recent, err := e.rpcClient.GetLatestBlockhash(ctx, rpc.CommitmentConfirmed)
+		if err != nil {
+			return solana.Signature{}, err
+		}
+		tx, err := e.build(signers, instructions, recent.Value.Blockhash, limit, price)
+		if err != nil {
+			return solana.Signature{}, err
+		}
+		landed, err := e.broadcast(ctx, tx, recent.Value.LastValidBlockHeight, attempt == 0)
+		if err != nil {
+			return solana.Signature{}, fmt.Errorf("attempt %d at price %d: %w", attempt+1, price, err)
+		}
+		if landed {
+			return tx.Signatures[0], nil
+		}
+	}
+	return solana.Signature{}, fmt.Errorf("%w after %d attempts, last price %d", ErrNotConfirmed, e.opts.MaxAttempts, price)
+}
+
+func (e *Estimator) build(signers []solana.PrivateKey, instructions []solana.Instruction, blockhash solana.Hash, limit uint32, price uint64) (*solana.Transaction, error) {
+	ixs := []solana.Instruction{
+		computebudget.NewSetComputeUnitLimitInstruction(limit).Build(),
+		computebudget.NewSetComputeUnitPriceInstruction(price).Build(),
+	}
+	tx, err := solana.NewTransaction(append(ixs, instructions...), blockhash, solana.TransactionPayer(signers[0].PublicKey()))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		for i := range signers {
+			if signers[i].PublicKey().Equals(key) {
+				return &signers[i]
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// broadcast sends tx, again every RebroadcastInterval, until it is
+// confirmed or the block height passes lastValid, after which it can no
+// longer land. Only the first send is preflighted.
+func (e *Estimator) broadcast(ctx context.Context, tx *solana.Transaction, lastValid uint64, preflight bool) (bool, error) {
+	sig := tx.Signatures[0]
+	maxRetries := uint(0)
+	send := func() error {
+		_, err := e.rpcClient.SendTransactionWithOpts(ctx, tx, rpc.TransactionOpts{
+			SkipPreflight: !preflight,
+			MaxRetries:    &maxRetries,
+		})
+		preflight = false
+		return err
+	}
+	if err := send(); err != nil {
+		return false, err
+	}
+	resend := time.NewTicker(e.opts.RebroadcastInterval)
+	defer resend.Stop()
+	for {
+		// The height is read before the status, so a transaction landing
+		// in between is still seen when the height says it expired.
+		height, err := e.rpcClient.GetBlockHeight(ctx, rpc.CommitmentConfirmed)
+		if err != nil {
+			return false, err
+		}
+		landed, err := e.landed(ctx, sig)
+		if err != nil || landed || height > lastValid {
+			return landed, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-resend.C:
+			if err := send(); err != nil {
+				return false, err
+			}
+		case <-time.After(rpcclient.PollInterval):
+		}
+	}
+}
+
+// landed reports whether sig is confirmed, failing when it landed with an
+// error.
+func (e *Estimator) landed(ctx context.Context, sig solana.Signature) (bool, error) {
+	out, err := e.rpcClient.GetSignatureStatuses(ctx, false, sig)
+	if err != nil {
+		return false, err
+	}
+	if len(out.Value) == 0 || out.Value[0] == nil {
+		return false, nil
+	}
+	status := out.Value[0]
+	if status.Err != nil {
+		return false, fmt.Errorf("transaction %s failed: %v", sig, status.Err)
+	}
+	return status.ConfirmationStatus == rpc.ConfirmationStatusConfirmed || status.ConfirmationStatus == rpc.ConfirmationStatusFinalized, nil
+}
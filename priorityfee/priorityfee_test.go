@@ -0,0 +1,203 @@
+package priorityfee
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/rpc"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/client"
+	"n3-solana-test/rpcclient"
+)
+
+func init() {
+	client.SetProgramID(solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy"))
+	rpcclient.PollInterval = time.Millisecond
+}
+
+// feeRPC is a cluster on which the distinct transaction number landOn, or
+// every one when landOn is zero, executes when sent before its blockhash
+// expires. Every block height request moves the height up by one, and the
+// status of an executed transaction shows statusDelay requests late.
+type feeRPC struct {
+	rpcclient.Client
+	t           *testing.T
+	fees        []uint64
+	queried     solana.PublicKeySlice
+	consumed    uint64
+	landOn      int
+	statusDelay int
+
+	height     uint64
+	lastValid  map[solana.Hash]uint64
+	prices     []uint64
+	limits     []uint32
+	sent       []solana.Signature
+	broadcasts int
+	executed   map[solana.Signature]int
+}
+
+// blockhashLifetime is how many heights a blockhash of feeRPC is valid for.
+const blockhashLifetime = 3
+
+func (r *feeRPC) GetRecentPrioritizationFees(_ context.Context, accounts solana.PublicKeySlice) ([]rpc.PriorizationFeeResult, error) {
+	r.queried = accounts
+	out := make([]rpc.PriorizationFeeResult, len(r.fees))
+	for i, fee := range r.fees {
+		out[i] = rpc.PriorizationFeeResult{Slot: uint64(i), PrioritizationFee: fee}
+	}
+	return out, nil
+}
+
+func (r *feeRPC) GetLatestBlockhash(context.Context, rpc.CommitmentType) (*rpc.GetLatestBlockhashResult, error) {
+	if r.lastValid == nil {
+		r.lastValid = make(map[solana.Hash]uint64)
+	}
+	hash := solana.Hash{9, byte(len(r.lastValid))}
+	r.lastValid[hash] = r.height + blockhashLifetime
+	return &rpc.GetLatestBlockhashResult{Value: &rpc.LatestBlockhashResult{Blockhash: hash, LastValidBlockHeight: r.lastValid[hash]}}, nil
+}
+
+func (r *feeRPC) GetBlockHeight(context.Context, rpc.CommitmentType) (uint64, error) {
+	r.height++
+	return r.height, nil
+}
+
+func (r *feeRPC) SimulateTransaction(_ context.Context, tx *solana.Transaction) (*rpc.SimulateTransactionResponse, error) {
+	_, err := tx.MarshalBinary()
+	ag_require.NoError(r.t, err)
+	return &rpc.SimulateTransactionResponse{Value: &rpc.SimulateTransactionResult{UnitsConsumed: &r.consumed}}, nil
+}
+
+func (r *feeRPC) SendTransactionWithOpts(_ context.Context, tx *solana.Transaction, _ rpc.TransactionOpts) (solana.Signature, error) {
+	ag_require.NoError(r.t, tx.VerifySignatures())
+	r.broadcasts++
+	sig := tx.Signatures[0]
+	n := slices.Index(r.sent, sig) + 1
+	if n == 0 {
+		for _, ins := range tx.Message.Instructions[:2] {
+			accounts, err := ins.ResolveInstructionAccounts(&tx.Message)
+			ag_require.NoError(r.t, err)
+			decoded, err := computebudget.DecodeInstruction(accounts, ins.Data)
+			ag_require.NoError(r.t, err)
+			switch impl := decoded.Impl.(type) {
+			case *computebudget.SetComputeUnitLimit:
+				r.limits = append(r.limits, impl.Units)
+			case *computebudget.SetComputeUnitPrice:
+				r.prices = append(r.prices, impl.MicroLamports)
+			}
+		}
+		r.sent = append(r.sent, sig)
+		n = len(r.sent)
+	}
+	lastValid, ok := r.lastValid[tx.Message.RecentBlockhash]
+	ag_require.True(r.t, ok, "unknown blockhash")
+	if _, done := r.executed[sig]; !done && r.height <= lastValid && (r.landOn == 0 || n == r.landOn) {
+		if r.executed == nil {
+			r.executed = make(map[solana.Signature]int)
+		}
+		r.executed[sig] = r.statusDelay
+	}
+	return sig, nil
+}
+
+func (r *feeRPC) GetSignatureStatuses(_ context.Context, _ bool, sigs ...solana.Signature) (*rpc.GetSignatureStatusesResult, error) {
+	out := &rpc.GetSignatureStatusesResult{Value: make([]*rpc.SignatureStatusesResult, len(sigs))}
+	for i, sig := range sigs {
+		if late, ok := r.executed[sig]; ok {
+			if late > 0 {
+				r.executed[sig]--
+				continue
+			}
+			out.Value[i] = &rpc.SignatureStatusesResult{ConfirmationStatus: rpc.ConfirmationStatusConfirmed}
+		}
+	}
+	return out, nil
+}
+
+func stakeDevice(provider, admin solana.PublicKey) solana.Instruction {
+	b := client.NewStakeDeviceInstructionBuilder()
+	mint := solana.NewWallet().PublicKey()
+	ata, _, _ := solana.FindAssociatedTokenAddress(provider, mint)
+	return client.NewStakeDeviceInstruction(
+		1, 1,
+		b.MustFindSupernodeAddress(), b.MustFindSupernodeStakeAccountAddress(), b.MustFindProviderStakeInfoAddress(provider),
+		ata, mint, provider, provider, admin,
+		solana.TokenProgramID, solana.SystemProgramID, solana.SPLAssociatedTokenAccountProgramID,
+	).Build()
+}
+
+func TestPercentile(t *testing.T) {
+	values := []uint64{50, 10, 40, 20, 30}
+	ag_require.Equal(t, uint64(0), percentile(nil, 75))
+	ag_require.Equal(t, uint64(10), percentile(values, 1))
+	ag_require.Equal(t, uint64(30), percentile(values, 50))
+	ag_require.Equal(t, uint64(40), percentile(values, 75))
+	ag_require.Equal(t, uint64(50), percentile(values, 100))
+}
+
+func TestSend_Escalates(t *testing.T) {
+	provider, admin := solana.NewWallet().PrivateKey, solana.NewWallet().PrivateKey
+	inst := stakeDevice(provider.PublicKey(), admin.PublicKey())
+	r := &feeRPC{t: t, fees: []uint64{100, 200, 300, 400}, consumed: 40_000, landOn: 3}
+	e := New(r, Options{MaxPrice: 500, RebroadcastInterval: 10 * time.Millisecond})
+
+	sig, err := e.Send(context.Background(), []solana.PrivateKey{provider, admin}, inst)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, r.sent[2], sig)
+	ag_require.Equal(t, []uint64{300, 450, 500}, r.prices)
+	ag_require.Equal(t, []uint32{44_000, 44_000, 44_000}, r.limits)
+
+	stakeInfo := client.NewStakeDeviceInstructionBuilder().MustFindProviderStakeInfoAddress(provider.PublicKey())
+	ag_require.True(t, r.queried.Contains(stakeInfo))
+	ag_require.True(t, r.queried.Contains(client.NewStakeDeviceInstructionBuilder().MustFindSupernodeStakeAccountAddress()))
+	ag_require.False(t, r.queried.Contains(solana.TokenProgramID))
+}
+
+func TestSend_NotConfirmed(t *testing.T) {
+	provider, admin := solana.NewWallet().PrivateKey, solana.NewWallet().PrivateKey
+	inst := stakeDevice(provider.PublicKey(), admin.PublicKey())
+	r := &feeRPC{t: t, consumed: 40_000, landOn: 100}
+	e := New(r, Options{MinPrice: 10, MaxAttempts: 2, RebroadcastInterval: time.Millisecond})
+
+	_, err := e.Send(context.Background(), []solana.PrivateKey{provider, admin}, inst)
+	ag_require.ErrorIs(t, err, ErrNotConfirmed)
+	// The escalation pays at least the floor price.
+	ag_require.Equal(t, []uint64{10, 100}, r.prices)
+	ag_require.Empty(t, r.executed)
+}
+
+// TestSend_LateStatus lands every transaction sent in time, reporting it
+// late: Send waits for the first one instead of escalating into a second
+// execution.
+func TestSend_LateStatus(t *testing.T) {
+	provider, admin := solana.NewWallet().PrivateKey, solana.NewWallet().PrivateKey
+	inst := stakeDevice(provider.PublicKey(), admin.PublicKey())
+	r := &feeRPC{t: t, consumed: 40_000, statusDelay: 2}
+	e := New(r, Options{MinPrice: 10, RebroadcastInterval: time.Nanosecond})
+
+	sig, err := e.Send(context.Background(), []solana.PrivateKey{provider, admin}, inst)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, r.sent[0], sig)
+	ag_require.Len(t, r.sent, 1)
+	ag_require.Len(t, r.executed, 1)
+	ag_require.Equal(t, []uint64{10}, r.prices)
+}
+
+func TestEscalate(t *testing.T) {
+	e := New(nil, Options{})
+	ag_require.Equal(t, uint64(100), e.Escalate(0))
+	ag_require.Equal(t, uint64(100), e.Escalate(10))
+	ag_require.Equal(t, uint64(1_500), e.Escalate(1_000))
+	ag_require.Equal(t, uint64(math.MaxUint64), e.Escalate(math.MaxUint64-1))
+	ag_require.Equal(t, uint64(math.MaxUint64), e.Escalate(math.MaxUint64))
+
+	capped := New(nil, Options{MaxPrice: 5_000, FloorPrice: 10})
+	ag_require.Equal(t, uint64(10), capped.Escalate(0))
+	ag_require.Equal(t, uint64(5_000), capped.Escalate(math.MaxUint64/3))
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
 	"log"
+	"n3-solana-test/keystore"
 )
 
 const rpcURL = "https://api.devnet.solana.com" // Change to mainnet if needed
@@ -18,7 +19,10 @@ func main() {
 	client := rpc.New(rpcURL)
 
 	// Load admin wallet (private key)
-	adminWallet := solana.MustPrivateKeyFromBase58("2fs5YwA2QkpkWx5qty4VhuASrNnnxFNTUhwaBUbcCS6R") // Replace with your keypair path
+	adminWallet, err := keystore.FromEnv("admin")
+	if err != nil {
+		log.Fatalf("Failed to load admin key: %v", err)
+	}
 
 	// Derive the PDA for the Supernode
 	supernodePDA, _, err := solana.FindProgramAddress([][]byte{[]byte("supernode")}, programID)
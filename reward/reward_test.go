@@ -8,6 +8,7 @@ import (
 	"github.com/test-go/testify/assert"
 	"log"
 	sol_client "n3-solana-test/client"
+	"n3-solana-test/keystore"
 	"testing"
 )
 
@@ -17,8 +18,7 @@ func Test_Reward(t *testing.T) {
 	client := rpc.New(rpcURL)
 
 	// Load admin wallet (private key)
-	//adminWallet, err := solana.PrivateKeyFromSolanaKeygenFile("/Users/kevinsheeran/Developer/depinx-repo/n3-solana-smart-contract/target/deploy/supernode-keypair.json") // Replace with your keypair path
-	adminWallet, err := solana.PrivateKeyFromSolanaKeygenFile("/Users/kevinsheeran/.config/solana/id.json") // Replace with your keypair path
+	adminWallet, err := keystore.FromEnv("admin")
 	if err != nil {
 		log.Fatalf("failed to generate private key: %v", err)
 	}
@@ -0,0 +1,61 @@
+package rpcclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// PollInterval is how often WaitForConfirmation polls signature statuses.
+var PollInterval = 500 * time.Millisecond
+
+// WaitForConfirmation blocks until sig reaches commitment, the transaction
+// fails, or ctx is done.
+func WaitForConfirmation(ctx context.Context, c Client, sig solana.Signature, commitment rpc.CommitmentType) error {
+	for {
+		out, err := c.GetSignatureStatuses(ctx, false, sig)
+		if err != nil {
+			return err
+		}
+		if len(out.Value) > 0 && out.Value[0] != nil {
+			status := out.Value[0]
+			if status.Err != nil {
+				return fmt.Errorf("transaction %s failed: %v", sig, status.Err)
+			}
+			if confirmationLevel(status.ConfirmationStatus) >= commitmentLevel(commitment) {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("transaction %s not confirmed: %w", sig, ctx.Err())
+		case <-time.After(PollInterval):
+		}
+	}
+}
+
+func confirmationLevel(status rpc.ConfirmationStatusType) int {
+	switch status {
+	case rpc.ConfirmationStatusFinalized:
+		return 3
+	case rpc.ConfirmationStatusConfirmed:
+		return 2
+	case rpc.ConfirmationStatusProcessed:
+		return 1
+	}
+	return 0
+}
+
+func commitmentLevel(commitment rpc.CommitmentType) int {
+	switch commitment {
+	case rpc.CommitmentFinalized:
+		return 3
+	case rpc.CommitmentConfirmed:
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,163 @@
+package rpctest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"n3-solana-test/client"
+)
+
+// codeSimulationFailed is the error code of a sendTransaction preflight
+// failure.
+const codeSimulationFailed = -32002
+
+// Faults are the failures a Server injects. The zero value injects none.
+type Faults struct {
+	// Latency delays every response.
+	Latency time.Duration
+	// DropTransactions is the number of upcoming sendTransaction calls that
+	// are answered with their signature but never land.
+	DropTransactions int
+	// ExpireBlockhashes rejects every transaction as if its blockhash had
+	// expired.
+	ExpireBlockhashes bool
+	// ProgramError fails transactions invoking the supernode program at their
+	// first supernode instruction, with the error payload the program would
+	// produce.
+	ProgramError client.CustomError
+}
+
+// SetFaults replaces the injected faults.
+func (s *Server) SetFaults(f Faults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = f
+}
+
+// Faults returns the injected faults.
+func (s *Server) Faults() Faults {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.faults
+}
+
+// drop consumes one of the dropped transactions, if any are left.
+func (s *Server) drop() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.faults.DropTransactions == 0 {
+		return false
+	}
+	s.faults.DropTransactions--
+	return true
+}
+
+// failure is a transaction error imposed by the faults.
+type failure struct {
+	err     interface{}
+	logs    []string
+	message string
+}
+
+// injected returns the failure the faults impose on tx, or nil.
+func (s *Server) injected(tx *solana.Transaction) *failure {
+	faults := s.Faults()
+	if faults.ExpireBlockhashes {
+		return &failure{err: "BlockhashNotFound", logs: []string{}, message: "Blockhash not found"}
+	}
+	if faults.ProgramError == nil {
+		return nil
+	}
+	program := s.sim.ProgramID()
+	for i, ins := range tx.Message.Instructions {
+		if id, err := tx.Message.Program(ins.ProgramIDIndex); err != nil || !id.Equals(program) {
+			continue
+		}
+		custom := faults.ProgramError
+		message := custom.Error()
+		if j := strings.Index(message, "): "); j >= 0 {
+			message = message[j+3:]
+		}
+		return &failure{
+			err: map[string]interface{}{
+				"InstructionError": []interface{}{i, map[string]interface{}{"Custom": custom.Code()}},
+			},
+			logs: []string{
+				fmt.Sprintf("Program %s invoke [1]", program),
+				fmt.Sprintf("Program log: AnchorError occurred. Error Code: %s. Error Number: %d. Error Message: %s.", custom.Name(), custom.Code(), message),
+				fmt.Sprintf("Program %s failed: custom program error: 0x%x", program, custom.Code()),
+			},
+			message: fmt.Sprintf("Error processing Instruction %d: custom program error: 0x%x", i, custom.Code()),
+		}
+	}
+	return nil
+}
+
+func (s *Server) sendTransaction(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	var opts struct {
+		Encoding      string `json:"encoding"`
+		SkipPreflight bool   `json:"skipPreflight"`
+	}
+	if err := param(params, 1, &opts, false); err != nil {
+		return nil, err
+	}
+	tx, err := decodeTransaction(params, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	if len(tx.Signatures) == 0 {
+		return nil, invalidParams("transaction has no signatures")
+	}
+
+	if f := s.injected(tx); f != nil {
+		if opts.SkipPreflight {
+			// Without preflight the node accepts the transaction, which
+			// then never lands.
+			return tx.Signatures[0], nil
+		}
+		return nil, &jsonrpc.RPCError{
+			Code:    codeSimulationFailed,
+			Message: "Transaction simulation failed: " + f.message,
+			Data:    map[string]interface{}{"err": f.err, "logs": f.logs, "accounts": nil, "unitsConsumed": 0},
+		}
+	}
+	if s.drop() {
+		return tx.Signatures[0], nil
+	}
+	return s.sim.SendTransactionWithOpts(ctx, tx, rpc.TransactionOpts{SkipPreflight: opts.SkipPreflight})
+}
+
+func (s *Server) simulateTransaction(ctx context.Context, params []json.RawMessage) (interface{}, error) {
+	var opts struct {
+		Encoding               string `json:"encoding"`
+		ReplaceRecentBlockhash bool   `json:"replaceRecentBlockhash"`
+	}
+	if err := param(params, 1, &opts, false); err != nil {
+		return nil, err
+	}
+	tx, err := decodeTransaction(params, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	if f := s.injected(tx); f != nil {
+		var units uint64
+		return &rpc.SimulateTransactionResponse{
+			RPCContext: rpc.RPCContext{Context: rpc.Context{Slot: s.sim.Slot()}},
+			Value:      &rpc.SimulateTransactionResult{Err: f.err, Logs: f.logs, UnitsConsumed: &units},
+		}, nil
+	}
+	if opts.ReplaceRecentBlockhash {
+		latest, err := s.sim.GetLatestBlockhash(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		tx.Message.RecentBlockhash = latest.Value.Blockhash
+	}
+	return s.sim.SimulateTransaction(ctx, tx)
+}
@@ -0,0 +1,320 @@
+// Package rpctest serves the Solana JSON-RPC methods the supernode tooling uses
+// over HTTP, backed by an in-process simulator.Simulator ledger, so tests can
+// exercise a real *rpc.Client end to end: request encoding, response decoding
+// and the error payloads client.DecodeCustomError parses. Faults can be
+// injected to reproduce slow nodes, dropped transactions, expired blockhashes
+// and program errors.
+package rpctest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/mr-tron/base58"
+	"n3-solana-test/simulator"
+)
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Server is a JSON-RPC endpoint over a simulator ledger.
+type Server struct {
+	sim  *simulator.Simulator
+	http *httptest.Server
+
+	mu     sync.Mutex
+	faults Faults
+}
+
+// NewServer starts a server over sim. Call Close when done.
+func NewServer(sim *simulator.Simulator) *Server {
+	s := &Server{sim: sim}
+	s.http = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL is the endpoint to pass to rpc.New.
+func (s *Server) URL() string {
+	return s.http.URL
+}
+
+// Client returns an *rpc.Client talking to the server.
+func (s *Server) Client() *rpc.Client {
+	return rpc.New(s.http.URL)
+}
+
+// Simulator returns the ledger behind the server.
+func (s *Server) Simulator() *simulator.Simulator {
+	return s.sim
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.http.Close()
+}
+
+type request struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      nil,
+			"error":   &jsonrpc.RPCError{Code: codeParseError, Message: "Parse error"},
+		})
+		return
+	}
+
+	faults := s.Faults()
+	if faults.Latency > 0 {
+		select {
+		case <-time.After(faults.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	out := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	result, err := s.call(r.Context(), req.Method, req.Params)
+	if err != nil {
+		var rpcErr *jsonrpc.RPCError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &jsonrpc.RPCError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		out["error"] = rpcErr
+	} else {
+		out["result"] = result
+	}
+	writeJSON(w, out)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	buf := new(bytes.Buffer)
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
+
+func invalidParams(format string, args ...interface{}) error {
+	return &jsonrpc.RPCError{Code: codeInvalidParams, Message: "Invalid params: " + fmt.Sprintf(format, args...)}
+}
+
+// param decodes params[i] into v; missing optional params leave v untouched.
+func param(params []json.RawMessage, i int, v interface{}, required bool) error {
+	if i >= len(params) {
+		if required {
+			return invalidParams("missing parameter %d", i)
+		}
+		return nil
+	}
+	if err := json.Unmarshal(params[i], v); err != nil {
+		return invalidParams("parameter %d: %s", i, err)
+	}
+	return nil
+}
+
+func (s *Server) call(ctx context.Context, method string, params []json.RawMessage) (interface{}, error) {
+	switch method {
+	case "getAccountInfo":
+		var key solana.PublicKey
+		if err := param(params, 0, &key, true); err != nil {
+			return nil, err
+		}
+		out, err := s.sim.GetMultipleAccounts(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return &rpc.GetAccountInfoResult{RPCContext: out.RPCContext, Value: out.Value[0]}, nil
+
+	case "getMultipleAccounts":
+		var keys []solana.PublicKey
+		if err := param(params, 0, &keys, true); err != nil {
+			return nil, err
+		}
+		return s.sim.GetMultipleAccounts(ctx, keys...)
+
+	case "getProgramAccounts":
+		var program solana.PublicKey
+		var opts struct {
+			Filters []rpc.RPCFilter `json:"filters"`
+		}
+		if err := param(params, 0, &program, true); err != nil {
+			return nil, err
+		}
+		if err := param(params, 1, &opts, false); err != nil {
+			return nil, err
+		}
+		accounts, err := s.sim.GetProgramAccounts(ctx, program)
+		if err != nil {
+			return nil, err
+		}
+		out := rpc.GetProgramAccountsResult{}
+		for _, acct := range accounts {
+			if matches(acct.Account.Data.GetBinary(), opts.Filters) {
+				out = append(out, acct)
+			}
+		}
+		return out, nil
+
+	case "getBalance":
+		var key solana.PublicKey
+		if err := param(params, 0, &key, true); err != nil {
+			return nil, err
+		}
+		return s.sim.GetBalance(ctx, key, "")
+
+	case "getTokenAccountBalance":
+		var key solana.PublicKey
+		if err := param(params, 0, &key, true); err != nil {
+			return nil, err
+		}
+		return s.sim.GetTokenAccountBalance(ctx, key, "")
+
+	case "getMinimumBalanceForRentExemption":
+		var size uint64
+		if err := param(params, 0, &size, true); err != nil {
+			return nil, err
+		}
+		return s.sim.GetMinimumBalanceForRentExemption(ctx, size, "")
+
+	case "getLatestBlockhash":
+		return s.sim.GetLatestBlockhash(ctx, "")
+
+	case "getSlot":
+		return s.sim.GetSlot(ctx, "")
+
+	case "getBlockHeight":
+		return s.sim.GetBlockHeight(ctx, "")
+
+	case "getRecentPrioritizationFees":
+		var keys solana.PublicKeySlice
+		if err := param(params, 0, &keys, false); err != nil {
+			return nil, err
+		}
+		out, err := s.sim.GetRecentPrioritizationFees(ctx, keys)
+		if out == nil {
+			out = []rpc.PriorizationFeeResult{}
+		}
+		return out, err
+
+	case "sendTransaction":
+		return s.sendTransaction(ctx, params)
+
+	case "simulateTransaction":
+		return s.simulateTransaction(ctx, params)
+
+	case "getSignatureStatuses":
+		var sigs []solana.Signature
+		if err := param(params, 0, &sigs, true); err != nil {
+			return nil, err
+		}
+		return s.sim.GetSignatureStatuses(ctx, true, sigs...)
+
+	case "getTransaction":
+		var sig solana.Signature
+		if err := param(params, 0, &sig, true); err != nil {
+			return nil, err
+		}
+		out, err := s.sim.GetTransaction(ctx, sig, nil)
+		if errors.Is(err, rpc.ErrNotFound) {
+			return json.RawMessage("null"), nil
+		}
+		return out, err
+
+	case "getSignaturesForAddress":
+		var key solana.PublicKey
+		var opts struct {
+			Limit int `json:"limit"`
+		}
+		if err := param(params, 0, &key, true); err != nil {
+			return nil, err
+		}
+		if err := param(params, 1, &opts, false); err != nil {
+			return nil, err
+		}
+		out, err := s.sim.GetSignaturesForAddress(ctx, key)
+		if opts.Limit > 0 && len(out) > opts.Limit {
+			out = out[:opts.Limit]
+		}
+		return out, err
+
+	case "requestAirdrop":
+		var key solana.PublicKey
+		var lamports uint64
+		if err := param(params, 0, &key, true); err != nil {
+			return nil, err
+		}
+		if err := param(params, 1, &lamports, true); err != nil {
+			return nil, err
+		}
+		return s.sim.RequestAirdrop(ctx, key, lamports, "")
+	}
+	return nil, &jsonrpc.RPCError{Code: codeMethodNotFound, Message: "Method not found"}
+}
+
+// matches applies getProgramAccounts filters to account data.
+func matches(data []byte, filters []rpc.RPCFilter) bool {
+	for _, filter := range filters {
+		if filter.DataSize != 0 && uint64(len(data)) != filter.DataSize {
+			return false
+		}
+		if m := filter.Memcmp; m != nil {
+			end := m.Offset + uint64(len(m.Bytes))
+			if end > uint64(len(data)) || !bytes.Equal(data[m.Offset:end], m.Bytes) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// decodeTransaction parses the encoded transaction in params[0], encoded as
+// opts.Encoding (base58 when empty, as the cluster defaults to).
+func decodeTransaction(params []json.RawMessage, encoding string) (*solana.Transaction, error) {
+	var encoded string
+	if err := param(params, 0, &encoded, true); err != nil {
+		return nil, err
+	}
+	var raw []byte
+	var err error
+	switch encoding {
+	case "", string(solana.EncodingBase58):
+		raw, err = base58.Decode(encoded)
+	case string(solana.EncodingBase64):
+		raw, err = base64.StdEncoding.DecodeString(encoded)
+	default:
+		return nil, invalidParams("unsupported encoding %q", encoding)
+	}
+	if err != nil {
+		return nil, invalidParams("failed to decode transaction: %s", err)
+	}
+	tx, err := solana.TransactionFromBytes(raw)
+	if err != nil {
+		return nil, invalidParams("failed to deserialize transaction: %s", err)
+	}
+	return tx, nil
+}
@@ -0,0 +1,168 @@
+package rpctest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/client"
+	"n3-solana-test/rpcclient"
+	"n3-solana-test/simulator"
+)
+
+var programID = solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy")
+
+func init() {
+	client.SetProgramID(programID)
+	rpcclient.PollInterval = time.Millisecond
+}
+
+func newServer(t *testing.T) (*Server, rpcclient.Client) {
+	srv := NewServer(simulator.New(programID))
+	t.Cleanup(srv.Close)
+	return srv, srv.Client()
+}
+
+func fund(t *testing.T, c rpcclient.Client) solana.PrivateKey {
+	key := solana.NewWallet().PrivateKey
+	_, err := c.RequestAirdrop(context.Background(), key.PublicKey(), solana.LAMPORTS_PER_SOL, rpc.CommitmentFinalized)
+	ag_require.NoError(t, err)
+	return key
+}
+
+func signed(t *testing.T, c rpcclient.Client, payer solana.PrivateKey, ixs ...solana.Instruction) *solana.Transaction {
+	recent, err := c.GetLatestBlockhash(context.Background(), rpc.CommitmentFinalized)
+	ag_require.NoError(t, err)
+	tx, err := solana.NewTransaction(ixs, recent.Value.Blockhash, solana.TransactionPayer(payer.PublicKey()))
+	ag_require.NoError(t, err)
+	_, err = tx.Sign(func(solana.PublicKey) *solana.PrivateKey { return &payer })
+	ag_require.NoError(t, err)
+	return tx
+}
+
+// initialize fails in the program: the mint does not exist.
+func initialize(admin solana.PublicKey) solana.Instruction {
+	b := client.NewInitializeInstructionBuilder()
+	return b.
+		SetRewardLockedTime(60).
+		SetStakingCoefficient(1).
+		SetSupernodeAccount(b.MustFindSupernodeAddress()).
+		SetSupernodeStakeAccountAccount(b.MustFindSupernodeStakeAccountAddress()).
+		SetSupernodeVestingAccountAccount(b.MustFindSupernodeVestingAccountAddress()).
+		SetSupernodeRentalAccountAccount(b.MustFindSupernodeRentalAccountAddress()).
+		SetTokenAccount(solana.NewWallet().PublicKey()).
+		SetAdminAccount(admin).
+		Build()
+}
+
+func TestMethods(t *testing.T) {
+	_, c := newServer(t)
+	ctx := context.Background()
+	payer := fund(t, c)
+	to := solana.NewWallet().PublicKey()
+
+	tx := signed(t, c, payer, system.NewTransferInstruction(1000, payer.PublicKey(), to).Build())
+	simulated, err := c.SimulateTransaction(ctx, tx)
+	ag_require.NoError(t, err)
+	ag_require.Nil(t, simulated.Value.Err)
+	ag_require.NotEmpty(t, simulated.Value.Logs)
+
+	sig, err := c.SendTransaction(ctx, tx)
+	ag_require.NoError(t, err)
+	ag_require.NoError(t, rpcclient.WaitForConfirmation(ctx, c, sig, rpc.CommitmentConfirmed))
+
+	balance, err := c.GetBalance(ctx, to, rpc.CommitmentFinalized)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, uint64(1000), balance.Value)
+	info, err := c.GetAccountInfo(ctx, to)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, solana.SystemProgramID, info.Value.Owner)
+	_, err = c.GetAccountInfo(ctx, solana.NewWallet().PublicKey())
+	ag_require.ErrorIs(t, err, rpc.ErrNotFound)
+	multiple, err := c.GetMultipleAccounts(ctx, to, solana.NewWallet().PublicKey())
+	ag_require.NoError(t, err)
+	ag_require.NotNil(t, multiple.Value[0])
+	ag_require.Nil(t, multiple.Value[1])
+	accounts, err := c.GetProgramAccounts(ctx, solana.SystemProgramID)
+	ag_require.NoError(t, err)
+	var found bool
+	for _, acct := range accounts {
+		found = found || acct.Pubkey.Equals(to)
+	}
+	ag_require.True(t, found)
+
+	got, err := c.GetTransaction(ctx, sig, nil)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, uint64(simulator.LamportsPerSignature), got.Meta.Fee)
+	decoded, err := got.Transaction.GetTransaction()
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, sig, decoded.Signatures[0])
+	_, err = c.GetTransaction(ctx, solana.Signature{1}, nil)
+	ag_require.ErrorIs(t, err, rpc.ErrNotFound)
+
+	sigs, err := c.GetSignaturesForAddress(ctx, payer.PublicKey())
+	ag_require.NoError(t, err)
+	ag_require.Len(t, sigs, 2)
+	ag_require.Equal(t, sig, sigs[0].Signature)
+}
+
+func TestProgramErrorOverTheWire(t *testing.T) {
+	_, c := newServer(t)
+	admin := fund(t, c)
+
+	_, err := c.SendTransaction(context.Background(), signed(t, c, admin, initialize(admin.PublicKey())))
+	decoded, ok := client.DecodeCustomError(err)
+	ag_require.True(t, ok, "%v", err)
+	ag_require.ErrorIs(t, decoded, client.ErrInvalidTokenAccount)
+}
+
+func TestFaults(t *testing.T) {
+	srv, c := newServer(t)
+	ctx := context.Background()
+	payer := fund(t, c)
+	transfer := func() *solana.Transaction {
+		return signed(t, c, payer, system.NewTransferInstruction(1, payer.PublicKey(), solana.NewWallet().PublicKey()).Build())
+	}
+
+	srv.SetFaults(Faults{Latency: 200 * time.Millisecond})
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	_, err := c.GetSlot(timeout, rpc.CommitmentFinalized)
+	cancel()
+	ag_require.Error(t, err)
+
+	srv.SetFaults(Faults{DropTransactions: 1})
+	dropped, err := c.SendTransaction(ctx, transfer())
+	ag_require.NoError(t, err)
+	landed, err := c.SendTransaction(ctx, transfer())
+	ag_require.NoError(t, err)
+	statuses, err := c.GetSignatureStatuses(ctx, true, dropped, landed)
+	ag_require.NoError(t, err)
+	ag_require.Nil(t, statuses.Value[0])
+	ag_require.NotNil(t, statuses.Value[1])
+
+	srv.SetFaults(Faults{ExpireBlockhashes: true})
+	_, err = c.SendTransaction(ctx, transfer())
+	var rpcErr *jsonrpc.RPCError
+	ag_require.ErrorAs(t, err, &rpcErr)
+	ag_require.Equal(t, "BlockhashNotFound", rpcErr.Data.(map[string]interface{})["err"])
+
+	srv.SetFaults(Faults{ProgramError: client.ErrDeviceStaked})
+	tx := signed(t, c, payer, initialize(payer.PublicKey()))
+	_, err = c.SendTransaction(ctx, tx)
+	decoded, ok := client.DecodeCustomError(err)
+	ag_require.True(t, ok, "%v", err)
+	ag_require.ErrorIs(t, decoded, client.ErrDeviceStaked)
+	simulated, err := c.SimulateTransaction(ctx, tx)
+	ag_require.NoError(t, err)
+	ag_require.NotNil(t, simulated.Value.Err)
+	ag_require.Contains(t, simulated.Value.Logs[1], "Error Code: DeviceStaked")
+
+	// Transactions not invoking the program are unaffected.
+	_, err = c.SendTransaction(ctx, transfer())
+	ag_require.NoError(t, err)
+}
@@ -0,0 +1,301 @@
+package scenario
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"n3-solana-test/amount"
+	"n3-solana-test/client"
+)
+
+func (env *Env) supernode() solana.PublicKey {
+	return env.Address([]byte("supernode"))
+}
+
+func (env *Env) vault(seed string) solana.PublicKey {
+	return env.Address([]byte(seed))
+}
+
+func (env *Env) stakeInfo(provider solana.PublicKey) solana.PublicKey {
+	return env.Address([]byte("provider_stake_info"), provider[:])
+}
+
+func (env *Env) vestingInfo(provider solana.PublicKey) solana.PublicKey {
+	return env.Address([]byte("provider_vesting_info"), provider[:])
+}
+
+// asAdmin sends ixs paid by provider (or the admin when provider < 0) and
+// co-signed by the admin.
+func (env *Env) asAdmin(provider int, ixs ...solana.Instruction) (solana.Signature, error) {
+	if provider < 0 {
+		return env.Send([]solana.PrivateKey{env.Admin}, ixs...)
+	}
+	return env.Send([]solana.PrivateKey{env.Providers[provider], env.Admin}, ixs...)
+}
+
+// Initialize initializes the supernode program with the env mint.
+// stakingCoefficient is the token amount staked per unit of k-value.
+func Initialize(rewardLockedTime uint64, stakingCoefficient amount.Amount) Action {
+	return Action{Name: "initialize", Run: func(env *Env) (solana.Signature, error) {
+		coefficient, err := env.units("staking coefficient", stakingCoefficient)
+		if err != nil {
+			return solana.Signature{}, err
+		}
+		return env.asAdmin(-1, client.NewInitializeInstructionBuilder().
+			SetRewardLockedTime(rewardLockedTime).
+			SetStakingCoefficient(coefficient).
+			SetSupernodeAccount(env.supernode()).
+			SetSupernodeStakeAccountAccount(env.vault("supernode_stake_account")).
+			SetSupernodeVestingAccountAccount(env.vault("supernode_vesting_account")).
+			SetSupernodeRentalAccountAccount(env.vault("supernode_rental_account")).
+			SetTokenAccount(env.Mint).
+			SetAdminAccount(env.Admin.PublicKey()).
+			Build())
+	}}
+}
+
+// InitRewardAccount creates the reward vault.
+func InitRewardAccount() Action {
+	return Action{Name: "init reward account", Run: func(env *Env) (solana.Signature, error) {
+		return env.asAdmin(-1, client.NewInitRewardAccountInstructionBuilder().
+			SetSupernodeAccount(env.supernode()).
+			SetSupernodeRewardAccountAccount(env.vault("supernode_reward_account")).
+			SetTokenAccount(env.Mint).
+			SetAdminAccount(env.Admin.PublicKey()).
+			Build())
+	}}
+}
+
+// UpdateKValue sets the k value of a device spec.
+func UpdateKValue(spec uint16, val uint64) Action {
+	return Action{Name: fmt.Sprintf("update k value %d", spec), Run: func(env *Env) (solana.Signature, error) {
+		return env.asAdmin(-1, client.NewUpdateKValueInstructionBuilder().
+			SetSpecId(spec).
+			SetVal(val).
+			SetSupernodeAccount(env.supernode()).
+			SetAdminAccount(env.Admin.PublicKey()).
+			Build())
+	}}
+}
+
+// FundRewards mints tokens into the reward vault.
+func FundRewards(tokens amount.Amount) Action {
+	return Action{Name: "fund rewards", Run: func(env *Env) (solana.Signature, error) {
+		units, err := env.units("rewards", tokens)
+		if err != nil {
+			return solana.Signature{}, err
+		}
+		return env.asAdmin(-1, token.NewMintToInstruction(units, env.Mint, env.vault("supernode_reward_account"), env.Admin.PublicKey(), nil).Build())
+	}}
+}
+
+// Stake stakes device for provider, acting as its own controller.
+func Stake(provider int, device, spec uint64) Action {
+	return Action{Name: fmt.Sprintf("stake device %d", device), Run: func(env *Env) (solana.Signature, error) {
+		owner := env.Provider(provider)
+		return env.asAdmin(provider, client.NewStakeDeviceInstructionBuilder().
+			SetDeviceId(device).
+			SetSpecId(spec).
+			SetSupernodeAccount(env.supernode()).
+			SetSupernodeStakeAccountAccount(env.vault("supernode_stake_account")).
+			SetProviderStakeInfoAccount(env.stakeInfo(owner)).
+			SetProviderTokenAccountAccount(env.TokenAccount(owner)).
+			SetTokenAccount(env.Mint).
+			SetProviderAccount(owner).
+			SetControllerAccount(owner).
+			SetAdminAccount(env.Admin.PublicKey()).
+			Build())
+	}}
+}
+
+// Unstake unstakes device of provider, scheduling its stake for release.
+func Unstake(provider int, device uint64) Action {
+	return Action{Name: fmt.Sprintf("unstake device %d", device), Run: func(env *Env) (solana.Signature, error) {
+		owner := env.Provider(provider)
+		return env.asAdmin(provider, client.NewUnstakeDeviceInstructionBuilder().
+			SetDeviceId(device).
+			SetSupernodeAccount(env.supernode()).
+			SetSupernodeStakeAccountAccount(env.vault("supernode_stake_account")).
+			SetSupernodeVestingAccountAccount(env.vault("supernode_vesting_account")).
+			SetProviderStakeInfoAccount(env.stakeInfo(owner)).
+			SetProviderVestingInfoAccount(env.vestingInfo(owner)).
+			SetProviderAccount(owner).
+			SetControllerAccount(owner).
+			SetAdminAccount(env.Admin.PublicKey()).
+			Build())
+	}}
+}
+
+// Advance moves the cluster clock forward.
+func Advance(d time.Duration) Action {
+	return Action{Name: fmt.Sprintf("advance %s", d), Run: func(env *Env) (solana.Signature, error) {
+		env.Sim.Advance(d)
+		return solana.Signature{}, nil
+	}}
+}
+
+// Releasable marks the matured vesting schedules of provider releasable.
+func Releasable(provider int) Action {
+	return Action{Name: "releasable", Run: func(env *Env) (solana.Signature, error) {
+		owner := env.Provider(provider)
+		return env.Send([]solana.PrivateKey{env.Providers[provider]}, client.NewReleasableInstructionBuilder().
+			SetProviderVestingInfoAccount(env.vestingInfo(owner)).
+			SetProviderStakeInfoAccount(env.stakeInfo(owner)).
+			SetProviderAccount(owner).
+			SetControllerAccount(owner).
+			Build())
+	}}
+}
+
+// Release pays the releasable schedules of provider out.
+func Release(provider int) Action {
+	return Action{Name: "release", Run: func(env *Env) (solana.Signature, error) {
+		owner := env.Provider(provider)
+		return env.asAdmin(provider, client.NewReleaseInstructionBuilder().
+			SetSupernodeAccount(env.supernode()).
+			SetSupernodeStakeAccountAccount(env.vault("supernode_stake_account")).
+			SetProviderStakeInfoAccount(env.stakeInfo(owner)).
+			SetProviderVestingInfoAccount(env.vestingInfo(owner)).
+			SetProviderTokenAccountAccount(env.TokenAccount(owner)).
+			SetTokenAccount(env.Mint).
+			SetProviderAccount(owner).
+			SetControllerAccount(owner).
+			SetAdminAccount(env.Admin.PublicKey()).
+			Build())
+	}}
+}
+
+// ClaimReward pays tokens from the reward vault to provider.
+func ClaimReward(provider int, tokens amount.Amount) Action {
+	return Action{Name: "claim reward", Run: func(env *Env) (solana.Signature, error) {
+		units, err := env.units("reward", tokens)
+		if err != nil {
+			return solana.Signature{}, err
+		}
+		owner := env.Provider(provider)
+		return env.asAdmin(provider, client.NewClaimRewardInstructionBuilder().
+			SetAmount(units).
+			SetSupernodeAccount(env.supernode()).
+			SetSupernodeRewardAccountAccount(env.vault("supernode_reward_account")).
+			SetProviderStakeInfoAccount(env.stakeInfo(owner)).
+			SetProviderTokenAccountAccount(env.TokenAccount(owner)).
+			SetTokenAccount(env.Mint).
+			SetProviderAccount(owner).
+			SetControllerAccount(owner).
+			SetAdminAccount(env.Admin.PublicKey()).
+			Build())
+	}}
+}
+
+// AddController lets controller act for provider.
+func AddController(provider int, controller solana.PublicKey) Action {
+	return Action{Name: "add controller", Run: func(env *Env) (solana.Signature, error) {
+		owner := env.Provider(provider)
+		return env.asAdmin(provider, client.NewAddExtraControllerInstructionBuilder().
+			SetSupernodeAccount(env.supernode()).
+			SetProviderStakeInfoAccount(env.stakeInfo(owner)).
+			SetProviderAccount(owner).
+			SetOperatorAccount(owner).
+			SetAdminAccount(env.Admin.PublicKey()).
+			SetNewControllerAccount(controller).
+			Build())
+	}}
+}
+
+// RemoveController revokes controller from provider.
+func RemoveController(provider int, controller solana.PublicKey) Action {
+	return Action{Name: "remove controller", Run: func(env *Env) (solana.Signature, error) {
+		owner := env.Provider(provider)
+		return env.asAdmin(provider, client.NewRemoveExtraControllerInstructionBuilder().
+			SetSupernodeAccount(env.supernode()).
+			SetProviderStakeInfoAccount(env.stakeInfo(owner)).
+			SetProviderAccount(owner).
+			SetOperatorAccount(owner).
+			SetAdminAccount(env.Admin.PublicKey()).
+			SetOldControllerAccount(controller).
+			Build())
+	}}
+}
+
+func tokenBalance(env *Env, account solana.PublicKey, want amount.Amount) error {
+	if _, err := env.units("balance", want); err != nil {
+		return err
+	}
+	units, err := env.Sim.TokenBalance(account)
+	if err != nil {
+		return err
+	}
+	if got := amount.New(units, env.Decimals); got != want {
+		return fmt.Errorf("token account %s holds %s, want %s", account, got, want)
+	}
+	return nil
+}
+
+// ProviderTokens checks the token balance of provider.
+func ProviderTokens(provider int, want amount.Amount) Check {
+	return func(env *Env) error {
+		return tokenBalance(env, env.TokenAccount(env.Provider(provider)), want)
+	}
+}
+
+// VaultTokens checks the token balance of the vault derived from seed, such
+// as "supernode_stake_account".
+func VaultTokens(seed string, want amount.Amount) Check {
+	return func(env *Env) error {
+		return tokenBalance(env, env.vault(seed), want)
+	}
+}
+
+// DeviceState checks the state of a device of provider.
+func DeviceState(provider int, device uint64, want uint16) Check {
+	return func(env *Env) error {
+		info, err := env.Sim.ProviderStakeInfo(env.Provider(provider))
+		if err != nil {
+			return err
+		}
+		if device >= uint64(len(info.Devices)) {
+			return fmt.Errorf("device %d not found", device)
+		}
+		if got := info.Devices[device].State; got != want {
+			return fmt.Errorf("device %d is in state %d, want %d", device, got, want)
+		}
+		return nil
+	}
+}
+
+// Vesting checks the vesting info of provider: the number of pending
+// schedules, how many of them are releasable and the amount released so far.
+func Vesting(provider int, schedules, releasable int, released amount.Amount) Check {
+	return func(env *Env) error {
+		want, err := env.units("released", released)
+		if err != nil {
+			return err
+		}
+		info, err := env.Sim.ProviderVestingInfo(env.Provider(provider))
+		if err != nil {
+			return err
+		}
+		if len(info.Schedules) != schedules || int(info.EndIdx) != releasable || info.ReleasedAmount != want {
+			return fmt.Errorf("vesting has %d schedules, %d releasable, %s released; want %d, %d, %s",
+				len(info.Schedules), info.EndIdx, amount.New(info.ReleasedAmount, env.Decimals), schedules, releasable, released)
+		}
+		return nil
+	}
+}
+
+// Event checks the data of the i-th event emitted by the step against the
+// value want builds, typically an *XEventData naming env keys.
+func Event(i int, want func(env *Env) interface{}) Check {
+	return func(env *Env) error {
+		if i >= len(env.Events) {
+			return fmt.Errorf("event %d not emitted", i)
+		}
+		if got, want := env.Events[i].Data, want(env); !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("event %d is %+v, want %+v", i, got, want)
+		}
+		return nil
+	}
+}
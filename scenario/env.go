@@ -0,0 +1,155 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/amount"
+	"n3-solana-test/client"
+	"n3-solana-test/simulator"
+)
+
+const mintSize = 82
+
+// Options configure a new Env. Zero fields take the defaults.
+type Options struct {
+	// Providers is the number of funded provider wallets (default 1).
+	Providers int
+	// Decimals of the mint (default 9).
+	Decimals uint8
+	// ProviderTokens minted to each provider's associated token account
+	// (default 1,000 tokens).
+	ProviderTokens amount.Amount
+}
+
+// Env is a simulator holding a funded admin, a mint and funded providers
+// with associated token accounts. The supernode program itself is left
+// uninitialized: scenarios start with the Initialize action.
+type Env struct {
+	Sim       *simulator.Simulator
+	Admin     solana.PrivateKey
+	Providers []solana.PrivateKey
+	Mint      solana.PublicKey
+	// Decimals of the mint, which the amounts of actions and checks must
+	// have.
+	Decimals uint8
+
+	// Events are the events emitted by the last step that sent a
+	// transaction.
+	Events []*client.Event
+}
+
+// NewEnv builds an Env on a fresh simulator.
+func NewEnv(programID solana.PublicKey, opts Options) (*Env, error) {
+	if opts.Providers == 0 {
+		opts.Providers = 1
+	}
+	if opts.Decimals == 0 {
+		opts.Decimals = 9
+	}
+	if opts.ProviderTokens.IsZero() {
+		opts.ProviderTokens = amount.MustTokens(1_000, opts.Decimals)
+	}
+
+	env := &Env{Sim: simulator.New(programID), Admin: solana.NewWallet().PrivateKey, Decimals: opts.Decimals}
+	providerTokens, err := env.units("provider tokens", opts.ProviderTokens)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < opts.Providers; i++ {
+		env.Providers = append(env.Providers, solana.NewWallet().PrivateKey)
+	}
+	ctx := context.Background()
+	wallets := []solana.PublicKey{env.Admin.PublicKey()}
+	for _, provider := range env.Providers {
+		wallets = append(wallets, provider.PublicKey())
+	}
+	for _, wallet := range wallets {
+		if _, err := env.Sim.RequestAirdrop(ctx, wallet, 100*solana.LAMPORTS_PER_SOL, rpc.CommitmentFinalized); err != nil {
+			return nil, fmt.Errorf("failed to fund %s: %w", wallet, err)
+		}
+	}
+
+	mint := solana.NewWallet().PrivateKey
+	env.Mint = mint.PublicKey()
+	admin := env.Admin.PublicKey()
+	rent, err := env.Sim.GetMinimumBalanceForRentExemption(ctx, mintSize, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, err
+	}
+	ixs := []solana.Instruction{
+		system.NewCreateAccountInstruction(rent, mintSize, solana.TokenProgramID, admin, env.Mint).Build(),
+		token.NewInitializeMint2Instruction(opts.Decimals, admin, admin, env.Mint).Build(),
+	}
+	if _, err := env.Send([]solana.PrivateKey{env.Admin, mint}, ixs...); err != nil {
+		return nil, fmt.Errorf("failed to create mint: %w", err)
+	}
+	for _, provider := range env.Providers {
+		owner := provider.PublicKey()
+		if _, err := env.Send([]solana.PrivateKey{env.Admin},
+			associatedtokenaccount.NewCreateInstruction(admin, owner, env.Mint).Build(),
+			token.NewMintToInstruction(providerTokens, env.Mint, env.TokenAccount(owner), admin, nil).Build(),
+		); err != nil {
+			return nil, fmt.Errorf("failed to fund provider %s: %w", owner, err)
+		}
+	}
+	return env, nil
+}
+
+// units returns the base units of a, which must have the decimals of the
+// mint.
+func (env *Env) units(name string, a amount.Amount) (uint64, error) {
+	if a.Decimals() != env.Decimals {
+		return 0, fmt.Errorf("%s %s has %d decimals, the mint %d: %w", name, a, a.Decimals(), env.Decimals, amount.ErrDecimals)
+	}
+	return a.Units(), nil
+}
+
+// Provider returns the public key of provider i.
+func (env *Env) Provider(i int) solana.PublicKey {
+	return env.Providers[i].PublicKey()
+}
+
+// TokenAccount returns the associated token account of owner for the mint.
+func (env *Env) TokenAccount(owner solana.PublicKey) solana.PublicKey {
+	ata, _, err := solana.FindAssociatedTokenAddress(owner, env.Mint)
+	if err != nil {
+		panic(err)
+	}
+	return ata
+}
+
+// Address derives a supernode PDA.
+func (env *Env) Address(seeds ...[]byte) solana.PublicKey {
+	return env.Sim.FindAddress(seeds...)
+}
+
+// Send signs ixs with signers, the first of which pays, and sends them in
+// one transaction.
+func (env *Env) Send(signers []solana.PrivateKey, ixs ...solana.Instruction) (solana.Signature, error) {
+	ctx := context.Background()
+	recent, err := env.Sim.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	tx, err := solana.NewTransaction(ixs, recent.Value.Blockhash, solana.TransactionPayer(signers[0].PublicKey()))
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	if _, err := tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		for i := range signers {
+			if signers[i].PublicKey().Equals(key) {
+				return &signers[i]
+			}
+		}
+		return nil
+	}); err != nil {
+		return solana.Signature{}, err
+	}
+	return env.Sim.SendTransaction(ctx, tx)
+}
@@ -0,0 +1,111 @@
+// Package scenario is a table-driven harness for supernode lifecycles on the
+// simulator. A Scenario is a list of steps, each an Action (a transaction or
+// a clock move) with the program error, events and ledger state expected
+// after it:
+//
+//	scenario.Scenario{Name: "stake", Steps: []scenario.Step{
+//		{Do: scenario.Initialize(lockedTime, coefficient)},
+//		{Do: scenario.Stake(0, 1, spec), Events: []string{"DeviceStakedEvent"},
+//			Checks: []scenario.Check{scenario.DeviceState(0, 1, simulator.DeviceStaked)}},
+//		{Do: scenario.Stake(0, 1, spec), Err: client.ErrDeviceStaked},
+//	}}
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/gagliardetto/solana-go"
+	"n3-solana-test/client"
+)
+
+// Action performs a step. Actions that send a transaction return its
+// signature; others return the zero signature.
+type Action struct {
+	Name string
+	Run  func(env *Env) (solana.Signature, error)
+}
+
+// Check asserts ledger state after a step.
+type Check func(env *Env) error
+
+// Step is an action and the outcome expected from it.
+type Step struct {
+	Do Action
+	// Err is the program error the step must fail with, compared with
+	// errors.Is after client.DecodeCustomError. Nil means the step must
+	// succeed.
+	Err error
+	// Events are the names of the events a successful transaction must emit,
+	// in order.
+	Events []string
+	// Checks run after the step.
+	Checks []Check
+}
+
+// Scenario is a named list of steps run in order on one Env.
+type Scenario struct {
+	Name  string
+	Steps []Step
+}
+
+// Repeat returns steps repeated n times.
+func Repeat(n int, steps ...Step) []Step {
+	var out []Step
+	for i := 0; i < n; i++ {
+		out = append(out, steps...)
+	}
+	return out
+}
+
+// Run runs the scenario on env and returns an error naming the first step
+// whose outcome differs from the expectation.
+func (s Scenario) Run(env *Env) error {
+	for i, step := range s.Steps {
+		if err := step.run(env); err != nil {
+			return fmt.Errorf("%s: step %d (%s): %w", s.Name, i, step.Do.Name, err)
+		}
+	}
+	return nil
+}
+
+func (step Step) run(env *Env) error {
+	env.Events = nil
+	sig, err := step.Do.Run(env)
+	if step.Err != nil {
+		if err == nil {
+			return fmt.Errorf("succeeded, want %v", step.Err)
+		}
+		if decoded, ok := client.DecodeCustomError(err); ok {
+			err = decoded
+		}
+		if !errors.Is(err, step.Err) {
+			return fmt.Errorf("failed with %v, want %v", err, step.Err)
+		}
+	} else {
+		if err != nil {
+			return err
+		}
+		if !sig.IsZero() {
+			if env.Events, err = env.Sim.Events(sig); err != nil {
+				return fmt.Errorf("failed to decode events: %w", err)
+			}
+		}
+		var names []string
+		for _, event := range env.Events {
+			names = append(names, event.Name)
+		}
+		if len(names) != 0 || len(step.Events) != 0 {
+			if !reflect.DeepEqual(names, step.Events) {
+				return fmt.Errorf("emitted %v, want %v", names, step.Events)
+			}
+		}
+	}
+	for _, check := range step.Checks {
+		if err := check(env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
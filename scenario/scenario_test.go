@@ -0,0 +1,158 @@
+package scenario
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/amount"
+	"n3-solana-test/client"
+	"n3-solana-test/simulator"
+)
+
+var programID = solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy")
+
+func init() {
+	client.SetProgramID(programID)
+}
+
+const (
+	decimals       = 9
+	providerTokens = 1_000_000
+	coefficient    = 100
+	kvalue         = 3
+	stake          = coefficient * kvalue
+	rewards        = 5_000
+	lockedTime     = 30 * 24 * 60 * 60
+	day            = 24 * time.Hour
+)
+
+// units is an amount of the scenario mint in base units.
+func units(n uint64) amount.Amount {
+	return amount.New(n, decimals)
+}
+
+// setup initializes the program and funds the reward vault.
+var setup = []Step{
+	{Do: Initialize(lockedTime, units(coefficient)), Checks: []Check{VaultTokens("supernode_stake_account", units(0))}},
+	{Do: InitRewardAccount()},
+	{Do: UpdateKValue(1, kvalue), Events: []string{"DeviceKValueUpdated"}},
+	{Do: FundRewards(units(rewards)), Checks: []Check{VaultTokens("supernode_reward_account", units(rewards))}},
+}
+
+func steps(groups ...[]Step) []Step {
+	var out []Step
+	for _, group := range groups {
+		out = append(out, group...)
+	}
+	return out
+}
+
+func TestScenarios(t *testing.T) {
+	controller := solana.NewWallet().PublicKey()
+	scenarios := []Scenario{
+		{Name: "lifecycle", Steps: steps(setup, []Step{
+			{Do: Stake(0, 0, 1), Events: []string{"DeviceStakedEvent"}},
+			{Do: Stake(0, 1, 1), Events: []string{"DeviceStakedEvent"}},
+			{Do: Stake(0, 2, 1), Events: []string{"DeviceStakedEvent"}, Checks: []Check{
+				Event(0, func(env *Env) interface{} {
+					return &client.DeviceStakedEventEventData{Provider: env.Provider(0), DeviceId: 2, SpecId: 1, Amount: stake}
+				}),
+				DeviceState(0, 2, simulator.DeviceStaked),
+				ProviderTokens(0, units(providerTokens-3*stake)),
+				VaultTokens("supernode_stake_account", units(3*stake)),
+			}},
+			{Do: Unstake(0, 1), Events: []string{"DeviceUnstakeEvent", "VestingScheduledEvent"}, Checks: []Check{
+				DeviceState(0, 1, simulator.DeviceUnstaked),
+				DeviceState(0, 0, simulator.DeviceStaked),
+				Vesting(0, 1, 0, units(0)),
+				VaultTokens("supernode_stake_account", units(3*stake)),
+			}},
+			{Do: Releasable(0), Checks: []Check{Vesting(0, 1, 0, units(0))}},
+			{Do: Advance(30 * day)},
+			{Do: Releasable(0), Checks: []Check{Vesting(0, 1, 1, units(0))}},
+			{Do: Release(0), Events: []string{"TokenReleasedEvent"}, Checks: []Check{
+				Event(0, func(env *Env) interface{} {
+					return &client.TokenReleasedEventEventData{Controller: env.Provider(0), Provider: env.Provider(0), Amount: stake}
+				}),
+				Vesting(0, 0, 0, units(stake)),
+				ProviderTokens(0, units(providerTokens-2*stake)),
+				VaultTokens("supernode_stake_account", units(2*stake)),
+			}},
+			{Do: ClaimReward(0, units(1_000)), Events: []string{"RewardClaimedEvent"}, Checks: []Check{
+				ProviderTokens(0, units(providerTokens-2*stake+1_000)),
+				VaultTokens("supernode_reward_account", units(rewards-1_000)),
+			}},
+			{Do: ClaimReward(0, units(rewards)), Err: client.ErrInsufficientFunds},
+		})},
+		{Name: "stake staked device", Steps: steps(setup, []Step{
+			{Do: Stake(0, 7, 1), Events: []string{"DeviceStakedEvent"}},
+			{Do: Stake(0, 7, 1), Err: client.ErrDeviceStaked, Checks: []Check{
+				ProviderTokens(0, units(providerTokens-stake)),
+			}},
+		})},
+		{Name: "remove unknown controller", Steps: steps(setup, []Step{
+			{Do: Stake(0, 0, 1), Events: []string{"DeviceStakedEvent"}},
+			{Do: RemoveController(0, controller), Err: client.ErrControllerNotExist},
+			{Do: AddController(0, controller), Events: []string{"ProviderControllerChangedEvent"}},
+			{Do: RemoveController(0, controller), Events: []string{"ProviderControllerChangedEvent"}},
+			{Do: RemoveController(0, controller), Err: client.ErrControllerNotExist},
+		})},
+		{Name: "too many controllers", Steps: steps(setup, []Step{
+			{Do: Stake(0, 0, 1), Events: []string{"DeviceStakedEvent"}},
+			{Do: AddController(0, solana.NewWallet().PublicKey()), Events: []string{"ProviderControllerChangedEvent"}},
+			{Do: AddController(0, solana.NewWallet().PublicKey()), Events: []string{"ProviderControllerChangedEvent"}},
+			{Do: AddController(0, controller), Err: client.ErrTooManyControllers},
+		})},
+	}
+	runScenarios(t, scenarios)
+}
+
+// TestSimulatorOnlyScenarios covers outcomes neither the IDL nor the account
+// layouts pin down, where the simulator picks one. They document the
+// simulator, not the program, and are not expected to hold on a cluster.
+func TestSimulatorOnlyScenarios(t *testing.T) {
+	scenarios := []Scenario{
+		{Name: "release before unlock", Steps: steps(setup, []Step{
+			{Do: Stake(0, 0, 1), Events: []string{"DeviceStakedEvent"}},
+			{Do: Unstake(0, 0), Events: []string{"DeviceUnstakeEvent", "VestingScheduledEvent"}},
+			{Do: Release(0), Err: client.ErrInvalidArgument, Checks: []Check{
+				Vesting(0, 1, 0, units(0)),
+				ProviderTokens(0, units(providerTokens-stake)),
+			}},
+		})},
+	}
+	runScenarios(t, scenarios)
+}
+
+func runScenarios(t *testing.T, scenarios []Scenario) {
+	for _, s := range scenarios {
+		t.Run(s.Name, func(t *testing.T) {
+			env, err := NewEnv(programID, Options{ProviderTokens: units(providerTokens)})
+			ag_require.NoError(t, err)
+			ag_require.NoError(t, s.Run(env))
+		})
+	}
+}
+
+func TestRunReportsStep(t *testing.T) {
+	env, err := NewEnv(programID, Options{})
+	ag_require.NoError(t, err)
+	err = Scenario{Name: "wrong expectation", Steps: steps(setup, []Step{
+		{Do: Stake(0, 0, 1), Err: client.ErrDeviceStaked},
+	})}.Run(env)
+	ag_require.EqualError(t, err, "wrong expectation: step 4 (stake device 0): succeeded, want DeviceStaked(6006): Device staked")
+}
+
+func TestAmountDecimals(t *testing.T) {
+	_, err := NewEnv(programID, Options{ProviderTokens: amount.New(1, 6)})
+	ag_require.ErrorIs(t, err, amount.ErrDecimals)
+
+	env, err := NewEnv(programID, Options{})
+	ag_require.NoError(t, err)
+	err = Scenario{Name: "six decimals", Steps: []Step{
+		{Do: Initialize(lockedTime, amount.MustTokens(10, 6))},
+	}}.Run(env)
+	ag_require.ErrorIs(t, err, amount.ErrDecimals)
+}
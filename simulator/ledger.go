@@ -0,0 +1,497 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	addresslookuptable "github.com/gagliardetto/solana-go/programs/address-lookup-table"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/client"
+)
+
+var addressLookupTableProgramID = solana.MustPublicKeyFromBase58("AddressLookupTab1e1111111111111111111111111")
+
+const (
+	defaultUnitsPerInstruction = 200_000
+	maxComputeUnits            = 1_400_000
+)
+
+// instructionFailure is an instruction error without a custom code, reported
+// by name, such as "MissingRequiredSignature".
+type instructionFailure string
+
+func (f instructionFailure) Error() string { return string(f) }
+
+var (
+	errMissingSignature   = instructionFailure("MissingRequiredSignature")
+	errReadonlyModified   = instructionFailure("ReadonlyDataModified")
+	errInvalidInstruction = instructionFailure("InvalidInstructionData")
+	errInvalidAccountData = instructionFailure("InvalidAccountData")
+	errUninitialized      = instructionFailure("UninitializedAccount")
+	errInvalidSeeds       = instructionFailure("InvalidSeeds")
+	errNotEnoughKeys      = instructionFailure("NotEnoughAccountKeys")
+	errComputeExceeded    = instructionFailure("ComputationalBudgetExceeded")
+	errUnsupportedProgram = instructionFailure("UnsupportedProgramId")
+	errInvalidRealloc     = instructionFailure("InvalidRealloc")
+)
+
+// programError is a custom error of a program. It implements
+// client.CustomError like the supernode program errors do.
+type programError struct {
+	code int
+	name string
+	msg  string
+}
+
+func (e *programError) Code() int     { return e.code }
+func (e *programError) Name() string  { return e.name }
+func (e *programError) Error() string { return fmt.Sprintf("%s(%d): %s", e.name, e.code, e.msg) }
+
+var _ client.CustomError = (*programError)(nil)
+
+// Native program errors.
+var (
+	errAccountAlreadyInUse    = &programError{0, "AccountAlreadyInUse", "an account with the same address already exists"}
+	errNegativeLamports       = &programError{1, "ResultWithNegativeLamports", "account does not have enough SOL to perform the operation"}
+	errTokenInsufficientFunds = &programError{1, "InsufficientFunds", "insufficient funds"}
+	errTokenMintMismatch      = &programError{3, "MintMismatch", "account not associated with this mint"}
+	errTokenOwnerMismatch     = &programError{4, "OwnerMismatch", "owner does not match"}
+	errTokenAlreadyInUse      = &programError{6, "AlreadyInUse", "account or token already in use"}
+)
+
+// Anchor framework errors raised by the supernode program before its own
+// checks run.
+var (
+	ErrInstructionFallbackNotFound  client.CustomError = &programError{101, "InstructionFallbackNotFound", "Fallback functions are not supported"}
+	ErrInstructionDidNotDeserialize client.CustomError = &programError{102, "InstructionDidNotDeserialize", "The program could not deserialize the given instruction"}
+	ErrConstraintSeeds              client.CustomError = &programError{2006, "ConstraintSeeds", "A seeds constraint was violated"}
+	ErrAccountDiscriminatorMismatch client.CustomError = &programError{3002, "AccountDiscriminatorMismatch", "Account discriminator did not match what was expected"}
+	ErrInvalidProgramID             client.CustomError = &programError{3008, "InvalidProgramId", "Program ID was not as expected"}
+	ErrAccountNotSigner             client.CustomError = &programError{3010, "AccountNotSigner", "The given account did not sign"}
+	ErrAccountNotInitialized        client.CustomError = &programError{3012, "AccountNotInitialized", "The program expected this account to be already initialized"}
+)
+
+// errorMessage returns the message part of a custom error.
+func errorMessage(err client.CustomError) string {
+	s := err.Error()
+	if i := strings.Index(s, "): "); i >= 0 {
+		return s[i+3:]
+	}
+	return s
+}
+
+// instructionError renders the failure of instruction index the way the RPC
+// reports transaction errors.
+func instructionError(index int, err error) map[string]interface{} {
+	var detail interface{} = "GenericError"
+	var custom client.CustomError
+	var failure instructionFailure
+	switch {
+	case errors.As(err, &custom):
+		detail = map[string]interface{}{"Custom": json.Number(strconv.Itoa(custom.Code()))}
+	case errors.As(err, &failure):
+		detail = string(failure)
+	}
+	return map[string]interface{}{"InstructionError": []interface{}{json.Number(strconv.Itoa(index)), detail}}
+}
+
+// outcome is the result of running a transaction.
+type outcome struct {
+	// landed is false when the transaction was rejected before execution;
+	// such transactions are neither charged nor recorded.
+	landed   bool
+	err      interface{}
+	logs     []string
+	units    uint64
+	fee      uint64
+	price    uint64
+	writable solana.PublicKeySlice
+	keys     solana.PublicKeySlice
+	pre      []uint64
+	post     []uint64
+	// accounts is the ledger to commit.
+	accounts map[solana.PublicKey]*Account
+}
+
+// execution is the state of one transaction being processed.
+type execution struct {
+	s        *Simulator
+	msg      *solana.Message
+	accounts map[solana.PublicKey]*Account
+	logs     []string
+	units    uint64
+	limit    uint64
+}
+
+// execute runs tx against a copy of the ledger. s.mu must be held.
+func (s *Simulator) execute(tx *solana.Transaction) *outcome {
+	msg := &tx.Message
+	if issued, ok := s.blockhashes[msg.RecentBlockhash]; !ok || s.slot > issued+BlockhashValidSlots {
+		return &outcome{err: "BlockhashNotFound"}
+	}
+	if msg.IsVersioned() && len(msg.AddressTableLookups) > 0 {
+		tables, err := s.lookupTables(msg.GetAddressTableLookups().GetTableIDs())
+		if err != nil {
+			return &outcome{err: "AddressLookupTableNotFound"}
+		}
+		if err := msg.SetAddressTables(tables); err != nil {
+			return &outcome{err: "InvalidAddressLookupTableIndex"}
+		}
+		if err := msg.ResolveLookups(); err != nil {
+			return &outcome{err: "InvalidAddressLookupTableIndex"}
+		}
+	}
+
+	e := &execution{s: s, msg: msg, accounts: make(map[solana.PublicKey]*Account, len(s.accounts))}
+	for key, acct := range s.accounts {
+		e.accounts[key] = acct
+	}
+
+	var price uint64
+	var programInstructions uint64
+	for _, ins := range msg.Instructions {
+		program, err := msg.Program(ins.ProgramIDIndex)
+		if err != nil {
+			return &outcome{err: "InvalidAccountIndex"}
+		}
+		if !program.Equals(solana.ComputeBudget) {
+			programInstructions++
+			continue
+		}
+		switch {
+		case len(ins.Data) == 5 && ins.Data[0] == 2:
+			e.limit = uint64(binary.LittleEndian.Uint32(ins.Data[1:]))
+		case len(ins.Data) == 9 && ins.Data[0] == 3:
+			price = binary.LittleEndian.Uint64(ins.Data[1:])
+		}
+	}
+	if e.limit == 0 {
+		e.limit = defaultUnitsPerInstruction * programInstructions
+	}
+	if e.limit > maxComputeUnits {
+		e.limit = maxComputeUnits
+	}
+
+	fee := uint64(msg.Header.NumRequiredSignatures)*LamportsPerSignature + (price*e.limit+999_999)/1_000_000
+	payerKey := msg.AccountKeys[0]
+	payer, ok := s.accounts[payerKey]
+	if !ok {
+		return &outcome{err: "AccountNotFound"}
+	}
+	if payer.Lamports < fee {
+		return &outcome{err: "InsufficientFundsForFee"}
+	}
+	charged := payer.clone()
+	charged.Lamports -= fee
+	e.accounts[payerKey] = charged
+
+	out := &outcome{landed: true, fee: fee, price: price, keys: append(solana.PublicKeySlice(nil), msg.AccountKeys...)}
+	for _, key := range out.keys {
+		out.pre = append(out.pre, s.balance(s.accounts, key))
+		if w, _ := msg.IsWritable(key); w {
+			out.writable = append(out.writable, key)
+		}
+	}
+
+	for i, ins := range msg.Instructions {
+		program, _ := msg.Program(ins.ProgramIDIndex)
+		accounts, err := ins.ResolveInstructionAccounts(msg)
+		if err == nil {
+			err = e.process(program, accounts, ins.Data)
+		}
+		if err != nil {
+			out.err = instructionError(i, err)
+			break
+		}
+	}
+	out.logs = e.logs
+	out.units = e.units
+
+	if out.err != nil {
+		// Only the fee is charged for failed transactions.
+		out.accounts = make(map[solana.PublicKey]*Account, len(s.accounts))
+		for key, acct := range s.accounts {
+			out.accounts[key] = acct
+		}
+		out.accounts[payerKey] = charged
+	} else {
+		out.accounts = e.accounts
+	}
+	for _, key := range out.keys {
+		out.post = append(out.post, s.balance(out.accounts, key))
+	}
+	return out
+}
+
+func (s *Simulator) balance(accounts map[solana.PublicKey]*Account, key solana.PublicKey) uint64 {
+	if acct, ok := accounts[key]; ok {
+		return acct.Lamports
+	}
+	return 0
+}
+
+// lookupTables reads address lookup tables from the ledger. s.mu must be held.
+func (s *Simulator) lookupTables(keys []solana.PublicKey) (map[solana.PublicKey]solana.PublicKeySlice, error) {
+	tables := make(map[solana.PublicKey]solana.PublicKeySlice, len(keys))
+	for _, key := range keys {
+		acct, ok := s.accounts[key]
+		if !ok || !acct.Owner.Equals(addressLookupTableProgramID) {
+			return nil, fmt.Errorf("address lookup table %s not found", key)
+		}
+		state, err := addresslookuptable.DecodeAddressLookupTableState(acct.Data)
+		if err != nil {
+			return nil, err
+		}
+		tables[key] = state.Addresses
+	}
+	return tables, nil
+}
+
+// addressTables is a client.AddressTablesGetter over the ledger.
+func (s *Simulator) addressTables(keys []solana.PublicKey) (map[solana.PublicKey]solana.PublicKeySlice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookupTables(keys)
+}
+
+func (e *execution) log(format string, args ...interface{}) {
+	e.logs = append(e.logs, fmt.Sprintf(format, args...))
+}
+
+func (e *execution) consume(units uint64) error {
+	e.units += units
+	if e.units > e.limit {
+		e.units = e.limit
+		return errComputeExceeded
+	}
+	return nil
+}
+
+// invoke runs fn as an invocation of program at the given depth, wrapping it
+// in the runtime's invoke, consumed and success or failed logs.
+func (e *execution) invoke(program solana.PublicKey, depth int, fn func() error) error {
+	e.log("Program %s invoke [%d]", program, depth)
+	start := e.units
+	err := fn()
+	if !program.Equals(solana.ComputeBudget) {
+		e.log("Program %s consumed %d of %d compute units", program, e.units-start, e.limit-start)
+	}
+	if err != nil {
+		var custom client.CustomError
+		if errors.As(err, &custom) {
+			e.log("Program %s failed: custom program error: 0x%x", program, custom.Code())
+		} else {
+			e.log("Program %s failed: %s", program, err)
+		}
+		return err
+	}
+	e.log("Program %s success", program)
+	return nil
+}
+
+// get returns the account at key, or nil. The account must not be modified.
+func (e *execution) get(key solana.PublicKey) *Account {
+	return e.accounts[key]
+}
+
+// put stores acct at key, which the transaction must have marked writable.
+func (e *execution) put(key solana.PublicKey, acct *Account) error {
+	if w, _ := e.msg.IsWritable(key); !w {
+		return errReadonlyModified
+	}
+	e.accounts[key] = acct
+	return nil
+}
+
+func (e *execution) process(program solana.PublicKey, accounts []*solana.AccountMeta, data []byte) error {
+	switch {
+	case program.Equals(solana.ComputeBudget):
+		return e.invoke(program, 1, func() error { return e.consume(150) })
+	case program.Equals(solana.SystemProgramID):
+		return e.invoke(program, 1, func() error { return e.system(accounts, data) })
+	case program.Equals(solana.TokenProgramID):
+		return e.invoke(program, 1, func() error { return e.token(accounts, data) })
+	case program.Equals(solana.SPLAssociatedTokenAccountProgramID):
+		return e.invoke(program, 1, func() error { return e.associatedToken(accounts, data) })
+	case program.Equals(addressLookupTableProgramID):
+		return e.invoke(program, 1, func() error { return e.addressLookupTable(accounts, data) })
+	case program.Equals(e.s.programID):
+		return e.invoke(program, 1, func() error { return e.supernode(accounts, data) })
+	}
+	return errUnsupportedProgram
+}
+
+func (e *execution) system(accounts []*solana.AccountMeta, data []byte) error {
+	if err := e.consume(150); err != nil {
+		return err
+	}
+	inst, err := system.DecodeInstruction(accounts, data)
+	if err != nil {
+		return errInvalidInstruction
+	}
+	switch impl := inst.Impl.(type) {
+	case *system.CreateAccount:
+		if len(accounts) < 2 {
+			return errNotEnoughKeys
+		}
+		if !accounts[0].IsSigner || !accounts[1].IsSigner {
+			return errMissingSignature
+		}
+		return e.createAccount(accounts[0].PublicKey, accounts[1].PublicKey, *impl.Lamports, *impl.Space, *impl.Owner)
+	case *system.Transfer:
+		if len(accounts) < 2 {
+			return errNotEnoughKeys
+		}
+		if !accounts[0].IsSigner {
+			return errMissingSignature
+		}
+		return e.transferLamports(accounts[0].PublicKey, accounts[1].PublicKey, *impl.Lamports)
+	}
+	return errInvalidInstruction
+}
+
+func (e *execution) createAccount(payer, key solana.PublicKey, lamports, space uint64, owner solana.PublicKey) error {
+	if existing := e.get(key); existing != nil && (len(existing.Data) > 0 || !existing.Owner.Equals(solana.SystemProgramID)) {
+		return errAccountAlreadyInUse
+	}
+	funder := e.get(payer)
+	if funder == nil || funder.Lamports < lamports {
+		return errNegativeLamports
+	}
+	funder = funder.clone()
+	funder.Lamports -= lamports
+	if err := e.put(payer, funder); err != nil {
+		return err
+	}
+	acct := &Account{Owner: owner, Data: make([]byte, space)}
+	if existing := e.get(key); existing != nil {
+		acct.Lamports = existing.Lamports
+	}
+	acct.Lamports += lamports
+	return e.put(key, acct)
+}
+
+func (e *execution) transferLamports(from, to solana.PublicKey, lamports uint64) error {
+	source := e.get(from)
+	if source == nil || source.Lamports < lamports {
+		return errNegativeLamports
+	}
+	if len(source.Data) > 0 {
+		return instructionFailure("InvalidArgument")
+	}
+	source = source.clone()
+	source.Lamports -= lamports
+	if err := e.put(from, source); err != nil {
+		return err
+	}
+	dest := e.get(to)
+	if dest == nil {
+		dest = &Account{Owner: solana.SystemProgramID}
+	} else {
+		dest = dest.clone()
+	}
+	dest.Lamports += lamports
+	return e.put(to, dest)
+}
+
+// createProgramAccount creates key through a system program CPI, as Anchor's
+// init constraint does.
+func (e *execution) createProgramAccount(payer, key solana.PublicKey, space int, owner solana.PublicKey) error {
+	return e.invoke(solana.SystemProgramID, 2, func() error {
+		if err := e.consume(150); err != nil {
+			return err
+		}
+		return e.createAccount(payer, key, rentExempt(space), uint64(space), owner)
+	})
+}
+
+func (e *execution) addressLookupTable(accounts []*solana.AccountMeta, data []byte) error {
+	if err := e.consume(1500); err != nil {
+		return err
+	}
+	if len(accounts) < 3 || len(data) < 4 {
+		return errNotEnoughKeys
+	}
+	tableKey, authority, payer := accounts[0].PublicKey, accounts[1], accounts[2]
+	if !authority.IsSigner || !payer.IsSigner {
+		return errMissingSignature
+	}
+	switch binary.LittleEndian.Uint32(data) {
+	case 0:
+		if len(data) < 13 {
+			return errInvalidInstruction
+		}
+		slot := binary.LittleEndian.Uint64(data[4:])
+		derived, _, err := solana.FindProgramAddress([][]byte{authority.PublicKey[:], data[4:12]}, addressLookupTableProgramID)
+		if err != nil || !derived.Equals(tableKey) || slot > e.s.slot {
+			return errInvalidSeeds
+		}
+		owner := authority.PublicKey
+		state := addresslookuptable.AddressLookupTableState{TypeIndex: 1, DeactivationSlot: ^uint64(0), Authority: &owner}
+		if err := e.createProgramAccount(payer.PublicKey, tableKey, addresslookuptable.LOOKUP_TABLE_META_SIZE, addressLookupTableProgramID); err != nil {
+			return err
+		}
+		return e.storeTable(tableKey, state)
+	case 2:
+		acct := e.get(tableKey)
+		if acct == nil || !acct.Owner.Equals(addressLookupTableProgramID) {
+			return errUninitialized
+		}
+		state, err := addresslookuptable.DecodeAddressLookupTableState(acct.Data)
+		if err != nil {
+			return errInvalidAccountData
+		}
+		if state.Authority == nil || !state.Authority.Equals(authority.PublicKey) {
+			return instructionFailure("IncorrectAuthority")
+		}
+		if len(data) < 12 {
+			return errInvalidInstruction
+		}
+		n := binary.LittleEndian.Uint64(data[4:])
+		if n == 0 || uint64(len(data)) != 12+32*n || len(state.Addresses)+int(n) > addresslookuptable.LOOKUP_TABLE_MAX_ADDRESSES {
+			return errInvalidInstruction
+		}
+		for i := uint64(0); i < n; i++ {
+			state.Addresses = append(state.Addresses, solana.PublicKeyFromBytes(data[12+32*i:44+32*i]))
+		}
+		state.LastExtendedSlot = e.s.slot
+		if rent := rentExempt(addresslookuptable.LOOKUP_TABLE_META_SIZE + 32*len(state.Addresses)); rent > acct.Lamports {
+			if err := e.transferLamports(payer.PublicKey, tableKey, rent-acct.Lamports); err != nil {
+				return err
+			}
+		}
+		return e.storeTable(tableKey, *state)
+	}
+	return errInvalidInstruction
+}
+
+func (e *execution) storeTable(key solana.PublicKey, state addresslookuptable.AddressLookupTableState) error {
+	buf := new(bytes.Buffer)
+	if err := state.MarshalWithEncoder(ag_binary.NewBinEncoder(buf)); err != nil {
+		return errInvalidAccountData
+	}
+	acct := e.get(key).clone()
+	acct.Data = buf.Bytes()
+	return e.put(key, acct)
+}
+
+// transactionMeta converts an outcome to the RPC representation.
+func (o *outcome) transactionMeta() rpc.TransactionMeta {
+	units := o.units
+	return rpc.TransactionMeta{
+		Err:                  o.err,
+		Fee:                  o.fee,
+		PreBalances:          o.pre,
+		PostBalances:         o.post,
+		LogMessages:          o.logs,
+		ComputeUnitsConsumed: &units,
+	}
+}
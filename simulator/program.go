@@ -0,0 +1,876 @@
+package simulator
+
+import (
+	"encoding/base64"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"n3-solana-test/client"
+)
+
+// Compute units the supernode program consumes per instruction, excluding
+// the CPIs it makes.
+const supernodeUnits = 12_000
+
+// supernodeAccount is an Anchor account of the supernode program.
+type supernodeAccount interface {
+	MarshalWithEncoder(*ag_binary.Encoder) error
+	UnmarshalWithDecoder(*ag_binary.Decoder) error
+}
+
+func (e *execution) supernode(accounts []*solana.AccountMeta, data []byte) error {
+	if err := e.consume(supernodeUnits); err != nil {
+		return err
+	}
+	inst, err := decodeSupernodeInstruction(accounts, data)
+	if err != nil {
+		return e.fail(err)
+	}
+	e.log("Program log: Instruction: %s", client.InstructionIDToName(inst.TypeID))
+
+	switch impl := inst.Impl.(type) {
+	case *client.Initialize:
+		err = e.initialize(impl)
+	case *client.InitRewardAccount:
+		err = e.initRewardAccount(impl)
+	case *client.UpdateKValue:
+		err = e.updateKValue(impl)
+	case *client.UpdateRewardLockTime:
+		err = e.updateRewardLockTime(impl)
+	case *client.UpdateStakingCoefficient:
+		err = e.updateStakingCoefficient(impl)
+	case *client.StakeDevice:
+		err = e.stakeDevice(impl)
+	case *client.UnstakeDevice:
+		err = e.unstakeDevice(impl)
+	case *client.Releasable:
+		err = e.releasable(impl)
+	case *client.Release:
+		err = e.release(impl)
+	case *client.ClaimReward:
+		err = e.claimReward(impl)
+	case *client.ClaimRentalFee:
+		err = e.claimRentalFee(impl)
+	case *client.PayRentalFee:
+		err = e.payRentalFee(impl)
+	case *client.WithdrawRentalFee:
+		err = e.withdrawRentalFee(impl)
+	case *client.AddExtraController:
+		err = e.addExtraController(impl)
+	case *client.RemoveExtraController:
+		err = e.removeExtraController(impl)
+	case *client.ReplaceExtraController:
+		err = e.replaceExtraController(impl)
+	default:
+		err = ErrInstructionFallbackNotFound
+	}
+	if err != nil {
+		return e.fail(err)
+	}
+	return nil
+}
+
+func decodeSupernodeInstruction(accounts []*solana.AccountMeta, data []byte) (*client.Instruction, error) {
+	if len(data) < 8 {
+		return nil, ErrInstructionFallbackNotFound
+	}
+	if client.InstructionIDToName(ag_binary.TypeID(data[:8])) == "" {
+		return nil, ErrInstructionFallbackNotFound
+	}
+	inst := new(client.Instruction)
+	if err := ag_binary.NewBorshDecoder(data).Decode(inst); err != nil {
+		return nil, ErrInstructionDidNotDeserialize
+	}
+	if v, ok := inst.Impl.(solana.AccountsSettable); ok {
+		if err := v.SetAccounts(accounts); err != nil {
+			return nil, errNotEnoughKeys
+		}
+	}
+	if v, ok := inst.Impl.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return nil, ErrInstructionDidNotDeserialize
+		}
+	}
+	return inst, nil
+}
+
+// fail logs a program error the way Anchor does before returning it.
+func (e *execution) fail(err error) error {
+	if custom, ok := err.(client.CustomError); ok {
+		e.log("Program log: AnchorError occurred. Error Code: %s. Error Number: %d. Error Message: %s.", custom.Name(), custom.Code(), errorMessage(custom))
+	}
+	return err
+}
+
+// emit logs an event as Anchor's emit! does.
+func (e *execution) emit(event interface {
+	MarshalWithEncoder(*ag_binary.Encoder) error
+}) {
+	e.log("Program data: %s", base64.StdEncoding.EncodeToString(encodeBorsh(event)))
+}
+
+// pda derives a supernode PDA.
+func (e *execution) pda(seeds ...[]byte) solana.PublicKey {
+	return e.s.FindAddress(seeds...)
+}
+
+func (e *execution) checkAddress(meta *solana.AccountMeta, seeds ...[]byte) error {
+	if !meta.PublicKey.Equals(e.pda(seeds...)) {
+		return ErrConstraintSeeds
+	}
+	return nil
+}
+
+func checkSigner(meta *solana.AccountMeta) error {
+	if !meta.IsSigner {
+		return ErrAccountNotSigner
+	}
+	return nil
+}
+
+func checkPrograms(tokenProgram, systemProgram, associatedTokenProgram *solana.AccountMeta) error {
+	if !tokenProgram.PublicKey.Equals(solana.TokenProgramID) ||
+		!systemProgram.PublicKey.Equals(solana.SystemProgramID) ||
+		!associatedTokenProgram.PublicKey.Equals(solana.SPLAssociatedTokenAccountProgramID) {
+		return ErrInvalidProgramID
+	}
+	return nil
+}
+
+// load decodes the supernode account stored at key into out.
+func (e *execution) load(key solana.PublicKey, out supernodeAccount) error {
+	acct := e.get(key)
+	if acct == nil || !acct.Owner.Equals(e.s.programID) {
+		return ErrAccountNotInitialized
+	}
+	if err := out.UnmarshalWithDecoder(ag_binary.NewBorshDecoder(acct.Data)); err != nil {
+		return ErrAccountDiscriminatorMismatch
+	}
+	return nil
+}
+
+// save stores v at key, creating the account paid by payer when it does not
+// exist yet (Anchor's init_if_needed).
+func (e *execution) save(key, payer solana.PublicKey, v supernodeAccount) error {
+	data := encodeBorsh(v)
+	if len(data) > maxAccountSize {
+		return errInvalidRealloc
+	}
+	acct := e.get(key)
+	if acct == nil {
+		if err := e.createProgramAccount(payer, key, len(data), e.s.programID); err != nil {
+			return err
+		}
+		acct = e.get(key)
+	}
+	acct = acct.clone()
+	acct.Data = data
+	return e.put(key, acct)
+}
+
+// state loads the supernode account and checks the admin signed and matches.
+func (e *execution) state(supernode, admin *solana.AccountMeta) (*client.SupernodeStateAccount, error) {
+	if err := e.checkAddress(supernode, []byte("supernode")); err != nil {
+		return nil, err
+	}
+	state := new(client.SupernodeStateAccount)
+	if err := e.load(supernode.PublicKey, state); err != nil {
+		return nil, err
+	}
+	if err := checkSigner(admin); err != nil {
+		return nil, err
+	}
+	if !admin.PublicKey.Equals(state.Admin) {
+		return nil, client.ErrUnauthorizedUser
+	}
+	return state, nil
+}
+
+// vault checks meta is the token vault derived from seed.
+func (e *execution) vault(meta *solana.AccountMeta, seed string) error {
+	if err := e.checkAddress(meta, []byte(seed)); err != nil {
+		return err
+	}
+	if _, err := e.tokenAccount(meta.PublicKey); err != nil {
+		return ErrAccountNotInitialized
+	}
+	return nil
+}
+
+// vaultSigner lets the program sign for its vaults in CPIs.
+func (e *execution) vaultSigner(vault solana.PublicKey) signerFunc {
+	return func(key solana.PublicKey) bool {
+		return key.Equals(vault) || e.msg.IsSigner(key)
+	}
+}
+
+func (e *execution) checkMint(state *client.SupernodeStateAccount, mint *solana.AccountMeta) error {
+	if !mint.PublicKey.Equals(state.Token) {
+		return client.ErrInvalidTokenAccount
+	}
+	return nil
+}
+
+// checkTokenAccount checks meta is the associated token account of owner.
+func (e *execution) checkTokenAccount(meta *solana.AccountMeta, owner, mint solana.PublicKey) error {
+	ata, _, err := solana.FindAssociatedTokenAddress(owner, mint)
+	if err != nil || !meta.PublicKey.Equals(ata) {
+		return client.ErrInvalidTokenAccount
+	}
+	account, err := e.tokenAccount(ata)
+	if err != nil || !account.Owner.Equals(owner) || !account.Mint.Equals(mint) {
+		return client.ErrInvalidTokenAccount
+	}
+	return nil
+}
+
+// ensureTokenAccount creates the associated token account of owner when
+// missing (Anchor's init_if_needed) and checks it otherwise.
+func (e *execution) ensureTokenAccount(meta *solana.AccountMeta, owner, mint, payer solana.PublicKey) error {
+	if e.get(meta.PublicKey) == nil {
+		ata, _, err := solana.FindAssociatedTokenAddress(owner, mint)
+		if err != nil || !meta.PublicKey.Equals(ata) {
+			return client.ErrInvalidTokenAccount
+		}
+		if err := e.invoke(solana.SPLAssociatedTokenAccountProgramID, 2, func() error {
+			if err := e.consume(20000); err != nil {
+				return err
+			}
+			e.log("Program log: Create")
+			return e.createTokenAccount(payer, ata, mint, owner)
+		}); err != nil {
+			return err
+		}
+	}
+	return e.checkTokenAccount(meta, owner, mint)
+}
+
+// stakeInfo loads the stake info of provider and checks controller may act
+// for it.
+func (e *execution) stakeInfo(meta, provider, controller *solana.AccountMeta) (*client.ProviderStakeInfoAccount, error) {
+	if err := e.checkAddress(meta, []byte("provider_stake_info"), provider.PublicKey[:]); err != nil {
+		return nil, err
+	}
+	info := new(client.ProviderStakeInfoAccount)
+	if err := e.load(meta.PublicKey, info); err != nil {
+		return nil, err
+	}
+	if controller != nil {
+		if err := checkController(info, provider, controller); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+func checkController(info *client.ProviderStakeInfoAccount, provider, controller *solana.AccountMeta) error {
+	if err := checkSigner(controller); err != nil {
+		return err
+	}
+	if controller.PublicKey.Equals(provider.PublicKey) || controllerIndex(info, controller.PublicKey) >= 0 {
+		return nil
+	}
+	return client.ErrUnauthorizedUser
+}
+
+func controllerIndex(info *client.ProviderStakeInfoAccount, key solana.PublicKey) int {
+	if key.IsZero() {
+		return -1
+	}
+	for i, c := range info.ExtraControllers {
+		if c.Equals(key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *execution) today() uint16 {
+	return uint16(e.s.now.Unix() / secondsPerDay)
+}
+
+func (e *execution) initialize(inst *client.Initialize) error {
+	supernode, admin := inst.GetSupernodeAccount(), inst.GetAdminAccount()
+	if err := checkSigner(admin); err != nil {
+		return err
+	}
+	if err := checkPrograms(inst.GetTokenProgramAccount(), inst.GetSystemProgramAccount(), inst.GetAssociatedTokenProgramAccount()); err != nil {
+		return err
+	}
+	for _, check := range []struct {
+		meta *solana.AccountMeta
+		seed string
+	}{
+		{supernode, "supernode"},
+		{inst.GetSupernodeStakeAccountAccount(), "supernode_stake_account"},
+		{inst.GetSupernodeVestingAccountAccount(), "supernode_vesting_account"},
+		{inst.GetSupernodeRentalAccountAccount(), "supernode_rental_account"},
+	} {
+		if err := e.checkAddress(check.meta, []byte(check.seed)); err != nil {
+			return err
+		}
+	}
+	mintKey := inst.GetTokenAccount().PublicKey
+	mint, err := e.mint(mintKey)
+	if err != nil {
+		return client.ErrInvalidTokenAccount
+	}
+	if e.get(supernode.PublicKey) != nil {
+		return errAccountAlreadyInUse
+	}
+
+	state := &client.SupernodeStateAccount{
+		Admin: admin.PublicKey,
+		Token: mintKey,
+		Policy: client.Policy{
+			Decimals:           mint.Decimals,
+			RewardLockedTime:   *inst.RewardLockedTime,
+			StakingCoefficient: *inst.StakingCoefficient,
+		},
+	}
+	if err := e.save(supernode.PublicKey, admin.PublicKey, state); err != nil {
+		return err
+	}
+	for _, vault := range []*solana.AccountMeta{inst.GetSupernodeStakeAccountAccount(), inst.GetSupernodeVestingAccountAccount(), inst.GetSupernodeRentalAccountAccount()} {
+		if err := e.createTokenAccount(admin.PublicKey, vault.PublicKey, mintKey, vault.PublicKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *execution) initRewardAccount(inst *client.InitRewardAccount) error {
+	state, err := e.state(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if err != nil {
+		return err
+	}
+	if err := checkPrograms(inst.GetTokenProgramAccount(), inst.GetSystemProgramAccount(), inst.GetAssociatedTokenProgramAccount()); err != nil {
+		return err
+	}
+	if err := e.checkMint(state, inst.GetTokenAccount()); err != nil {
+		return err
+	}
+	reward := inst.GetSupernodeRewardAccountAccount()
+	if err := e.checkAddress(reward, []byte("supernode_reward_account")); err != nil {
+		return err
+	}
+	return e.createTokenAccount(inst.GetAdminAccount().PublicKey, reward.PublicKey, state.Token, reward.PublicKey)
+}
+
+func (e *execution) updateKValue(inst *client.UpdateKValue) error {
+	supernode, admin := inst.GetSupernodeAccount(), inst.GetAdminAccount()
+	state, err := e.state(supernode, admin)
+	if err != nil {
+		return err
+	}
+	spec := int(*inst.SpecId)
+	for len(state.Policy.KValues) <= spec {
+		state.Policy.KValues = append(state.Policy.KValues, 0)
+	}
+	old := state.Policy.KValues[spec]
+	state.Policy.KValues[spec] = *inst.Val
+	if err := e.save(supernode.PublicKey, admin.PublicKey, state); err != nil {
+		return err
+	}
+	e.emit(&client.DeviceKValueUpdatedEventData{SpecId: *inst.SpecId, Old: old, New: *inst.Val, Admin: admin.PublicKey})
+	return nil
+}
+
+func (e *execution) updateRewardLockTime(inst *client.UpdateRewardLockTime) error {
+	supernode, admin := inst.GetSupernodeAccount(), inst.GetAdminAccount()
+	state, err := e.state(supernode, admin)
+	if err != nil {
+		return err
+	}
+	old := state.Policy.RewardLockedTime
+	state.Policy.RewardLockedTime = *inst.New
+	if err := e.save(supernode.PublicKey, admin.PublicKey, state); err != nil {
+		return err
+	}
+	e.emit(&client.RewardLockedTimeUpdatedEventData{Old: old, New: *inst.New, Admin: admin.PublicKey})
+	return nil
+}
+
+func (e *execution) updateStakingCoefficient(inst *client.UpdateStakingCoefficient) error {
+	supernode, admin := inst.GetSupernodeAccount(), inst.GetAdminAccount()
+	state, err := e.state(supernode, admin)
+	if err != nil {
+		return err
+	}
+	if *inst.Val == 0 {
+		return client.ErrInvalidArgument
+	}
+	old := state.Policy.StakingCoefficient
+	state.Policy.StakingCoefficient = *inst.Val
+	if err := e.save(supernode.PublicKey, admin.PublicKey, state); err != nil {
+		return err
+	}
+	e.emit(&client.StakingCoefficientUpdatedEventData{Old: old, New: *inst.Val, Admin: admin.PublicKey})
+	return nil
+}
+
+func (e *execution) stakeDevice(inst *client.StakeDevice) error {
+	state, err := e.state(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if err != nil {
+		return err
+	}
+	if err := checkPrograms(inst.GetTokenProgramAccount(), inst.GetSystemProgramAccount(), inst.GetAssociatedTokenProgramAccount()); err != nil {
+		return err
+	}
+	stake := inst.GetSupernodeStakeAccountAccount()
+	if err := e.vault(stake, "supernode_stake_account"); err != nil {
+		return err
+	}
+	if err := e.checkMint(state, inst.GetTokenAccount()); err != nil {
+		return err
+	}
+	provider, controller, infoMeta := inst.GetProviderAccount(), inst.GetControllerAccount(), inst.GetProviderStakeInfoAccount()
+	if err := e.checkAddress(infoMeta, []byte("provider_stake_info"), provider.PublicKey[:]); err != nil {
+		return err
+	}
+	info := new(client.ProviderStakeInfoAccount)
+	if e.get(infoMeta.PublicKey) != nil {
+		if err := e.load(infoMeta.PublicKey, info); err != nil {
+			return err
+		}
+	}
+	if err := checkController(info, provider, controller); err != nil {
+		return err
+	}
+	source := inst.GetProviderTokenAccountAccount()
+	if err := e.checkTokenAccount(source, provider.PublicKey, state.Token); err != nil {
+		return err
+	}
+
+	deviceID, specID := *inst.DeviceId, *inst.SpecId
+	if specID >= uint64(len(state.Policy.KValues)) || state.Policy.KValues[specID] == 0 {
+		return client.ErrSpecIDMismatch
+	}
+	if deviceID < uint64(len(info.Devices)) && info.Devices[deviceID].State == DeviceStaked {
+		return client.ErrDeviceStaked
+	}
+	kvalue := state.Policy.KValues[specID]
+	amount := state.Policy.StakingCoefficient * kvalue
+	if kvalue != 0 && amount/kvalue != state.Policy.StakingCoefficient {
+		return client.ErrInvalidArgument
+	}
+
+	if deviceID >= maxAccountSize/deviceSize {
+		// The account cannot grow to hold the device.
+		return errInvalidRealloc
+	}
+	for uint64(len(info.Devices)) <= deviceID {
+		info.Devices = append(info.Devices, client.DeviceState{})
+	}
+	info.Devices[deviceID] = client.DeviceState{
+		State:              DeviceStaked,
+		SpecId:             uint16(specID),
+		StakingCoefficient: state.Policy.StakingCoefficient,
+		Kvalue:             kvalue,
+	}
+	if err := e.save(infoMeta.PublicKey, controller.PublicKey, info); err != nil {
+		return err
+	}
+	if err := e.cpiTransfer(source.PublicKey, stake.PublicKey, controller.PublicKey, amount, e.vaultSigner(stake.PublicKey)); err != nil {
+		return err
+	}
+	e.emit(&client.DeviceStakedEventEventData{Provider: provider.PublicKey, DeviceId: deviceID, SpecId: specID, Amount: amount})
+	return nil
+}
+
+func (e *execution) unstakeDevice(inst *client.UnstakeDevice) error {
+	state, err := e.state(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if err != nil {
+		return err
+	}
+	if err := checkPrograms(inst.GetTokenProgramAccount(), inst.GetSystemProgramAccount(), inst.GetAssociatedTokenProgramAccount()); err != nil {
+		return err
+	}
+	if err := e.vault(inst.GetSupernodeStakeAccountAccount(), "supernode_stake_account"); err != nil {
+		return err
+	}
+	if err := e.vault(inst.GetSupernodeVestingAccountAccount(), "supernode_vesting_account"); err != nil {
+		return err
+	}
+	provider, controller := inst.GetProviderAccount(), inst.GetControllerAccount()
+	infoMeta := inst.GetProviderStakeInfoAccount()
+	info, err := e.stakeInfo(infoMeta, provider, controller)
+	if err != nil {
+		return err
+	}
+	vestingMeta := inst.GetProviderVestingInfoAccount()
+	if err := e.checkAddress(vestingMeta, []byte("provider_vesting_info"), provider.PublicKey[:]); err != nil {
+		return err
+	}
+	vesting := new(client.ProviderVestingInfoAccount)
+	if e.get(vestingMeta.PublicKey) != nil {
+		if err := e.load(vestingMeta.PublicKey, vesting); err != nil {
+			return err
+		}
+	}
+
+	deviceID := *inst.DeviceId
+	if deviceID >= uint64(len(info.Devices)) || info.Devices[deviceID].State != DeviceStaked {
+		return client.ErrInvalidArgument
+	}
+	device := info.Devices[deviceID]
+	amount := device.StakingCoefficient * device.Kvalue
+	day := uint16((e.s.now.Unix() + int64(state.Policy.RewardLockedTime)) / secondsPerDay)
+
+	info.Devices[deviceID].State = DeviceUnstaked
+	vesting.Schedules = append(vesting.Schedules, client.Schedule{Day: day, Amount: amount})
+	if err := e.save(infoMeta.PublicKey, controller.PublicKey, info); err != nil {
+		return err
+	}
+	if err := e.save(vestingMeta.PublicKey, controller.PublicKey, vesting); err != nil {
+		return err
+	}
+	e.emit(&client.DeviceUnstakeEventEventData{Provider: provider.PublicKey, DeviceId: deviceID, Amount: amount, ProviderVestingInfoKey: vestingMeta.PublicKey})
+	e.emit(&client.VestingScheduledEventEventData{Provider: provider.PublicKey, Day: day, Amount: amount})
+	return nil
+}
+
+func (e *execution) vestingInfo(meta, provider *solana.AccountMeta) (*client.ProviderVestingInfoAccount, error) {
+	if err := e.checkAddress(meta, []byte("provider_vesting_info"), provider.PublicKey[:]); err != nil {
+		return nil, err
+	}
+	vesting := new(client.ProviderVestingInfoAccount)
+	if err := e.load(meta.PublicKey, vesting); err != nil {
+		return nil, err
+	}
+	return vesting, nil
+}
+
+func (e *execution) releasable(inst *client.Releasable) error {
+	if err := checkPrograms(inst.GetTokenProgramAccount(), inst.GetSystemProgramAccount(), inst.GetAssociatedTokenProgramAccount()); err != nil {
+		return err
+	}
+	provider, controller := inst.GetProviderAccount(), inst.GetControllerAccount()
+	if _, err := e.stakeInfo(inst.GetProviderStakeInfoAccount(), provider, controller); err != nil {
+		return err
+	}
+	vestingMeta := inst.GetProviderVestingInfoAccount()
+	vesting, err := e.vestingInfo(vestingMeta, provider)
+	if err != nil {
+		return err
+	}
+	today := e.today()
+	end := int(vesting.EndIdx)
+	for end < len(vesting.Schedules) && vesting.Schedules[end].Day <= today {
+		end++
+	}
+	vesting.EndIdx = uint8(end)
+	return e.save(vestingMeta.PublicKey, controller.PublicKey, vesting)
+}
+
+func (e *execution) release(inst *client.Release) error {
+	state, err := e.state(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if err != nil {
+		return err
+	}
+	if err := checkPrograms(inst.GetTokenProgramAccount(), inst.GetSystemProgramAccount(), inst.GetAssociatedTokenProgramAccount()); err != nil {
+		return err
+	}
+	stake := inst.GetSupernodeStakeAccountAccount()
+	if err := e.vault(stake, "supernode_stake_account"); err != nil {
+		return err
+	}
+	if err := e.checkMint(state, inst.GetTokenAccount()); err != nil {
+		return err
+	}
+	provider, controller := inst.GetProviderAccount(), inst.GetControllerAccount()
+	if _, err := e.stakeInfo(inst.GetProviderStakeInfoAccount(), provider, controller); err != nil {
+		return err
+	}
+	vestingMeta := inst.GetProviderVestingInfoAccount()
+	vesting, err := e.vestingInfo(vestingMeta, provider)
+	if err != nil {
+		return err
+	}
+	dest := inst.GetProviderTokenAccountAccount()
+	if err := e.ensureTokenAccount(dest, provider.PublicKey, state.Token, controller.PublicKey); err != nil {
+		return err
+	}
+
+	var amount uint64
+	for _, schedule := range vesting.Schedules[:vesting.EndIdx] {
+		amount += schedule.Amount
+	}
+	if amount == 0 {
+		return client.ErrInvalidArgument
+	}
+	vesting.Schedules = vesting.Schedules[vesting.EndIdx:]
+	vesting.EndIdx = 0
+	vesting.ReleasedAmount += amount
+	vesting.LastReleaseDay = e.today()
+	if err := e.save(vestingMeta.PublicKey, controller.PublicKey, vesting); err != nil {
+		return err
+	}
+	if err := e.cpiTransfer(stake.PublicKey, dest.PublicKey, stake.PublicKey, amount, e.vaultSigner(stake.PublicKey)); err != nil {
+		return err
+	}
+	e.emit(&client.TokenReleasedEventEventData{Controller: controller.PublicKey, Provider: provider.PublicKey, Amount: amount})
+	return nil
+}
+
+// payout moves amount from a supernode vault to the provider.
+func (e *execution) payout(state *client.SupernodeStateAccount, vault *solana.AccountMeta, seed string, dest, provider, controller *solana.AccountMeta, amount uint64) error {
+	if err := e.vault(vault, seed); err != nil {
+		return err
+	}
+	if amount == 0 {
+		return client.ErrInvalidArgument
+	}
+	if err := e.ensureTokenAccount(dest, provider.PublicKey, state.Token, controller.PublicKey); err != nil {
+		return err
+	}
+	balance, err := e.tokenAccount(vault.PublicKey)
+	if err != nil {
+		return err
+	}
+	if balance.Amount < amount {
+		return client.ErrInsufficientFunds
+	}
+	return e.cpiTransfer(vault.PublicKey, dest.PublicKey, vault.PublicKey, amount, e.vaultSigner(vault.PublicKey))
+}
+
+func (e *execution) claimReward(inst *client.ClaimReward) error {
+	state, err := e.state(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if err != nil {
+		return err
+	}
+	if err := checkPrograms(inst.GetTokenProgramAccount(), inst.GetSystemProgramAccount(), inst.GetAssociatedTokenProgramAccount()); err != nil {
+		return err
+	}
+	if err := e.checkMint(state, inst.GetTokenAccount()); err != nil {
+		return err
+	}
+	provider, controller := inst.GetProviderAccount(), inst.GetControllerAccount()
+	if _, err := e.stakeInfo(inst.GetProviderStakeInfoAccount(), provider, controller); err != nil {
+		return err
+	}
+	if err := e.payout(state, inst.GetSupernodeRewardAccountAccount(), "supernode_reward_account", inst.GetProviderTokenAccountAccount(), provider, controller, *inst.Amount); err != nil {
+		return err
+	}
+	e.emit(&client.RewardClaimedEventEventData{Provider: provider.PublicKey, Amount: *inst.Amount})
+	return nil
+}
+
+func (e *execution) claimRentalFee(inst *client.ClaimRentalFee) error {
+	state, err := e.state(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if err != nil {
+		return err
+	}
+	if err := checkPrograms(inst.GetTokenProgramAccount(), inst.GetSystemProgramAccount(), inst.GetAssociatedTokenProgramAccount()); err != nil {
+		return err
+	}
+	if err := e.checkMint(state, inst.GetTokenAccount()); err != nil {
+		return err
+	}
+	provider, controller := inst.GetProviderAccount(), inst.GetControllerAccount()
+	if _, err := e.stakeInfo(inst.GetProviderStakeInfoAccount(), provider, controller); err != nil {
+		return err
+	}
+	if err := e.payout(state, inst.GetSupernodeRentalAccountAccount(), "supernode_rental_account", inst.GetProviderTokenAccountAccount(), provider, controller, *inst.Amount); err != nil {
+		return err
+	}
+	e.emit(&client.ClaimRentalFeeEventEventData{Provider: provider.PublicKey, Controller: controller.PublicKey, Amount: *inst.Amount})
+	return nil
+}
+
+func (e *execution) tenantInfo(meta, tenant *solana.AccountMeta, create bool) (*client.TenantInfoAccount, error) {
+	if err := e.checkAddress(meta, []byte("tenant_info"), tenant.PublicKey[:]); err != nil {
+		return nil, err
+	}
+	info := new(client.TenantInfoAccount)
+	if create && e.get(meta.PublicKey) == nil {
+		return info, nil
+	}
+	if err := e.load(meta.PublicKey, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (e *execution) payRentalFee(inst *client.PayRentalFee) error {
+	state, err := e.state(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if err != nil {
+		return err
+	}
+	if err := checkPrograms(inst.GetTokenProgramAccount(), inst.GetSystemProgramAccount(), inst.GetAssociatedTokenProgramAccount()); err != nil {
+		return err
+	}
+	if err := e.checkMint(state, inst.GetTokenAccount()); err != nil {
+		return err
+	}
+	rental := inst.GetSupernodeRentalAccountAccount()
+	if err := e.vault(rental, "supernode_rental_account"); err != nil {
+		return err
+	}
+	tenant := inst.GetTenantAccount()
+	if err := checkSigner(tenant); err != nil {
+		return err
+	}
+	infoMeta := inst.GetTenantInfoAccount()
+	info, err := e.tenantInfo(infoMeta, tenant, true)
+	if err != nil {
+		return err
+	}
+	source := inst.GetTenantTokenAccountAccount()
+	if err := e.checkTokenAccount(source, tenant.PublicKey, state.Token); err != nil {
+		return err
+	}
+	amount := *inst.Amount
+	if amount == 0 || info.Funds+amount < info.Funds {
+		return client.ErrInvalidArgument
+	}
+	info.Funds += amount
+	if err := e.save(infoMeta.PublicKey, tenant.PublicKey, info); err != nil {
+		return err
+	}
+	if err := e.cpiTransfer(source.PublicKey, rental.PublicKey, tenant.PublicKey, amount, e.vaultSigner(rental.PublicKey)); err != nil {
+		return err
+	}
+	e.emit(&client.PayRentalEventEventData{Tenant: tenant.PublicKey, Amount: amount})
+	return nil
+}
+
+func (e *execution) withdrawRentalFee(inst *client.WithdrawRentalFee) error {
+	state, err := e.state(inst.GetSupernodeAccount(), inst.GetAdminAccount())
+	if err != nil {
+		return err
+	}
+	if err := checkPrograms(inst.GetTokenProgramAccount(), inst.GetSystemProgramAccount(), inst.GetAssociatedTokenProgramAccount()); err != nil {
+		return err
+	}
+	if err := e.checkMint(state, inst.GetTokenAccount()); err != nil {
+		return err
+	}
+	rental := inst.GetSupernodeRentalAccountAccount()
+	if err := e.vault(rental, "supernode_rental_account"); err != nil {
+		return err
+	}
+	tenant := inst.GetTenantAccount()
+	if err := checkSigner(tenant); err != nil {
+		return err
+	}
+	infoMeta := inst.GetTenantInfoAccount()
+	info, err := e.tenantInfo(infoMeta, tenant, false)
+	if err != nil {
+		return err
+	}
+	dest := inst.GetTenantTokenAccountAccount()
+	if err := e.checkTokenAccount(dest, tenant.PublicKey, state.Token); err != nil {
+		return err
+	}
+	amount := *inst.Amount
+	if amount == 0 {
+		return client.ErrInvalidArgument
+	}
+	if amount > info.Funds-info.Withdrawn {
+		return client.ErrInsufficientFunds
+	}
+	info.Withdrawn += amount
+	if err := e.save(infoMeta.PublicKey, tenant.PublicKey, info); err != nil {
+		return err
+	}
+	if err := e.cpiTransfer(rental.PublicKey, dest.PublicKey, rental.PublicKey, amount, e.vaultSigner(rental.PublicKey)); err != nil {
+		return err
+	}
+	e.emit(&client.WithdrawEventEventData{Tenant: tenant.PublicKey, Amount: amount})
+	return nil
+}
+
+// controllerChange loads the stake info a controller instruction edits and
+// checks the operator is the provider.
+func (e *execution) controllerChange(supernode, admin, infoMeta, provider, operator *solana.AccountMeta) (*client.ProviderStakeInfoAccount, error) {
+	if _, err := e.state(supernode, admin); err != nil {
+		return nil, err
+	}
+	info, err := e.stakeInfo(infoMeta, provider, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSigner(operator); err != nil {
+		return nil, err
+	}
+	if !operator.PublicKey.Equals(provider.PublicKey) {
+		return nil, client.ErrUnauthorizedUser
+	}
+	return info, nil
+}
+
+func (e *execution) addExtraController(inst *client.AddExtraController) error {
+	provider, operator, infoMeta := inst.GetProviderAccount(), inst.GetOperatorAccount(), inst.GetProviderStakeInfoAccount()
+	info, err := e.controllerChange(inst.GetSupernodeAccount(), inst.GetAdminAccount(), infoMeta, provider, operator)
+	if err != nil {
+		return err
+	}
+	newController := inst.GetNewControllerAccount().PublicKey
+	if newController.IsZero() {
+		return client.ErrInvalidArgument
+	}
+	if newController.Equals(provider.PublicKey) || controllerIndex(info, newController) >= 0 {
+		return client.ErrControllerAlreadyExist
+	}
+	slot := -1
+	for i, c := range info.ExtraControllers {
+		if c.IsZero() {
+			slot = i
+			break
+		}
+	}
+	if slot < 0 {
+		return client.ErrTooManyControllers
+	}
+	info.ExtraControllers[slot] = newController
+	if err := e.save(infoMeta.PublicKey, operator.PublicKey, info); err != nil {
+		return err
+	}
+	e.emit(&client.ProviderControllerChangedEventEventData{Provider: provider.PublicKey, Action: "add", NewController: newController, Operator: operator.PublicKey})
+	return nil
+}
+
+func (e *execution) removeExtraController(inst *client.RemoveExtraController) error {
+	provider, operator, infoMeta := inst.GetProviderAccount(), inst.GetOperatorAccount(), inst.GetProviderStakeInfoAccount()
+	info, err := e.controllerChange(inst.GetSupernodeAccount(), inst.GetAdminAccount(), infoMeta, provider, operator)
+	if err != nil {
+		return err
+	}
+	oldController := inst.GetOldControllerAccount().PublicKey
+	slot := controllerIndex(info, oldController)
+	if slot < 0 {
+		return client.ErrControllerNotExist
+	}
+	info.ExtraControllers[slot] = solana.PublicKey{}
+	if err := e.save(infoMeta.PublicKey, operator.PublicKey, info); err != nil {
+		return err
+	}
+	e.emit(&client.ProviderControllerChangedEventEventData{Provider: provider.PublicKey, Action: "remove", Operator: operator.PublicKey, OldController: oldController})
+	return nil
+}
+
+func (e *execution) replaceExtraController(inst *client.ReplaceExtraController) error {
+	provider, operator, infoMeta := inst.GetProviderAccount(), inst.GetOperatorAccount(), inst.GetProviderStakeInfoAccount()
+	info, err := e.controllerChange(inst.GetSupernodeAccount(), inst.GetAdminAccount(), infoMeta, provider, operator)
+	if err != nil {
+		return err
+	}
+	oldController, newController := inst.GetOldControllerAccount().PublicKey, inst.GetNewControllerAccount().PublicKey
+	slot := controllerIndex(info, oldController)
+	if slot < 0 {
+		return client.ErrControllerNotExist
+	}
+	if newController.IsZero() {
+		return client.ErrInvalidArgument
+	}
+	if newController.Equals(provider.PublicKey) || controllerIndex(info, newController) >= 0 {
+		return client.ErrControllerAlreadyExist
+	}
+	info.ExtraControllers[slot] = newController
+	if err := e.save(infoMeta.PublicKey, operator.PublicKey, info); err != nil {
+		return err
+	}
+	e.emit(&client.ProviderControllerChangedEventEventData{Provider: provider.PublicKey, Action: "replace", NewController: newController, Operator: operator.PublicKey, OldController: oldController})
+	return nil
+}
@@ -0,0 +1,364 @@
+package simulator
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"n3-solana-test/client"
+)
+
+// JSON-RPC error codes the cluster answers sendTransaction failures with.
+const (
+	errCodeSimulationFailed   = -32002
+	errCodeSignatureFailure   = -32003
+	errCodeInvalidTransaction = -32602
+)
+
+func (s *Simulator) context() rpc.RPCContext {
+	return rpc.RPCContext{Context: rpc.Context{Slot: s.slot}}
+}
+
+func toRPCAccount(acct *Account) *rpc.Account {
+	if acct == nil {
+		return nil
+	}
+	return &rpc.Account{
+		Lamports:   acct.Lamports,
+		Owner:      acct.Owner,
+		Data:       rpc.DataBytesOrJSONFromBytes(append([]byte(nil), acct.Data...)),
+		Executable: acct.Executable,
+	}
+}
+
+// GetAccountInfo returns rpc.ErrNotFound for missing accounts, as the rpc
+// package does.
+func (s *Simulator) GetAccountInfo(_ context.Context, account solana.PublicKey) (*rpc.GetAccountInfoResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acct, ok := s.accounts[account]
+	if !ok {
+		return nil, rpc.ErrNotFound
+	}
+	return &rpc.GetAccountInfoResult{RPCContext: s.context(), Value: toRPCAccount(acct)}, nil
+}
+
+func (s *Simulator) GetMultipleAccounts(_ context.Context, accounts ...solana.PublicKey) (*rpc.GetMultipleAccountsResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := &rpc.GetMultipleAccountsResult{RPCContext: s.context(), Value: make([]*rpc.Account, len(accounts))}
+	for i, key := range accounts {
+		out.Value[i] = toRPCAccount(s.accounts[key])
+	}
+	return out, nil
+}
+
+// GetProgramAccounts returns the accounts owned by publicKey, sorted by
+// address.
+func (s *Simulator) GetProgramAccounts(_ context.Context, publicKey solana.PublicKey) (rpc.GetProgramAccountsResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out rpc.GetProgramAccountsResult
+	for key, acct := range s.accounts {
+		if acct.Owner.Equals(publicKey) {
+			out = append(out, &rpc.KeyedAccount{Pubkey: key, Account: toRPCAccount(acct)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Pubkey.String() < out[j].Pubkey.String() })
+	return out, nil
+}
+
+func (s *Simulator) GetBalance(_ context.Context, publicKey solana.PublicKey, _ rpc.CommitmentType) (*rpc.GetBalanceResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &rpc.GetBalanceResult{RPCContext: s.context(), Value: s.balance(s.accounts, publicKey)}, nil
+}
+
+func (s *Simulator) GetTokenAccountBalance(_ context.Context, account solana.PublicKey, _ rpc.CommitmentType) (*rpc.GetTokenAccountBalanceResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acct, ok := s.accounts[account]
+	if !ok || !acct.Owner.Equals(solana.TokenProgramID) {
+		return nil, &jsonrpc.RPCError{Code: errCodeInvalidTransaction, Message: "Invalid param: not a Token account"}
+	}
+	var state token.Account
+	if err := ag_binary.NewBinDecoder(acct.Data).Decode(&state); err != nil {
+		return nil, fmt.Errorf("failed to decode token account %s: %w", account, err)
+	}
+	var decimals uint8
+	if mint, ok := s.accounts[state.Mint]; ok {
+		var m token.Mint
+		if err := ag_binary.NewBinDecoder(mint.Data).Decode(&m); err == nil {
+			decimals = m.Decimals
+		}
+	}
+	amount := fmt.Sprint(state.Amount)
+	return &rpc.GetTokenAccountBalanceResult{
+		RPCContext: s.context(),
+		Value:      &rpc.UiTokenAmount{Amount: amount, Decimals: decimals, UiAmountString: uiAmount(state.Amount, decimals)},
+	}, nil
+}
+
+// uiAmount formats amount with decimals places, trimming trailing zeros.
+func uiAmount(amount uint64, decimals uint8) string {
+	s := fmt.Sprintf("%0*d", int(decimals)+1, amount)
+	whole, frac := s[:len(s)-int(decimals)], s[len(s)-int(decimals):]
+	for len(frac) > 0 && frac[len(frac)-1] == '0' {
+		frac = frac[:len(frac)-1]
+	}
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
+
+func (s *Simulator) GetMinimumBalanceForRentExemption(_ context.Context, dataSize uint64, _ rpc.CommitmentType) (uint64, error) {
+	return rentExempt(int(dataSize)), nil
+}
+
+// GetLatestBlockhash issues the blockhash of the current slot, valid for
+// BlockhashValidSlots slots.
+func (s *Simulator) GetLatestBlockhash(_ context.Context, _ rpc.CommitmentType) (*rpc.GetLatestBlockhashResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hash := blockhash(s.slot)
+	s.blockhashes[hash] = s.slot
+	return &rpc.GetLatestBlockhashResult{
+		RPCContext: s.context(),
+		Value:      &rpc.LatestBlockhashResult{Blockhash: hash, LastValidBlockHeight: s.slot + BlockhashValidSlots},
+	}, nil
+}
+
+func (s *Simulator) GetSlot(_ context.Context, _ rpc.CommitmentType) (uint64, error) {
+	return s.Slot(), nil
+}
+
+// GetBlockHeight returns the current slot: the simulator skips no slots, so
+// its block height is its slot, the height LastValidBlockHeight counts in.
+func (s *Simulator) GetBlockHeight(_ context.Context, _ rpc.CommitmentType) (uint64, error) {
+	return s.Slot(), nil
+}
+
+// GetRecentPrioritizationFees reports, per slot, the lowest price paid by a
+// transaction writing any of accounts, or by any transaction when accounts
+// is empty.
+func (s *Simulator) GetRecentPrioritizationFees(_ context.Context, accounts solana.PublicKeySlice) ([]rpc.PriorizationFeeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []rpc.PriorizationFeeResult
+	for _, sample := range s.fees {
+		if len(accounts) > 0 && !intersects(sample.writable, accounts) {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Slot == sample.slot {
+			if sample.price < out[n-1].PrioritizationFee {
+				out[n-1].PrioritizationFee = sample.price
+			}
+			continue
+		}
+		out = append(out, rpc.PriorizationFeeResult{Slot: sample.slot, PrioritizationFee: sample.price})
+	}
+	return out, nil
+}
+
+func intersects(a, b solana.PublicKeySlice) bool {
+	for _, key := range a {
+		if b.Contains(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Simulator) SendTransaction(ctx context.Context, transaction *solana.Transaction) (solana.Signature, error) {
+	return s.SendTransactionWithOpts(ctx, transaction, rpc.TransactionOpts{})
+}
+
+// SendTransactionWithOpts executes the transaction and, when it lands,
+// commits it in a new slot. Unless preflight is skipped, failing
+// transactions are rejected with the cluster's simulation failure error and
+// nothing is charged. Landed transactions are notified to the subscribers of
+// SubscribeLogs.
+func (s *Simulator) SendTransactionWithOpts(_ context.Context, transaction *solana.Transaction, opts rpc.TransactionOpts) (solana.Signature, error) {
+	sig, err := s.send(transaction, opts)
+	s.notify()
+	return sig, err
+}
+
+func (s *Simulator) send(transaction *solana.Transaction, opts rpc.TransactionOpts) (solana.Signature, error) {
+	raw, tx, err := roundTrip(transaction)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sig := tx.Signatures[0]
+	if _, ok := s.transactions[sig]; ok {
+		return sig, nil
+	}
+	out := s.execute(tx)
+	if out.err != nil && (!opts.SkipPreflight || !out.landed) {
+		if opts.SkipPreflight {
+			return sig, nil
+		}
+		return solana.Signature{}, &jsonrpc.RPCError{
+			Code:    errCodeSimulationFailed,
+			Message: fmt.Sprintf("Transaction simulation failed: %s", describe(out.err)),
+			Data:    map[string]interface{}{"err": out.err, "logs": out.logs},
+		}
+	}
+	s.commit(sig, raw, out)
+	return sig, nil
+}
+
+// commit records a landed transaction. s.mu must be held.
+func (s *Simulator) commit(sig solana.Signature, raw []byte, out *outcome) {
+	s.accounts = out.accounts
+	s.slot++
+	s.transactions[sig] = &record{slot: s.slot, blockTime: s.now, raw: raw, meta: out.transactionMeta()}
+	for _, key := range out.keys {
+		s.history[key] = append(s.history[key], sig)
+	}
+	s.fees = append(s.fees, feeSample{slot: s.slot, price: out.price, writable: out.writable})
+	if len(s.subscribers) > 0 {
+		s.notifications = append(s.notifications, client.TransactionLogs{Signature: sig, Slot: s.slot, Err: out.err, Logs: out.logs})
+	}
+}
+
+// roundTrip serializes transaction and parses it back, so the ledger never
+// shares state with the caller, and verifies its signatures.
+func roundTrip(transaction *solana.Transaction) ([]byte, *solana.Transaction, error) {
+	raw, err := transaction.MarshalBinary()
+	if err != nil {
+		return nil, nil, &jsonrpc.RPCError{Code: errCodeInvalidTransaction, Message: fmt.Sprintf("invalid transaction: %s", err)}
+	}
+	tx, err := solana.TransactionFromBytes(raw)
+	if err != nil {
+		return nil, nil, &jsonrpc.RPCError{Code: errCodeInvalidTransaction, Message: fmt.Sprintf("invalid transaction: %s", err)}
+	}
+	if len(tx.Signatures) == 0 || tx.VerifySignatures() != nil {
+		return nil, nil, &jsonrpc.RPCError{Code: errCodeSignatureFailure, Message: "Transaction signature verification failure"}
+	}
+	return raw, tx, nil
+}
+
+func describe(err interface{}) string {
+	if s, ok := err.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(err)
+	return string(b)
+}
+
+// SimulateTransaction executes the transaction without committing it.
+// Signatures are not verified, so unsigned transactions can be simulated.
+func (s *Simulator) SimulateTransaction(_ context.Context, transaction *solana.Transaction) (*rpc.SimulateTransactionResponse, error) {
+	raw, err := transaction.MarshalBinary()
+	if err != nil {
+		return nil, &jsonrpc.RPCError{Code: errCodeInvalidTransaction, Message: fmt.Sprintf("invalid transaction: %s", err)}
+	}
+	tx, err := solana.TransactionFromBytes(raw)
+	if err != nil {
+		return nil, &jsonrpc.RPCError{Code: errCodeInvalidTransaction, Message: fmt.Sprintf("invalid transaction: %s", err)}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := s.execute(tx)
+	units := out.units
+	return &rpc.SimulateTransactionResponse{
+		RPCContext: s.context(),
+		Value:      &rpc.SimulateTransactionResult{Err: out.err, Logs: out.logs, UnitsConsumed: &units},
+	}, nil
+}
+
+// GetSignatureStatuses reports landed transactions as finalized; unknown
+// signatures have a nil status.
+func (s *Simulator) GetSignatureStatuses(_ context.Context, _ bool, transactionSignatures ...solana.Signature) (*rpc.GetSignatureStatusesResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := &rpc.GetSignatureStatusesResult{RPCContext: s.context(), Value: make([]*rpc.SignatureStatusesResult, len(transactionSignatures))}
+	for i, sig := range transactionSignatures {
+		if rec, ok := s.transactions[sig]; ok {
+			out.Value[i] = &rpc.SignatureStatusesResult{Slot: rec.slot, Err: rec.meta.Err, ConfirmationStatus: rpc.ConfirmationStatusFinalized}
+		}
+	}
+	return out, nil
+}
+
+// GetTransaction returns rpc.ErrNotFound for unknown signatures.
+func (s *Simulator) GetTransaction(_ context.Context, txSig solana.Signature, _ *rpc.GetTransactionOpts) (*rpc.GetTransactionResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.transactions[txSig]
+	if !ok {
+		return nil, rpc.ErrNotFound
+	}
+	envelope := new(rpc.TransactionResultEnvelope)
+	encoded, _ := json.Marshal([]string{base64.StdEncoding.EncodeToString(rec.raw), "base64"})
+	if err := json.Unmarshal(encoded, envelope); err != nil {
+		return nil, err
+	}
+	meta := rec.meta
+	blockTime := solana.UnixTimeSeconds(rec.blockTime.Unix())
+	return &rpc.GetTransactionResult{Slot: rec.slot, BlockTime: &blockTime, Transaction: envelope, Meta: &meta}, nil
+}
+
+// GetSignaturesForAddress returns the transactions that referenced account,
+// newest first.
+func (s *Simulator) GetSignaturesForAddress(_ context.Context, account solana.PublicKey) ([]*rpc.TransactionSignature, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sigs := s.history[account]
+	out := make([]*rpc.TransactionSignature, 0, len(sigs))
+	for i := len(sigs) - 1; i >= 0; i-- {
+		rec := s.transactions[sigs[i]]
+		blockTime := solana.UnixTimeSeconds(rec.blockTime.Unix())
+		out = append(out, &rpc.TransactionSignature{
+			Err:                rec.meta.Err,
+			Signature:          sigs[i],
+			Slot:               rec.slot,
+			BlockTime:          &blockTime,
+			ConfirmationStatus: rpc.ConfirmationStatusFinalized,
+		})
+	}
+	return out, nil
+}
+
+// RequestAirdrop transfers lamports to account from the cluster faucet in a
+// transaction of its own.
+func (s *Simulator) RequestAirdrop(_ context.Context, account solana.PublicKey, lamports uint64, _ rpc.CommitmentType) (solana.Signature, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	faucet := s.faucet.PublicKey()
+	hash := blockhash(s.slot)
+	s.blockhashes[hash] = s.slot
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{system.NewTransferInstruction(lamports, faucet, account).Build()},
+		hash, solana.TransactionPayer(faucet),
+	)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	if _, err := tx.Sign(func(solana.PublicKey) *solana.PrivateKey { return &s.faucet }); err != nil {
+		return solana.Signature{}, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	out := s.execute(tx)
+	if out.err != nil {
+		return solana.Signature{}, &jsonrpc.RPCError{Code: errCodeInvalidTransaction, Message: fmt.Sprintf("airdrop failed: %s", describe(out.err))}
+	}
+	s.commit(tx.Signatures[0], raw, out)
+	return tx.Signatures[0], nil
+}
@@ -0,0 +1,299 @@
+// Package simulator is an in-process fake of a Solana cluster running the
+// supernode program. It implements rpcclient.Client, so the supernode tooling
+// runs against it as against a live cluster: transactions are verified,
+// charged and executed atomically and the supernode program emits the same
+// events and logs. The program fails with the client.Errors codes of the
+// checks the IDL and account layouts imply; the simulator adds no limits of
+// its own beyond the runtime's 10 MiB account size.
+//
+// Besides the supernode program the ledger runs the parts of the system,
+// token, associated token, compute budget and address lookup table programs
+// the workflows use.
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/client"
+	"n3-solana-test/rpcclient"
+)
+
+const (
+	// DeviceUnstaked and DeviceStaked are the DeviceState.State values.
+	DeviceUnstaked uint16 = 0
+	DeviceStaked   uint16 = 1
+
+	// LamportsPerSignature is the base transaction fee.
+	LamportsPerSignature = 5000
+	// SlotDuration is the time a slot lasts.
+	SlotDuration = 400 * time.Millisecond
+	// BlockhashValidSlots is how long a blockhash can be used.
+	BlockhashValidSlots = 150
+
+	secondsPerDay  = 24 * 60 * 60
+	faucetLamports = 1 << 62
+	// maxAccountSize is the runtime's limit on the data of an account.
+	maxAccountSize = 10 << 20
+	// deviceSize is the encoded size of a client.DeviceState.
+	deviceSize = 20
+)
+
+// Account is a ledger entry.
+type Account struct {
+	Lamports   uint64
+	Owner      solana.PublicKey
+	Data       []byte
+	Executable bool
+}
+
+func (a *Account) clone() *Account {
+	out := *a
+	out.Data = append([]byte(nil), a.Data...)
+	return &out
+}
+
+// Simulator is the fake cluster. It is safe for concurrent use.
+type Simulator struct {
+	programID solana.PublicKey
+	faucet    solana.PrivateKey
+
+	mu           sync.Mutex
+	now          time.Time
+	slot         uint64
+	accounts     map[solana.PublicKey]*Account
+	blockhashes  map[solana.Hash]uint64
+	transactions map[solana.Signature]*record
+	history      map[solana.PublicKey][]solana.Signature
+	fees         []feeSample
+
+	subscribers   []*func(client.TransactionLogs)
+	notifications []client.TransactionLogs
+	notifying     bool
+}
+
+var _ rpcclient.Client = (*Simulator)(nil)
+
+// record is a landed transaction.
+type record struct {
+	slot      uint64
+	blockTime time.Time
+	raw       []byte
+	meta      rpc.TransactionMeta
+}
+
+// feeSample is the compute unit price one landed transaction paid.
+type feeSample struct {
+	slot     uint64
+	price    uint64
+	writable solana.PublicKeySlice
+}
+
+// New returns an empty cluster with programID deployed and the clock at the
+// current time.
+func New(programID solana.PublicKey) *Simulator {
+	s := &Simulator{
+		programID:    programID,
+		faucet:       solana.NewWallet().PrivateKey,
+		now:          time.Now().UTC().Truncate(time.Second),
+		slot:         1,
+		accounts:     make(map[solana.PublicKey]*Account),
+		blockhashes:  make(map[solana.Hash]uint64),
+		transactions: make(map[solana.Signature]*record),
+		history:      make(map[solana.PublicKey][]solana.Signature),
+	}
+	for _, program := range []solana.PublicKey{
+		programID, solana.SystemProgramID, solana.TokenProgramID, solana.SPLAssociatedTokenAccountProgramID,
+		solana.ComputeBudget, addressLookupTableProgramID,
+	} {
+		s.accounts[program] = &Account{Lamports: 1, Owner: solana.BPFLoaderUpgradeableProgramID, Executable: true}
+	}
+	s.accounts[s.faucet.PublicKey()] = &Account{Lamports: faucetLamports, Owner: solana.SystemProgramID}
+	return s
+}
+
+// ProgramID returns the id the supernode program is deployed at.
+func (s *Simulator) ProgramID() solana.PublicKey {
+	return s.programID
+}
+
+// Now returns the cluster clock.
+func (s *Simulator) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Advance moves the clock, and the slot with it, forward by d.
+func (s *Simulator) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+	s.slot += uint64(d / SlotDuration)
+}
+
+// Slot returns the current slot.
+func (s *Simulator) Slot() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.slot
+}
+
+// Account returns a copy of the account at key, or nil.
+func (s *Simulator) Account(key solana.PublicKey) *Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if acct, ok := s.accounts[key]; ok {
+		return acct.clone()
+	}
+	return nil
+}
+
+// SetAccount stores a copy of acct at key, or deletes key when acct is nil.
+func (s *Simulator) SetAccount(key solana.PublicKey, acct *Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if acct == nil {
+		delete(s.accounts, key)
+		return
+	}
+	s.accounts[key] = acct.clone()
+}
+
+// FindAddress derives a supernode PDA.
+func (s *Simulator) FindAddress(seeds ...[]byte) solana.PublicKey {
+	key, _, err := solana.FindProgramAddress(seeds, s.programID)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// SupernodeState decodes the supernode account.
+func (s *Simulator) SupernodeState() (*client.SupernodeStateAccount, error) {
+	out := new(client.SupernodeStateAccount)
+	return out, s.decode(s.FindAddress([]byte("supernode")), out)
+}
+
+// ProviderStakeInfo decodes the stake info of provider.
+func (s *Simulator) ProviderStakeInfo(provider solana.PublicKey) (*client.ProviderStakeInfoAccount, error) {
+	out := new(client.ProviderStakeInfoAccount)
+	return out, s.decode(s.FindAddress([]byte("provider_stake_info"), provider[:]), out)
+}
+
+// ProviderVestingInfo decodes the vesting info of provider.
+func (s *Simulator) ProviderVestingInfo(provider solana.PublicKey) (*client.ProviderVestingInfoAccount, error) {
+	out := new(client.ProviderVestingInfoAccount)
+	return out, s.decode(s.FindAddress([]byte("provider_vesting_info"), provider[:]), out)
+}
+
+// TenantInfo decodes the info of tenant.
+func (s *Simulator) TenantInfo(tenant solana.PublicKey) (*client.TenantInfoAccount, error) {
+	out := new(client.TenantInfoAccount)
+	return out, s.decode(s.FindAddress([]byte("tenant_info"), tenant[:]), out)
+}
+
+// TokenBalance returns the amount held by a token account.
+func (s *Simulator) TokenBalance(tokenAccount solana.PublicKey) (uint64, error) {
+	acct := s.Account(tokenAccount)
+	if acct == nil {
+		return 0, fmt.Errorf("token account %s not found", tokenAccount)
+	}
+	var state token.Account
+	if err := ag_binary.NewBinDecoder(acct.Data).Decode(&state); err != nil {
+		return 0, fmt.Errorf("failed to decode token account %s: %w", tokenAccount, err)
+	}
+	return state.Amount, nil
+}
+
+// SubscribeLogs calls fn with the logs of every transaction sent that lands,
+// failed ones included, as a logsSubscribe subscription notifies them, so a
+// client.Dispatcher fed by a live subscription runs on the simulator
+// unchanged. Notifications come in the order the transactions land, one at a
+// time, after the ledger is unlocked: fn may query and send transactions.
+// unsubscribe stops the notifications.
+func (s *Simulator) SubscribeLogs(fn func(client.TransactionLogs)) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscriber := &fn
+	s.subscribers = append(s.subscribers, subscriber)
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.subscribers = slices.DeleteFunc(s.subscribers, func(f *func(client.TransactionLogs)) bool { return f == subscriber })
+	}
+}
+
+// notify passes the pending notifications to the subscribers, unless another
+// call is already doing so: it delivers the notifications queued meanwhile,
+// keeping them in order.
+func (s *Simulator) notify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.notifying {
+		return
+	}
+	s.notifying = true
+	for len(s.notifications) > 0 {
+		logs := s.notifications[0]
+		s.notifications = s.notifications[1:]
+		subscribers := slices.Clone(s.subscribers)
+		s.mu.Unlock()
+		for _, fn := range subscribers {
+			(*fn)(logs)
+		}
+		s.mu.Lock()
+	}
+	s.notifying = false
+}
+
+// Events returns the supernode events emitted by a landed transaction.
+func (s *Simulator) Events(sig solana.Signature) ([]*client.Event, error) {
+	out, err := s.GetTransaction(context.Background(), sig, nil)
+	if err != nil {
+		return nil, err
+	}
+	return client.DecodeEvents(out, s.programID, s.addressTables)
+}
+
+func (s *Simulator) decode(key solana.PublicKey, out interface {
+	UnmarshalWithDecoder(*ag_binary.Decoder) error
+}) error {
+	acct := s.Account(key)
+	if acct == nil {
+		return fmt.Errorf("account %s not found", key)
+	}
+	return out.UnmarshalWithDecoder(ag_binary.NewBorshDecoder(acct.Data))
+}
+
+// blockhash returns the blockhash of slot.
+func blockhash(slot uint64) solana.Hash {
+	var seed [8]byte
+	binary.LittleEndian.PutUint64(seed[:], slot)
+	return solana.Hash(sha256.Sum256(seed[:]))
+}
+
+// rentExempt is the minimum balance of an account holding size bytes.
+func rentExempt(size int) uint64 {
+	return uint64(size+128) * 3480 * 2
+}
+
+func encodeBorsh(v interface {
+	MarshalWithEncoder(*ag_binary.Encoder) error
+}) []byte {
+	buf := new(bytes.Buffer)
+	if err := v.MarshalWithEncoder(ag_binary.NewBorshEncoder(buf)); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
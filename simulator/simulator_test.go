@@ -0,0 +1,367 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/client"
+)
+
+var programID = solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy")
+
+func init() {
+	client.SetProgramID(programID)
+}
+
+const (
+	decimals    = 9
+	coefficient = 1_000
+	kvalue      = 5
+	lockedTime  = 30 * secondsPerDay
+)
+
+// env is a simulator with the supernode program initialized and a provider
+// holding tokens.
+type env struct {
+	t        *testing.T
+	sim      *Simulator
+	admin    solana.PrivateKey
+	provider solana.PrivateKey
+	mint     solana.PublicKey
+}
+
+func newEnv(t *testing.T) *env {
+	e := &env{t: t, sim: New(programID), admin: solana.NewWallet().PrivateKey, provider: solana.NewWallet().PrivateKey}
+	ctx := context.Background()
+	for _, key := range []solana.PublicKey{e.admin.PublicKey(), e.provider.PublicKey()} {
+		_, err := e.sim.RequestAirdrop(ctx, key, 10*solana.LAMPORTS_PER_SOL, rpc.CommitmentFinalized)
+		ag_require.NoError(t, err)
+	}
+
+	mint := solana.NewWallet().PrivateKey
+	e.mint = mint.PublicKey()
+	admin := e.admin.PublicKey()
+	rent, err := e.sim.GetMinimumBalanceForRentExemption(ctx, mintSize, rpc.CommitmentFinalized)
+	ag_require.NoError(t, err)
+	e.send(e.admin, []solana.PrivateKey{mint},
+		system.NewCreateAccountInstruction(rent, mintSize, solana.TokenProgramID, admin, e.mint).Build(),
+		token.NewInitializeMint2Instruction(decimals, admin, admin, e.mint).Build(),
+		associatedtokenaccount.NewCreateInstruction(admin, e.provider.PublicKey(), e.mint).Build(),
+		token.NewMintToInstruction(100*coefficient*kvalue, e.mint, e.ata(e.provider.PublicKey()), admin, nil).Build(),
+	)
+	e.send(e.admin, nil,
+		client.NewInitializeInstructionBuilder().
+			SetRewardLockedTime(lockedTime).
+			SetStakingCoefficient(coefficient).
+			SetSupernodeAccount(e.sim.FindAddress([]byte("supernode"))).
+			SetSupernodeStakeAccountAccount(e.sim.FindAddress([]byte("supernode_stake_account"))).
+			SetSupernodeVestingAccountAccount(e.sim.FindAddress([]byte("supernode_vesting_account"))).
+			SetSupernodeRentalAccountAccount(e.sim.FindAddress([]byte("supernode_rental_account"))).
+			SetTokenAccount(e.mint).
+			SetAdminAccount(admin).
+			Build(),
+		client.NewInitRewardAccountInstructionBuilder().
+			SetSupernodeAccount(e.sim.FindAddress([]byte("supernode"))).
+			SetSupernodeRewardAccountAccount(e.sim.FindAddress([]byte("supernode_reward_account"))).
+			SetTokenAccount(e.mint).
+			SetAdminAccount(admin).
+			Build(),
+		client.NewUpdateKValueInstructionBuilder().
+			SetSpecId(1).
+			SetVal(kvalue).
+			SetSupernodeAccount(e.sim.FindAddress([]byte("supernode"))).
+			SetAdminAccount(admin).
+			Build(),
+		token.NewMintToInstruction(50, e.mint, e.sim.FindAddress([]byte("supernode_reward_account")), admin, nil).Build(),
+	)
+	return e
+}
+
+func (e *env) ata(owner solana.PublicKey) solana.PublicKey {
+	ata, _, err := solana.FindAssociatedTokenAddress(owner, e.mint)
+	ag_require.NoError(e.t, err)
+	return ata
+}
+
+func (e *env) trySend(payer solana.PrivateKey, signers []solana.PrivateKey, ixs ...solana.Instruction) (solana.Signature, error) {
+	ctx := context.Background()
+	recent, err := e.sim.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	ag_require.NoError(e.t, err)
+	tx, err := solana.NewTransaction(ixs, recent.Value.Blockhash, solana.TransactionPayer(payer.PublicKey()))
+	ag_require.NoError(e.t, err)
+	keys := append([]solana.PrivateKey{payer}, signers...)
+	_, err = tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		for i := range keys {
+			if keys[i].PublicKey().Equals(key) {
+				return &keys[i]
+			}
+		}
+		return nil
+	})
+	ag_require.NoError(e.t, err)
+	return e.sim.SendTransaction(ctx, tx)
+}
+
+func (e *env) send(payer solana.PrivateKey, signers []solana.PrivateKey, ixs ...solana.Instruction) solana.Signature {
+	sig, err := e.trySend(payer, signers, ixs...)
+	ag_require.NoError(e.t, err)
+	return sig
+}
+
+func (e *env) stake(deviceID uint64) solana.Instruction {
+	provider := e.provider.PublicKey()
+	return client.NewStakeDeviceInstructionBuilder().
+		SetDeviceId(deviceID).
+		SetSpecId(1).
+		SetSupernodeAccount(e.sim.FindAddress([]byte("supernode"))).
+		SetSupernodeStakeAccountAccount(e.sim.FindAddress([]byte("supernode_stake_account"))).
+		SetProviderStakeInfoAccount(e.sim.FindAddress([]byte("provider_stake_info"), provider[:])).
+		SetProviderTokenAccountAccount(e.ata(provider)).
+		SetTokenAccount(e.mint).
+		SetProviderAccount(provider).
+		SetControllerAccount(provider).
+		SetAdminAccount(e.admin.PublicKey()).
+		Build()
+}
+
+func (e *env) unstake(deviceID uint64) solana.Instruction {
+	provider := e.provider.PublicKey()
+	return client.NewUnstakeDeviceInstructionBuilder().
+		SetDeviceId(deviceID).
+		SetSupernodeAccount(e.sim.FindAddress([]byte("supernode"))).
+		SetSupernodeStakeAccountAccount(e.sim.FindAddress([]byte("supernode_stake_account"))).
+		SetSupernodeVestingAccountAccount(e.sim.FindAddress([]byte("supernode_vesting_account"))).
+		SetProviderStakeInfoAccount(e.sim.FindAddress([]byte("provider_stake_info"), provider[:])).
+		SetProviderVestingInfoAccount(e.sim.FindAddress([]byte("provider_vesting_info"), provider[:])).
+		SetProviderAccount(provider).
+		SetControllerAccount(provider).
+		SetAdminAccount(e.admin.PublicKey()).
+		Build()
+}
+
+func eventNames(events []*client.Event) []string {
+	var names []string
+	for _, event := range events {
+		names = append(names, event.Name)
+	}
+	return names
+}
+
+func TestWorkflow(t *testing.T) {
+	e := newEnv(t)
+	provider := e.provider.PublicKey()
+	stakeVault := e.sim.FindAddress([]byte("supernode_stake_account"))
+
+	sig := e.send(e.provider, []solana.PrivateKey{e.admin}, e.stake(0), e.stake(1), e.stake(2))
+	events, err := e.sim.Events(sig)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, []string{"DeviceStakedEvent", "DeviceStakedEvent", "DeviceStakedEvent"}, eventNames(events))
+	for i, event := range events {
+		// Each event is attributed to the outer instruction that emitted it.
+		ag_require.Equal(t, i, event.Instruction)
+		ag_require.Equal(t, 1, event.Depth)
+		ag_require.NotZero(t, event.ComputeUnits)
+	}
+	staked := events[2].Data.(*client.DeviceStakedEventEventData)
+	ag_require.Equal(t, uint64(2), staked.DeviceId)
+	ag_require.Equal(t, uint64(coefficient*kvalue), staked.Amount)
+	balance, err := e.sim.TokenBalance(stakeVault)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, uint64(3*coefficient*kvalue), balance)
+
+	sig = e.send(e.provider, []solana.PrivateKey{e.admin}, e.unstake(1))
+	events, err = e.sim.Events(sig)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, []string{"DeviceUnstakeEvent", "VestingScheduledEvent"}, eventNames(events))
+	vesting, err := e.sim.ProviderVestingInfo(provider)
+	ag_require.NoError(t, err)
+	ag_require.Len(t, vesting.Schedules, 1)
+	info, err := e.sim.ProviderStakeInfo(provider)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, DeviceUnstaked, info.Devices[1].State)
+	ag_require.Equal(t, DeviceStaked, info.Devices[2].State)
+
+	releasable := client.NewReleasableInstructionBuilder().
+		SetProviderVestingInfoAccount(e.sim.FindAddress([]byte("provider_vesting_info"), provider[:])).
+		SetProviderStakeInfoAccount(e.sim.FindAddress([]byte("provider_stake_info"), provider[:])).
+		SetProviderAccount(provider).
+		SetControllerAccount(provider).
+		Build()
+	release := client.NewReleaseInstructionBuilder().
+		SetSupernodeAccount(e.sim.FindAddress([]byte("supernode"))).
+		SetSupernodeStakeAccountAccount(stakeVault).
+		SetProviderStakeInfoAccount(e.sim.FindAddress([]byte("provider_stake_info"), provider[:])).
+		SetProviderVestingInfoAccount(e.sim.FindAddress([]byte("provider_vesting_info"), provider[:])).
+		SetProviderTokenAccountAccount(e.ata(provider)).
+		SetTokenAccount(e.mint).
+		SetProviderAccount(provider).
+		SetControllerAccount(provider).
+		SetAdminAccount(e.admin.PublicKey()).
+		Build()
+
+	// Nothing has matured yet.
+	_, err = e.trySend(e.provider, []solana.PrivateKey{e.admin}, releasable, release)
+	decoded, ok := client.DecodeCustomError(err)
+	ag_require.True(t, ok, "%v", err)
+	ag_require.ErrorIs(t, decoded, client.ErrInvalidArgument)
+
+	e.sim.Advance(31 * 24 * time.Hour)
+	e.send(e.provider, nil, releasable)
+	vesting, err = e.sim.ProviderVestingInfo(provider)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, uint8(1), vesting.EndIdx)
+
+	before, err := e.sim.TokenBalance(e.ata(provider))
+	ag_require.NoError(t, err)
+	sig = e.send(e.provider, []solana.PrivateKey{e.admin}, release)
+	events, err = e.sim.Events(sig)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, []string{"TokenReleasedEvent"}, eventNames(events))
+	after, err := e.sim.TokenBalance(e.ata(provider))
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, uint64(coefficient*kvalue), after-before)
+	vesting, err = e.sim.ProviderVestingInfo(provider)
+	ag_require.NoError(t, err)
+	ag_require.Empty(t, vesting.Schedules)
+	ag_require.Equal(t, uint64(coefficient*kvalue), vesting.ReleasedAmount)
+
+	claim := client.NewClaimRewardInstructionBuilder().
+		SetAmount(20).
+		SetSupernodeAccount(e.sim.FindAddress([]byte("supernode"))).
+		SetSupernodeRewardAccountAccount(e.sim.FindAddress([]byte("supernode_reward_account"))).
+		SetProviderStakeInfoAccount(e.sim.FindAddress([]byte("provider_stake_info"), provider[:])).
+		SetProviderTokenAccountAccount(e.ata(provider)).
+		SetTokenAccount(e.mint).
+		SetProviderAccount(provider).
+		SetControllerAccount(provider).
+		SetAdminAccount(e.admin.PublicKey()).
+		Build()
+	sig = e.send(e.provider, []solana.PrivateKey{e.admin}, claim)
+	events, err = e.sim.Events(sig)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, &client.RewardClaimedEventEventData{Provider: provider, Amount: 20}, events[0].Data)
+
+	sigs, err := e.sim.GetSignaturesForAddress(context.Background(), provider)
+	ag_require.NoError(t, err)
+	ag_require.Equal(t, sig, sigs[0].Signature)
+}
+
+func TestErrors(t *testing.T) {
+	e := newEnv(t)
+	provider := e.provider.PublicKey()
+	e.send(e.provider, []solana.PrivateKey{e.admin}, e.stake(0))
+
+	expectError := func(want error, ixs ...solana.Instruction) {
+		t.Helper()
+		_, err := e.trySend(e.provider, []solana.PrivateKey{e.admin}, ixs...)
+		decoded, ok := client.DecodeCustomError(err)
+		ag_require.True(t, ok, "%v", err)
+		ag_require.ErrorIs(t, decoded, want)
+	}
+	expectError(client.ErrDeviceStaked, e.stake(0))
+	expectError(client.ErrSpecIDMismatch, client.NewStakeDeviceInstructionBuilder().
+		SetDeviceId(1).
+		SetSpecId(7).
+		SetSupernodeAccount(e.sim.FindAddress([]byte("supernode"))).
+		SetSupernodeStakeAccountAccount(e.sim.FindAddress([]byte("supernode_stake_account"))).
+		SetProviderStakeInfoAccount(e.sim.FindAddress([]byte("provider_stake_info"), provider[:])).
+		SetProviderTokenAccountAccount(e.ata(provider)).
+		SetTokenAccount(e.mint).
+		SetProviderAccount(provider).
+		SetControllerAccount(provider).
+		SetAdminAccount(e.admin.PublicKey()).
+		Build())
+	expectError(client.ErrControllerNotExist, client.NewRemoveExtraControllerInstructionBuilder().
+		SetSupernodeAccount(e.sim.FindAddress([]byte("supernode"))).
+		SetProviderStakeInfoAccount(e.sim.FindAddress([]byte("provider_stake_info"), provider[:])).
+		SetProviderAccount(provider).
+		SetOperatorAccount(provider).
+		SetAdminAccount(e.admin.PublicKey()).
+		SetOldControllerAccount(solana.NewWallet().PublicKey()).
+		Build())
+
+	// A device the 10 MiB account has no room for fails to realloc.
+	_, err := e.trySend(e.provider, []solana.PrivateKey{e.admin}, e.stake(maxAccountSize/deviceSize))
+	ag_require.Error(t, err)
+	ag_require.Contains(t, err.Error(), "InvalidRealloc")
+
+	// Failed preflight charges nothing and the logs carry the Anchor error.
+	balance := e.sim.Account(provider).Lamports
+	_, err = e.trySend(e.provider, []solana.PrivateKey{e.admin}, e.stake(0))
+	ag_require.Error(t, err)
+	ag_require.Equal(t, balance, e.sim.Account(provider).Lamports)
+	simulated, err := e.sim.SimulateTransaction(context.Background(), mustTransaction(t, e, e.stake(0)))
+	ag_require.NoError(t, err)
+	ag_require.NotNil(t, simulated.Value.Err)
+	ag_require.Contains(t, simulated.Value.Logs, "Program log: AnchorError occurred. Error Code: DeviceStaked. Error Number: 6006. Error Message: Device staked.")
+}
+
+func mustTransaction(t *testing.T, e *env, ixs ...solana.Instruction) *solana.Transaction {
+	recent, err := e.sim.GetLatestBlockhash(context.Background(), rpc.CommitmentFinalized)
+	ag_require.NoError(t, err)
+	tx, err := solana.NewTransaction(ixs, recent.Value.Blockhash, solana.TransactionPayer(e.provider.PublicKey()))
+	ag_require.NoError(t, err)
+	return tx
+}
+
+// TestSubscribeLogs dispatches the events of the workflow live, from the
+// log notifications, and as a backfill, from the landed transactions.
+func TestSubscribeLogs(t *testing.T) {
+	e := newEnv(t)
+	ctx := context.Background()
+	collect := func(d *client.Dispatcher, seen *[]string) {
+		d.OnDeviceStaked(func(_ context.Context, data *client.DeviceStakedEventEventData, meta client.EventMeta) error {
+			*seen = append(*seen, fmt.Sprintf("staked %d at %d", data.DeviceId, meta.Slot))
+			return nil
+		})
+		d.OnDeviceUnstake(func(_ context.Context, data *client.DeviceUnstakeEventEventData, meta client.EventMeta) error {
+			*seen = append(*seen, fmt.Sprintf("unstaked %d at %d", data.DeviceId, meta.Slot))
+			return nil
+		})
+		d.OnVestingScheduled(func(_ context.Context, _ *client.VestingScheduledEventEventData, meta client.EventMeta) error {
+			*seen = append(*seen, fmt.Sprintf("vesting at %d", meta.Slot))
+			return nil
+		})
+	}
+	var live, backfill []string
+	subscription, archive := client.NewDispatcher(programID), client.NewDispatcher(programID)
+	collect(subscription, &live)
+	collect(archive, &backfill)
+	var sigs []solana.Signature
+	unsubscribe := e.sim.SubscribeLogs(func(logs client.TransactionLogs) {
+		ag_require.NoError(t, subscription.DispatchLogs(ctx, logs))
+		// Notifications come with the ledger unlocked.
+		_, err := e.sim.GetTransaction(ctx, logs.Signature, nil)
+		ag_require.NoError(t, err)
+		sigs = append(sigs, logs.Signature)
+	})
+
+	e.send(e.provider, []solana.PrivateKey{e.admin}, e.stake(0), e.stake(1))
+	e.send(e.provider, []solana.PrivateKey{e.admin}, e.unstake(1))
+	slot := e.sim.Slot()
+	ag_require.Equal(t, []string{
+		fmt.Sprintf("staked 0 at %d", slot-1),
+		fmt.Sprintf("staked 1 at %d", slot-1),
+		fmt.Sprintf("unstaked 1 at %d", slot),
+		fmt.Sprintf("vesting at %d", slot),
+	}, live)
+
+	for _, sig := range sigs {
+		txData, err := e.sim.GetTransaction(ctx, sig, nil)
+		ag_require.NoError(t, err)
+		ag_require.NoError(t, archive.DispatchTransaction(ctx, txData))
+	}
+	ag_require.Equal(t, live, backfill)
+
+	unsubscribe()
+	e.send(e.provider, []solana.PrivateKey{e.admin}, e.stake(1))
+	ag_require.Len(t, live, 4)
+}
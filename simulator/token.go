@@ -0,0 +1,273 @@
+package simulator
+
+import (
+	"bytes"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+)
+
+const (
+	mintSize         = 82
+	tokenAccountSize = 165
+)
+
+// signerFunc reports whether key signed the current invocation, either in the
+// transaction or as a PDA of the invoking program.
+type signerFunc func(key solana.PublicKey) bool
+
+func (e *execution) token(accounts []*solana.AccountMeta, data []byte) error {
+	if err := e.consume(4500); err != nil {
+		return err
+	}
+	inst, err := token.DecodeInstruction(accounts, data)
+	if err != nil {
+		return errInvalidInstruction
+	}
+	signed := func(key solana.PublicKey) bool { return e.msg.IsSigner(key) }
+	key := func(i int) solana.PublicKey { return accounts[i].PublicKey }
+	switch impl := inst.Impl.(type) {
+	case *token.InitializeMint:
+		e.log("Program log: Instruction: InitializeMint")
+		return e.initializeMint(key(0), *impl.Decimals, *impl.MintAuthority, impl.FreezeAuthority)
+	case *token.InitializeMint2:
+		e.log("Program log: Instruction: InitializeMint2")
+		return e.initializeMint(key(0), *impl.Decimals, *impl.MintAuthority, impl.FreezeAuthority)
+	case *token.InitializeAccount:
+		e.log("Program log: Instruction: InitializeAccount")
+		return e.initializeTokenAccount(key(0), key(1), key(2))
+	case *token.InitializeAccount3:
+		e.log("Program log: Instruction: InitializeAccount3")
+		return e.initializeTokenAccount(key(0), key(1), *impl.Owner)
+	case *token.MintTo:
+		e.log("Program log: Instruction: MintTo")
+		return e.mintTo(key(0), key(1), key(2), *impl.Amount, signed)
+	case *token.Transfer:
+		e.log("Program log: Instruction: Transfer")
+		return e.transferTokens(key(0), key(1), key(2), *impl.Amount, signed)
+	case *token.TransferChecked:
+		e.log("Program log: Instruction: TransferChecked")
+		mint, err := e.mint(key(1))
+		if err != nil {
+			return err
+		}
+		if mint.Decimals != *impl.Decimals {
+			return &programError{18, "MintDecimalsMismatch", "the provided decimals value different from the mint decimals"}
+		}
+		return e.transferTokens(key(0), key(2), key(3), *impl.Amount, signed)
+	case *token.Approve:
+		e.log("Program log: Instruction: Approve")
+		return e.approve(key(0), key(1), key(2), *impl.Amount, signed)
+	}
+	return errInvalidInstruction
+}
+
+func (e *execution) mint(key solana.PublicKey) (*token.Mint, error) {
+	acct := e.get(key)
+	if acct == nil || !acct.Owner.Equals(solana.TokenProgramID) || len(acct.Data) != mintSize {
+		return nil, errInvalidAccountData
+	}
+	var mint token.Mint
+	if err := ag_binary.NewBinDecoder(acct.Data).Decode(&mint); err != nil {
+		return nil, errInvalidAccountData
+	}
+	if !mint.IsInitialized {
+		return nil, errUninitialized
+	}
+	return &mint, nil
+}
+
+func (e *execution) tokenAccount(key solana.PublicKey) (*token.Account, error) {
+	acct := e.get(key)
+	if acct == nil || !acct.Owner.Equals(solana.TokenProgramID) || len(acct.Data) != tokenAccountSize {
+		return nil, errInvalidAccountData
+	}
+	var state token.Account
+	if err := ag_binary.NewBinDecoder(acct.Data).Decode(&state); err != nil {
+		return nil, errInvalidAccountData
+	}
+	if state.State == token.Uninitialized {
+		return nil, errUninitialized
+	}
+	return &state, nil
+}
+
+func (e *execution) store(key solana.PublicKey, state interface {
+	MarshalWithEncoder(*ag_binary.Encoder) error
+}) error {
+	buf := new(bytes.Buffer)
+	if err := state.MarshalWithEncoder(ag_binary.NewBinEncoder(buf)); err != nil {
+		return errInvalidAccountData
+	}
+	acct := e.get(key).clone()
+	acct.Data = buf.Bytes()
+	return e.put(key, acct)
+}
+
+func (e *execution) initializeMint(key solana.PublicKey, decimals uint8, authority solana.PublicKey, freeze *solana.PublicKey) error {
+	acct := e.get(key)
+	if acct == nil || !acct.Owner.Equals(solana.TokenProgramID) || len(acct.Data) != mintSize {
+		return errInvalidAccountData
+	}
+	if _, err := e.mint(key); err == nil {
+		return errTokenAlreadyInUse
+	}
+	return e.store(key, token.Mint{MintAuthority: &authority, Decimals: decimals, IsInitialized: true, FreezeAuthority: freeze})
+}
+
+func (e *execution) initializeTokenAccount(key, mint, owner solana.PublicKey) error {
+	acct := e.get(key)
+	if acct == nil || !acct.Owner.Equals(solana.TokenProgramID) || len(acct.Data) != tokenAccountSize {
+		return errInvalidAccountData
+	}
+	if _, err := e.tokenAccount(key); err == nil {
+		return errTokenAlreadyInUse
+	}
+	if _, err := e.mint(mint); err != nil {
+		return err
+	}
+	return e.store(key, token.Account{Mint: mint, Owner: owner, State: token.Initialized})
+}
+
+func (e *execution) mintTo(mintKey, dest, authority solana.PublicKey, amount uint64, signed signerFunc) error {
+	mint, err := e.mint(mintKey)
+	if err != nil {
+		return err
+	}
+	if mint.MintAuthority == nil || !mint.MintAuthority.Equals(authority) {
+		return errTokenOwnerMismatch
+	}
+	if !signed(authority) {
+		return errMissingSignature
+	}
+	account, err := e.tokenAccount(dest)
+	if err != nil {
+		return err
+	}
+	if !account.Mint.Equals(mintKey) {
+		return errTokenMintMismatch
+	}
+	if mint.Supply+amount < mint.Supply {
+		return &programError{14, "Overflow", "operation overflowed"}
+	}
+	mint.Supply += amount
+	account.Amount += amount
+	if err := e.store(mintKey, mint); err != nil {
+		return err
+	}
+	return e.store(dest, account)
+}
+
+func (e *execution) transferTokens(source, dest, authority solana.PublicKey, amount uint64, signed signerFunc) error {
+	from, err := e.tokenAccount(source)
+	if err != nil {
+		return err
+	}
+	to, err := e.tokenAccount(dest)
+	if err != nil {
+		return err
+	}
+	if !from.Mint.Equals(to.Mint) {
+		return errTokenMintMismatch
+	}
+	switch {
+	case from.Owner.Equals(authority):
+	case from.Delegate != nil && from.Delegate.Equals(authority):
+		if from.DelegatedAmount < amount {
+			return errTokenInsufficientFunds
+		}
+		from.DelegatedAmount -= amount
+		if from.DelegatedAmount == 0 {
+			from.Delegate = nil
+		}
+	default:
+		return errTokenOwnerMismatch
+	}
+	if !signed(authority) {
+		return errMissingSignature
+	}
+	if from.Amount < amount {
+		return errTokenInsufficientFunds
+	}
+	if source.Equals(dest) {
+		return nil
+	}
+	from.Amount -= amount
+	to.Amount += amount
+	if err := e.store(source, from); err != nil {
+		return err
+	}
+	return e.store(dest, to)
+}
+
+func (e *execution) approve(source, delegate, owner solana.PublicKey, amount uint64, signed signerFunc) error {
+	account, err := e.tokenAccount(source)
+	if err != nil {
+		return err
+	}
+	if !account.Owner.Equals(owner) {
+		return errTokenOwnerMismatch
+	}
+	if !signed(owner) {
+		return errMissingSignature
+	}
+	account.Delegate = &delegate
+	account.DelegatedAmount = amount
+	return e.store(source, account)
+}
+
+// cpiTransfer moves tokens through a token program CPI at depth 2.
+func (e *execution) cpiTransfer(source, dest, authority solana.PublicKey, amount uint64, signed signerFunc) error {
+	return e.invoke(solana.TokenProgramID, 2, func() error {
+		if err := e.consume(4500); err != nil {
+			return err
+		}
+		e.log("Program log: Instruction: Transfer")
+		return e.transferTokens(source, dest, authority, amount, signed)
+	})
+}
+
+// createTokenAccount creates and initializes a token account through system
+// and token program CPIs.
+func (e *execution) createTokenAccount(payer, key, mint, owner solana.PublicKey) error {
+	if err := e.createProgramAccount(payer, key, tokenAccountSize, solana.TokenProgramID); err != nil {
+		return err
+	}
+	return e.invoke(solana.TokenProgramID, 2, func() error {
+		if err := e.consume(3000); err != nil {
+			return err
+		}
+		e.log("Program log: Instruction: InitializeAccount3")
+		return e.initializeTokenAccount(key, mint, owner)
+	})
+}
+
+func (e *execution) associatedToken(accounts []*solana.AccountMeta, data []byte) error {
+	if err := e.consume(20000); err != nil {
+		return err
+	}
+	if len(accounts) < 6 {
+		return errNotEnoughKeys
+	}
+	payer, ata, wallet, mint := accounts[0], accounts[1].PublicKey, accounts[2].PublicKey, accounts[3].PublicKey
+	idempotent := len(data) > 0 && data[0] == 1
+	if len(data) > 0 && data[0] > 1 {
+		return errInvalidInstruction
+	}
+	e.log("Program log: Create")
+	derived, _, err := solana.FindAssociatedTokenAddress(wallet, mint)
+	if err != nil || !derived.Equals(ata) {
+		return errInvalidSeeds
+	}
+	if existing, err := e.tokenAccount(ata); err == nil {
+		if idempotent && existing.Owner.Equals(wallet) && existing.Mint.Equals(mint) {
+			return nil
+		}
+		return errAccountAlreadyInUse
+	}
+	if !payer.IsSigner {
+		return errMissingSignature
+	}
+	return e.createTokenAccount(payer.PublicKey, ata, mint, wallet)
+}
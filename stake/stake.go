@@ -7,6 +7,7 @@ import (
 	"github.com/gagliardetto/solana-go/rpc"
 	"log"
 	sol_client "n3-solana-test/client"
+	"n3-solana-test/keystore"
 )
 
 const rpcURL = "https://api.devnet.solana.com" // Change to mainnet if needed
@@ -24,7 +25,10 @@ func stakeDevice() {
 	client := rpc.New(rpcURL)
 
 	// Load admin wallet (private key)
-	adminWallet := solana.MustPrivateKeyFromBase58("2fs5YwA2QkpkWx5qty4VhuASrNnnxFNTUhwaBUbcCS6R") // Replace with your keypair path
+	adminWallet, err := keystore.FromEnv("admin")
+	if err != nil {
+		log.Fatalf("Failed to load admin key: %v", err)
+	}
 
 	// Derive the PDA for the Supernode
 	supernodePDA, _, err := solana.FindProgramAddress([][]byte{[]byte("supernode")}, programID)
@@ -11,6 +11,7 @@ import (
 	"github.com/test-go/testify/assert"
 	"log"
 	"n3-solana-test/client"
+	"n3-solana-test/keystore"
 	"testing"
 )
 
@@ -21,7 +22,10 @@ func Test_stakedevice(t *testing.T) {
 
 	ctx := context.TODO()
 
-	admin, err := solana.PrivateKeyFromSolanaKeygenFile("/Users/kevinsheeran/.config/solana/id.json") // Replace with your keypair path
+	admin, err := keystore.FromEnv("admin")
+	if err != nil {
+		t.Skipf("admin key: %v", err)
+	}
 
 	supernodeAccount, _, err := solana.FindProgramAddress([][]byte{[]byte("supernode")}, programID)
 	assert.NoError(t, err)
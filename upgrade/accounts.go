@@ -0,0 +1,195 @@
+package upgrade
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"n3-solana-test/client"
+	"n3-solana-test/dynamic"
+)
+
+// ErrNeedsMigration is returned by Account.Err for accounts holding the
+// layout of another version.
+var ErrNeedsMigration = errors.New("account needs migration")
+
+// Status is the state of a decoded account.
+type Status int
+
+const (
+	// StatusCurrent accounts hold the layout of the deployed version.
+	StatusCurrent Status = iota
+	// StatusNeedsMigration accounts hold an older layout, or one no known
+	// version decodes.
+	StatusNeedsMigration
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusCurrent:
+		return "current"
+	case StatusNeedsMigration:
+		return "needs migration"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// Account is a decoded program account.
+type Account struct {
+	// Name is the IDL name of the account type, such as "ProviderStakeInfo".
+	Name   string
+	Status Status
+	// Version is the version whose layout the account holds, nil when no
+	// known version decodes it.
+	Version *Version
+	// Value is the decoded account: the generated client type, such as
+	// *client.ProviderStakeInfoAccount, for the current version, else a
+	// *dynamic.Value. It is nil when no known version decodes the account.
+	Value any
+	// Reason says why the account does not decode with the deployed layout.
+	Reason string
+}
+
+// Err returns nil for current accounts and an error wrapping
+// ErrNeedsMigration otherwise.
+func (a *Account) Err() error {
+	if a.Status == StatusCurrent {
+		return nil
+	}
+	return fmt.Errorf("%s: %w: %s", a.Name, ErrNeedsMigration, a.Reason)
+}
+
+type staticAccount interface {
+	UnmarshalWithDecoder(*ag_binary.Decoder) error
+}
+
+// staticAccounts are the generated account types by IDL name.
+var staticAccounts = map[string]struct {
+	discriminator [8]byte
+	new           func() staticAccount
+}{
+	"ProviderStakeInfo":   {client.ProviderStakeInfoAccountDiscriminator, func() staticAccount { return new(client.ProviderStakeInfoAccount) }},
+	"ProviderVestingInfo": {client.ProviderVestingInfoAccountDiscriminator, func() staticAccount { return new(client.ProviderVestingInfoAccount) }},
+	"SupernodeState":      {client.SupernodeStateAccountDiscriminator, func() staticAccount { return new(client.SupernodeStateAccount) }},
+	"TenantInfo":          {client.TenantInfoAccountDiscriminator, func() staticAccount { return new(client.TenantInfoAccount) }},
+}
+
+// Decoders decodes program accounts with the layouts of a deployed version,
+// falling back to the layouts of the other known versions for accounts that
+// have not been migrated.
+type Decoders struct {
+	deployed *Version
+	// others are the other versions, newest first.
+	others  []*Version
+	dynamic map[*Version]*dynamic.Decoder
+}
+
+// Decoders returns the decoder set of the deployed version, which may be nil
+// when it is unknown; the current version is then assumed.
+func (r *Registry) Decoders(deployed *Version) *Decoders {
+	if deployed == nil {
+		if deployed = r.Current(); deployed == nil {
+			deployed = CurrentVersion()
+		}
+	}
+	d := &Decoders{deployed: deployed, dynamic: map[*Version]*dynamic.Decoder{}}
+	for i := len(r.versions) - 1; i >= 0; i-- {
+		if v := r.versions[i]; v != deployed {
+			d.others = append(d.others, v)
+		}
+	}
+	for _, v := range append([]*Version{deployed}, d.others...) {
+		if !v.Current {
+			d.dynamic[v] = dynamic.New(v.IDL)
+		}
+	}
+	return d
+}
+
+// Deployed returns the version whose layouts are current.
+func (d *Decoders) Deployed() *Version {
+	return d.deployed
+}
+
+// DecodeAccount decodes data, the data of an account expected to be of type
+// name. An account that fails the discriminator or length of the deployed
+// layout comes back with StatusNeedsMigration and, when another known version
+// decodes it, that version and value. The error is only set for names the
+// deployed version does not have.
+func (d *Decoders) DecodeAccount(name string, data []byte) (*Account, error) {
+	value, reason, err := d.decode(d.deployed, name, data)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		return &Account{Name: name, Status: StatusCurrent, Version: d.deployed, Value: value}, nil
+	}
+	acct := &Account{Name: name, Status: StatusNeedsMigration, Reason: reason}
+	for _, v := range d.others {
+		if value, why, err := d.decode(v, name, data); err == nil && why == "" {
+			acct.Version, acct.Value = v, value
+			acct.Reason = fmt.Sprintf("%s; holds the layout of version %s", reason, v.Name)
+			break
+		}
+	}
+	return acct, nil
+}
+
+// decode decodes data as account name of version v, or returns the reason it
+// does not decode; err is set when v has no account name.
+func (d *Decoders) decode(v *Version, name string, data []byte) (value any, reason string, err error) {
+	if v.Current {
+		static, ok := staticAccounts[name]
+		if !ok {
+			return nil, "", fmt.Errorf("unknown account %s", name)
+		}
+		if len(data) < 8 || !bytes.Equal(data[:8], static.discriminator[:]) {
+			return nil, "wrong discriminator", nil
+		}
+		out := static.new()
+		decoder := ag_binary.NewBorshDecoder(data)
+		if err := out.UnmarshalWithDecoder(decoder); err != nil {
+			return nil, fmt.Sprintf("layout mismatch: %v", err), nil
+		}
+		if rest := data[decoder.Position():]; !isZero(rest) {
+			return nil, fmt.Sprintf("layout mismatch: %d bytes left over", len(rest)), nil
+		}
+		return out, "", nil
+	}
+
+	known := false
+	for _, a := range v.IDL.Accounts {
+		known = known || a.Name == name
+	}
+	if !known {
+		if v == d.deployed {
+			return nil, "", fmt.Errorf("unknown account %s", name)
+		}
+		return nil, "no such account", nil
+	}
+	out, n, err := d.dynamic[v].DecodeAccountN(data)
+	switch {
+	case errors.Is(err, dynamic.ErrUnknownDiscriminator):
+		return nil, "wrong discriminator", nil
+	case err != nil:
+		return nil, fmt.Sprintf("layout mismatch: %v", err), nil
+	case out.Name != name:
+		return nil, "wrong discriminator", nil
+	case !isZero(data[n:]):
+		return nil, fmt.Sprintf("layout mismatch: %d bytes left over", len(data)-n), nil
+	}
+	return out, "", nil
+}
+
+// isZero reports whether data is unused account space: Anchor zero-fills the
+// space it allocates, so data a layout leaves over means the account holds a
+// longer one.
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package upgrade
+
+import (
+	"encoding/hex"
+	"sort"
+
+	"n3-solana-test/idl"
+)
+
+// Version is a release of the supernode program.
+type Version struct {
+	Name string
+	// Slot is the slot the version was deployed at, zero when unknown.
+	Slot uint64
+	IDL  *idl.IDL
+	// Current is set for the version the client package was generated from,
+	// whose accounts decode to the generated types.
+	Current bool
+}
+
+// CurrentVersion returns the version the client package was generated from.
+func CurrentVersion() *Version {
+	program := idl.Supernode()
+	return &Version{Name: program.Metadata.Version, IDL: program, Current: true}
+}
+
+// Registry lists the known versions of the program.
+type Registry struct {
+	versions []*Version
+}
+
+// NewRegistry returns a registry of versions, which should include the
+// current version.
+func NewRegistry(versions ...*Version) *Registry {
+	r := &Registry{versions: append([]*Version(nil), versions...)}
+	sort.SliceStable(r.versions, func(i, j int) bool { return r.versions[i].Slot < r.versions[j].Slot })
+	return r
+}
+
+// DefaultRegistry holds the current version only.
+func DefaultRegistry() *Registry {
+	return NewRegistry(CurrentVersion())
+}
+
+// Versions returns the versions of r ordered by deployment slot.
+func (r *Registry) Versions() []*Version {
+	return append([]*Version(nil), r.versions...)
+}
+
+// Current returns the current version of r, or nil.
+func (r *Registry) Current() *Version {
+	for _, v := range r.versions {
+		if v.Current {
+			return v
+		}
+	}
+	return nil
+}
+
+// match finds the version of a deployment. With an on-chain IDL, it is the
+// version with the same IDL, or a new unnamed version built from it. Without
+// one, it is the version deployed at the deployment's slot; a deployment
+// after every known slot is taken to be the current version. Otherwise the
+// version is unknown and match returns nil.
+func (r *Registry) match(d *Deployment) *Version {
+	if d.IDL != nil {
+		for _, v := range r.versions {
+			if idl.Hash(v.IDL) == d.IDLHash {
+				return v
+			}
+		}
+		return &Version{
+			Name: d.IDL.Metadata.Version + "+" + hex.EncodeToString(d.IDLHash[:4]),
+			Slot: d.Slot,
+			IDL:  d.IDL,
+		}
+	}
+	latest := uint64(0)
+	for _, v := range r.versions {
+		if v.Slot != 0 && v.Slot == d.Slot {
+			return v
+		}
+		latest = max(latest, v.Slot)
+	}
+	if d.Slot > latest {
+		return r.Current()
+	}
+	return nil
+}
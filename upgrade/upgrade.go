@@ -0,0 +1,128 @@
+// Package upgrade detects which version of the supernode program is deployed
+// and decodes program accounts with the matching decoder set. The deployed
+// version is identified by the hash of the IDL Anchor stores on-chain, else
+// by the slot of the last upgrade recorded in the program-data account.
+// Accounts still holding the layout of an older version decode to a
+// StatusNeedsMigration Account rather than a discriminator error.
+package upgrade
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"n3-solana-test/dynamic"
+	"n3-solana-test/idl"
+	"n3-solana-test/rpcclient"
+)
+
+// ErrNotUpgradeable is returned by Detect for programs not owned by the
+// upgradeable BPF loader.
+var ErrNotUpgradeable = errors.New("program is not upgradeable")
+
+// Upgradeable loader account states, as bincode enum tags.
+const (
+	loaderStateProgram     = 2
+	loaderStateProgramData = 3
+)
+
+// Deployment describes the deployed program.
+type Deployment struct {
+	ProgramID   solana.PublicKey
+	ProgramData solana.PublicKey
+	// Slot is the slot the program was last deployed or upgraded at.
+	Slot uint64
+	// Authority is the upgrade authority; nil for immutable programs.
+	Authority *solana.PublicKey
+	// IDL is the on-chain IDL, nil when the program has none.
+	IDL     *idl.IDL
+	IDLHash [32]byte
+	// Version is the version the deployment was matched to.
+	Version *Version
+}
+
+// Detect reads the program and program-data accounts of programID and its
+// on-chain IDL, and matches them to a version of registry.
+func Detect(ctx context.Context, c rpcclient.Client, programID solana.PublicKey, registry *Registry) (*Deployment, error) {
+	d := &Deployment{ProgramID: programID}
+	program, err := accountData(ctx, c, programID, solana.BPFLoaderUpgradeableProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("program %s: %w", programID, err)
+	}
+	if len(program) < 36 || binary.LittleEndian.Uint32(program) != loaderStateProgram {
+		return nil, fmt.Errorf("program %s: not a program account", programID)
+	}
+	d.ProgramData = solana.PublicKeyFromBytes(program[4:36])
+
+	programData, err := accountData(ctx, c, d.ProgramData, solana.BPFLoaderUpgradeableProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("program data %s: %w", d.ProgramData, err)
+	}
+	if d.Slot, d.Authority, err = parseProgramData(programData); err != nil {
+		return nil, fmt.Errorf("program data %s: %w", d.ProgramData, err)
+	}
+
+	d.IDL, err = dynamic.FetchIDL(ctx, c, programID)
+	switch {
+	case errors.Is(err, dynamic.ErrNoIDL):
+	case err != nil:
+		return nil, err
+	default:
+		d.IDLHash = idl.Hash(d.IDL)
+	}
+	d.Version = registry.match(d)
+	return d, nil
+}
+
+func accountData(ctx context.Context, c rpcclient.Client, key, owner solana.PublicKey) ([]byte, error) {
+	out, err := c.GetAccountInfo(ctx, key)
+	if errors.Is(err, rpc.ErrNotFound) || err == nil && (out == nil || out.Value == nil) {
+		return nil, fmt.Errorf("account not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !out.Value.Owner.Equals(owner) {
+		return nil, fmt.Errorf("owned by %s: %w", out.Value.Owner, ErrNotUpgradeable)
+	}
+	return out.Value.Data.GetBinary(), nil
+}
+
+// parseProgramData reads the header of a program-data account: the state tag,
+// the deployment slot and the optional upgrade authority.
+func parseProgramData(data []byte) (slot uint64, authority *solana.PublicKey, err error) {
+	if len(data) < 13 || binary.LittleEndian.Uint32(data) != loaderStateProgramData {
+		return 0, nil, fmt.Errorf("not a program data account")
+	}
+	slot = binary.LittleEndian.Uint64(data[4:12])
+	switch data[12] {
+	case 0:
+	case 1:
+		if len(data) < 45 {
+			return 0, nil, fmt.Errorf("truncated upgrade authority")
+		}
+		key := solana.PublicKeyFromBytes(data[13:45])
+		authority = &key
+	default:
+		return 0, nil, fmt.Errorf("invalid upgrade authority tag %d", data[12])
+	}
+	return slot, authority, nil
+}
+
+// EncodeProgramAccounts lays out the program and program-data accounts the
+// upgradeable loader keeps for a program deployed at slot, without the
+// program's ELF.
+func EncodeProgramAccounts(programData solana.PublicKey, slot uint64, authority *solana.PublicKey) (program, data []byte) {
+	program = binary.LittleEndian.AppendUint32(nil, loaderStateProgram)
+	program = append(program, programData[:]...)
+	data = binary.LittleEndian.AppendUint32(nil, loaderStateProgramData)
+	data = binary.LittleEndian.AppendUint64(data, slot)
+	if authority == nil {
+		return program, append(data, make([]byte, 33)...)
+	}
+	data = append(data, 1)
+	return program, append(data, authority[:]...)
+}
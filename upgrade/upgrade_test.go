@@ -0,0 +1,176 @@
+package upgrade
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	ag_binary "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	ag_require "github.com/stretchr/testify/require"
+	"n3-solana-test/client"
+	"n3-solana-test/dynamic"
+	"n3-solana-test/idl"
+	"n3-solana-test/simulator"
+)
+
+var programID = solana.MustPublicKeyFromBase58("549dKMjWhEy5GeeR9uaQmhgL9nZafPMBpv9cG1wXdzjy")
+
+// legacyVersion is a version whose ProviderStakeInfo held a single extra
+// controller.
+func legacyVersion(t *testing.T) *Version {
+	program := idl.Supernode()
+	def, ok := program.TypeDef("ProviderStakeInfo")
+	ag_require.True(t, ok)
+	def.Type.Fields = []idl.Field{
+		{Name: "extra_controller", Type: idl.Type{Primitive: "pubkey"}},
+		{Name: "devices", Type: idl.Type{Vec: &idl.Type{Defined: "DeviceState"}}},
+	}
+	program.Metadata.Version = "0.0.9"
+	return &Version{Name: "0.0.9", Slot: 100, IDL: program}
+}
+
+func deploy(t *testing.T, sim *simulator.Simulator, slot uint64, authority *solana.PublicKey, idlJSON []byte) {
+	programData, _, err := solana.FindProgramAddress([][]byte{programID[:]}, solana.BPFLoaderUpgradeableProgramID)
+	ag_require.NoError(t, err)
+	program, data := EncodeProgramAccounts(programData, slot, authority)
+	loader := solana.BPFLoaderUpgradeableProgramID
+	sim.SetAccount(programID, &simulator.Account{Lamports: 1, Owner: loader, Data: program, Executable: true})
+	sim.SetAccount(programData, &simulator.Account{Lamports: 1, Owner: loader, Data: data})
+	if idlJSON != nil {
+		address, err := dynamic.IDLAddress(programID)
+		ag_require.NoError(t, err)
+		account, err := dynamic.EncodeIDLAccount(solana.PublicKey{}, idlJSON)
+		ag_require.NoError(t, err)
+		sim.SetAccount(address, &simulator.Account{Lamports: 1, Owner: programID, Data: account})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	legacy := legacyVersion(t)
+	current := CurrentVersion()
+	registry := NewRegistry(current, legacy)
+	legacyJSON, err := json.Marshal(legacy.IDL)
+	ag_require.NoError(t, err)
+	authority := solana.NewWallet().PublicKey()
+
+	for _, c := range []struct {
+		name    string
+		slot    uint64
+		idlJSON []byte
+		want    *Version
+	}{
+		{"current by slot", 250, nil, current},
+		{"legacy by slot", 100, nil, legacy},
+		{"unknown slot", 50, nil, nil},
+		{"current by IDL", 100, idl.SupernodeJSON(), current},
+		{"legacy by IDL", 250, legacyJSON, legacy},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			sim := simulator.New(programID)
+			deploy(t, sim, c.slot, &authority, c.idlJSON)
+			d, err := Detect(context.Background(), sim, programID, registry)
+			ag_require.NoError(t, err)
+			ag_require.Equal(t, c.slot, d.Slot)
+			ag_require.Equal(t, &authority, d.Authority)
+			ag_require.Equal(t, c.want, d.Version)
+			ag_require.Equal(t, c.idlJSON != nil, d.IDL != nil)
+		})
+	}
+
+	t.Run("unknown IDL", func(t *testing.T) {
+		sim := simulator.New(programID)
+		other := idl.Supernode()
+		other.Metadata.Version = "0.2.0"
+		data, err := json.Marshal(other)
+		ag_require.NoError(t, err)
+		deploy(t, sim, 300, nil, data)
+		d, err := Detect(context.Background(), sim, programID, registry)
+		ag_require.NoError(t, err)
+		ag_require.Nil(t, d.Authority)
+		ag_require.Equal(t, uint64(300), d.Version.Slot)
+		ag_require.Contains(t, d.Version.Name, "0.2.0+")
+		ag_require.False(t, d.Version.Current)
+	})
+
+	t.Run("not upgradeable", func(t *testing.T) {
+		sim := simulator.New(programID)
+		sim.SetAccount(programID, &simulator.Account{Lamports: 1, Owner: solana.BPFLoaderProgramID, Executable: true})
+		_, err := Detect(context.Background(), sim, programID, registry)
+		ag_require.ErrorIs(t, err, ErrNotUpgradeable)
+	})
+}
+
+func encodeAccount(t *testing.T, v interface {
+	MarshalWithEncoder(*ag_binary.Encoder) error
+}) []byte {
+	buf := new(bytes.Buffer)
+	ag_require.NoError(t, v.MarshalWithEncoder(ag_binary.NewBorshEncoder(buf)))
+	return buf.Bytes()
+}
+
+func TestDecodeAccount(t *testing.T) {
+	legacy := legacyVersion(t)
+	registry := NewRegistry(CurrentVersion(), legacy)
+	decoders := registry.Decoders(registry.Current())
+
+	controller := solana.NewWallet().PublicKey()
+	info := &client.ProviderStakeInfoAccount{
+		ExtraControllers: [2]solana.PublicKey{controller},
+		Devices:          []client.DeviceState{{State: 1, SpecId: 2, StakingCoefficient: 3, Kvalue: 4}},
+	}
+	current := encodeAccount(t, info)
+
+	t.Run("current", func(t *testing.T) {
+		// Anchor zero-fills the space it allocates.
+		acct, err := decoders.DecodeAccount("ProviderStakeInfo", append(current, make([]byte, 64)...))
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, StatusCurrent, acct.Status)
+		ag_require.NoError(t, acct.Err())
+		ag_require.Equal(t, info, acct.Value)
+		ag_require.True(t, acct.Version.Current)
+	})
+
+	t.Run("legacy layout", func(t *testing.T) {
+		old := append(client.ProviderStakeInfoAccountDiscriminator[:], controller[:]...)
+		old = append(old, 0, 0, 0, 0)
+		acct, err := decoders.DecodeAccount("ProviderStakeInfo", old)
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, StatusNeedsMigration, acct.Status)
+		ag_require.Equal(t, legacy, acct.Version)
+		ag_require.Equal(t, &dynamic.Value{Name: "ProviderStakeInfo", Fields: map[string]any{
+			"extra_controller": controller,
+			"devices":          []any{},
+		}}, acct.Value)
+		ag_require.ErrorIs(t, acct.Err(), ErrNeedsMigration)
+		ag_require.Contains(t, acct.Reason, "version 0.0.9")
+	})
+
+	t.Run("left over data", func(t *testing.T) {
+		acct, err := decoders.DecodeAccount("ProviderStakeInfo", append(current, 1))
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, StatusNeedsMigration, acct.Status)
+		ag_require.Contains(t, acct.Reason, "left over")
+	})
+
+	t.Run("wrong discriminator", func(t *testing.T) {
+		acct, err := decoders.DecodeAccount("ProviderStakeInfo", encodeAccount(t, &client.TenantInfoAccount{}))
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, StatusNeedsMigration, acct.Status)
+		ag_require.Nil(t, acct.Version)
+		ag_require.Nil(t, acct.Value)
+		ag_require.Equal(t, "wrong discriminator", acct.Reason)
+	})
+
+	t.Run("legacy deployment", func(t *testing.T) {
+		acct, err := registry.Decoders(legacy).DecodeAccount("ProviderStakeInfo", current)
+		ag_require.NoError(t, err)
+		ag_require.Equal(t, StatusNeedsMigration, acct.Status)
+		ag_require.True(t, acct.Version.Current)
+		ag_require.Equal(t, info, acct.Value)
+	})
+
+	_, err := decoders.DecodeAccount("Unknown", current)
+	ag_require.Error(t, err)
+}